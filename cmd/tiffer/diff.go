@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/diff"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// Names of changes of entries.
+const (
+	ChangeType   = "type"
+	ChangeCount  = "count"
+	ChangeValue  = "value"
+	ChangeOffset = "offset"
+)
+
+const DiffUsageHint = `Usage:
+	tiffer diff [options] old-file new-file
+
+Compares IFDs, SubIFDs and their entries. IFDs are matched by their indices,
+SubIFDs by their paths and entries by their tags. Exit code is %v when the
+files differ.
+
+Options:
+`
+
+func runDiff(args []string) (exitCode int) {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), DiffUsageHint, ExitCodeFilesDiffer)
+		fs.PrintDefaults()
+	}
+
+	var format string
+	var paths listFlag
+	var opts diff.Options
+	fs.StringVar(&format, "format", FormatText, "output format: text or json")
+	fs.Var(&paths, "path", "compare only entries matching the path pattern, e.g. 'IFD0/Exif/*'; may be repeated")
+	fs.BoolVar(&opts.IgnoreOffsets, "ignore-offsets", false, "ignore changes of positions of directories and values")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(files) != 2 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+	if (format != FormatText) && (format != FormatJSON) {
+		_, _ = fmt.Fprintf(os.Stderr, ErrUnsupportedFormat+"\n", format)
+		return ExitCodeArgumentsError
+	}
+	opts.Paths = paths
+
+	var trees [2]*tiff.TIFF
+	for j, filePath := range files {
+		trees[j], err = openTIFF(filePath)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+			return ExitCodeFileError
+		}
+	}
+
+	r := diff.Compare(trees[0], trees[1], opts)
+
+	if format == FormatJSON {
+		err = writeJSON(os.Stdout, diffObject(files[0], files[1], r))
+	} else {
+		writeDiffText(os.Stdout, files[0], files[1], r)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return ExitCodeFileError
+	}
+
+	if !r.IsEmpty() {
+		return ExitCodeFilesDiffer
+	}
+
+	return ExitCodeOK
+}
+
+// diffObject builds the JSON representation of the result.
+func diffObject(oldFile string, newFile string, r *diff.Result) (doc object) {
+	doc.add("old", oldFile)
+	doc.add("new", newFile)
+	doc.add("equal", r.IsEmpty())
+
+	if r.ByteOrderChanged {
+		var byteOrder object
+		byteOrder.add("old", r.OldByteOrder.String())
+		byteOrder.add("new", r.NewByteOrder.String())
+		doc.add("byteOrder", byteOrder)
+	}
+
+	dirs := make([]any, 0, len(r.Directories))
+	for _, dd := range r.Directories {
+		var dir object
+		dir.add("kind", dd.Kind)
+		dir.add("path", dd.Path)
+		if dd.Kind != diff.KindAdded {
+			dir.add("oldOffset", dd.OldOffset)
+		}
+		if dd.Kind != diff.KindRemoved {
+			dir.add("newOffset", dd.NewOffset)
+		}
+		dirs = append(dirs, dir)
+	}
+	doc.add("directories", dirs)
+
+	entries := make([]any, 0, len(r.Entries))
+	for _, ed := range r.Entries {
+		var entry object
+		entry.add("kind", ed.Kind)
+		entry.add("path", ed.Path)
+		entry.add("tag", ed.Tag)
+		if ed.Kind == diff.KindChanged {
+			entry.add("changes", toAnySlice(entryChanges(ed)))
+		}
+		if ed.OldEntry != nil {
+			entry.add("old", entryObject(ed.OldEntry))
+		}
+		if ed.NewEntry != nil {
+			entry.add("new", entryObject(ed.NewEntry))
+		}
+		entries = append(entries, entry)
+	}
+	doc.add("entries", entries)
+
+	return doc
+}
+
+// entryObject builds the JSON representation of the compared entry.
+func entryObject(de *ifd.DirectoryEntry) (entry object) {
+	opts := &dumpOptions{maxBytes: DefaultMaxBytes, maxValues: DefaultMaxValues}
+
+	entry.add("type", t.Name(de.Type))
+	entry.add("count", de.Count)
+	if !de.HasFastValue() {
+		entry.add("offset", de.Offset)
+	}
+
+	value, shown := renderValue(de, opts)
+	entry.add("value", value)
+	if shown < itemCount(de) {
+		entry.add("truncated", true)
+	}
+
+	return entry
+}
+
+// entryChanges returns names of changes of the entry.
+func entryChanges(ed *diff.EntryDiff) (changes []string) {
+	if ed.TypeChanged {
+		changes = append(changes, ChangeType)
+	}
+	if ed.CountChanged {
+		changes = append(changes, ChangeCount)
+	}
+	if ed.ValueChanged {
+		changes = append(changes, ChangeValue)
+	}
+	if ed.OffsetMoved {
+		changes = append(changes, ChangeOffset)
+	}
+
+	return changes
+}
+
+// writeDiffText writes the result in a human-readable form. Added items are
+// marked with '+', removed items with '-' and changed items with '~'.
+func writeDiffText(w io.Writer, oldFile string, newFile string, r *diff.Result) {
+	_, _ = fmt.Fprintf(w, "--- %v\n+++ %v\n", oldFile, newFile)
+
+	if r.ByteOrderChanged {
+		_, _ = fmt.Fprintf(w, "~ byte order: %v -> %v\n", r.OldByteOrder, r.NewByteOrder)
+	}
+
+	for _, dd := range r.Directories {
+		switch dd.Kind {
+		case diff.KindAdded:
+			_, _ = fmt.Fprintf(w, "+ %v @ %v\n", dd.Path, dd.NewOffset)
+		case diff.KindRemoved:
+			_, _ = fmt.Fprintf(w, "- %v @ %v\n", dd.Path, dd.OldOffset)
+		default:
+			_, _ = fmt.Fprintf(w, "~ %v moved: %v -> %v\n", dd.Path, dd.OldOffset, dd.NewOffset)
+		}
+	}
+
+	for _, ed := range r.Entries {
+		switch ed.Kind {
+		case diff.KindAdded:
+			_, _ = fmt.Fprintf(w, "+ %v %v = %v\n", ed.Path, entryTypeText(ed.NewEntry), entryText(ed.NewEntry))
+		case diff.KindRemoved:
+			_, _ = fmt.Fprintf(w, "- %v %v = %v\n", ed.Path, entryTypeText(ed.OldEntry), entryText(ed.OldEntry))
+		default:
+			var parts []string
+			if ed.TypeChanged || ed.CountChanged {
+				parts = append(parts, fmt.Sprintf("%v -> %v", entryTypeText(ed.OldEntry), entryTypeText(ed.NewEntry)))
+			}
+			if ed.ValueChanged {
+				parts = append(parts, fmt.Sprintf("value %v -> %v", entryText(ed.OldEntry), entryText(ed.NewEntry)))
+			}
+			if ed.OffsetMoved {
+				parts = append(parts, fmt.Sprintf("offset %v -> %v", entryOffset(ed.OldEntry), entryOffset(ed.NewEntry)))
+			}
+			_, _ = fmt.Fprintf(w, "~ %v: %v\n", ed.Path, strings.Join(parts, "; "))
+		}
+	}
+}
+
+// entryTypeText returns the type and the count of the entry, e.g. 'SHORT[3]'.
+func entryTypeText(de *ifd.DirectoryEntry) string {
+	return fmt.Sprintf("%v[%v]", t.Name(de.Type), de.Count)
+}
+
+// entryOffset returns the offset of the value of the entry, or the value
+// itself for entries referring to SubIFDs.
+func entryOffset(de *ifd.DirectoryEntry) uint32 {
+	if len(de.SubIFDs) > 0 {
+		return de.ValueOrOffset
+	}
+
+	return de.Offset
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// Default limits of value sizes in the output.
+const (
+	DefaultMaxBytes  = 64
+	DefaultMaxValues = 32
+)
+
+const DumpUsageHint = `Usage:
+	tiffer dump [options] file...
+
+Prints the tree of IFDs and SubIFDs with offsets, types, counts and values of
+their entries. Exit code is %v when any of the files can not be parsed.
+
+JSON and YAML outputs use the schema of the parsed model, which is described
+by the MarshalJSON methods of the TIFF and DirectoryEntry types. Several files
+are written as a list of objects with the 'file' and 'tiff' fields, YAML
+documents are written one per file.
+
+Options:
+`
+
+const ErrUnknownTag = "unknown tag: %v"
+
+// dumpOptions are settings of the dump command.
+type dumpOptions struct {
+	format    string
+	tags      map[tag.Tag]bool
+	paths     []string
+	maxBytes  int
+	maxValues int
+}
+
+func runDump(args []string) (exitCode int) {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), DumpUsageHint, ExitCodeFileError)
+		fs.PrintDefaults()
+	}
+
+	var opts dumpOptions
+	var tagNames, paths listFlag
+	fs.StringVar(&opts.format, "format", FormatText, "output format: text, json or yaml")
+	fs.Var(&tagNames, "tag", "show only the tag, by name or number; may be repeated")
+	fs.Var(&paths, "path", "show only entries matching the path pattern, e.g. 'IFD0/Exif/*'; may be repeated")
+	fs.IntVar(&opts.maxBytes, "max-bytes", DefaultMaxBytes, "maximum number of bytes shown for blobs, 0 means no limit")
+	fs.IntVar(&opts.maxValues, "max-values", DefaultMaxValues, "maximum number of items shown for arrays, 0 means no limit")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+
+	switch opts.format {
+	case FormatText, FormatJSON, FormatYAML:
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, ErrUnsupportedFormat+"\n", opts.format)
+		return ExitCodeArgumentsError
+	}
+
+	opts.paths = paths
+	opts.tags, err = parseTags(tagNames)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return ExitCodeArgumentsError
+	}
+
+	exitCode = ExitCodeOK
+	var documents []any
+	for _, filePath := range files {
+		var tf *tiff.TIFF
+		tf, err = openTIFF(filePath)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+			exitCode = ExitCodeFileError
+			continue
+		}
+
+		if opts.format == FormatText {
+			writeDumpText(os.Stdout, dumpFile(filePath, tf, &opts))
+			continue
+		}
+
+		var doc any
+		doc, err = modelDocument(tf, &opts)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+			exitCode = ExitCodeFileError
+			continue
+		}
+		if len(files) > 1 {
+			var fileDoc object
+			fileDoc.add("file", filePath)
+			fileDoc.add("tiff", doc)
+			doc = fileDoc
+		}
+		documents = append(documents, doc)
+	}
+
+	var writeErr error
+	switch opts.format {
+	case FormatJSON:
+		var v any = documents
+		if len(files) == 1 {
+			v = nil
+			if len(documents) == 1 {
+				v = documents[0]
+			}
+		}
+		if v != nil {
+			writeErr = writeJSON(os.Stdout, v)
+		}
+	case FormatYAML:
+		for _, doc := range documents {
+			writeErr = writeYAML(os.Stdout, doc)
+			if writeErr != nil {
+				break
+			}
+		}
+	}
+	if writeErr != nil {
+		_, _ = fmt.Fprintln(os.Stderr, writeErr)
+		return ExitCodeFileError
+	}
+
+	return exitCode
+}
+
+// parseTags parses tags specified by names or numbers.
+func parseTags(names []string) (tags map[tag.Tag]bool, err error) {
+	tags = make(map[tag.Tag]bool)
+	for _, name := range names {
+		tg, ok := tiff.TagByEntryName(name)
+		if !ok {
+			return nil, fmt.Errorf(ErrUnknownTag, name)
+		}
+		tags[tg] = true
+	}
+
+	return tags, nil
+}
+
+// openTIFF parses the file.
+func openTIFF(filePath string) (tf *tiff.TIFF, err error) {
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	return tiff.New(f)
+}
+
+// modelDocument returns the JSON document of the parsed model with the
+// entries and the limits of values of the options. Fields of objects of the
+// document keep their order.
+func modelDocument(tf *tiff.TIFF, opts *dumpOptions) (doc any, err error) {
+	jsonOpts := tiff.JSONOptions{
+		MaxBytes:  opts.maxBytes,
+		MaxValues: opts.maxValues,
+	}
+	if opts.hasFilters() {
+		jsonOpts.Filter = opts.matches
+	}
+
+	var data []byte
+	data, err = tf.MarshalJSONWithOptions(jsonOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(data)
+}
+
+// dumpFile builds the text dump of the file.
+func dumpFile(filePath string, tf *tiff.TIFF, opts *dumpOptions) (doc object) {
+	doc.add("file", filePath)
+	doc.add("byteOrder", tf.Header().ByteOrder.String())
+
+	dirs := make([]any, 0)
+	for _, d := range tf.Directories() {
+		dir, ok := dumpDirectory(d, opts)
+		if ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	doc.add("ifds", dirs)
+
+	return doc
+}
+
+// dumpDirectory builds the dump of the directory. False is returned when
+// neither the directory nor its SubIFDs have entries passing the filters.
+func dumpDirectory(d *tiff.Directory, opts *dumpOptions) (dir object, ok bool) {
+	dir.add("path", d.Path)
+	dir.add("offset", d.Offset)
+
+	entries := make([]any, 0, len(d.Entries))
+	for _, de := range d.Entries {
+		entryPath := d.EntryPath(de)
+
+		var children []any
+		for _, child := range d.Children {
+			if child.Parent != de {
+				continue
+			}
+			childDir, childOK := dumpDirectory(child, opts)
+			if childOK {
+				children = append(children, childDir)
+			}
+		}
+
+		if !opts.matches(entryPath, de) && (len(children) == 0) {
+			continue
+		}
+
+		entry := dumpEntry(de, entryPath, opts)
+		if len(children) > 0 {
+			entry.add("subIFDs", children)
+		}
+		entries = append(entries, entry)
+	}
+
+	dir.add("entries", entries)
+
+	return dir, len(entries) > 0 || !opts.hasFilters()
+}
+
+// hasFilters tells whether any filters are set.
+func (opts *dumpOptions) hasFilters() bool {
+	return (len(opts.tags) > 0) || (len(opts.paths) > 0)
+}
+
+// matches tells whether the entry passes the filters.
+func (opts *dumpOptions) matches(entryPath string, de *ifd.DirectoryEntry) bool {
+	if (len(opts.tags) > 0) && !opts.tags[de.Tag] {
+		return false
+	}
+
+	return tiff.MatchAnyPath(opts.paths, entryPath)
+}
+
+// dumpEntry builds the dump of the directory entry.
+func dumpEntry(de *ifd.DirectoryEntry, entryPath string, opts *dumpOptions) (entry object) {
+	entry.add("path", entryPath)
+	entry.add("tag", de.Tag)
+	entry.add("name", tiff.EntryName(de.Tag))
+	entry.add("type", t.Name(de.Type))
+	entry.add("count", de.Count)
+	if !de.HasFastValue() {
+		entry.add("offset", de.Offset)
+	}
+
+	value, shown := renderValue(de, opts)
+	entry.add("value", value)
+	if shown < itemCount(de) {
+		entry.add("truncated", true)
+	}
+
+	return entry
+}
+
+// itemCount returns the number of items of the value as shown by renderValue.
+func itemCount(de *ifd.DirectoryEntry) int {
+	if de.Type == t.ASCII {
+		return 0
+	}
+	return int(de.Count)
+}
+
+// renderValue converts the value of the entry into a form suitable for the
+// output. Strings are shown as strings, rationals are shown as fractions,
+// undefined data is shown as a hexadecimal string. Single values are shown as
+// scalars. The number of shown items is returned.
+func renderValue(de *ifd.DirectoryEntry, opts *dumpOptions) (value any, shown int) {
+	switch de.Type {
+	case t.ASCII:
+		ss, err := de.ValueAsArrayOfString()
+		if err == nil {
+			return scalarOrArray(toAnySlice(ss)), 0
+		}
+
+		// Strings which are not terminated properly are shown as they are.
+		ba, ok := de.Value.([]byte)
+		if !ok {
+			return nil, 0
+		}
+		return strings.TrimRight(string(ba), "\x00"), 0
+
+	case t.Undefined:
+		ba, err := de.ValueAsArrayOfUndefined()
+		if err != nil {
+			return nil, 0
+		}
+		shown = limit(len(ba), opts.maxBytes)
+		return hex.EncodeToString(ba[:shown]), shown
+	}
+
+	var items []any
+	switch v := de.Value.(type) {
+	case []byte:
+		items = toAnySlice(v)
+	case []int8:
+		items = toAnySlice(v)
+	case []uint16:
+		items = toAnySlice(v)
+	case []int16:
+		items = toAnySlice(v)
+	case []uint32:
+		items = toAnySlice(v)
+	case []int32:
+		items = toAnySlice(v)
+	case []float32:
+		items = make([]any, 0, len(v))
+		for _, f := range v {
+			items = append(items, renderFloat(float64(f)))
+		}
+	case []float64:
+		items = make([]any, 0, len(v))
+		for _, f := range v {
+			items = append(items, renderFloat(f))
+		}
+	case []*big.Rat:
+		items = make([]any, 0, len(v))
+		for _, r := range v {
+			items = append(items, r.Num().String()+"/"+r.Denom().String())
+		}
+	default:
+		return nil, 0
+	}
+
+	shown = limit(len(items), opts.maxValues)
+	return scalarOrArray(items[:shown]), shown
+}
+
+// renderFloat converts special values of floats into strings, because they
+// are not supported by JSON.
+func renderFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return f
+}
+
+// limit returns the number of items to show.
+func limit(n int, max int) int {
+	if (max > 0) && (n > max) {
+		return max
+	}
+	return n
+}
+
+// scalarOrArray returns the only item of the array as a scalar.
+func scalarOrArray(items []any) any {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return items
+}
+
+// toAnySlice converts the slice into a slice of interfaces.
+func toAnySlice[T any](s []T) []any {
+	items := make([]any, 0, len(s))
+	for _, x := range s {
+		items = append(items, x)
+	}
+	return items
+}
+
+// writeDumpText writes the dump in a human-readable form.
+func writeDumpText(w io.Writer, doc object) {
+	_, _ = fmt.Fprintf(w, "File: %v\n", fieldValue(doc, "file"))
+	_, _ = fmt.Fprintf(w, "Byte order: %v\n", fieldValue(doc, "byteOrder"))
+
+	for _, dir := range fieldValue(doc, "ifds").([]any) {
+		writeDirectoryText(w, dir.(object), 0)
+	}
+}
+
+// writeDirectoryText writes the directory in a human-readable form.
+func writeDirectoryText(w io.Writer, dir object, level int) {
+	indent := strings.Repeat("  ", level)
+	_, _ = fmt.Fprintf(w, "%s%v @ %v\n", indent, fieldValue(dir, "path"), fieldValue(dir, "offset"))
+
+	for _, e := range fieldValue(dir, "entries").([]any) {
+		entry := e.(object)
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s  %v (%v) %v[%v]", indent,
+			fieldValue(entry, "name"), fieldValue(entry, "tag"),
+			fieldValue(entry, "type"), fieldValue(entry, "count")))
+		if offset := fieldValue(entry, "offset"); offset != nil {
+			sb.WriteString(fmt.Sprintf(" @ %v", offset))
+		}
+		sb.WriteString(" = " + textValue(fieldValue(entry, "value")))
+		if fieldValue(entry, "truncated") != nil {
+			sb.WriteString(" ...")
+		}
+		_, _ = fmt.Fprintln(w, sb.String())
+
+		if children, ok := fieldValue(entry, "subIFDs").([]any); ok {
+			for _, child := range children {
+				writeDirectoryText(w, child.(object), level+2)
+			}
+		}
+	}
+}
+
+// textValue returns the human-readable representation of a rendered value.
+func textValue(v any) string {
+	items, ok := v.([]any)
+	if !ok {
+		return yamlScalar(v)
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, yamlScalar(item))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// fieldValue returns the value of the field of the object, or nil.
+func fieldValue(o object, key string) any {
+	for _, f := range o {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/edit"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+)
+
+// AssignmentSeparator separates the path of an entry and its value in
+// arguments of the set command.
+const AssignmentSeparator = "="
+
+const SetUsageHint = `Usage:
+	tiffer set [options] file path=value...
+
+Sets values of tags, e.g. 'IFD0/Artist=John Doe'. Existing tags keep their
+types, numeric values are lists separated by commas, rationals may be written
+as fractions, e.g. 'IFD0/XResolution=300/1'.
+
+Options:
+`
+
+const DeleteUsageHint = `Usage:
+	tiffer delete [options] file path...
+
+Deletes tags, e.g. 'IFD0/Exif/MakerNote'. Deletion of a tag referring to a
+SubIFD, e.g. 'IFD0/GPSIFD', deletes the SubIFD.
+
+Options:
+`
+
+const CopyTagsUsageHint = `Usage:
+	tiffer copy-tags [options] source destination -tags pattern...
+
+Copies tags matching the path patterns, e.g. 'GPS/*', from the source file to
+the destination file. Offsets and sizes of data of the source file and maker
+notes are not copied.
+
+Options:
+`
+
+const EditUsageNote = `
+Files are changed by appending new IFDs, image data is not moved. The changed
+file replaces the original one only after it is written completely and parsed
+successfully.
+`
+
+const ErrAssignmentIsNotValid = "assignment is not valid, 'path=value' is expected: %v"
+
+// newEditFlagSet creates a set of flags of an editing command.
+func newEditFlagSet(name string, usageHint string, opts *edit.FileOptions) (fs *flag.FlagSet) {
+	fs = flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), usageHint)
+		fs.PrintDefaults()
+		_, _ = fmt.Fprint(fs.Output(), EditUsageNote)
+	}
+
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show changes without changing the file")
+	fs.BoolVar(&opts.Backup, "backup", false, "keep a copy of the original file with the '"+edit.BackupSuffix+"' suffix")
+
+	return fs
+}
+
+func runSet(args []string) (exitCode int) {
+	var opts edit.FileOptions
+	fs := newEditFlagSet("set", SetUsageHint, &opts)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(positional) < 2 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+
+	filePath, assignments := positional[0], positional[1:]
+	for _, a := range assignments {
+		if !strings.Contains(a, AssignmentSeparator) {
+			_, _ = fmt.Fprintf(os.Stderr, ErrAssignmentIsNotValid+"\n", a)
+			return ExitCodeArgumentsError
+		}
+	}
+
+	return editFile(filePath, &opts, func(e *edit.Editor) (err error) {
+		for _, a := range assignments {
+			entryPath, value, _ := strings.Cut(a, AssignmentSeparator)
+			err = e.Set(entryPath, value)
+			if err != nil {
+				return fmt.Errorf("%v: %v", entryPath, err)
+			}
+		}
+		return nil
+	})
+}
+
+func runDelete(args []string) (exitCode int) {
+	var opts edit.FileOptions
+	fs := newEditFlagSet("delete", DeleteUsageHint, &opts)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(positional) < 2 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+
+	filePath, entryPaths := positional[0], positional[1:]
+
+	return editFile(filePath, &opts, func(e *edit.Editor) (err error) {
+		for _, entryPath := range entryPaths {
+			err = e.Delete(entryPath)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func runCopyTags(args []string) (exitCode int) {
+	var opts edit.FileOptions
+	var patterns listFlag
+	fs := newEditFlagSet("copy-tags", CopyTagsUsageHint, &opts)
+	fs.Var(&patterns, "tags", "path pattern of tags to copy, e.g. 'GPS/*'; may be repeated")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if (len(positional) != 2) || (len(patterns) == 0) {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+
+	srcPath, dstPath := positional[0], positional[1]
+
+	var src *tiff.TIFF
+	src, err = openTIFF(srcPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", srcPath, err)
+		return ExitCodeFileError
+	}
+
+	return editFile(dstPath, &opts, func(e *edit.Editor) (err error) {
+		_, err = e.CopyTags(src, patterns)
+		return err
+	})
+}
+
+// editFile edits the file and prints the changes.
+func editFile(filePath string, opts *edit.FileOptions, fn func(e *edit.Editor) error) (exitCode int) {
+	changes, err := edit.EditFile(filePath, *opts, fn)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+		return ExitCodeFileError
+	}
+
+	for _, c := range changes {
+		_, _ = fmt.Println(describeChange(c))
+	}
+	if opts.DryRun {
+		_, _ = fmt.Printf("%v: dry run, the file is not changed\n", filePath)
+	}
+
+	return ExitCodeOK
+}
+
+// describeChange returns a human-readable description of the change.
+func describeChange(c *edit.Change) string {
+	switch {
+	case c.NewEntry == nil:
+		return fmt.Sprintf("%s %s (was %s)", c.Operation, c.Path, entryText(c.OldEntry))
+	case c.OldEntry == nil:
+		return fmt.Sprintf("%s %s = %s (new)", c.Operation, c.Path, entryText(c.NewEntry))
+	default:
+		return fmt.Sprintf("%s %s = %s (was %s)", c.Operation, c.Path, entryText(c.NewEntry), entryText(c.OldEntry))
+	}
+}
+
+// entryText returns the human-readable value of the entry.
+func entryText(de *ifd.DirectoryEntry) string {
+	opts := &dumpOptions{maxBytes: DefaultMaxBytes, maxValues: DefaultMaxValues}
+
+	value, shown := renderValue(de, opts)
+	s := textValue(value)
+	if shown < itemCount(de) {
+		s += " ..."
+	}
+
+	return s
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/layout"
+)
+
+// HexDumpLineSize is the number of bytes in a line of a hex dump.
+const HexDumpLineSize = 16
+
+const LayoutUsageHint = `Usage:
+	tiffer layout [options] file
+
+Prints the byte-level map of the file: the header, tables of entries of IFDs,
+values of entries, strips and tiles. Unreferenced gaps, overlapping regions
+and references beyond the end of the file are flagged with '!'. Exit code is
+%v when regions overlap or end beyond the end of the file.
+
+Options:
+`
+
+// layoutOptions are settings of the layout command.
+type layoutOptions struct {
+	format      string
+	hex         bool
+	flaggedOnly bool
+	maxBytes    int
+
+	// source is the file for hex dumps. It is nil when hex dumps are not
+	// requested.
+	source io.ReaderAt
+}
+
+func runLayout(args []string) (exitCode int) {
+	fs := flag.NewFlagSet("layout", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), LayoutUsageHint, ExitCodeInvalidFile)
+		fs.PrintDefaults()
+	}
+
+	var opts layoutOptions
+	fs.StringVar(&opts.format, "format", FormatText, "output format: text or json")
+	fs.BoolVar(&opts.hex, "hex", false, "print annotated hex dumps of regions")
+	fs.BoolVar(&opts.flaggedOnly, "flagged", false, "print only gaps, overlapping regions and regions beyond the end of the file")
+	fs.IntVar(&opts.maxBytes, "max-bytes", DefaultMaxBytes, "maximum number of bytes in a hex dump of a region, 0 means no limit")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+	if (opts.format != FormatText) && (opts.format != FormatJSON) {
+		_, _ = fmt.Fprintf(os.Stderr, ErrUnsupportedFormat+"\n", opts.format)
+		return ExitCodeArgumentsError
+	}
+
+	filePath := files[0]
+	var l *layout.Layout
+	l, err = layout.MapFile(filePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+		return ExitCodeFileError
+	}
+
+	if opts.format == FormatJSON {
+		err = writeJSON(os.Stdout, l)
+	} else {
+		err = writeLayoutFile(os.Stdout, filePath, l, &opts)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", filePath, err)
+		return ExitCodeFileError
+	}
+
+	if l.Errors() > 0 {
+		return ExitCodeInvalidFile
+	}
+
+	return ExitCodeOK
+}
+
+// writeLayoutFile writes the layout as text, opening the file for hex dumps
+// when they are requested.
+func writeLayoutFile(w io.Writer, filePath string, l *layout.Layout, opts *layoutOptions) (err error) {
+	if !opts.hex {
+		writeLayoutText(w, filePath, l, opts)
+		return nil
+	}
+
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	opts.source = f
+	writeLayoutText(w, filePath, l, opts)
+
+	return nil
+}
+
+// writeLayoutText writes the table of regions followed by the list of issues.
+func writeLayoutText(w io.Writer, filePath string, l *layout.Layout, opts *layoutOptions) {
+	_, _ = fmt.Fprintf(w, "%v: %v bytes, %v regions, %v issues\n", filePath, l.Size, len(l.Regions), len(l.Issues))
+	_, _ = fmt.Fprintf(w, "%-10s %-10s %10s  %-9s %s\n", "START", "END", "SIZE", "KIND", "PATH")
+
+	for _, r := range l.Regions {
+		if opts.flaggedOnly && !r.IsFlagged() {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%08x   %08x   %10d  %-9s %s%s\n", r.Start, r.End, r.Size(), r.Kind, r.Path, regionFlags(r))
+
+		if opts.source != nil {
+			writeHexDump(w, opts.source, r, opts.maxBytes)
+		}
+	}
+
+	if len(l.Issues) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(w)
+	for _, issue := range l.Issues {
+		_, _ = fmt.Fprintf(w, "%v: %08x-%08x %v: %v\n", issue.Severity, issue.Start, issue.End, issue.Kind, issue.Message)
+	}
+}
+
+// regionFlags returns marks of the flagged region.
+func regionFlags(r *layout.Region) string {
+	var flags []string
+	if r.Kind == layout.KindGap {
+		flags = append(flags, "unreferenced")
+	}
+	if r.OutOfBounds {
+		flags = append(flags, "beyond the end of the file")
+	}
+	if len(r.Overlaps) > 0 {
+		flags = append(flags, "overlaps "+strings.Join(r.Overlaps, ", "))
+	}
+
+	if len(flags) == 0 {
+		return ""
+	}
+
+	return "  ! " + strings.Join(flags, "; ")
+}
+
+// writeHexDump writes the hex dump of the region. Fields of the header and of
+// tables of entries are written one per line with their names, other regions
+// are written by lines of 16 bytes with their printable characters. Bytes
+// beyond the end of the file are not shown.
+func writeHexDump(w io.Writer, ra io.ReaderAt, r *layout.Region, maxBytes int) {
+	if len(r.Fields) > 0 {
+		for _, field := range r.Fields {
+			data := readRange(ra, field.Start, field.End)
+			_, _ = fmt.Fprintf(w, "    %08x  %-36s %s\n", field.Start, hexBytes(data), field.Name)
+		}
+		return
+	}
+
+	end := r.End
+	if (maxBytes > 0) && (r.Size() > int64(maxBytes)) {
+		end = r.Start + int64(maxBytes)
+	}
+
+	data := readRange(ra, r.Start, end)
+	for j := 0; j < len(data); j += HexDumpLineSize {
+		line := data[j:min(j+HexDumpLineSize, len(data))]
+		_, _ = fmt.Fprintf(w, "    %08x  %-48s |%s|\n", r.Start+int64(j), hexBytes(line), printable(line))
+	}
+
+	if int64(len(data)) < r.Size() {
+		_, _ = fmt.Fprintf(w, "    ... %v more bytes\n", r.Size()-int64(len(data)))
+	}
+}
+
+// readRange reads the range of bytes of the file. The result is shorter than
+// the range when the range ends beyond the end of the file.
+func readRange(ra io.ReaderAt, start int64, end int64) []byte {
+	if end <= start {
+		return nil
+	}
+
+	buf := make([]byte, end-start)
+	n, _ := ra.ReadAt(buf, start)
+
+	return buf[:n]
+}
+
+// hexBytes returns bytes as hexadecimal numbers separated by spaces.
+func hexBytes(data []byte) string {
+	parts := make([]string, 0, len(data))
+	for _, b := range data {
+		parts = append(parts, fmt.Sprintf("%02x", b))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// printable returns bytes as characters, replacing non-printable characters
+// with dots.
+func printable(data []byte) string {
+	buf := make([]byte, len(data))
+	for j, b := range data {
+		if (b >= ' ') && (b <= '~') {
+			buf[j] = b
+		} else {
+			buf[j] = '.'
+		}
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,107 @@
+// tiffer is a command-line tool for inspecting TIFF files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Exit codes.
+const (
+	ExitCodeOK             = 0
+	ExitCodeArgumentsError = 1
+	ExitCodeFileError      = 2
+	ExitCodeInvalidFile    = 3
+	ExitCodeFilesDiffer    = 4
+)
+
+const UsageHint = `Usage:
+	tiffer <command> [options] <arguments>
+
+Commands:
+%s
+Run 'tiffer <command> -h' for help on a command.
+`
+
+// command is a subcommand of the tool. It returns the exit code.
+type command struct {
+	summary string
+	run     func(args []string) (exitCode int)
+}
+
+var commands = map[string]command{
+	"copy-tags": {summary: "copy tags from one file to another", run: runCopyTags},
+	"delete":    {summary: "delete tags", run: runDelete},
+	"diff":      {summary: "compare tags of two files", run: runDiff},
+	"dump":      {summary: "print the tree of IFDs and their entries", run: runDump},
+	"layout":    {summary: "print the byte-level map of a file", run: runLayout},
+	"set":       {summary: "set values of tags", run: runSet},
+	"validate":  {summary: "check files against rule sets", run: runValidate},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) (exitCode int) {
+	if len(args) < 1 {
+		printUsage()
+		return ExitCodeArgumentsError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown command: %v\n", args[0])
+		printUsage()
+		return ExitCodeArgumentsError
+	}
+
+	return cmd.run(args[1:])
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("\t%-12s %s\n", name, commands[name].summary))
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, UsageHint, sb.String())
+}
+
+// listFlag is a flag which may be specified several times.
+type listFlag []string
+
+func (lf *listFlag) String() string {
+	return strings.Join(*lf, ",")
+}
+
+func (lf *listFlag) Set(value string) error {
+	*lf = append(*lf, value)
+	return nil
+}
+
+// parseArgs parses flags which may be mixed with positional arguments, e.g.
+// 'src.tif dst.tif -tags GPS/*', and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		err = fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+const ErrUnsupportedFormat = "unsupported format: %v"
+
+// object is a JSON object which keeps the order of its fields. Values of the
+// fields are strings, booleans, numbers, arrays ([]any) and objects.
+type object []field
+
+// field is a field of an object.
+type field struct {
+	Key   string
+	Value any
+}
+
+// add appends the field to the object.
+func (o *object) add(key string, value any) {
+	*o = append(*o, field{Key: key, Value: value})
+}
+
+// MarshalJSON encodes the object keeping the order of fields.
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for j, f := range o {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		var value []byte
+		value, err = json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes the JSON value into objects, which keep the order of
+// their fields, arrays ([]any) and scalars. Numbers are kept as they are
+// written, as json.Number.
+func decodeJSON(data []byte) (v any, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeJSONValue(dec)
+}
+
+// decodeJSONValue decodes the next value of the decoder.
+func decodeJSONValue(dec *json.Decoder) (v any, err error) {
+	var token json.Token
+	token, err = dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, isDelim := token.(json.Delim)
+	if !isDelim {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		o := object{}
+		for dec.More() {
+			token, err = dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var value any
+			value, err = decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o.add(token.(string), value)
+		}
+		v = o
+
+	default:
+		a := make([]any, 0)
+		for dec.More() {
+			var item any
+			item, err = decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, item)
+		}
+		v = a
+	}
+
+	// The closing delimiter.
+	_, err = dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// writeJSON writes the value as indented JSON.
+func writeJSON(w io.Writer, v any) (err error) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML writes the value as a YAML document. Strings are written in the
+// double-quoted style, which is compatible with JSON strings.
+func writeYAML(w io.Writer, v any) (err error) {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	writeYAMLValue(&sb, v, 0, false)
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// writeYAMLValue writes the value at the indentation level. The inline flag
+// tells that the value follows a key or a dash on the same line.
+func writeYAMLValue(sb *strings.Builder, v any, level int, inline bool) {
+	indent := strings.Repeat("  ", level)
+
+	switch x := v.(type) {
+	case object:
+		if len(x) == 0 {
+			sb.WriteString(yamlInlinePrefix(inline) + "{}\n")
+			return
+		}
+		if inline {
+			sb.WriteString("\n")
+		}
+		for _, f := range x {
+			sb.WriteString(indent + yamlString(f.Key) + ":")
+			writeYAMLValue(sb, f.Value, level+1, true)
+		}
+
+	case []any:
+		if len(x) == 0 {
+			sb.WriteString(yamlInlinePrefix(inline) + "[]\n")
+			return
+		}
+		if isScalarArray(x) {
+			parts := make([]string, 0, len(x))
+			for _, item := range x {
+				parts = append(parts, yamlScalar(item))
+			}
+			sb.WriteString(yamlInlinePrefix(inline) + "[" + strings.Join(parts, ", ") + "]\n")
+			return
+		}
+		if inline {
+			sb.WriteString("\n")
+		}
+		for _, item := range x {
+			sb.WriteString(indent + "-")
+			writeYAMLValue(sb, item, level+1, true)
+		}
+
+	default:
+		sb.WriteString(yamlInlinePrefix(inline) + yamlScalar(x) + "\n")
+	}
+}
+
+// yamlInlinePrefix returns the separator of an inline value.
+func yamlInlinePrefix(inline bool) string {
+	if inline {
+		return " "
+	}
+	return ""
+}
+
+// isScalarArray tells whether the array has no objects and no arrays.
+func isScalarArray(a []any) bool {
+	for _, item := range a {
+		switch item.(type) {
+		case object, []any:
+			return false
+		}
+	}
+	return true
+}
+
+// yamlScalar returns the YAML representation of a scalar value.
+func yamlScalar(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(x)
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		return yamlFloat(x)
+	case float32:
+		return yamlFloat(float64(x))
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
+
+// yamlString returns the double-quoted YAML representation of a string.
+func yamlString(s string) string {
+	ba, _ := json.Marshal(s)
+	return string(ba)
+}
+
+// yamlFloat returns the YAML representation of a float.
+func yamlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
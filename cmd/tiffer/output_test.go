@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON_KeepsOrder(t *testing.T) {
+	doc, err := decodeJSON([]byte(`{"z": 1, "a": [1.5, "x", null, true], "m": {"k": [], "j": {}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = writeJSON(&out, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact := strings.Join(strings.Fields(out.String()), "")
+	if compact != `{"z":1,"a":[1.5,"x",null,true],"m":{"k":[],"j":{}}}` {
+		t.Errorf("JSON is written as %v", compact)
+	}
+
+	out.Reset()
+	err = writeYAML(&out, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+"z": 1
+"a": [1.5, "x", null, true]
+"m":
+  "k": []
+  "j": {}
+`
+	if out.String() != want {
+		t.Errorf("YAML is written as\n%v", out.String())
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vault-thirteen/TIFFer/validate"
+)
+
+// Formats of validation reports.
+const (
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+// RuleSetSeparator separates names of rule sets in the list of rule sets.
+const RuleSetSeparator = ","
+
+const ValidateUsageHint = `Usage:
+	tiffer validate [options] file...
+
+Checks files against rule sets and prints a summary. A JUnit XML or SARIF
+report may be written for continuous integration systems. Exit code is %v when
+any of the files has errors and %v when any of the files can not be read.
+
+Rule sets: %v.
+
+Options:
+`
+
+func runValidate(args []string) (exitCode int) {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), ValidateUsageHint, ExitCodeInvalidFile, ExitCodeFileError,
+			strings.Join(validate.RuleSets(), ", "))
+		fs.PrintDefaults()
+	}
+
+	var ruleSets, format, output string
+	var workers int
+	var verbose bool
+	fs.StringVar(&ruleSets, "rules", validate.RuleSetBaseline, "rule sets separated by commas")
+	fs.StringVar(&format, "format", FormatText, "report format: text, junit or sarif")
+	fs.StringVar(&output, "output", "", "file of the report, the report is written to stdout by default")
+	fs.IntVar(&workers, "workers", runtime.NumCPU(), "number of files checked concurrently")
+	fs.BoolVar(&verbose, "v", false, "print issues of valid files too")
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return ExitCodeArgumentsError
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return ExitCodeArgumentsError
+	}
+
+	switch format {
+	case FormatText, FormatJUnit, FormatSARIF:
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, ErrUnsupportedFormat+"\n", format)
+		return ExitCodeArgumentsError
+	}
+
+	sets := strings.Split(ruleSets, RuleSetSeparator)
+	for j := range sets {
+		sets[j] = strings.TrimSpace(sets[j])
+		if !slices.Contains(validate.RuleSets(), sets[j]) {
+			_, _ = fmt.Fprintf(os.Stderr, validate.ErrUnknownRuleSet+"\n", sets[j])
+			return ExitCodeArgumentsError
+		}
+	}
+
+	reports, errs := validateFiles(files, sets, workers)
+
+	// The summary must not be mixed with the report.
+	summary := io.Writer(os.Stdout)
+	if (format != FormatText) && (len(output) == 0) {
+		summary = os.Stderr
+	}
+
+	exitCode = ExitCodeOK
+	var valid, invalid int
+	for j, r := range reports {
+		if errs[j] != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%v: %v\n", files[j], errs[j])
+			exitCode = ExitCodeFileError
+			continue
+		}
+
+		if r.IsValid {
+			valid++
+			_, _ = fmt.Fprintf(summary, "PASS %v (%v warning(s))\n", r.File, r.Warnings())
+		} else {
+			invalid++
+			_, _ = fmt.Fprintf(summary, "FAIL %v (%v error(s), %v warning(s))\n", r.File, r.Errors(), r.Warnings())
+			if exitCode == ExitCodeOK {
+				exitCode = ExitCodeInvalidFile
+			}
+		}
+
+		if !r.IsValid || verbose {
+			for _, issue := range r.Issues {
+				_, _ = fmt.Fprintf(summary, "  %-7s %s: %s\n", issue.Severity,
+					validate.FullRuleID(issue.RuleSet, issue.Rule), issueText(issue))
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(summary, "%v file(s) checked: %v valid, %v invalid, %v not read\n",
+		len(files), valid, invalid, len(files)-valid-invalid)
+
+	if format == FormatText {
+		return exitCode
+	}
+
+	err = writeReport(format, output, reports)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return ExitCodeFileError
+	}
+
+	return exitCode
+}
+
+// validateFiles checks files concurrently. Reports and errors are returned in
+// the order of files.
+func validateFiles(files []string, ruleSets []string, workers int) (reports []*validate.Report, errs []error) {
+	reports = make([]*validate.Report, len(files))
+	errs = make([]error, len(files))
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range indices {
+				reports[j], errs[j] = validate.ValidateFile(files[j], ruleSets)
+			}
+		}()
+	}
+
+	for j := range files {
+		indices <- j
+	}
+	close(indices)
+	wg.Wait()
+
+	return reports, errs
+}
+
+// issueText returns the message of the issue with its path.
+func issueText(issue *validate.Issue) string {
+	if len(issue.Path) == 0 {
+		return issue.Message
+	}
+
+	return issue.Path + ": " + issue.Message
+}
+
+// writeReport writes the report of valid and invalid files into the output
+// file or to stdout.
+func writeReport(format string, output string, reports []*validate.Report) (err error) {
+	checked := make([]*validate.Report, 0, len(reports))
+	for _, r := range reports {
+		if r != nil {
+			checked = append(checked, r)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if len(output) > 0 {
+		var f *os.File
+		f, err = os.Create(output)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			derr := f.Close()
+			if (derr != nil) && (err == nil) {
+				err = derr
+			}
+		}()
+
+		w = f
+	}
+
+	switch format {
+	case FormatJUnit:
+		return validate.WriteJUnit(w, checked)
+	case FormatSARIF:
+		return validate.WriteSARIF(w, checked)
+	default:
+		return fmt.Errorf(ErrUnsupportedFormat, format)
+	}
+}
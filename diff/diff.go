@@ -0,0 +1,277 @@
+// Package diff compares two parsed TIFF files.
+//
+// Directories are matched by their paths, so IFDs are matched by their
+// indices and SubIFDs by the tags which refer to them, e.g. 'IFD0/Exif'.
+// Entries of matched directories are matched by their tags.
+package diff
+
+import (
+	"bytes"
+	"slices"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Kinds of differences.
+const (
+	KindAdded   = "added"
+	KindRemoved = "removed"
+	KindChanged = "changed"
+)
+
+// Options are settings of the comparison.
+type Options struct {
+	// IgnoreOffsets flag hides entries and directories which differ only in
+	// their positions in the files. Files written by different tools almost
+	// always have different positions of data.
+	IgnoreOffsets bool
+
+	// Paths are patterns of paths of entries to compare, see tiff.MatchPath.
+	// All entries are compared when no patterns are set.
+	Paths []string
+}
+
+// Result is the result of a comparison.
+type Result struct {
+	// ByteOrderChanged flag is set when the files have different byte orders.
+	ByteOrderChanged bool
+
+	// OldByteOrder and NewByteOrder are the byte orders of the files.
+	OldByteOrder bo.ByteOrder
+	NewByteOrder bo.ByteOrder
+
+	// Directories are added, removed and moved directories.
+	Directories []*DirectoryDiff
+
+	// Entries are added, removed and changed entries in the order of
+	// directories.
+	Entries []*EntryDiff
+}
+
+// DirectoryDiff is a difference of directories.
+type DirectoryDiff struct {
+	// Kind is the kind of the difference. Changed directories are those which
+	// are moved in the file.
+	Kind string
+
+	// Path is the path of the directory.
+	Path string
+
+	// OldOffset and NewOffset are positions of the directory in the files.
+	// The offset is zero when the directory is missing.
+	OldOffset uint32
+	NewOffset uint32
+}
+
+// EntryDiff is a difference of entries.
+type EntryDiff struct {
+	// Kind is the kind of the difference.
+	Kind string
+
+	// Path is the path of the entry.
+	Path string
+
+	// Tag is the tag of the entry.
+	Tag tag.Tag
+
+	// OldEntry and NewEntry are the compared entries. OldEntry is nil for
+	// added entries, NewEntry is nil for removed entries.
+	OldEntry *ifd.DirectoryEntry
+	NewEntry *ifd.DirectoryEntry
+
+	// Flags of changes of entries.
+	TypeChanged  bool
+	CountChanged bool
+	ValueChanged bool
+	OffsetMoved  bool
+}
+
+// IsEmpty tells whether the files are equal.
+func (r *Result) IsEmpty() bool {
+	return !r.ByteOrderChanged && (len(r.Directories) == 0) && (len(r.Entries) == 0)
+}
+
+// Compare compares the old file with the new file.
+func Compare(oldTIFF *tiff.TIFF, newTIFF *tiff.TIFF, opts Options) (r *Result) {
+	r = &Result{
+		OldByteOrder: oldTIFF.Header().ByteOrder,
+		NewByteOrder: newTIFF.Header().ByteOrder,
+		Directories:  make([]*DirectoryDiff, 0),
+		Entries:      make([]*EntryDiff, 0),
+	}
+	r.ByteOrderChanged = r.OldByteOrder != r.NewByteOrder
+
+	oldDirs := directoriesByPath(oldTIFF)
+	newDirs := directoriesByPath(newTIFF)
+
+	for _, p := range mergePaths(oldDirs, newDirs) {
+		oldDir, newDir := oldDirs.byPath[p], newDirs.byPath[p]
+
+		switch {
+		case !tiff.MatchAnyPath(opts.Paths, p):
+		case newDir == nil:
+			r.Directories = append(r.Directories, &DirectoryDiff{Kind: KindRemoved, Path: p, OldOffset: oldDir.Offset})
+		case oldDir == nil:
+			r.Directories = append(r.Directories, &DirectoryDiff{Kind: KindAdded, Path: p, NewOffset: newDir.Offset})
+		case (oldDir.Offset != newDir.Offset) && !opts.IgnoreOffsets:
+			r.Directories = append(r.Directories, &DirectoryDiff{Kind: KindChanged, Path: p, OldOffset: oldDir.Offset, NewOffset: newDir.Offset})
+		}
+
+		r.compareDirectories(p, oldDir, newDir, &opts)
+	}
+
+	return r
+}
+
+// directories is an index of directories of a file.
+type directories struct {
+	paths  []string
+	byPath map[string]*tiff.Directory
+}
+
+// directoriesByPath indexes directories of the file by their paths.
+func directoriesByPath(t *tiff.TIFF) (dirs *directories) {
+	dirs = &directories{byPath: make(map[string]*tiff.Directory)}
+
+	_ = t.WalkDirectories(func(d *tiff.Directory) error {
+		dirs.paths = append(dirs.paths, d.Path)
+		dirs.byPath[d.Path] = d
+		return nil
+	})
+
+	return dirs
+}
+
+// mergePaths returns paths of directories of both files. Paths of the old
+// file go first, followed by paths which exist in the new file only.
+func mergePaths(oldDirs *directories, newDirs *directories) (paths []string) {
+	paths = slices.Clone(oldDirs.paths)
+	for _, p := range newDirs.paths {
+		_, ok := oldDirs.byPath[p]
+		if !ok {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+// compareDirectories compares entries of the directories. Any of the
+// directories may be nil.
+func (r *Result) compareDirectories(dirPath string, oldDir *tiff.Directory, newDir *tiff.Directory, opts *Options) {
+	var oldEntries, newEntries []*ifd.DirectoryEntry
+	if oldDir != nil {
+		oldEntries = oldDir.Entries
+	}
+	if newDir != nil {
+		newEntries = newDir.Entries
+	}
+
+	// Entries are listed in the order of tags of the old directory followed
+	// by tags which exist in the new directory only.
+	tags := make([]tag.Tag, 0, len(oldEntries)+len(newEntries))
+	seen := make(map[tag.Tag]bool)
+	for _, entries := range [][]*ifd.DirectoryEntry{oldEntries, newEntries} {
+		for _, de := range entries {
+			if !seen[de.Tag] {
+				seen[de.Tag] = true
+				tags = append(tags, de.Tag)
+			}
+		}
+	}
+
+	for _, tg := range tags {
+		entryPath := dirPath + tiff.PathSeparator + tiff.EntryName(tg)
+		if !tiff.MatchAnyPath(opts.Paths, entryPath) {
+			continue
+		}
+
+		oldEntry, newEntry := findEntry(oldEntries, tg), findEntry(newEntries, tg)
+		ed := &EntryDiff{
+			Path:     entryPath,
+			Tag:      tg,
+			OldEntry: oldEntry,
+			NewEntry: newEntry,
+		}
+
+		switch {
+		case newEntry == nil:
+			ed.Kind = KindRemoved
+		case oldEntry == nil:
+			ed.Kind = KindAdded
+		default:
+			ed.Kind = KindChanged
+			if !ed.compareEntries(opts) {
+				continue
+			}
+		}
+
+		r.Entries = append(r.Entries, ed)
+	}
+}
+
+// compareEntries sets flags of changes of the entries and tells whether the
+// entries differ.
+func (ed *EntryDiff) compareEntries(opts *Options) (differ bool) {
+	oldEntry, newEntry := ed.OldEntry, ed.NewEntry
+
+	ed.TypeChanged = oldEntry.Type != newEntry.Type
+	ed.CountChanged = oldEntry.Count != newEntry.Count
+	ed.OffsetMoved = oldEntry.Offset != newEntry.Offset
+
+	// Values of entries referring to SubIFDs are offsets, changes of SubIFDs
+	// themselves are reported separately.
+	if tag.IsSubIFDTag(ed.Tag) && (len(oldEntry.SubIFDs) > 0) && (len(newEntry.SubIFDs) > 0) {
+		ed.OffsetMoved = ed.OffsetMoved || (oldEntry.ValueOrOffset != newEntry.ValueOrOffset)
+	} else {
+		ed.ValueChanged = !equalValues(oldEntry, newEntry)
+	}
+
+	if opts.IgnoreOffsets {
+		ed.OffsetMoved = false
+	}
+
+	return ed.TypeChanged || ed.CountChanged || ed.ValueChanged || ed.OffsetMoved
+}
+
+// equalValues tells whether the entries have equal values. Values of
+// different numeric types are equal when they represent the same numbers.
+func equalValues(a *ifd.DirectoryEntry, b *ifd.DirectoryEntry) bool {
+	if a.Type == b.Type {
+		// Values are compared in the same byte order, so that values of files
+		// with different byte orders can be compared.
+		rawA, errA := ifd.EncodeValue(a.Type, a.Value, bo.LittleEndian)
+		rawB, errB := ifd.EncodeValue(b.Type, b.Value, bo.LittleEndian)
+		if (errA == nil) && (errB == nil) {
+			return bytes.Equal(rawA, rawB)
+		}
+	}
+
+	numbersA, errA := a.ValueAsArrayOfNumber()
+	numbersB, errB := b.ValueAsArrayOfNumber()
+	if (errA == nil) && (errB == nil) {
+		return slices.Equal(numbersA, numbersB)
+	}
+
+	bytesA, okA := a.Value.([]byte)
+	bytesB, okB := b.Value.([]byte)
+	if okA && okB {
+		return bytes.Equal(bytesA, bytesB)
+	}
+
+	return false
+}
+
+// findEntry returns the entry of the tag, or nil.
+func findEntry(entries []*ifd.DirectoryEntry, tg tag.Tag) *ifd.DirectoryEntry {
+	for _, de := range entries {
+		if de.Tag == tg {
+			return de
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,461 @@
+// Package edit changes tags of TIFF files.
+//
+// Files are edited in the append-only manner. All the original bytes of the
+// file, including image data, are kept in place, and a new chain of IFDs is
+// appended to the end of the file together with new values of the changed
+// tags. Then the header is updated to point to the new chain. Unchanged
+// values are not copied, new directory entries refer to the old values, so
+// that private structures which refer to absolute offsets, such as maker
+// notes, stay valid. Each edit makes the file a little bigger, old IFDs are
+// left in the file unreferenced.
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// Operations of changes.
+const (
+	OperationSet    = "set"
+	OperationDelete = "delete"
+)
+
+const (
+	ErrPathIsNotValid       = "path is not valid: %v"
+	ErrUnknownTag           = "unknown tag: %v"
+	ErrDirectoryIsNotFound  = "directory is not found: %v"
+	ErrEntryIsNotFound      = "entry is not found: %v"
+	ErrTypeIsNotKnown       = "type of tag %v is not known, it must be set explicitly"
+	ErrTypeIsNotAllowed     = "type %v is not allowed for tag %v"
+	ErrTagHoldsOffsets      = "tag %v holds offsets in the file and can not be set"
+	ErrTagRefersToSubIFD    = "tag %v refers to a SubIFD and can not be set"
+	ErrSubIFDCanNotBeEdited = "SubIFD of tag %v is not read and can not be edited"
+	ErrNoPatterns           = "no path patterns are specified"
+)
+
+// Tags which refer to data in the file by offsets. Their values are valid
+// only in the file they are read from.
+var offsetTags = map[tag.Tag]bool{
+	tag.StripOffsets:          true,
+	tag.TileOffsets:           true,
+	tag.FreeOffsets:           true,
+	tag.JPEGInterchangeFormat: true,
+}
+
+// Tags which are not copied between files. Byte counts describe the data
+// referred by the offset tags, which are not copied either, and a maker note
+// contains private offsets which are valid only in the file it is read from.
+var notCopiedTags = map[tag.Tag]bool{
+	tag.StripByteCounts:             true,
+	tag.TileByteCounts:              true,
+	tag.JPEGInterchangeFormatLength: true,
+	tag.MakerNote:                   true,
+}
+
+// Change is a change of a directory entry made by the editor.
+type Change struct {
+	// Operation is either OperationSet or OperationDelete.
+	Operation string
+
+	// Path is the path of the entry.
+	Path string
+
+	// OldEntry is the entry before the change. It is nil for new entries.
+	OldEntry *ifd.DirectoryEntry
+
+	// NewEntry is the entry after the change. It is nil for deleted entries.
+	NewEntry *ifd.DirectoryEntry
+}
+
+// Editor accumulates changes of tags of a TIFF file and writes the changed
+// file.
+type Editor struct {
+	source    io.ReaderAt
+	size      int64
+	byteOrder bo.ByteOrder
+	ifds      []*directory
+	dirs      map[string]*directory
+	changes   []*Change
+}
+
+// directory is an editable copy of an IFD or a SubIFD.
+type directory struct {
+	path    string
+	entries []*entry
+}
+
+// entry is an editable copy of a directory entry.
+type entry struct {
+	de *ifd.DirectoryEntry
+
+	// data is the encoded new value. It is nil for unchanged entries, which
+	// keep their original value or offset.
+	data []byte
+
+	// subIFDs is the chain of SubIFDs referred by the entry.
+	subIFDs []*directory
+}
+
+// New parses the TIFF file of the specified size and creates an editor for
+// it.
+func New(source io.ReaderAt, size int64) (e *Editor, err error) {
+	var tf *tiff.TIFF
+	tf, err = tiff.NewFromReaderAt(source, size)
+	if err != nil {
+		return nil, err
+	}
+
+	e = &Editor{
+		source:    source,
+		size:      size,
+		byteOrder: tf.Header().ByteOrder,
+		ifds:      make([]*directory, 0),
+		dirs:      make(map[string]*directory),
+		changes:   make([]*Change, 0),
+	}
+
+	for _, d := range tf.Directories() {
+		e.ifds = append(e.ifds, e.copyDirectory(d))
+	}
+
+	return e, nil
+}
+
+// copyDirectory makes an editable copy of the directory and its SubIFDs.
+func (e *Editor) copyDirectory(d *tiff.Directory) (dir *directory) {
+	dir = &directory{
+		path:    d.Path,
+		entries: make([]*entry, 0, len(d.Entries)),
+	}
+	e.dirs[dir.path] = dir
+
+	for _, de := range d.Entries {
+		en := &entry{de: de}
+		for _, child := range d.Children {
+			if child.Parent == de {
+				en.subIFDs = append(en.subIFDs, e.copyDirectory(child))
+			}
+		}
+		dir.entries = append(dir.entries, en)
+	}
+
+	return dir
+}
+
+// ByteOrder returns the byte order of the file.
+func (e *Editor) ByteOrder() bo.ByteOrder {
+	return e.byteOrder
+}
+
+// Changes returns the changes made so far.
+func (e *Editor) Changes() []*Change {
+	return e.changes
+}
+
+// Set sets the value of the entry from its textual representation, see
+// ParseValue. Existing entries keep their types. New entries get the first
+// type allowed for the tag.
+func (e *Editor) Set(entryPath string, value string) (err error) {
+	var dirPath string
+	var tg tag.Tag
+	dirPath, tg, err = splitEntryPath(entryPath)
+	if err != nil {
+		return err
+	}
+
+	var typ t.Type
+	dir, ok := e.dirs[dirPath]
+	if ok {
+		if en := dir.find(tg); en != nil {
+			typ = en.de.Type
+		}
+	}
+	if typ == 0 {
+		validTypes := ifd.ValidTypesPerTag()[tg]
+		if len(validTypes) == 0 {
+			return fmt.Errorf(ErrTypeIsNotKnown, tiff.EntryName(tg))
+		}
+		typ = validTypes[0]
+	}
+
+	var v any
+	v, err = ParseValue(typ, value)
+	if err != nil {
+		return err
+	}
+
+	return e.SetValue(entryPath, typ, v)
+}
+
+// SetValue sets the value of the entry. The value is an array of data items
+// of the same Go type which is used by the parser for decoded values of the
+// type. Missing Exif, GPS and Interoperability SubIFDs are created.
+func (e *Editor) SetValue(entryPath string, typ t.Type, value any) (err error) {
+	var dirPath string
+	var tg tag.Tag
+	dirPath, tg, err = splitEntryPath(entryPath)
+	if err != nil {
+		return err
+	}
+
+	err = checkTag(tg, typ)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	data, err = ifd.EncodeValue(typ, value, e.byteOrder)
+	if err != nil {
+		return err
+	}
+
+	var dir *directory
+	dir, err = e.directory(dirPath, true)
+	if err != nil {
+		return err
+	}
+
+	de := &ifd.DirectoryEntry{
+		Tag:     tg,
+		Type:    typ,
+		Count:   uint32(len(data) / t.ItemSize(typ)),
+		Value:   value,
+		TagName: tiff.EntryName(tg),
+	}
+
+	change := &Change{
+		Operation: OperationSet,
+		Path:      dirPath + tiff.PathSeparator + tiff.EntryName(tg),
+		NewEntry:  de,
+	}
+	if en := dir.find(tg); en != nil {
+		change.OldEntry = en.de
+	}
+
+	dir.put(&entry{de: de, data: data})
+	e.changes = append(e.changes, change)
+
+	return nil
+}
+
+// Delete deletes the entry. Deletion of an entry which refers to a SubIFD
+// deletes the SubIFD.
+func (e *Editor) Delete(entryPath string) (err error) {
+	var dirPath string
+	var tg tag.Tag
+	dirPath, tg, err = splitEntryPath(entryPath)
+	if err != nil {
+		return err
+	}
+
+	var dir *directory
+	dir, err = e.directory(dirPath, false)
+	if err != nil {
+		return err
+	}
+
+	en := dir.find(tg)
+	if en == nil {
+		return fmt.Errorf(ErrEntryIsNotFound, entryPath)
+	}
+
+	dir.remove(tg)
+	for _, si := range en.subIFDs {
+		e.forget(si)
+	}
+
+	e.changes = append(e.changes, &Change{
+		Operation: OperationDelete,
+		Path:      dirPath + tiff.PathSeparator + tiff.EntryName(tg),
+		OldEntry:  en.de,
+	})
+
+	return nil
+}
+
+// CopyTags copies entries matching any of the path patterns from the source
+// file, see tiff.MatchPath. Entries which refer to SubIFDs, hold offsets in
+// the file or sizes of the data at those offsets, maker notes and entries
+// with undecoded values are not copied. Missing Exif, GPS and
+// Interoperability SubIFDs are created. The number of copied entries is
+// returned.
+func (e *Editor) CopyTags(src *tiff.TIFF, patterns []string) (n int, err error) {
+	if len(patterns) == 0 {
+		return 0, errors.New(ErrNoPatterns)
+	}
+
+	err = src.WalkDirectories(func(d *tiff.Directory) error {
+		for _, de := range d.Entries {
+			if tag.IsSubIFDTag(de.Tag) || offsetTags[de.Tag] || notCopiedTags[de.Tag] {
+				continue
+			}
+			if de.ValueError != nil {
+				continue
+			}
+			if !tiff.MatchAnyPath(patterns, d.EntryPath(de)) {
+				continue
+			}
+
+			err = e.SetValue(d.EntryPath(de), de.Type, de.Value)
+			if err != nil {
+				return err
+			}
+			n++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// checkTag checks that the tag may be set with a value of the type.
+func checkTag(tg tag.Tag, typ t.Type) (err error) {
+	if tag.IsSubIFDTag(tg) {
+		return fmt.Errorf(ErrTagRefersToSubIFD, tiff.EntryName(tg))
+	}
+	if offsetTags[tg] {
+		return fmt.Errorf(ErrTagHoldsOffsets, tiff.EntryName(tg))
+	}
+	if t.ItemSize(typ) == 0 {
+		return fmt.Errorf(t.ErrUnknownType, typ)
+	}
+
+	validTypes, ruleExists := ifd.ValidTypesPerTag()[tg]
+	if !ruleExists {
+		return nil
+	}
+	for _, validType := range validTypes {
+		if typ == validType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(ErrTypeIsNotAllowed, t.Name(typ), tiff.EntryName(tg))
+}
+
+// splitEntryPath splits the path of an entry into the normalized path of its
+// directory and its tag.
+func splitEntryPath(entryPath string) (dirPath string, tg tag.Tag, err error) {
+	p := tiff.NormalizePathPattern(entryPath)
+
+	idx := strings.LastIndex(p, tiff.PathSeparator)
+	if idx < 0 {
+		return "", 0, fmt.Errorf(ErrPathIsNotValid, entryPath)
+	}
+
+	var ok bool
+	tg, ok = tiff.TagByEntryName(p[idx+1:])
+	if !ok {
+		return "", 0, fmt.Errorf(ErrUnknownTag, p[idx+1:])
+	}
+
+	return p[:idx], tg, nil
+}
+
+// directory returns the directory by its path. When the directory does not
+// exist and creation is allowed, a SubIFD named after its tag is created in
+// an existing parent directory.
+func (e *Editor) directory(dirPath string, create bool) (dir *directory, err error) {
+	dir, ok := e.dirs[dirPath]
+	if ok {
+		return dir, nil
+	}
+	if !create {
+		return nil, fmt.Errorf(ErrDirectoryIsNotFound, dirPath)
+	}
+
+	idx := strings.LastIndex(dirPath, tiff.PathSeparator)
+	if idx < 0 {
+		return nil, fmt.Errorf(ErrDirectoryIsNotFound, dirPath)
+	}
+
+	var parent *directory
+	parent, ok = e.dirs[dirPath[:idx]]
+	if !ok {
+		return nil, fmt.Errorf(ErrDirectoryIsNotFound, dirPath)
+	}
+
+	tg, ok := tiff.TagByEntryName(dirPath[idx+1:])
+	if !ok || !tag.IsSubIFDTag(tg) || (tiff.SubIFDName(tg) != dirPath[idx+1:]) {
+		return nil, fmt.Errorf(ErrDirectoryIsNotFound, dirPath)
+	}
+	if parent.find(tg) != nil {
+		return nil, fmt.Errorf(ErrSubIFDCanNotBeEdited, tiff.EntryName(tg))
+	}
+
+	dir = &directory{path: dirPath, entries: make([]*entry, 0)}
+	e.dirs[dirPath] = dir
+
+	de := &ifd.DirectoryEntry{
+		Tag:     tg,
+		Type:    t.Long,
+		Count:   1,
+		TagName: tiff.EntryName(tg),
+	}
+	parent.put(&entry{de: de, subIFDs: []*directory{dir}})
+
+	return dir, nil
+}
+
+// forget removes the directory and its SubIFDs from the index of paths.
+func (e *Editor) forget(dir *directory) {
+	delete(e.dirs, dir.path)
+	for _, en := range dir.entries {
+		for _, si := range en.subIFDs {
+			e.forget(si)
+		}
+	}
+}
+
+// find returns the entry of the tag, or nil.
+func (dir *directory) find(tg tag.Tag) *entry {
+	for _, en := range dir.entries {
+		if en.de.Tag == tg {
+			return en
+		}
+	}
+
+	return nil
+}
+
+// put replaces the entry having the same tag or inserts the entry keeping
+// entries sorted by tags, as required by the TIFF 6.0 Specification.
+func (dir *directory) put(en *entry) {
+	for j, old := range dir.entries {
+		if old.de.Tag == en.de.Tag {
+			dir.entries[j] = en
+			return
+		}
+	}
+
+	idx := sort.Search(len(dir.entries), func(j int) bool {
+		return dir.entries[j].de.Tag > en.de.Tag
+	})
+	dir.entries = append(dir.entries, nil)
+	copy(dir.entries[idx+1:], dir.entries[idx:])
+	dir.entries[idx] = en
+}
+
+// remove removes the entry of the tag.
+func (dir *directory) remove(tg tag.Tag) {
+	for j, en := range dir.entries {
+		if en.de.Tag == tg {
+			dir.entries = append(dir.entries[:j], dir.entries[j+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package edit
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+const testFilePath = "../test/test.tiff"
+
+// testFile returns the content of the sample TIFF file of the repository.
+func testFile(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(testFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// parse parses the file and returns its entries by their paths.
+func parse(t *testing.T, data []byte) (entries map[string]*ifd.DirectoryEntry) {
+	t.Helper()
+
+	tf, err := tiff.NewFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries = make(map[string]*ifd.DirectoryEntry)
+	_ = tf.WalkDirectories(func(d *tiff.Directory) error {
+		for _, de := range d.Entries {
+			entries[d.EntryPath(de)] = de
+		}
+		return nil
+	})
+
+	return entries
+}
+
+// sameValues tells whether the entries have equal types and values.
+func sameValues(t *testing.T, a *ifd.DirectoryEntry, b *ifd.DirectoryEntry) bool {
+	t.Helper()
+
+	if a.Type != b.Type {
+		return false
+	}
+
+	bufA, errA := ifd.EncodeValue(a.Type, a.Value, bo.LittleEndian)
+	bufB, errB := ifd.EncodeValue(b.Type, b.Value, bo.LittleEndian)
+	if (errA != nil) || (errB != nil) {
+		t.Fatalf("values can not be encoded: %v, %v", errA, errB)
+	}
+
+	return bytes.Equal(bufA, bufB)
+}
+
+func TestEditor_RoundTrip(t *testing.T) {
+	data := testFile(t)
+	original := parse(t, data)
+
+	e, err := New(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := map[string]string{
+		"IFD0/Artist":            "Somebody",
+		"IFD0/ImageDescription":  "Edited",
+		"IFD0/GPS/GPSAltitude":   "617/5",
+		"IFD0/Exif/ExposureTime": "1/250",
+	}
+	for p, value := range wanted {
+		err = e.Set(p, value)
+		if err != nil {
+			t.Fatalf("%v: %v", p, err)
+		}
+	}
+	err = e.Delete("IFD0/PageName")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Changes()) != len(wanted)+1 {
+		t.Errorf("%v changes are made", len(e.Changes()))
+	}
+
+	var out bytes.Buffer
+	err = e.Write(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything except the header is kept in place.
+	if !bytes.Equal(out.Bytes()[8:len(data)], data[8:]) {
+		t.Error("original data is changed")
+	}
+
+	edited := parse(t, out.Bytes())
+	for p, value := range wanted {
+		de, ok := edited[p]
+		if !ok {
+			t.Errorf("%v is not written", p)
+			continue
+		}
+
+		var v any
+		v, err = ParseValue(de.Type, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameValues(t, de, &ifd.DirectoryEntry{Type: de.Type, Value: v}) {
+			t.Errorf("%v is %v instead of %v", p, de.Value, value)
+		}
+	}
+	if _, ok := edited["IFD0/PageName"]; ok {
+		t.Error("IFD0/PageName is not deleted")
+	}
+
+	// Other entries are not changed, except offsets of rewritten SubIFDs.
+	for p, de := range original {
+		_, isChanged := wanted[p]
+		if isChanged || (p == "IFD0/PageName") || tag.IsSubIFDTag(de.Tag) {
+			continue
+		}
+		if _, ok := edited[p]; !ok {
+			t.Errorf("%v is lost", p)
+			continue
+		}
+		if !sameValues(t, de, edited[p]) {
+			t.Errorf("%v is changed: %v instead of %v", p, edited[p].Value, de.Value)
+		}
+	}
+	// Artist and ExposureTime are added, PageName is deleted.
+	if len(edited) != len(original)+1 {
+		t.Errorf("%v entries are written instead of %v", len(edited), len(original)+1)
+	}
+}
+
+func TestEditor_SetValue(t *testing.T) {
+	data := testFile(t)
+	e, err := New(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := []*big.Rat{big.NewRat(1, 3), big.NewRat(7, 2)}
+	err = e.SetValue("IFD0/Exif/ExposureBiasValue", typ.SRational, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = e.Write(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	de := parse(t, out.Bytes())["IFD0/Exif/ExposureBiasValue"]
+	if (de == nil) || !sameValues(t, de, &ifd.DirectoryEntry{Type: typ.SRational, Value: value}) {
+		t.Errorf("value is not written: %v", de)
+	}
+
+	// Offsets in the file and SubIFD tags can not be set.
+	for _, p := range []string{"IFD0/StripOffsets", "IFD0/ExifIFD"} {
+		err = e.SetValue(p, typ.Long, []uint32{1})
+		if err == nil {
+			t.Errorf("%v is set", p)
+		}
+	}
+}
+
+func TestEditor_CopyTags(t *testing.T) {
+	data := testFile(t)
+	src := parse(t, data)
+
+	tf, err := tiff.NewFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e *Editor
+	e, err = New(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	n, err = e.CopyTags(tf, []string{"IFD0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(e.Changes()) {
+		t.Errorf("%v entries are copied, %v changes are made", n, len(e.Changes()))
+	}
+
+	copied := make(map[string]bool)
+	for _, change := range e.Changes() {
+		copied[change.Path] = true
+	}
+	for _, p := range []string{"IFD0/XResolution", "IFD0/GPS/GPSLatitude", "IFD0/Exif/FNumber"} {
+		if !copied[p] {
+			t.Errorf("%v is not copied", p)
+		}
+	}
+	for _, p := range []string{
+		"IFD0/StripOffsets",
+		"IFD0/StripByteCounts",
+		"IFD0/ExifIFD",
+		"IFD0/Exif/MakerNote",
+	} {
+		if copied[p] {
+			t.Errorf("%v is copied", p)
+		}
+	}
+
+	var out bytes.Buffer
+	err = e.Write(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Copies of the file's own tags change nothing.
+	edited := parse(t, out.Bytes())
+	for p, de := range src {
+		if tag.IsSubIFDTag(de.Tag) {
+			continue
+		}
+		if (edited[p] == nil) || !sameValues(t, de, edited[p]) {
+			t.Errorf("%v is changed", p)
+		}
+	}
+}
+
+func TestEditFile(t *testing.T) {
+	data := testFile(t)
+	filePath := filepath.Join(t.TempDir(), "test.tiff")
+	err := os.WriteFile(filePath, data, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setArtist := func(e *Editor) error {
+		return e.Set("IFD0/Artist", "Somebody")
+	}
+
+	// A dry run collects changes only.
+	var changes []*Change
+	changes, err = EditFile(filePath, FileOptions{DryRun: true}, setArtist)
+	if (err != nil) || (len(changes) != 1) {
+		t.Fatalf("dry run: %v changes, error %v", len(changes), err)
+	}
+	if current, _ := os.ReadFile(filePath); !bytes.Equal(current, data) {
+		t.Error("dry run changes the file")
+	}
+
+	_, err = EditFile(filePath, FileOptions{Backup: true}, setArtist)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backup, current []byte
+	backup, err = os.ReadFile(filePath + BackupSuffix)
+	if (err != nil) || !bytes.Equal(backup, data) {
+		t.Errorf("backup is not valid: %v", err)
+	}
+	current, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if de := parse(t, current)["IFD0/Artist"]; de == nil {
+		t.Error("edited file has no artist")
+	}
+
+	// An edit which fails leaves the file as is.
+	_, err = EditFile(filePath, FileOptions{}, func(e *Editor) error {
+		return e.Set("IFD0/Unknown", "x")
+	})
+	if err == nil {
+		t.Error("edit of an unknown tag succeeds")
+	}
+	if after, _ := os.ReadFile(filePath); !bytes.Equal(after, current) {
+		t.Error("failed edit changes the file")
+	}
+}
@@ -0,0 +1,200 @@
+package edit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+)
+
+// BackupSuffix is appended to the name of a file to get the name of its
+// backup copy.
+const BackupSuffix = ".bak"
+
+const ErrEditedFileIsNotValid = "edited file can not be parsed, the original file is kept: %v"
+
+// FileOptions are settings of editing of files.
+type FileOptions struct {
+	// DryRun prevents changing of the file. Changes are only collected.
+	DryRun bool
+
+	// Backup makes a copy of the original file before replacing it. The copy
+	// has the BackupSuffix appended to its name.
+	Backup bool
+}
+
+// EditFile edits the file with the function and returns the changes made.
+//
+// The changed file is written into a temporary file in the same folder,
+// which is parsed again to check it, and then the temporary file replaces the
+// original file by renaming, so that the original file is either kept or
+// fully replaced. Files without changes are not rewritten.
+func EditFile(filePath string, opts FileOptions, fn func(e *Editor) error) (changes []*Change, err error) {
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	var fi os.FileInfo
+	fi, err = f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var e *Editor
+	e, err = New(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	err = fn(e)
+	if err != nil {
+		return nil, err
+	}
+
+	changes = e.Changes()
+	if opts.DryRun || (len(changes) == 0) {
+		return changes, nil
+	}
+
+	var tmpPath string
+	tmpPath, err = writeTemporaryFile(filePath, fi.Mode().Perm(), e)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	err = checkFile(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf(ErrEditedFileIsNotValid, err)
+	}
+
+	if opts.Backup {
+		err = copyFile(filePath, filePath+BackupSuffix, fi.Mode().Perm())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = os.Rename(tmpPath, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// writeTemporaryFile writes the changed file into a temporary file next to
+// the original file and returns its path.
+func writeTemporaryFile(filePath string, perm os.FileMode, e *Editor) (tmpPath string, err error) {
+	var tmp *os.File
+	tmp, err = os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpPath = tmp.Name()
+
+	defer func() {
+		derr := tmp.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	bw := bufio.NewWriter(tmp)
+	err = e.Write(bw)
+	if err != nil {
+		return "", err
+	}
+
+	err = bw.Flush()
+	if err != nil {
+		return "", err
+	}
+
+	err = tmp.Chmod(perm)
+	if err != nil {
+		return "", err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		return "", err
+	}
+
+	return tmpPath, nil
+}
+
+// checkFile parses the file.
+func checkFile(filePath string) (err error) {
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	_, err = tiff.New(f)
+	return err
+}
+
+// copyFile copies the file.
+func copyFile(srcPath string, dstPath string, perm os.FileMode) (err error) {
+	var src *os.File
+	src, err = os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		derr := src.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	var dst *os.File
+	dst, err = os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		derr := dst.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return err
+	}
+
+	return dst.Sync()
+}
@@ -0,0 +1,125 @@
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+	"github.com/vault-thirteen/auxie/NTS"
+)
+
+// ValueSeparator separates data items in textual values of numeric types.
+const ValueSeparator = ","
+
+const (
+	ErrValueIsNotValid = "value is out of range of the data item type %v: %v"
+	ErrValueIsEmpty    = "value is empty"
+)
+
+// ParseValue converts the textual value into an array of data items of the
+// type. ASCII values are taken as is and terminated with NUL, UNDEFINED values
+// are taken as raw bytes. Numeric values are lists of numbers separated by
+// commas, rationals may be written as fractions, e.g. '72/1', or as decimal
+// numbers.
+func ParseValue(typ t.Type, s string) (value any, err error) {
+	switch typ {
+	case t.ASCII:
+		return append([]byte(s), nts.NUL), nil
+	case t.Undefined:
+		return []byte(s), nil
+	}
+
+	items := strings.Split(s, ValueSeparator)
+	for j := range items {
+		items[j] = strings.TrimSpace(items[j])
+	}
+	if (len(items) == 1) && (len(items[0]) == 0) {
+		return nil, errors.New(ErrValueIsEmpty)
+	}
+
+	switch typ {
+	case t.Byte:
+		return parseItems(items, typ, func(s string) (byte, error) {
+			n, err := strconv.ParseUint(s, 0, 8)
+			return byte(n), err
+		})
+	case t.Short:
+		return parseItems(items, typ, func(s string) (uint16, error) {
+			n, err := strconv.ParseUint(s, 0, 16)
+			return uint16(n), err
+		})
+	case t.Long, t.IFD:
+		return parseItems(items, typ, func(s string) (uint32, error) {
+			n, err := strconv.ParseUint(s, 0, 32)
+			return uint32(n), err
+		})
+	case t.SByte:
+		return parseItems(items, typ, func(s string) (int8, error) {
+			n, err := strconv.ParseInt(s, 0, 8)
+			return int8(n), err
+		})
+	case t.SShort:
+		return parseItems(items, typ, func(s string) (int16, error) {
+			n, err := strconv.ParseInt(s, 0, 16)
+			return int16(n), err
+		})
+	case t.SLong:
+		return parseItems(items, typ, func(s string) (int32, error) {
+			n, err := strconv.ParseInt(s, 0, 32)
+			return int32(n), err
+		})
+	case t.Float:
+		return parseItems(items, typ, func(s string) (float32, error) {
+			f, err := strconv.ParseFloat(s, 32)
+			return float32(f), err
+		})
+	case t.Double:
+		return parseItems(items, typ, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+	case t.Rational, t.SRational:
+		return parseRationals(items, typ)
+	default:
+		return nil, fmt.Errorf(t.ErrUnknownType, typ)
+	}
+}
+
+// parseItems parses items with the parser of a single item.
+func parseItems[T any](items []string, typ t.Type, parse func(s string) (T, error)) (value []T, err error) {
+	value = make([]T, 0, len(items))
+	for _, item := range items {
+		var x T
+		x, err = parse(item)
+		if err != nil {
+			return nil, fmt.Errorf(ErrValueIsNotValid, t.Name(typ), item)
+		}
+		value = append(value, x)
+	}
+
+	return value, nil
+}
+
+// parseRationals parses rational numbers and checks that they can be
+// stored as pairs of 32-bit integers.
+func parseRationals(items []string, typ t.Type) (value []*big.Rat, err error) {
+	value = make([]*big.Rat, 0, len(items))
+	for _, item := range items {
+		r, ok := new(big.Rat).SetString(item)
+		if !ok {
+			return nil, fmt.Errorf(ErrValueIsNotValid, t.Name(typ), item)
+		}
+
+		_, _, err = ifd.RationalParts(r, typ)
+		if err != nil {
+			return nil, err
+		}
+
+		value = append(value, r)
+	}
+
+	return value, nil
+}
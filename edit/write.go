@@ -0,0 +1,226 @@
+package edit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+)
+
+// headerSize is the size of the TIFF header. The offset of the first IFD is
+// stored in its last four bytes.
+const headerSize = 8
+
+// Sizes of parts of an IFD.
+const (
+	entryCountSize = 2
+	entrySize      = 12
+)
+
+const (
+	ErrFileIsTooBig = "file is too big, offset exceeds 4 GiB: %v"
+	ErrNoIFDs       = "file has no IFDs"
+)
+
+// byteOrder is a byte order which can both put and append integers.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// writer builds the tail of the file which is appended to the original data.
+type writer struct {
+	order byteOrder
+
+	// base is the offset of the tail in the file.
+	base int64
+
+	// buf is the tail.
+	buf []byte
+}
+
+// Write writes the changed file. The original data is copied as is, with the
+// offset of the first IFD changed, and new IFDs are appended to it.
+func (e *Editor) Write(w io.Writer) (err error) {
+	if len(e.ifds) == 0 {
+		return errors.New(ErrNoIFDs)
+	}
+
+	tw := &writer{
+		// Values and IFDs must begin on a word boundary.
+		base: e.size + e.size%2,
+	}
+	switch e.byteOrder {
+	case bo.BigEndian:
+		tw.order = binary.BigEndian
+	case bo.LittleEndian:
+		tw.order = binary.LittleEndian
+	default:
+		return fmt.Errorf(bo.ErrUnsupportedBO, e.byteOrder)
+	}
+
+	var firstIFDOffset uint32
+	firstIFDOffset, err = tw.writeChain(e.ifds)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerSize)
+	_, err = e.source.ReadAt(header, 0)
+	if err != nil {
+		return err
+	}
+	tw.order.PutUint32(header[headerSize-4:], firstIFDOffset)
+
+	_, err = w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, io.NewSectionReader(e.source, headerSize, e.size-headerSize))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(make([]byte, tw.base-e.size))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(tw.buf)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// offset returns the offset of the end of the tail in the file.
+func (tw *writer) offset() (offset uint32, err error) {
+	o := tw.base + int64(len(tw.buf))
+	if o > math.MaxUint32 {
+		return 0, fmt.Errorf(ErrFileIsTooBig, o)
+	}
+
+	return uint32(o), nil
+}
+
+// align makes the tail end on a word boundary.
+func (tw *writer) align() {
+	if len(tw.buf)%2 != 0 {
+		tw.buf = append(tw.buf, 0)
+	}
+}
+
+// writeChain writes the chain of directories and returns the offset of the
+// first one.
+func (tw *writer) writeChain(dirs []*directory) (firstOffset uint32, err error) {
+	var prevNextPos = -1
+	for j, dir := range dirs {
+		var offset uint32
+		var nextPos int
+		offset, nextPos, err = tw.writeDirectory(dir)
+		if err != nil {
+			return 0, err
+		}
+
+		if j == 0 {
+			firstOffset = offset
+		} else {
+			tw.order.PutUint32(tw.buf[prevNextPos:], offset)
+		}
+		prevNextPos = nextPos
+	}
+
+	return firstOffset, nil
+}
+
+// writeSeparately writes directories which are not chained and returns
+// their offsets encoded as an array of longs.
+func (tw *writer) writeSeparately(dirs []*directory) (offsets []byte, err error) {
+	offsets = make([]byte, 0, len(dirs)*4)
+	for _, dir := range dirs {
+		var offset uint32
+		offset, _, err = tw.writeDirectory(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		offsets = tw.order.AppendUint32(offsets, offset)
+	}
+
+	return offsets, nil
+}
+
+// writeDirectory writes the directory, values of its changed entries and its
+// SubIFDs. The offset of the directory and the position of the offset of the
+// next directory in the tail are returned.
+func (tw *writer) writeDirectory(dir *directory) (offset uint32, nextPos int, err error) {
+	tw.align()
+	offset, err = tw.offset()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := len(tw.buf)
+	tw.buf = tw.order.AppendUint16(tw.buf, uint16(len(dir.entries)))
+	for _, en := range dir.entries {
+		tw.buf = tw.order.AppendUint16(tw.buf, en.de.Tag)
+		tw.buf = tw.order.AppendUint16(tw.buf, en.de.Type)
+		tw.buf = tw.order.AppendUint32(tw.buf, en.de.Count)
+		tw.buf = tw.order.AppendUint32(tw.buf, en.de.ValueOrOffset)
+	}
+	nextPos = len(tw.buf)
+	tw.buf = tw.order.AppendUint32(tw.buf, ifd.LastIFDOffsetOfNextIFD)
+
+	for j, en := range dir.entries {
+		valuePos := start + entryCountSize + j*entrySize + entrySize - 4
+
+		data := en.data
+		if len(en.subIFDs) > 0 {
+			if en.de.HasSubIFDChain() {
+				var subIFDOffset uint32
+				subIFDOffset, err = tw.writeChain(en.subIFDs)
+				if err != nil {
+					return 0, 0, err
+				}
+				tw.order.PutUint32(tw.buf[valuePos:], subIFDOffset)
+				continue
+			}
+
+			// Each offset refers to its own SubIFD, offsets are stored as
+			// the value of the entry.
+			data, err = tw.writeSeparately(en.subIFDs)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		if data == nil {
+			continue
+		}
+
+		// Fast values are left-justified.
+		if len(data) <= ifd.FastValueLimitSize {
+			var fast [ifd.FastValueLimitSize]byte
+			copy(fast[:], data)
+			copy(tw.buf[valuePos:], fast[:])
+			continue
+		}
+
+		tw.align()
+		var valueOffset uint32
+		valueOffset, err = tw.offset()
+		if err != nil {
+			return 0, 0, err
+		}
+		tw.buf = append(tw.buf, data...)
+		tw.order.PutUint32(tw.buf[valuePos:], valueOffset)
+	}
+
+	return offset, nextPos, nil
+}
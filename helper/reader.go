@@ -1,66 +1,77 @@
+// Package helper reads single data items from streams.
+//
+// Deprecated: values of entries are decoded in bulk by the 'ifd' package,
+// use ifd.DecodeValue instead. Functions of this package forward to it.
 package helper
 
 import (
 	"math/big"
 
-	bt "github.com/vault-thirteen/TIFFer/models/basic-types"
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
 	"github.com/vault-thirteen/auxie/rs"
 )
 
 // ReadASCII reads an ASCII byte.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadASCII(rs *rs.ReaderSeeker) (b byte, err error) {
 	return rs.ReadByte()
 }
 
 // ReadUndefined reads Undefined type item.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadUndefined(rs *rs.ReaderSeeker) (b byte, err error) {
 	return rs.ReadByte()
 }
 
 // ReadRational_BE reads a Rational using the big endian technique.
-// This method is not fully compatible with TIFF 6.0 Specification.
+// A zero denominator is an error.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadRational_BE(rs *rs.ReaderSeeker) (rat *big.Rat, err error) {
-	return ReadSRational_BE(rs)
+	return readRational(rs, t.Rational, bo.BigEndian)
 }
 
 // ReadRational_LE reads a Rational using the little endian technique.
-// This method is not fully compatible with TIFF 6.0 Specification.
+// A zero denominator is an error.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadRational_LE(rs *rs.ReaderSeeker) (rat *big.Rat, err error) {
-	return ReadSRational_LE(rs)
+	return readRational(rs, t.Rational, bo.LittleEndian)
 }
 
 // ReadSRational_BE reads an SRational using the big endian technique.
-// This method is not fully compatible with TIFF 6.0 Specification.
+// A zero denominator is an error.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadSRational_BE(rs *rs.ReaderSeeker) (rat *big.Rat, err error) {
-	var numerator bt.DWord
-	numerator, err = rs.ReadDWord_BE()
-	if err != nil {
-		return rat, err
-	}
-
-	var denominator bt.DWord
-	denominator, err = rs.ReadDWord_BE()
-	if err != nil {
-		return rat, err
-	}
-
-	return big.NewRat(int64(numerator), int64(denominator)), nil
+	return readRational(rs, t.SRational, bo.BigEndian)
 }
 
 // ReadSRational_LE reads an SRational using the little endian technique.
-// This method is not fully compatible with TIFF 6.0 Specification.
+// A zero denominator is an error.
+//
+// Deprecated: use ifd.DecodeValue.
 func ReadSRational_LE(rs *rs.ReaderSeeker) (rat *big.Rat, err error) {
-	var numerator bt.DWord
-	numerator, err = rs.ReadDWord_LE()
+	return readRational(rs, t.SRational, bo.LittleEndian)
+}
+
+// readRational reads a single rational of the type.
+func readRational(rs *rs.ReaderSeeker, typ t.Type, byteOrder bo.ByteOrder) (rat *big.Rat, err error) {
+	var buf []byte
+	buf, err = rs.Read8Bytes()
 	if err != nil {
-		return rat, err
+		return nil, err
 	}
 
-	var denominator bt.DWord
-	denominator, err = rs.ReadDWord_LE()
+	var value any
+	value, err = ifd.DecodeValue(typ, buf, byteOrder)
 	if err != nil {
-		return rat, err
+		return nil, err
 	}
 
-	return big.NewRat(int64(numerator), int64(denominator)), nil
+	return value.([]*big.Rat)[0], nil
 }
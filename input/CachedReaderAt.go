@@ -0,0 +1,315 @@
+package input
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Default settings of the block cache.
+const (
+	DefaultBlockSize = 64 * 1024
+	DefaultMaxBlocks = 256
+	DefaultReadAhead = 0
+)
+
+const (
+	ErrNegativeOffset   = "negative offset"
+	ErrBlockSizeIsWrong = "block size must be positive"
+	ErrMaxBlocksIsWrong = "maximum number of blocks must be positive"
+	ErrReadAheadIsWrong = "read-ahead must not be negative"
+	ErrSizeIsNegative   = "size must not be negative"
+	ErrSourceIsMissing  = "source is missing"
+	ErrShortSourceRead  = "source returned less data than expected"
+)
+
+// CacheOptions are settings of the block cache.
+type CacheOptions struct {
+	// BlockSize is the size of a cached block, in bytes.
+	BlockSize int64
+
+	// MaxBlocks is the maximum number of blocks stored in the cache. Least
+	// recently used blocks are evicted first.
+	MaxBlocks int
+
+	// ReadAhead is the number of blocks which are additionally read after the
+	// requested ones.
+	ReadAhead int
+}
+
+// DefaultCacheOptions returns the default settings of the block cache.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		BlockSize: DefaultBlockSize,
+		MaxBlocks: DefaultMaxBlocks,
+		ReadAhead: DefaultReadAhead,
+	}
+}
+
+// CachedReaderAt is an io.ReaderAt which reads the underlying source by
+// aligned blocks and keeps recently used blocks in memory.
+//
+// When a read touches several blocks which are not cached, all of them are
+// requested from the source with a single read. This way many small reads
+// made by the parser turn into a few big reads, which is important for
+// sources with a high latency, such as HTTP servers.
+//
+// CachedReaderAt is safe for concurrent use. The source is read without
+// holding the lock of the cache, so reads of cached blocks are not delayed by
+// reads of other blocks from the source. Concurrent reads which need the same
+// missing block wait for a single request of it.
+type CachedReaderAt struct {
+	source  io.ReaderAt
+	size    int64
+	options CacheOptions
+
+	lock     sync.Mutex
+	blocks   map[int64]*list.Element
+	lru      *list.List
+	inFlight map[int64]*blockFetch
+
+	counters counters
+}
+
+// cachedBlock is a block stored in the cache.
+type cachedBlock struct {
+	index int64
+	data  []byte
+}
+
+// blockFetch is a read of a run of blocks from the source, which is in
+// progress. The data and the error may be used after the done channel is
+// closed.
+type blockFetch struct {
+	first int64
+	last  int64
+	data  []byte
+	err   error
+	done  chan struct{}
+}
+
+// NewCachedReaderAt creates a caching reader of the source having the
+// specified size.
+func NewCachedReaderAt(source io.ReaderAt, size int64, options CacheOptions) (cra *CachedReaderAt, err error) {
+	if source == nil {
+		return nil, errors.New(ErrSourceIsMissing)
+	}
+	if size < 0 {
+		return nil, errors.New(ErrSizeIsNegative)
+	}
+	if options.BlockSize <= 0 {
+		return nil, errors.New(ErrBlockSizeIsWrong)
+	}
+	if options.MaxBlocks <= 0 {
+		return nil, errors.New(ErrMaxBlocksIsWrong)
+	}
+	if options.ReadAhead < 0 {
+		return nil, errors.New(ErrReadAheadIsWrong)
+	}
+
+	cra = &CachedReaderAt{
+		source:   source,
+		size:     size,
+		options:  options,
+		blocks:   make(map[int64]*list.Element),
+		lru:      list.New(),
+		inFlight: make(map[int64]*blockFetch),
+	}
+
+	return cra, nil
+}
+
+// Size returns the size of the source.
+func (cra *CachedReaderAt) Size() int64 {
+	return cra.size
+}
+
+// Stats returns the usage statistics. Requests and bytes are those sent to
+// and received from the underlying source.
+func (cra *CachedReaderAt) Stats() Stats {
+	return cra.counters.snapshot()
+}
+
+// ReadAt reads len(p) bytes starting at the offset.
+func (cra *CachedReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New(ErrNegativeOffset)
+	}
+	if off >= cra.size {
+		return 0, io.EOF
+	}
+
+	end := off + int64(len(p))
+	if end > cra.size {
+		end = cra.size
+		err = io.EOF
+	}
+	if end == off {
+		return 0, err
+	}
+
+	bs := cra.options.BlockSize
+	first, last := off/bs, (end-1)/bs
+
+	// Reads which do not fit into the cache bypass it.
+	if last-first+1+int64(cra.options.ReadAhead) > int64(cra.options.MaxBlocks) {
+		return cra.readDirectly(p[:end-off], off, err)
+	}
+
+	data := make([][]byte, last-first+1)
+	pending, fetches := cra.plan(first, last, data)
+	for _, bf := range fetches {
+		cra.readBlocks(bf)
+	}
+
+	for idx, bf := range pending {
+		<-bf.done
+		if bf.err != nil {
+			return 0, bf.err
+		}
+		data[idx-first] = bf.block(idx, bs)
+	}
+
+	for idx := first; idx <= last; idx++ {
+		blockStart := idx * bs
+		from := max(off, blockStart) - blockStart
+		to := min(end, blockStart+int64(len(data[idx-first]))) - blockStart
+		n += copy(p[n:], data[idx-first][from:to])
+	}
+
+	return n, err
+}
+
+// plan finds blocks of the range in the cache and puts their data into the
+// list of data of blocks. Missing blocks are returned as pending ones, by
+// their indices, together with the fetches which will read them. Blocks which
+// are already being read by other reads are waited for, sequences of other
+// missing blocks are planned as new fetches, each of them to be read from the
+// source with a single request. The caller must perform the new fetches.
+func (cra *CachedReaderAt) plan(first, last int64, data [][]byte) (pending map[int64]*blockFetch, fetches []*blockFetch) {
+	cra.lock.Lock()
+	defer cra.lock.Unlock()
+
+	pending = make(map[int64]*blockFetch)
+	lastBlock := (cra.size - 1) / cra.options.BlockSize
+
+	idx := first
+	for idx <= last {
+		if element, ok := cra.blocks[idx]; ok {
+			cra.lru.MoveToFront(element)
+			cra.counters.cacheHits.Add(1)
+			data[idx-first] = element.Value.(*cachedBlock).data
+			idx++
+			continue
+		}
+		if bf, ok := cra.inFlight[idx]; ok {
+			cra.counters.cacheMisses.Add(1)
+			pending[idx] = bf
+			idx++
+			continue
+		}
+
+		// Collect the run of missing blocks.
+		runEnd := idx
+		for (runEnd+1 <= last) && cra.isMissing(runEnd+1) {
+			runEnd++
+		}
+		cra.counters.cacheMisses.Add(runEnd - idx + 1)
+
+		// Extend the run with read-ahead blocks which are missing too.
+		readEnd := runEnd
+		for j := 0; j < cra.options.ReadAhead; j++ {
+			if (readEnd+1 > lastBlock) || !cra.isMissing(readEnd+1) {
+				break
+			}
+			readEnd++
+		}
+
+		bf := &blockFetch{first: idx, last: readEnd, done: make(chan struct{})}
+		for j := idx; j <= readEnd; j++ {
+			cra.inFlight[j] = bf
+		}
+		for j := idx; j <= runEnd; j++ {
+			pending[j] = bf
+		}
+		fetches = append(fetches, bf)
+
+		idx = runEnd + 1
+	}
+
+	return pending, fetches
+}
+
+// readDirectly reads the data from the source without the cache.
+func (cra *CachedReaderAt) readDirectly(p []byte, off int64, eofErr error) (n int, err error) {
+	n, err = cra.source.ReadAt(p, off)
+	cra.counters.requests.Add(1)
+	cra.counters.bytes.Add(int64(n))
+	if n < len(p) {
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, errors.New(ErrShortSourceRead)
+		}
+		return n, err
+	}
+
+	return n, eofErr
+}
+
+// isMissing tells whether the block is neither cached nor being read.
+func (cra *CachedReaderAt) isMissing(idx int64) bool {
+	_, isCached := cra.blocks[idx]
+	_, isInFlight := cra.inFlight[idx]
+	return !isCached && !isInFlight
+}
+
+// readBlocks reads the blocks of the fetch from the source with a single
+// request, puts them into the cache and wakes up reads waiting for them.
+func (cra *CachedReaderAt) readBlocks(bf *blockFetch) {
+	bs := cra.options.BlockSize
+	start := bf.first * bs
+	end := min((bf.last+1)*bs, cra.size)
+
+	buf := make([]byte, end-start)
+	n, err := cra.source.ReadAt(buf, start)
+	cra.counters.requests.Add(1)
+	cra.counters.bytes.Add(int64(n))
+	if n < len(buf) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = errors.New(ErrShortSourceRead)
+		}
+	} else {
+		err = nil
+	}
+	bf.data, bf.err = buf, err
+
+	cra.lock.Lock()
+	for idx := bf.first; idx <= bf.last; idx++ {
+		delete(cra.inFlight, idx)
+		if bf.err == nil {
+			cra.store(idx, bf.block(idx, bs))
+		}
+	}
+	cra.lock.Unlock()
+
+	close(bf.done)
+}
+
+// block returns the data of the block read by the fetch.
+func (bf *blockFetch) block(idx int64, blockSize int64) (data []byte) {
+	from := (idx - bf.first) * blockSize
+	to := min(from+blockSize, int64(len(bf.data)))
+	return bf.data[from:to]
+}
+
+// store puts the block into the cache, evicting the least recently used
+// blocks when the cache is full.
+func (cra *CachedReaderAt) store(idx int64, data []byte) {
+	for cra.lru.Len() >= cra.options.MaxBlocks {
+		oldest := cra.lru.Back()
+		cra.lru.Remove(oldest)
+		delete(cra.blocks, oldest.Value.(*cachedBlock).index)
+	}
+
+	cra.blocks[idx] = cra.lru.PushFront(&cachedBlock{index: idx, data: data})
+}
@@ -0,0 +1,122 @@
+package input
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedSource is a source which blocks reads at the gated offset until the
+// gate is opened.
+type gatedSource struct {
+	data      []byte
+	gatedOff  int64
+	gate      chan struct{}
+	started   chan struct{}
+	requests  atomic.Int64
+	startOnce sync.Once
+}
+
+func (gs *gatedSource) ReadAt(p []byte, off int64) (n int, err error) {
+	gs.requests.Add(1)
+	if off == gs.gatedOff {
+		gs.startOnce.Do(func() { close(gs.started) })
+		<-gs.gate
+	}
+
+	return copy(p, gs.data[off:]), nil
+}
+
+func newGatedSource(size int, gatedOff int64) *gatedSource {
+	data := make([]byte, size)
+	for j := range data {
+		data[j] = byte(j * 7)
+	}
+
+	return &gatedSource{
+		data:     data,
+		gatedOff: gatedOff,
+		gate:     make(chan struct{}),
+		started:  make(chan struct{}),
+	}
+}
+
+func TestCachedReaderAt_ReadAt(t *testing.T) {
+	gs := newGatedSource(1000, -1)
+	cra, err := NewCachedReaderAt(gs, int64(len(gs.data)), CacheOptions{BlockSize: 64, MaxBlocks: 4, ReadAhead: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, off := range []int64{0, 10, 60, 500, 900, 999, 3} {
+		p := make([]byte, 100)
+		n, _ := cra.ReadAt(p, off)
+		want := gs.data[off:min(off+100, int64(len(gs.data)))]
+		if !bytes.Equal(p[:n], want) {
+			t.Errorf("ReadAt(100, %v) returned wrong data", off)
+		}
+	}
+
+	// Reads larger than the cache bypass it.
+	p := make([]byte, 600)
+	n, err := cra.ReadAt(p, 100)
+	if (err != nil) || !bytes.Equal(p[:n], gs.data[100:700]) {
+		t.Errorf("big read returned wrong data: %v", err)
+	}
+}
+
+func TestCachedReaderAt_FetchDoesNotBlockCachedReads(t *testing.T) {
+	const blockSize = 64
+	gs := newGatedSource(blockSize*4, 2*blockSize)
+	cra, err := NewCachedReaderAt(gs, int64(len(gs.data)), CacheOptions{BlockSize: blockSize, MaxBlocks: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first block is cached.
+	_, err = cra.ReadAt(make([]byte, 1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two reads of the gated block wait for a single request of it.
+	var wg sync.WaitGroup
+	results := make([][]byte, 2)
+	for j := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[j] = make([]byte, 8)
+			_, _ = cra.ReadAt(results[j], 2*blockSize)
+		}()
+	}
+	<-gs.started
+
+	// The cached block is read while the fetch of the gated one is blocked.
+	done := make(chan struct{})
+	go func() {
+		_, _ = cra.ReadAt(make([]byte, 1), 1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("read of a cached block waits for a fetch of another block")
+	}
+
+	// Let the second reader reach the wait for the fetch.
+	time.Sleep(10 * time.Millisecond)
+	close(gs.gate)
+	wg.Wait()
+
+	for _, result := range results {
+		if !bytes.Equal(result, gs.data[2*blockSize:2*blockSize+8]) {
+			t.Error("read of the gated block returned wrong data")
+		}
+	}
+	if requests := gs.requests.Load(); requests != 2 {
+		t.Errorf("%v requests were sent instead of 2", requests)
+	}
+}
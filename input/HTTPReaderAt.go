@@ -0,0 +1,236 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrUnexpectedStatus      = "unexpected HTTP status: %v"
+	ErrContentRangeIsMissing = "Content-Range header is missing"
+	ErrContentRangeIsWrong   = "Content-Range header is not valid: %v"
+	ErrContentRangeMismatch  = "server returned bytes %v-%v instead of %v-%v"
+	ErrRangesAreNotSupported = "server does not support range requests any more"
+)
+
+// HTTP headers and values used by range requests.
+const (
+	HeaderRange        = "Range"
+	HeaderContentRange = "Content-Range"
+	RangeUnitBytes     = "bytes"
+)
+
+// HTTPReaderAt is an io.ReaderAt which reads a remote file using HTTP range
+// requests. Each call of ReadAt makes a single request, so it is normally
+// wrapped into a CachedReaderAt. Ranges of responses are checked against the
+// requested ones.
+//
+// When the server does not support range requests, it sends the whole file
+// in response to the first request. The file is then kept in memory and all
+// reads are served from it.
+//
+// HTTPReaderAt is safe for concurrent use.
+type HTTPReaderAt struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+
+	// body is the whole file sent by a server which ignores ranges.
+	body []byte
+
+	counters counters
+}
+
+// NewHTTPReaderAt creates a reader of the remote file. The size of the file
+// is requested with a range request of the first byte, because object
+// storages often do not allow HEAD requests for pre-signed URLs. The context
+// is used for all requests of the reader. If the client is nil, the default
+// HTTP client is used.
+func NewHTTPReaderAt(ctx context.Context, client *http.Client, url string) (hra *HTTPReaderAt, err error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	hra = &HTTPReaderAt{
+		ctx:    ctx,
+		client: client,
+		url:    url,
+	}
+
+	var resp *http.Response
+	resp, err = hra.get(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			err = errors.Join(err, derr)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var cr *contentRange
+		cr, err = parseContentRange(resp.Header.Get(HeaderContentRange))
+		if err != nil {
+			return nil, err
+		}
+		if cr.size < 0 {
+			return nil, fmt.Errorf(ErrContentRangeIsWrong, resp.Header.Get(HeaderContentRange))
+		}
+		hra.size = cr.size
+
+	case http.StatusOK:
+		// The server ignores ranges and sends the whole file.
+		hra.body, err = io.ReadAll(resp.Body)
+		hra.counters.bytes.Add(int64(len(hra.body)))
+		if err != nil {
+			return nil, err
+		}
+		hra.size = int64(len(hra.body))
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The file is empty.
+		hra.size = 0
+
+	default:
+		return nil, fmt.Errorf(ErrUnexpectedStatus, resp.Status)
+	}
+
+	return hra, nil
+}
+
+// Size returns the size of the remote file.
+func (hra *HTTPReaderAt) Size() int64 {
+	return hra.size
+}
+
+// Stats returns the usage statistics.
+func (hra *HTTPReaderAt) Stats() Stats {
+	return hra.counters.snapshot()
+}
+
+// ReadAt reads len(p) bytes starting at the offset with a single range
+// request.
+func (hra *HTTPReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New(ErrNegativeOffset)
+	}
+	if off >= hra.size {
+		return 0, io.EOF
+	}
+
+	end := off + int64(len(p))
+	var eofErr error
+	if end > hra.size {
+		end = hra.size
+		eofErr = io.EOF
+	}
+	if end == off {
+		return 0, eofErr
+	}
+
+	if hra.body != nil {
+		n = copy(p, hra.body[off:end])
+		return n, eofErr
+	}
+
+	var resp *http.Response
+	resp, err = hra.get(off, end-1)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		derr := resp.Body.Close()
+		if derr != nil {
+			err = errors.Join(err, derr)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var cr *contentRange
+		cr, err = parseContentRange(resp.Header.Get(HeaderContentRange))
+		if err != nil {
+			return 0, err
+		}
+		if (cr.start != off) || (cr.end != end-1) {
+			return 0, fmt.Errorf(ErrContentRangeMismatch, cr.start, cr.end, off, end-1)
+		}
+	case http.StatusOK:
+		return 0, errors.New(ErrRangesAreNotSupported)
+	default:
+		return 0, fmt.Errorf(ErrUnexpectedStatus, resp.Status)
+	}
+
+	n, err = io.ReadFull(resp.Body, p[:end-off])
+	hra.counters.bytes.Add(int64(n))
+	if err != nil {
+		return n, err
+	}
+
+	return n, eofErr
+}
+
+// get sends a range request of bytes from start to end, inclusive.
+func (hra *HTTPReaderAt) get(start, end int64) (resp *http.Response, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(hra.ctx, http.MethodGet, hra.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(HeaderRange, fmt.Sprintf("%s=%d-%d", RangeUnitBytes, start, end))
+
+	hra.counters.requests.Add(1)
+	return hra.client.Do(req)
+}
+
+// contentRange is the value of the Content-Range header. The size is -1
+// when it is unknown.
+type contentRange struct {
+	start int64
+	end   int64
+	size  int64
+}
+
+// parseContentRange parses the Content-Range header, e.g.
+// 'bytes 0-0/12345'. Start and end of the range are inclusive.
+func parseContentRange(header string) (cr *contentRange, err error) {
+	if len(header) == 0 {
+		return nil, errors.New(ErrContentRangeIsMissing)
+	}
+
+	rangeText, found := strings.CutPrefix(header, RangeUnitBytes+" ")
+	if !found {
+		return nil, fmt.Errorf(ErrContentRangeIsWrong, header)
+	}
+	rangeText, sizeText, ok1 := strings.Cut(rangeText, "/")
+	startText, endText, ok2 := strings.Cut(rangeText, "-")
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf(ErrContentRangeIsWrong, header)
+	}
+
+	cr = &contentRange{size: -1}
+	var err1, err2, err3 error
+	cr.start, err1 = strconv.ParseInt(startText, 10, 64)
+	cr.end, err2 = strconv.ParseInt(endText, 10, 64)
+	if sizeText != "*" {
+		cr.size, err3 = strconv.ParseInt(sizeText, 10, 64)
+	}
+	if (err1 != nil) || (err2 != nil) || (err3 != nil) ||
+		(cr.start < 0) || (cr.end < cr.start) || ((cr.size >= 0) && (cr.end >= cr.size)) {
+		return nil, fmt.Errorf(ErrContentRangeIsWrong, header)
+	}
+
+	return cr, nil
+}
@@ -0,0 +1,189 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+)
+
+// testFile returns the content of the sample TIFF file of the repository.
+func testFile(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// newRangeServer starts a server of the data which supports range requests.
+func newRangeServer(t *testing.T, data []byte) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "test.tiff", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestHTTPReaderAt_ReadAt(t *testing.T) {
+	data := testFile(t)
+	srv := newRangeServer(t, data)
+
+	hra, err := NewHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hra.Size() != int64(len(data)) {
+		t.Fatalf("size is %v instead of %v", hra.Size(), len(data))
+	}
+
+	tests := []struct {
+		off    int64
+		length int
+		eof    bool
+	}{
+		{off: 0, length: 8},
+		{off: 100, length: 1000},
+		{off: int64(len(data)) - 10, length: 10},
+		{off: int64(len(data)) - 10, length: 20, eof: true},
+	}
+	for _, test := range tests {
+		p := make([]byte, test.length)
+		n, err := hra.ReadAt(p, test.off)
+
+		want := data[test.off:min(test.off+int64(test.length), int64(len(data)))]
+		if !bytes.Equal(p[:n], want) {
+			t.Errorf("ReadAt(%v, %v) returned wrong data", test.length, test.off)
+		}
+		if test.eof != errors.Is(err, io.EOF) {
+			t.Errorf("ReadAt(%v, %v) returned error %v", test.length, test.off, err)
+		}
+		if !test.eof && (err != nil) {
+			t.Errorf("ReadAt(%v, %v) returned error %v", test.length, test.off, err)
+		}
+	}
+
+	_, err = hra.ReadAt(make([]byte, 1), int64(len(data)))
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("read beyond the end returned error %v", err)
+	}
+}
+
+func TestHTTPReaderAt_Parse(t *testing.T) {
+	data := testFile(t)
+	srv := newRangeServer(t, data)
+
+	hra, err := NewHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cra *CachedReaderAt
+	cra, err = NewCachedReaderAt(hra, hra.Size(), DefaultCacheOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tiff.NewFromReaderAt(cra, cra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The whole sample file fits into a single block of the cache.
+	if stats := hra.Stats(); stats.Requests != 2 {
+		t.Errorf("%v requests were sent instead of 2", stats.Requests)
+	}
+}
+
+func TestHTTPReaderAt_WrongContentRange(t *testing.T) {
+	data := testFile(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first byte is served correctly, so that the size is known,
+		// other ranges are shifted.
+		if r.Header.Get(HeaderRange) != "bytes=0-0" {
+			r.Header.Set(HeaderRange, "bytes=1-8")
+		}
+		http.ServeContent(w, r, "test.tiff", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	hra, err := NewHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = hra.ReadAt(make([]byte, 8), 0)
+	if (err == nil) || !strings.Contains(err.Error(), "instead of") {
+		t.Errorf("mismatching range is not detected: %v", err)
+	}
+}
+
+func TestHTTPReaderAt_RangesAreIgnored(t *testing.T) {
+	data := testFile(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	hra, err := NewHTTPReaderAt(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hra.Size() != int64(len(data)) {
+		t.Fatalf("size is %v instead of %v", hra.Size(), len(data))
+	}
+
+	for off := int64(0); off < int64(len(data)); off += 1000 {
+		p := make([]byte, 100)
+		n, _ := hra.ReadAt(p, off)
+		if !bytes.Equal(p[:n], data[off:off+int64(n)]) || (n == 0) {
+			t.Fatalf("ReadAt(100, %v) returned wrong data", off)
+		}
+	}
+
+	// The file is downloaded once.
+	if stats := hra.Stats(); (stats.Requests != 1) || (stats.Bytes != int64(len(data))) {
+		t.Errorf("%v requests and %v bytes were used", stats.Requests, stats.Bytes)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *contentRange
+	}{
+		{"bytes 0-0/12345", &contentRange{start: 0, end: 0, size: 12345}},
+		{"bytes 10-19/*", &contentRange{start: 10, end: 19, size: -1}},
+		{"", nil},
+		{"bytes 10-9/100", nil},
+		{"bytes 0-100/100", nil},
+		{"items 0-1/2", nil},
+		{"bytes 0/2", nil},
+	}
+	for _, test := range tests {
+		cr, err := parseContentRange(test.header)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("%q: error is expected", test.header)
+			}
+			continue
+		}
+		if (err != nil) || (*cr != *test.want) {
+			t.Errorf("%q: got %v, %v", test.header, cr, err)
+		}
+	}
+}
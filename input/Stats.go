@@ -0,0 +1,36 @@
+package input
+
+import "sync/atomic"
+
+// Stats is the usage statistics of an input.
+type Stats struct {
+	// Requests is the number of read requests sent to the underlying source.
+	Requests int64
+
+	// Bytes is the number of bytes received from the underlying source.
+	Bytes int64
+
+	// CacheHits is the number of blocks served from the cache.
+	CacheHits int64
+
+	// CacheMisses is the number of blocks which were not found in the cache.
+	CacheMisses int64
+}
+
+// counters are the concurrency-safe counters of usage statistics.
+type counters struct {
+	requests    atomic.Int64
+	bytes       atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+// snapshot returns current values of the counters.
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Requests:    c.requests.Load(),
+		Bytes:       c.bytes.Load(),
+		CacheHits:   c.cacheHits.Load(),
+		CacheMisses: c.cacheMisses.Load(),
+	}
+}
@@ -0,0 +1,23 @@
+// Package input provides sources of TIFF data built around io.ReaderAt.
+//
+// Random access readers allow to fetch only those parts of a file which are
+// needed for parsing of its meta-data. Remote files may be read with HTTP
+// range requests, and a block cache turns many small reads of the parser into
+// a few big ones.
+package input
+
+import (
+	"io"
+)
+
+// ReaderAt is an io.ReaderAt which knows the size of its source.
+type ReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// NewReadSeeker creates a reader with seeking support on top of the random
+// access reader, which is suitable for the TIFF parser.
+func NewReadSeeker(ra ReaderAt) *io.SectionReader {
+	return io.NewSectionReader(ra, 0, ra.Size())
+}
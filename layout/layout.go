@@ -0,0 +1,397 @@
+// Package layout builds a byte-level map of a TIFF file.
+//
+// The map shows which ranges of the file belong to the header, to tables of
+// entries of IFDs and SubIFDs, to values which do not fit into their entries,
+// to strips, tiles and JPEG thumbnails. Ranges which are not referred by
+// anything, ranges referred several times and references beyond the end of
+// the file are reported as issues. It is useful for debugging corrupt files.
+package layout
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// Kinds of regions.
+const (
+	KindHeader    = "header"
+	KindDirectory = "directory"
+	KindValue     = "value"
+	KindStrip     = "strip"
+	KindTile      = "tile"
+	KindThumbnail = "thumbnail"
+	KindPadding   = "padding"
+	KindGap       = "gap"
+)
+
+// Kinds of issues.
+const (
+	IssueGap         = "gap"
+	IssueOverlap     = "overlap"
+	IssueOutOfBounds = "out-of-bounds"
+)
+
+// Sizes of structures of the file.
+const (
+	HeaderSize           = 8
+	EntryCountSize       = 2
+	EntrySize            = 12
+	OffsetOfNextIFDSize  = 4
+	WordAlignmentDivisor = 2
+)
+
+const (
+	MsgGap         = "%v unreferenced bytes"
+	MsgOverlap     = "%v overlaps %v by %v bytes"
+	MsgOutOfBounds = "%v ends at %v beyond the end of the file at %v"
+)
+
+// Region is a range of bytes of the file.
+type Region struct {
+	// Kind is the kind of the region.
+	Kind string `json:"kind"`
+
+	// Path is the path of the directory or of the entry which the region
+	// belongs to, e.g. 'IFD0/Exif' or 'IFD0/XResolution'. Strips and tiles
+	// are named by the entries of their offsets and their indices, e.g.
+	// 'IFD0/StripOffsets#3'. It is empty for the header and for gaps.
+	Path string `json:"path,omitempty"`
+
+	// Start is the position of the first byte of the region.
+	Start int64 `json:"start"`
+
+	// End is the position of the byte following the region.
+	End int64 `json:"end"`
+
+	// Fields are parts of the header and of tables of entries.
+	Fields []*Field `json:"fields,omitempty"`
+
+	// OutOfBounds flag is set when the region ends beyond the end of the
+	// file.
+	OutOfBounds bool `json:"outOfBounds,omitempty"`
+
+	// Overlaps are paths of regions which overlap this region.
+	Overlaps []string `json:"overlaps,omitempty"`
+}
+
+// Field is a named part of a region.
+type Field struct {
+	// Name describes the field, e.g. 'count' or 'ImageWidth'.
+	Name string `json:"name"`
+
+	// Start is the position of the first byte of the field.
+	Start int64 `json:"start"`
+
+	// End is the position of the byte following the field.
+	End int64 `json:"end"`
+}
+
+// Issue is a problem of the layout. Gaps are warnings, since many writers
+// leave unused bytes in files, other issues are errors.
+type Issue struct {
+	models.Issue
+
+	// Kind is the kind of the issue.
+	Kind string `json:"kind"`
+
+	// Start and End are the range of bytes of the issue.
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// Layout is the byte-level map of a file.
+type Layout struct {
+	// Size is the size of the file.
+	Size int64 `json:"size"`
+
+	// Regions are regions of the file sorted by their positions. Gaps between
+	// referenced regions are regions too.
+	Regions []*Region `json:"regions"`
+
+	// Issues are gaps, overlaps and references beyond the end of the file.
+	Issues []*Issue `json:"issues"`
+}
+
+// Pairs of tags of offsets and byte counts of image data.
+var dataTags = []struct {
+	offsets tag.Tag
+	counts  tag.Tag
+	kind    string
+}{
+	{tag.StripOffsets, tag.StripByteCounts, KindStrip},
+	{tag.TileOffsets, tag.TileByteCounts, KindTile},
+	{tag.JPEGInterchangeFormat, tag.JPEGInterchangeFormatLength, KindThumbnail},
+}
+
+// MapFile builds the layout of the file.
+func MapFile(filePath string) (l *Layout, err error) {
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	var size int64
+	size, err = f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var tf *tiff.TIFF
+	tf, err = tiff.New(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return Map(tf, size), nil
+}
+
+// Map builds the layout of the parsed file of the specified size.
+func Map(tf *tiff.TIFF, size int64) (l *Layout) {
+	l = &Layout{
+		Size:    size,
+		Regions: make([]*Region, 0),
+		Issues:  make([]*Issue, 0),
+	}
+
+	l.addRegion(&Region{
+		Kind:  KindHeader,
+		Start: 0,
+		End:   HeaderSize,
+		Fields: []*Field{
+			{Name: "byte order", Start: 0, End: 2},
+			{Name: "magic number", Start: 2, End: 4},
+			{Name: "offset of IFD", Start: 4, End: HeaderSize},
+		},
+	})
+
+	_ = tf.WalkDirectories(func(d *tiff.Directory) error {
+		l.addDirectory(d)
+		return nil
+	})
+
+	sort.SliceStable(l.Regions, func(i, j int) bool {
+		if l.Regions[i].Start != l.Regions[j].Start {
+			return l.Regions[i].Start < l.Regions[j].Start
+		}
+		return l.Regions[i].End < l.Regions[j].End
+	})
+
+	l.findOverlaps()
+	l.addGaps()
+
+	sort.SliceStable(l.Issues, func(i, j int) bool {
+		return l.Issues[i].Start < l.Issues[j].Start
+	})
+
+	return l
+}
+
+// addDirectory adds regions of the table of entries of the directory, of
+// values of its entries and of image data referred by them.
+func (l *Layout) addDirectory(d *tiff.Directory) {
+	start := int64(d.Offset)
+	table := &Region{
+		Kind:   KindDirectory,
+		Path:   d.Path,
+		Start:  start,
+		End:    start + EntryCountSize + int64(len(d.Entries))*EntrySize + OffsetOfNextIFDSize,
+		Fields: make([]*Field, 0, len(d.Entries)+2),
+	}
+
+	pos := start
+	table.Fields = append(table.Fields, &Field{Name: "count", Start: pos, End: pos + EntryCountSize})
+	pos += EntryCountSize
+	for _, de := range d.Entries {
+		table.Fields = append(table.Fields, &Field{Name: tiff.EntryName(de.Tag), Start: pos, End: pos + EntrySize})
+		pos += EntrySize
+	}
+	table.Fields = append(table.Fields, &Field{Name: "offset of next", Start: pos, End: pos + OffsetOfNextIFDSize})
+	l.addRegion(table)
+
+	for _, de := range d.Entries {
+		if de.HasFastValue() || (de.Offset == 0) {
+			continue
+		}
+
+		valueStart := int64(de.Offset)
+		l.addRegion(&Region{
+			Kind:  KindValue,
+			Path:  d.EntryPath(de),
+			Start: valueStart,
+			End:   valueStart + int64(de.Count)*int64(t.ItemSize(de.Type)),
+		})
+	}
+
+	for _, dt := range dataTags {
+		l.addData(d, dt.offsets, dt.counts, dt.kind)
+	}
+}
+
+// addData adds regions of image data described by the tags of offsets and
+// byte counts.
+func (l *Layout) addData(d *tiff.Directory, offsetsTag tag.Tag, countsTag tag.Tag, kind string) {
+	offsetsEntry, ok1 := d.EntriesByTagNumber[offsetsTag]
+	countsEntry, ok2 := d.EntriesByTagNumber[countsTag]
+	if !ok1 || !ok2 {
+		return
+	}
+
+	offsets, err1 := offsetsEntry.ValueAsArrayOfInteger()
+	counts, err2 := countsEntry.ValueAsArrayOfInteger()
+	if (err1 != nil) || (err2 != nil) {
+		return
+	}
+
+	entryPath := d.EntryPath(offsetsEntry)
+	for j := 0; (j < len(offsets)) && (j < len(counts)); j++ {
+		p := entryPath
+		if len(offsets) > 1 {
+			p += tiff.SubIFDIndexSeparator + strconv.Itoa(j)
+		}
+
+		l.addRegion(&Region{
+			Kind:  kind,
+			Path:  p,
+			Start: offsets[j],
+			End:   offsets[j] + counts[j],
+		})
+	}
+}
+
+// addRegion adds the region, checking that it is inside the file.
+func (l *Layout) addRegion(r *Region) {
+	if r.End > l.Size {
+		r.OutOfBounds = true
+		l.addIssue(models.SeverityError, IssueOutOfBounds, r.Start, r.End, MsgOutOfBounds, r.name(), r.End, l.Size)
+	}
+
+	l.Regions = append(l.Regions, r)
+}
+
+// findOverlaps marks regions which overlap each other. Regions must be sorted.
+func (l *Layout) findOverlaps() {
+	// Regions which are still open at the current position.
+	var open []*Region
+
+	for _, r := range l.Regions {
+		if r.Start == r.End {
+			continue
+		}
+
+		stillOpen := open[:0]
+		for _, o := range open {
+			if o.End > r.Start {
+				stillOpen = append(stillOpen, o)
+			}
+		}
+		open = stillOpen
+
+		for _, o := range open {
+			end := min(o.End, r.End)
+			o.Overlaps = append(o.Overlaps, r.name())
+			r.Overlaps = append(r.Overlaps, o.name())
+			l.addIssue(models.SeverityError, IssueOverlap, r.Start, end, MsgOverlap, r.name(), o.name(), end-r.Start)
+		}
+
+		open = append(open, r)
+	}
+}
+
+// addGaps adds regions of bytes which are not referred by anything. Single
+// bytes before word-aligned regions are padding, not gaps. Regions must be
+// sorted.
+func (l *Layout) addGaps() {
+	regions := make([]*Region, 0, len(l.Regions))
+
+	var pos int64
+	for _, r := range l.Regions {
+		if (r.Start > pos) && (pos < l.Size) {
+			regions = append(regions, l.gap(pos, min(r.Start, l.Size), r.Start))
+		}
+		regions = append(regions, r)
+		pos = max(pos, r.End)
+	}
+	if pos < l.Size {
+		regions = append(regions, l.gap(pos, l.Size, l.Size))
+	}
+
+	l.Regions = regions
+}
+
+// gap creates the region of unreferenced bytes. Next is the start of the
+// following region.
+func (l *Layout) gap(start int64, end int64, next int64) (r *Region) {
+	r = &Region{Kind: KindGap, Start: start, End: end}
+
+	if (end-start == 1) && (start%WordAlignmentDivisor == 1) && (next%WordAlignmentDivisor == 0) {
+		r.Kind = KindPadding
+		return r
+	}
+
+	l.addIssue(models.SeverityWarning, IssueGap, start, end, MsgGap, end-start)
+	return r
+}
+
+// addIssue adds an issue to the layout.
+func (l *Layout) addIssue(severity models.Severity, kind string, start int64, end int64, format string, args ...any) {
+	l.Issues = append(l.Issues, &Issue{
+		Issue: models.Issue{
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		},
+		Kind:  kind,
+		Start: start,
+		End:   end,
+	})
+}
+
+// name returns the path of the region or its kind when it has no path.
+func (r *Region) name() string {
+	if r.Path != "" {
+		return r.Path
+	}
+
+	return r.Kind
+}
+
+// Size returns the size of the region.
+func (r *Region) Size() int64 {
+	return r.End - r.Start
+}
+
+// Errors returns the number of errors of the layout.
+func (l *Layout) Errors() (n int) {
+	for _, issue := range l.Issues {
+		if issue.Severity == models.SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// IsFlagged tells whether the region is a gap, overlaps other regions or ends
+// beyond the end of the file.
+func (r *Region) IsFlagged() bool {
+	return (r.Kind == KindGap) || r.OutOfBounds || (len(r.Overlaps) > 0)
+}
@@ -28,6 +28,18 @@ const (
 // It can be either big endian or little endian.
 type ByteOrder byte
 
+// String returns the byte order mark of the byte order.
+func (bo ByteOrder) String() string {
+	switch bo {
+	case BigEndian:
+		return string([]byte{ByteM, ByteM})
+	case LittleEndian:
+		return string([]byte{ByteI, ByteI})
+	default:
+		return fmt.Sprintf("%d", byte(bo))
+	}
+}
+
 // New reads the byte order from the stream and returns it.
 func New(rs *rs.ReaderSeeker) (bo ByteOrder, err error) {
 	var ba []byte
@@ -0,0 +1,292 @@
+package cog
+
+import (
+	"fmt"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	iors "github.com/vault-thirteen/auxie/ReaderSeeker"
+)
+
+// Rules checked by the validator.
+const (
+	RuleGhostHeader         = "ghost-header"
+	RuleMainImageIsTiled    = "main-image-tiled"
+	RuleOverviewIsReduced   = "overview-reduced-resolution"
+	RuleOverviewIsTiled     = "overview-tiled"
+	RuleOverviewSize        = "overview-size-decreasing"
+	RuleIFDsBeforeData      = "ifds-before-data"
+	RuleOverviewDataFirst   = "overview-data-first"
+	RuleBlockOrder          = "block-order"
+	RuleImageLayout         = "image-layout"
+	RuleIncompatibleEdition = "known-incompatible-edition"
+)
+
+const (
+	MsgGhostHeaderIsNotValid   = "ghost header can not be read: %v"
+	MsgLayoutIsNotIFDsFirst    = "ghost header declares layout %q instead of %q"
+	MsgIncompatibleEdition     = "file has been modified after its creation and is no longer optimized"
+	MsgMainImageIsNotTiled     = "main image is not tiled"
+	MsgOverviewIsNotReduced    = "IFD #%v is not a reduced-resolution image, NewSubfileType=%v"
+	MsgOverviewIsNotTiled      = "overview in IFD #%v is not tiled"
+	MsgOverviewIsNotSmaller    = "overview in IFD #%v (%vx%v) is not smaller than the previous image (%vx%v)"
+	MsgIFDIsAfterData          = "IFD #%v at offset %v is placed after the image data starting at offset %v"
+	MsgIFDsAreNotOrdered       = "IFD #%v at offset %v is placed before the previous IFD at offset %v"
+	MsgDataIsNotOverviewFirst  = "data of IFD #%v starting at offset %v is placed before the data of its overview in IFD #%v starting at offset %v"
+	MsgBlocksAreNotOrdered     = "blocks of IFD #%v are not stored in the row-major order"
+	MsgImageLayoutCanNotBeRead = "layout of IFD #%v can not be read: %v"
+	MsgMaskIsNotTiled          = "mask in IFD #%v is not tiled"
+	MsgMainImageIsMissing      = "file has no main image"
+)
+
+const ErrTIFFCanNotBeParsed = "TIFF can not be parsed: %v"
+
+// Issue is a single problem found by the validator.
+type Issue struct {
+	models.Issue
+
+	// Rule is the identifier of the violated rule.
+	Rule string `json:"rule"`
+
+	// IFDIndex is the index of the IFD which the issue is related to. It is
+	// negative for issues related to the whole file.
+	IFDIndex int `json:"ifdIndex"`
+}
+
+// Report is the result of a validation of a Cloud-Optimized GeoTIFF file.
+type Report struct {
+	// IsValid flag is set when no errors were found. Warnings do not make a
+	// file invalid.
+	IsValid bool `json:"isValid"`
+
+	// GhostHeader is the structural metadata written by GDAL. It is nil when
+	// the file has no ghost header.
+	GhostHeader *GhostHeader `json:"ghostHeader,omitempty"`
+
+	// MainImage is the full-resolution image.
+	MainImage *Image `json:"mainImage,omitempty"`
+
+	// Overviews are reduced-resolution images in the order of IFDs.
+	Overviews []*Image `json:"overviews,omitempty"`
+
+	// Masks are transparency masks of the main image and of the overviews.
+	Masks []*Image `json:"masks,omitempty"`
+
+	// Issues are problems found by the validator.
+	Issues []*Issue `json:"issues"`
+}
+
+// Validate checks whether the stream is a Cloud-Optimized GeoTIFF.
+//
+// COG specification (OGC Cloud Optimized GeoTIFF Standard):
+// https://docs.ogc.org/is/21-026/21-026.html
+// GDAL COG driver: https://gdal.org/drivers/raster/cog.html
+func Validate(stream iors.ReaderSeeker) (r *Report, err error) {
+	var t *tiff.TIFF
+	t, err = tiff.New(stream)
+	if err != nil {
+		return nil, fmt.Errorf(ErrTIFFCanNotBeParsed, err.Error())
+	}
+
+	return ValidateTIFF(t, stream)
+}
+
+// ValidateTIFF checks whether the parsed TIFF is a Cloud-Optimized GeoTIFF.
+// The stream is the one from which the TIFF was parsed, it is used for
+// reading the ghost header.
+func ValidateTIFF(t *tiff.TIFF, stream iors.ReaderSeeker) (r *Report, err error) {
+	r = &Report{
+		Issues: make([]*Issue, 0),
+	}
+
+	r.checkGhostHeader(stream)
+
+	images := r.readImages(t)
+	r.checkImages(images)
+	r.checkIFDsBeforeData(images)
+	r.checkDataOrder()
+
+	r.IsValid = true
+	for _, issue := range r.Issues {
+		if issue.Severity == models.SeverityError {
+			r.IsValid = false
+			break
+		}
+	}
+
+	return r, nil
+}
+
+// addIssue adds an issue to the report.
+func (r *Report) addIssue(severity models.Severity, rule string, ifdIndex int, format string, args ...any) {
+	r.Issues = append(r.Issues, &Issue{
+		Issue: models.Issue{
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		},
+		Rule:     rule,
+		IFDIndex: ifdIndex,
+	})
+}
+
+// checkGhostHeader reads and checks the ghost header.
+func (r *Report) checkGhostHeader(stream iors.ReaderSeeker) {
+	var err error
+	r.GhostHeader, err = ReadGhostHeader(stream)
+	if err != nil {
+		r.addIssue(models.SeverityError, RuleGhostHeader, -1, MsgGhostHeaderIsNotValid, err.Error())
+		return
+	}
+
+	layout, _ := r.GhostHeader.Option(OptionLayout)
+	if layout != LayoutIFDsBeforeData {
+		r.addIssue(models.SeverityError, RuleGhostHeader, -1, MsgLayoutIsNotIFDsFirst, layout, LayoutIFDsBeforeData)
+	}
+
+	edition, _ := r.GhostHeader.Option(OptionKnownIncompatibleEdition)
+	if edition == ValueYes {
+		r.addIssue(models.SeverityError, RuleIncompatibleEdition, -1, MsgIncompatibleEdition)
+	}
+}
+
+// readImages reads layouts of all images. The images are sorted into the main
+// image, overviews and masks.
+func (r *Report) readImages(t *tiff.TIFF) (images []*Image) {
+	ifdOffset := t.Header().OffsetOfFirstIFD
+	for idx, curIFD := range t.IFDs() {
+		img, err := newImage(curIFD, idx, ifdOffset)
+		if err != nil {
+			r.addIssue(models.SeverityError, RuleImageLayout, idx, MsgImageLayoutCanNotBeRead, idx, err.Error())
+			img = &Image{IFDIndex: idx, IFDOffset: ifdOffset}
+		}
+		images = append(images, img)
+		ifdOffset = curIFD.OffsetOfNextIFD
+
+		switch {
+		case idx == 0:
+			r.MainImage = img
+		case img.IsMask():
+			r.Masks = append(r.Masks, img)
+		default:
+			r.Overviews = append(r.Overviews, img)
+		}
+	}
+
+	return images
+}
+
+// checkImages checks that the main image and overviews are tiled and that
+// sizes of overviews are decreasing.
+func (r *Report) checkImages(images []*Image) {
+	if r.MainImage == nil {
+		r.addIssue(models.SeverityError, RuleMainImageIsTiled, -1, MsgMainImageIsMissing)
+		return
+	}
+
+	if !r.MainImage.IsTiled {
+		r.addIssue(models.SeverityError, RuleMainImageIsTiled, r.MainImage.IFDIndex, MsgMainImageIsNotTiled)
+	}
+
+	previous := r.MainImage
+	for _, ovr := range r.Overviews {
+		if !ovr.IsReducedResolution() {
+			r.addIssue(models.SeverityError, RuleOverviewIsReduced, ovr.IFDIndex, MsgOverviewIsNotReduced, ovr.IFDIndex, ovr.NewSubfileType)
+		}
+
+		if !ovr.IsTiled {
+			r.addIssue(models.SeverityError, RuleOverviewIsTiled, ovr.IFDIndex, MsgOverviewIsNotTiled, ovr.IFDIndex)
+		}
+
+		if (ovr.Width > previous.Width) || (ovr.Height > previous.Height) ||
+			((ovr.Width == previous.Width) && (ovr.Height == previous.Height)) {
+			r.addIssue(models.SeverityError, RuleOverviewSize, ovr.IFDIndex, MsgOverviewIsNotSmaller,
+				ovr.IFDIndex, ovr.Width, ovr.Height, previous.Width, previous.Height)
+		}
+
+		previous = ovr
+	}
+
+	for _, mask := range r.Masks {
+		if !mask.IsTiled {
+			r.addIssue(models.SeverityError, RuleOverviewIsTiled, mask.IFDIndex, MsgMaskIsNotTiled, mask.IFDIndex)
+		}
+	}
+
+	for _, img := range images {
+		if !isRowMajor(img) {
+			r.addIssue(models.SeverityWarning, RuleBlockOrder, img.IFDIndex, MsgBlocksAreNotOrdered, img.IFDIndex)
+		}
+	}
+}
+
+// checkIFDsBeforeData checks that all IFDs are stored before any image data
+// and that they are stored in the order of the chain.
+func (r *Report) checkIFDsBeforeData(images []*Image) {
+	var dataStart int64
+	var hasData bool
+	for _, img := range images {
+		start, _, ok := img.DataRange()
+		if ok && (!hasData || (start < dataStart)) {
+			dataStart = start
+			hasData = true
+		}
+	}
+
+	for j, img := range images {
+		if (j > 0) && (img.IFDOffset < images[j-1].IFDOffset) {
+			r.addIssue(models.SeverityError, RuleIFDsBeforeData, img.IFDIndex, MsgIFDsAreNotOrdered,
+				img.IFDIndex, img.IFDOffset, images[j-1].IFDOffset)
+		}
+
+		if hasData && (int64(img.IFDOffset) >= dataStart) {
+			r.addIssue(models.SeverityError, RuleIFDsBeforeData, img.IFDIndex, MsgIFDIsAfterData,
+				img.IFDIndex, img.IFDOffset, dataStart)
+		}
+	}
+}
+
+// checkDataOrder checks that the data of the smallest overview is stored
+// first and the data of the main image is stored last.
+func (r *Report) checkDataOrder() {
+	if r.MainImage == nil {
+		return
+	}
+
+	chain := append([]*Image{r.MainImage}, r.Overviews...)
+	for j := 1; j < len(chain); j++ {
+		bigger, smaller := chain[j-1], chain[j]
+
+		biggerStart, _, ok := bigger.DataRange()
+		if !ok {
+			continue
+		}
+
+		smallerStart, _, ok := smaller.DataRange()
+		if !ok {
+			continue
+		}
+
+		if smallerStart > biggerStart {
+			r.addIssue(models.SeverityError, RuleOverviewDataFirst, bigger.IFDIndex, MsgDataIsNotOverviewFirst,
+				bigger.IFDIndex, biggerStart, smaller.IFDIndex, smallerStart)
+		}
+	}
+}
+
+// isRowMajor tells whether non-empty blocks of the image are stored in the
+// order of their indices.
+func isRowMajor(img *Image) bool {
+	var previous int64
+	for j, offset := range img.BlockOffsets {
+		if (j >= len(img.BlockByteCounts)) || (img.BlockByteCounts[j] == 0) {
+			continue
+		}
+
+		if offset < previous {
+			return false
+		}
+		previous = offset
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package cog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	iors "github.com/vault-thirteen/auxie/ReaderSeeker"
+)
+
+// GhostHeaderOffset is the position of the ghost header in a classic TIFF
+// file. It is placed right after the TIFF header.
+const GhostHeaderOffset = 8
+
+// Prefix and suffix of the first line of the ghost header. The size is a
+// decimal number of 6 digits.
+const (
+	GhostHeaderPrefix     = "GDAL_STRUCTURAL_METADATA_SIZE="
+	GhostHeaderSizeDigits = 6
+	GhostHeaderSuffix     = " bytes\n"
+)
+
+// Options of the ghost header.
+const (
+	OptionLayout                   = "LAYOUT"
+	OptionBlockOrder               = "BLOCK_ORDER"
+	OptionBlockLeader              = "BLOCK_LEADER"
+	OptionBlockTrailer             = "BLOCK_TRAILER"
+	OptionKnownIncompatibleEdition = "KNOWN_INCOMPATIBLE_EDITION"
+	OptionMaskInterleavedWithImage = "MASK_INTERLEAVED_WITH_IMAGERY"
+)
+
+// Values of the ghost header options.
+const (
+	LayoutIFDsBeforeData = "IFDS_BEFORE_DATA"
+	BlockOrderRowMajor   = "ROW_MAJOR"
+	ValueYes             = "YES"
+	ValueNo              = "NO"
+)
+
+const (
+	ErrGhostHeaderIsMissing = "ghost header is missing"
+	ErrGhostHeaderSize      = "ghost header size is not valid: %v"
+	ErrGhostHeaderLine      = "ghost header line is not valid: %v"
+)
+
+// GhostHeader is the structural metadata which GDAL writes in the beginning of
+// a Cloud-Optimized GeoTIFF file.
+//
+// https://gdal.org/drivers/raster/cog.html#header-ghost-area
+type GhostHeader struct {
+	// Size is the declared size of the metadata, in bytes.
+	Size int
+
+	// Options are metadata items in the order of their appearance.
+	Options []Option
+
+	// OptionsByName are metadata items by their names.
+	OptionsByName map[string]string
+}
+
+// Option is a single item of the ghost header metadata.
+type Option struct {
+	Name  string
+	Value string
+}
+
+// ReadGhostHeader reads the ghost header from the stream.
+func ReadGhostHeader(stream iors.ReaderSeeker) (gh *GhostHeader, err error) {
+	_, err = stream.Seek(GhostHeaderOffset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	firstLine := make([]byte, len(GhostHeaderPrefix)+GhostHeaderSizeDigits+len(GhostHeaderSuffix))
+	_, err = io.ReadFull(stream, firstLine)
+	if err != nil {
+		return nil, errors.New(ErrGhostHeaderIsMissing)
+	}
+
+	if !bytes.HasPrefix(firstLine, []byte(GhostHeaderPrefix)) ||
+		!bytes.HasSuffix(firstLine, []byte(GhostHeaderSuffix)) {
+		return nil, errors.New(ErrGhostHeaderIsMissing)
+	}
+
+	sizeText := string(firstLine[len(GhostHeaderPrefix) : len(GhostHeaderPrefix)+GhostHeaderSizeDigits])
+	gh = &GhostHeader{
+		OptionsByName: make(map[string]string),
+	}
+	gh.Size, err = strconv.Atoi(sizeText)
+	if err != nil || (gh.Size < 0) {
+		return nil, fmt.Errorf(ErrGhostHeaderSize, sizeText)
+	}
+
+	metadata := make([]byte, gh.Size)
+	_, err = io.ReadFull(stream, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// GDAL pads the metadata with spaces.
+	for _, line := range strings.Split(string(metadata), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf(ErrGhostHeaderLine, line)
+		}
+
+		gh.Options = append(gh.Options, Option{Name: name, Value: value})
+		gh.OptionsByName[name] = value
+	}
+
+	return gh, nil
+}
+
+// Option returns the value of the option.
+func (gh *GhostHeader) Option(name string) (value string, ok bool) {
+	value, ok = gh.OptionsByName[name]
+	return value, ok
+}
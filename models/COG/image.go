@@ -0,0 +1,160 @@
+package cog
+
+import (
+	"fmt"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Bits of the NewSubfileType tag.
+const (
+	SubfileTypeReducedResolution = 1 << 0
+	SubfileTypePage              = 1 << 1
+	SubfileTypeTransparencyMask  = 1 << 2
+)
+
+const ErrTagIsMissing = "tag is missing: %v"
+
+// Image describes the layout of a single image stored in an IFD.
+type Image struct {
+	// IFDIndex is the index of the IFD in the chain, starting from zero.
+	IFDIndex int
+
+	// IFDOffset is the position of the IFD in the file.
+	IFDOffset uint32
+
+	// Width and Height are the size of the image in pixels.
+	Width  uint32
+	Height uint32
+
+	// NewSubfileType is the value of the NewSubfileType tag.
+	NewSubfileType uint32
+
+	// IsTiled flag is set when the image is stored in tiles, otherwise it is
+	// stored in strips.
+	IsTiled bool
+
+	// TileWidth and TileLength are the size of a tile in pixels.
+	TileWidth  uint32
+	TileLength uint32
+
+	// BlockOffsets and BlockByteCounts are positions and sizes of tiles or
+	// strips.
+	BlockOffsets    []int64
+	BlockByteCounts []int64
+}
+
+// newImage reads the layout of the image stored in the IFD.
+func newImage(i *ifd.IFD, ifdIndex int, ifdOffset uint32) (img *Image, err error) {
+	img = &Image{
+		IFDIndex:  ifdIndex,
+		IFDOffset: ifdOffset,
+	}
+
+	entries := i.DirectoryEntriesByTagNumber
+
+	img.Width, err = integer(entries, tag.ImageWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	img.Height, err = integer(entries, tag.ImageLength)
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasNewSubfileType := entries[tag.NewSubfileType]
+	if hasNewSubfileType {
+		img.NewSubfileType, err = integer(entries, tag.NewSubfileType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	offsetsTag, byteCountsTag := tag.Tag(tag.StripOffsets), tag.Tag(tag.StripByteCounts)
+	_, img.IsTiled = entries[tag.TileOffsets]
+	if img.IsTiled {
+		offsetsTag, byteCountsTag = tag.TileOffsets, tag.TileByteCounts
+
+		img.TileWidth, err = integer(entries, tag.TileWidth)
+		if err != nil {
+			return nil, err
+		}
+
+		img.TileLength, err = integer(entries, tag.TileLength)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	img.BlockOffsets, err = integers(entries, offsetsTag)
+	if err != nil {
+		return nil, err
+	}
+
+	img.BlockByteCounts, err = integers(entries, byteCountsTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// IsReducedResolution tells whether the image is a reduced-resolution version
+// of another image, i.e. an overview.
+func (img *Image) IsReducedResolution() bool {
+	return img.NewSubfileType&SubfileTypeReducedResolution != 0
+}
+
+// IsMask tells whether the image is a transparency mask.
+func (img *Image) IsMask() bool {
+	return img.NewSubfileType&SubfileTypeTransparencyMask != 0
+}
+
+// DataRange returns the position of the first byte of the image data and the
+// position after the last byte of the image data. Empty blocks, which are
+// used by sparse files, are ignored. False is returned if the image has no
+// data.
+func (img *Image) DataRange() (start, end int64, ok bool) {
+	for j, offset := range img.BlockOffsets {
+		if (j >= len(img.BlockByteCounts)) || (img.BlockByteCounts[j] == 0) {
+			continue
+		}
+
+		if !ok || (offset < start) {
+			start = offset
+		}
+		if !ok || (offset+img.BlockByteCounts[j] > end) {
+			end = offset + img.BlockByteCounts[j]
+		}
+		ok = true
+	}
+
+	return start, end, ok
+}
+
+// integers returns values of the tag.
+func integers(entries map[tag.Tag]*ifd.DirectoryEntry, tg tag.Tag) (v []int64, err error) {
+	de, ok := entries[tg]
+	if !ok {
+		return nil, fmt.Errorf(ErrTagIsMissing, tag.HumanReadableTagNames()[tg])
+	}
+
+	return de.ValueAsArrayOfInteger()
+}
+
+// integer returns the first value of the tag.
+func integer(entries map[tag.Tag]*ifd.DirectoryEntry, tg tag.Tag) (v uint32, err error) {
+	var values []int64
+	values, err = integers(entries, tg)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf(ErrTagIsMissing, tag.HumanReadableTagNames()[tg])
+	}
+
+	return uint32(values[0]), nil
+}
@@ -0,0 +1,600 @@
+package dng
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	exif "github.com/vault-thirteen/TIFFer/models/EXIF"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrTIFFHasNoIFD        = "TIFF has no IFD"
+	ErrDNGVersionIsMissing = "DNGVersion is missing, TIFF is not a DNG"
+	ErrRawIFDIsMissing     = "IFD of the raw image is missing"
+	ErrInRawIFD            = "error in the raw IFD %v: %v"
+	ErrValueSize           = "value size is wrong: %v"
+)
+
+// Sizes of values.
+const (
+	VersionSize        = 4
+	RectangleSize      = 4 // Top, left, bottom, right.
+	PairSize           = 2 // Horizontal, vertical.
+	ChromaticitySize   = 2 // x, y.
+	RepeatDimSize      = 2 // Rows, columns.
+	XYZComponentsCount = 3
+)
+
+// NewSubfileTypeMainImage is the value of NewSubfileType of the main image,
+// which is the raw image in DNG files.
+const NewSubfileTypeMainImage = 0
+
+// Version is a version of the DNG specification, e.g. 1.4.0.0.
+type Version [VersionSize]byte
+
+// String returns the version in its usual form, e.g. "1.4.0.0".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
+
+// DNG is a typed model of DNG tags.
+//
+// Tags describing the camera and its colour calibration are read from the
+// first IFD. Tags describing the raw image data are read from the IFD of the
+// raw image, which is the main image of the file. Optional values which are
+// not stored in the file are either left nil or get their default values, as
+// the specification defines them.
+//
+// Digital Negative (DNG) Specification (Version 1.7.1.0):
+// https://helpx.adobe.com/camera-raw/digital-negative.html
+type DNG struct {
+	// Version is the version of the DNG specification used by the file.
+	Version Version
+
+	// BackwardVersion is the oldest version of the DNG specification which
+	// is able to read the file.
+	BackwardVersion Version
+
+	// Camera.
+	UniqueCameraModel    string
+	LocalizedCameraModel string
+	CameraSerialNumber   string
+
+	// ColorPlanes is the number of colour planes of the camera, usually 3.
+	ColorPlanes int
+
+	// Illuminants of the colour calibration. The second one is nil for
+	// cameras calibrated under a single illuminant.
+	CalibrationIlluminant1 exif.LightSource
+	CalibrationIlluminant2 *exif.LightSource
+
+	// ColorMatrix converts XYZ values into reference camera colours under
+	// the calibration illuminant. It has ColorPlanes rows and 3 columns.
+	ColorMatrix1 *Matrix
+	ColorMatrix2 *Matrix
+
+	// CameraCalibration converts reference camera colours into colours of
+	// the individual camera. It is a square matrix of ColorPlanes size.
+	CameraCalibration1 *Matrix
+	CameraCalibration2 *Matrix
+
+	// ReductionMatrix reduces camera colours to XYZ values, when there are
+	// more than 3 colour planes. It has 3 rows and ColorPlanes columns.
+	ReductionMatrix1 *Matrix
+	ReductionMatrix2 *Matrix
+
+	// ForwardMatrix converts white balanced camera colours into XYZ values
+	// of the D50 white. It has 3 rows and ColorPlanes columns.
+	ForwardMatrix1 *Matrix
+	ForwardMatrix2 *Matrix
+
+	// AnalogBalance is the gain applied to each colour plane before
+	// digitisation. The default value is 1 for each plane.
+	AnalogBalance []float64
+
+	// White balance of the shot, either as the neutral camera colour or as
+	// the chromaticity of the white. Usually only one of them is stored.
+	AsShotNeutral []float64
+	AsShotWhiteXY *Chromaticity
+
+	// Exposure and noise.
+	BaselineExposure    *float64
+	BaselineNoise       *float64
+	BaselineSharpness   *float64
+	LinearResponseLimit *float64
+
+	// MD5 digests of the raw image data and of the original raw file.
+	// RawImageDigest is the legacy digest, NewRawImageDigest is the digest
+	// of tiles introduced in DNG 1.4.
+	RawImageDigest        []byte
+	NewRawImageDigest     []byte
+	OriginalRawFileDigest []byte
+
+	// OriginalRawFileData is the embedded original raw file, as it is
+	// stored.
+	OriginalRawFileData []byte
+
+	// Raw is the raw image.
+	Raw *RawImage
+
+	// Errors are errors of values of the first IFD and of the raw IFD which
+	// could not be read. Fields of such values are left empty or get their
+	// default values, other fields are read as usual.
+	Errors []error
+}
+
+// RawImage describes the raw image data: its linearization, black and white
+// levels and the areas of the sensor.
+type RawImage struct {
+	// Path is the path of the directory of the raw image, e.g.
+	// 'IFD0/SubIFD'.
+	Path string
+
+	// Size of the stored image in pixels.
+	Width  int
+	Length int
+
+	// Samples.
+	SamplesPerPixel int
+	BitsPerSample   []int
+	SampleFormat    int
+
+	// PhotometricInterpretation is either PhotometricCFA or
+	// PhotometricLinearRaw.
+	PhotometricInterpretation int
+
+	// CFA is the colour filter array of CFA images. It is nil for linear
+	// raw images.
+	CFA *CFA
+
+	// Compression and PlanarConfiguration of the stored image data.
+	Compression         int
+	PlanarConfiguration int
+
+	// IsTiled flag is set when the image is stored in tiles, otherwise it is
+	// stored in strips. BlockWidth and BlockLength are the size of a tile or
+	// of a strip in pixels, BlockOffsets and BlockByteCounts are positions
+	// and sizes of tiles or strips.
+	IsTiled         bool
+	BlockWidth      int
+	BlockLength     int
+	BlockOffsets    []int
+	BlockByteCounts []int
+
+	// LinearizationTable maps stored values into linear values. It is nil
+	// when the stored values are linear.
+	LinearizationTable []int
+
+	// BlackLevelRepeatRows and BlackLevelRepeatColumns are the size of the
+	// pattern of black levels. The default pattern is a single pixel.
+	BlackLevelRepeatRows    int
+	BlackLevelRepeatColumns int
+
+	// BlackLevel are black levels of the pattern, stored by rows, then by
+	// columns, then by samples. The default level is 0.
+	BlackLevel []float64
+
+	// BlackLevelDeltaH and BlackLevelDeltaV are additions to black levels
+	// of each column and of each row of the active area.
+	BlackLevelDeltaH []float64
+	BlackLevelDeltaV []float64
+
+	// WhiteLevel is the fully saturated value of each sample. The default
+	// level is the largest value of the sample.
+	WhiteLevel []int
+
+	// ActiveArea is the area of the image containing the image data. The
+	// default area is the whole image.
+	ActiveArea image.Rectangle
+
+	// MaskedAreas are areas of the image covered by the mask of the sensor,
+	// which may be used to measure black levels.
+	MaskedAreas []image.Rectangle
+
+	// DefaultScale is the horizontal and the vertical scale which makes
+	// pixels square. The default scale is 1.
+	DefaultScale [PairSize]float64
+
+	// DefaultCropOrigin and DefaultCropSize are the horizontal and the
+	// vertical origin and size of the final image in the active area, in raw
+	// pixels. The default crop is the whole active area.
+	DefaultCropOrigin [PairSize]float64
+	DefaultCropSize   [PairSize]float64
+
+	// BayerGreenSplit tells how closely the values of green pixels of blue
+	// and red rows track each other.
+	BayerGreenSplit *int
+
+	// OpcodeList1, OpcodeList2 and OpcodeList3 are opcodes applied to the
+	// raw image as it is read from the file, after it is linearized and
+	// after it is demosaiced, respectively.
+	OpcodeList1 []*Opcode
+	OpcodeList2 []*Opcode
+	OpcodeList3 []*Opcode
+}
+
+// New constructs the DNG model of the TIFF.
+func New(t *tiff.TIFF) (d *DNG, err error) {
+	dirs := t.Directories()
+	if len(dirs) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromDirectory(dirs[0])
+}
+
+// NewFromDirectory constructs the DNG model of the first IFD of a DNG file.
+// The raw image is searched in the IFD and in its SubIFDs.
+func NewFromDirectory(ifd0 *tiff.Directory) (d *DNG, err error) {
+	if _, ok := ifd0.EntriesByTagNumber[tag.DNGVersion]; !ok {
+		return nil, errors.New(ErrDNGVersionIsMissing)
+	}
+
+	d = &DNG{}
+	d.readIFD0(ifd0)
+
+	rawDir := FindRawDirectory(ifd0)
+	if rawDir == nil {
+		return nil, errors.New(ErrRawIFDIsMissing)
+	}
+
+	var rawErrors []error
+	d.Raw, rawErrors = newRawImage(rawDir)
+	for _, rawErr := range rawErrors {
+		d.Errors = append(d.Errors, fmt.Errorf(ErrInRawIFD, rawDir.Path, rawErr.Error()))
+	}
+
+	return d, nil
+}
+
+// FindRawDirectory returns the directory of the raw image of the DNG file
+// whose first IFD is specified, or nil. The raw image is the main image,
+// i.e. its NewSubfileType is 0. It is usually stored in a SubIFD, while the
+// first IFD stores a preview. SubIFDs are searched before the IFD itself.
+func FindRawDirectory(ifd0 *tiff.Directory) (d *tiff.Directory) {
+	d = findRawSubIFD(ifd0)
+	if d != nil {
+		return d
+	}
+
+	if isMainImage(ifd0) {
+		return ifd0
+	}
+
+	return nil
+}
+
+// findRawSubIFD searches the main image in SubIFDs of the directory.
+func findRawSubIFD(parent *tiff.Directory) (d *tiff.Directory) {
+	for _, child := range parent.Children {
+		if child.Parent.Tag != tag.SubIFDs {
+			continue
+		}
+
+		if isMainImage(child) {
+			return child
+		}
+
+		d = findRawSubIFD(child)
+		if d != nil {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// isMainImage tells whether the directory stores the main image.
+func isMainImage(d *tiff.Directory) bool {
+	if _, ok := d.EntriesByTagNumber[tag.ImageWidth]; !ok {
+		return false
+	}
+
+	r := ifd.NewEntryReader(d.EntriesByTagNumber)
+	nst := r.Integer(tag.NewSubfileType)
+	if len(r.Errors) > 0 {
+		return false
+	}
+
+	return (nst == nil) || (*nst == NewSubfileTypeMainImage)
+}
+
+// readIFD0 reads values of the first IFD.
+func (d *DNG) readIFD0(ifd0 *tiff.Directory) {
+	r := ifd.NewEntryReader(ifd0.EntriesByTagNumber)
+
+	// Versions.
+	copy(d.Version[:], r.Bytes(tag.DNGVersion))
+	backwardVersion := r.Bytes(tag.DNGBackwardVersion)
+	if len(backwardVersion) == VersionSize {
+		copy(d.BackwardVersion[:], backwardVersion)
+	} else {
+		// The default is the version with the last two bytes set to 0.
+		d.BackwardVersion = Version{d.Version[0], d.Version[1]}
+	}
+
+	// Camera.
+	d.UniqueCameraModel = r.String(tag.UniqueCameraModel)
+	d.LocalizedCameraModel = r.String(tag.LocalizedCameraModel)
+	d.CameraSerialNumber = r.String(tag.CameraSerialNumber)
+
+	// Colour calibration.
+	d.ColorPlanes = len(r.Numbers(tag.ColorMatrix1)) / XYZComponentsCount
+	if illuminant := r.Integer(tag.CalibrationIlluminant1); illuminant != nil {
+		d.CalibrationIlluminant1 = exif.LightSource(*illuminant)
+	}
+	if illuminant := r.Integer(tag.CalibrationIlluminant2); illuminant != nil {
+		ls := exif.LightSource(*illuminant)
+		d.CalibrationIlluminant2 = &ls
+	}
+	d.ColorMatrix1 = readMatrix(r, tag.ColorMatrix1, d.ColorPlanes)
+	d.ColorMatrix2 = readMatrix(r, tag.ColorMatrix2, d.ColorPlanes)
+	d.CameraCalibration1 = readMatrix(r, tag.CameraCalibration1, d.ColorPlanes)
+	d.CameraCalibration2 = readMatrix(r, tag.CameraCalibration2, d.ColorPlanes)
+	d.ReductionMatrix1 = readMatrix(r, tag.ReductionMatrix1, XYZComponentsCount)
+	d.ReductionMatrix2 = readMatrix(r, tag.ReductionMatrix2, XYZComponentsCount)
+	d.ForwardMatrix1 = readMatrix(r, tag.ForwardMatrix1, XYZComponentsCount)
+	d.ForwardMatrix2 = readMatrix(r, tag.ForwardMatrix2, XYZComponentsCount)
+
+	d.AnalogBalance = r.Numbers(tag.AnalogBalance)
+	if d.AnalogBalance == nil {
+		d.AnalogBalance = make([]float64, d.ColorPlanes)
+		for j := range d.AnalogBalance {
+			d.AnalogBalance[j] = 1
+		}
+	}
+
+	// White balance.
+	d.AsShotNeutral = r.Numbers(tag.AsShotNeutral)
+	if xy := r.NumbersOfCount(tag.AsShotWhiteXY, ChromaticitySize); xy != nil {
+		d.AsShotWhiteXY = &Chromaticity{X: xy[0], Y: xy[1]}
+	}
+
+	// Exposure and noise.
+	d.BaselineExposure = r.Number(tag.BaselineExposure)
+	d.BaselineNoise = r.Number(tag.BaselineNoise)
+	d.BaselineSharpness = r.Number(tag.BaselineSharpness)
+	d.LinearResponseLimit = r.Number(tag.LinearResponseLimit)
+
+	// Digests.
+	d.RawImageDigest = r.Bytes(tag.RawImageDigest)
+	d.NewRawImageDigest = r.Bytes(tag.NewRawImageDigest)
+	d.OriginalRawFileDigest = r.Bytes(tag.OriginalRawFileDigest)
+	d.OriginalRawFileData = r.Bytes(tag.OriginalRawFileData)
+
+	d.checkColorPlanes(r)
+	d.Errors = append(d.Errors, r.Errors...)
+}
+
+// checkColorPlanes checks that sizes of colour values match the number of
+// colour planes. Matrices of wrong sizes are dropped, the analog balance of
+// a wrong size is replaced by the default one.
+func (d *DNG) checkColorPlanes(r *ifd.EntryReader) {
+	if (d.ColorMatrix1 != nil) && (d.ColorMatrix1.Columns != XYZComponentsCount) {
+		r.Fail(tag.ColorMatrix1, fmt.Errorf(ErrValueSize, len(d.ColorMatrix1.Values)))
+		d.ColorMatrix1 = nil
+	}
+
+	matrices := []struct {
+		tg      tag.Tag
+		m       **Matrix
+		rows    int
+		columns int
+	}{
+		{tag.ColorMatrix2, &d.ColorMatrix2, d.ColorPlanes, XYZComponentsCount},
+		{tag.CameraCalibration1, &d.CameraCalibration1, d.ColorPlanes, d.ColorPlanes},
+		{tag.CameraCalibration2, &d.CameraCalibration2, d.ColorPlanes, d.ColorPlanes},
+		{tag.ReductionMatrix1, &d.ReductionMatrix1, XYZComponentsCount, d.ColorPlanes},
+		{tag.ReductionMatrix2, &d.ReductionMatrix2, XYZComponentsCount, d.ColorPlanes},
+		{tag.ForwardMatrix1, &d.ForwardMatrix1, XYZComponentsCount, d.ColorPlanes},
+		{tag.ForwardMatrix2, &d.ForwardMatrix2, XYZComponentsCount, d.ColorPlanes},
+	}
+	for _, x := range matrices {
+		m := *x.m
+		if (m != nil) && ((m.Rows != x.rows) || (m.Columns != x.columns)) {
+			r.Fail(x.tg, fmt.Errorf(ErrValueSize, len(m.Values)))
+			*x.m = nil
+		}
+	}
+
+	if len(d.AnalogBalance) != d.ColorPlanes {
+		r.Fail(tag.AnalogBalance, fmt.Errorf(ErrValueSize, len(d.AnalogBalance)))
+		d.AnalogBalance = make([]float64, d.ColorPlanes)
+		for j := range d.AnalogBalance {
+			d.AnalogBalance[j] = 1
+		}
+	}
+	if (d.AsShotNeutral != nil) && (len(d.AsShotNeutral) != d.ColorPlanes) {
+		r.Fail(tag.AsShotNeutral, fmt.Errorf(ErrValueSize, len(d.AsShotNeutral)))
+		d.AsShotNeutral = nil
+	}
+}
+
+// newRawImage reads values of the directory of the raw image. Errors of
+// values which could not be read are returned along with the image.
+func newRawImage(dir *tiff.Directory) (ri *RawImage, errs []error) {
+	r := ifd.NewEntryReader(dir.EntriesByTagNumber)
+	ri = &RawImage{
+		Path:                    dir.Path,
+		SamplesPerPixel:         1,
+		BlackLevelRepeatRows:    1,
+		BlackLevelRepeatColumns: 1,
+		DefaultScale:            [PairSize]float64{1, 1},
+	}
+
+	// Image.
+	if v := r.Integer(tag.ImageWidth); v != nil {
+		ri.Width = *v
+	}
+	if v := r.Integer(tag.ImageLength); v != nil {
+		ri.Length = *v
+	}
+	if v := r.Integer(tag.SamplesPerPixel); v != nil {
+		ri.SamplesPerPixel = *v
+	}
+	ri.BitsPerSample = r.Integers(tag.BitsPerSample)
+	if ri.BitsPerSample == nil {
+		ri.BitsPerSample = []int{1}
+	}
+	ri.LinearizationTable = r.Integers(tag.LinearizationTable)
+	ri.BayerGreenSplit = r.Integer(tag.BayerGreenSplit)
+
+	// Areas.
+	ri.ActiveArea = image.Rect(0, 0, ri.Width, ri.Length)
+	if v := r.Integers(tag.ActiveArea); v != nil {
+		if len(v) != RectangleSize {
+			r.Fail(tag.ActiveArea, fmt.Errorf(ErrValueSize, len(v)))
+		} else {
+			ri.ActiveArea = rectangle(v)
+		}
+	}
+	if v := r.Integers(tag.MaskedAreas); v != nil {
+		if len(v)%RectangleSize != 0 {
+			r.Fail(tag.MaskedAreas, fmt.Errorf(ErrValueSize, len(v)))
+		}
+		for j := 0; j+RectangleSize <= len(v); j += RectangleSize {
+			ri.MaskedAreas = append(ri.MaskedAreas, rectangle(v[j:j+RectangleSize]))
+		}
+	}
+
+	// Crop and scale.
+	ri.DefaultCropSize = [PairSize]float64{float64(ri.ActiveArea.Dx()), float64(ri.ActiveArea.Dy())}
+	readPair(r, tag.DefaultScale, &ri.DefaultScale)
+	readPair(r, tag.DefaultCropOrigin, &ri.DefaultCropOrigin)
+	readPair(r, tag.DefaultCropSize, &ri.DefaultCropSize)
+
+	// Levels.
+	ri.readBlackLevels(r)
+	ri.WhiteLevel = r.Integers(tag.WhiteLevel)
+	if ri.WhiteLevel == nil {
+		ri.WhiteLevel = make([]int, ri.SamplesPerPixel)
+		for j := range ri.WhiteLevel {
+			ri.WhiteLevel[j] = (1 << ri.bitsPerSample(j)) - 1
+		}
+	} else if len(ri.WhiteLevel) != ri.SamplesPerPixel {
+		r.Fail(tag.WhiteLevel, fmt.Errorf(ErrValueSize, len(ri.WhiteLevel)))
+	}
+
+	// Image data.
+	ri.readDataLayout(r)
+
+	// Opcodes.
+	ri.OpcodeList1 = readOpcodes(r, tag.OpcodeList1)
+	ri.OpcodeList2 = readOpcodes(r, tag.OpcodeList2)
+	ri.OpcodeList3 = readOpcodes(r, tag.OpcodeList3)
+
+	return ri, r.Errors
+}
+
+// readBlackLevels reads the pattern of black levels and its deltas.
+func (ri *RawImage) readBlackLevels(r *ifd.EntryReader) {
+	if v := r.Integers(tag.BlackLevelRepeatDim); v != nil {
+		if (len(v) != RepeatDimSize) || (v[0] <= 0) || (v[1] <= 0) {
+			r.Fail(tag.BlackLevelRepeatDim, fmt.Errorf(ErrValueSize, v))
+			return
+		}
+		ri.BlackLevelRepeatRows, ri.BlackLevelRepeatColumns = v[0], v[1]
+	}
+
+	size := ri.BlackLevelRepeatRows * ri.BlackLevelRepeatColumns * ri.SamplesPerPixel
+	ri.BlackLevel = r.Numbers(tag.BlackLevel)
+	if ri.BlackLevel == nil {
+		ri.BlackLevel = make([]float64, size)
+	} else if len(ri.BlackLevel) != size {
+		r.Fail(tag.BlackLevel, fmt.Errorf(ErrValueSize, len(ri.BlackLevel)))
+	}
+
+	ri.BlackLevelDeltaH = r.Numbers(tag.BlackLevelDeltaH)
+	if (ri.BlackLevelDeltaH != nil) && (len(ri.BlackLevelDeltaH) != ri.ActiveArea.Dx()) {
+		r.Fail(tag.BlackLevelDeltaH, fmt.Errorf(ErrValueSize, len(ri.BlackLevelDeltaH)))
+	}
+
+	ri.BlackLevelDeltaV = r.Numbers(tag.BlackLevelDeltaV)
+	if (ri.BlackLevelDeltaV != nil) && (len(ri.BlackLevelDeltaV) != ri.ActiveArea.Dy()) {
+		r.Fail(tag.BlackLevelDeltaV, fmt.Errorf(ErrValueSize, len(ri.BlackLevelDeltaV)))
+	}
+}
+
+// readPair reads the value of two numbers into the array, if the value
+// exists.
+func readPair(r *ifd.EntryReader, tg tag.Tag, p *[PairSize]float64) {
+	copy(p[:], r.NumbersOfCount(tg, PairSize))
+}
+
+// rectangle converts top, left, bottom and right coordinates into a
+// rectangle.
+func rectangle(tlbr []int) image.Rectangle {
+	return image.Rect(tlbr[1], tlbr[0], tlbr[3], tlbr[2])
+}
+
+// bitsPerSample returns the number of bits of the sample.
+func (ri *RawImage) bitsPerSample(sample int) int {
+	if sample < len(ri.BitsPerSample) {
+		return ri.BitsPerSample[sample]
+	}
+
+	return ri.BitsPerSample[len(ri.BitsPerSample)-1]
+}
+
+// BlackLevelAt returns the black level of the sample of the pixel. The row
+// and the column are counted from the top left corner of the active area.
+// The pattern of black levels is tiled over the active area, and deltas of
+// the row and of the column are added to it.
+func (ri *RawImage) BlackLevelAt(row, column, sample int) (level float64) {
+	idx := ((row%ri.BlackLevelRepeatRows)*ri.BlackLevelRepeatColumns+column%ri.BlackLevelRepeatColumns)*ri.SamplesPerPixel + sample
+	if idx < len(ri.BlackLevel) {
+		level = ri.BlackLevel[idx]
+	}
+
+	if column < len(ri.BlackLevelDeltaH) {
+		level += ri.BlackLevelDeltaH[column]
+	}
+	if row < len(ri.BlackLevelDeltaV) {
+		level += ri.BlackLevelDeltaV[row]
+	}
+
+	return level
+}
+
+// Linearize converts the stored value into the linear value using the
+// linearization table. Values beyond the table are mapped to its last
+// value.
+func (ri *RawImage) Linearize(value int) int {
+	if len(ri.LinearizationTable) == 0 {
+		return value
+	}
+
+	if value < 0 {
+		value = 0
+	}
+	if value >= len(ri.LinearizationTable) {
+		value = len(ri.LinearizationTable) - 1
+	}
+
+	return ri.LinearizationTable[value]
+}
+
+// DefaultCrop returns the default crop of the final image in coordinates of
+// the stored image. Fractional coordinates are rounded.
+func (ri *RawImage) DefaultCrop() image.Rectangle {
+	x := float64(ri.ActiveArea.Min.X) + ri.DefaultCropOrigin[0]
+	y := float64(ri.ActiveArea.Min.Y) + ri.DefaultCropOrigin[1]
+
+	return image.Rect(
+		int(math.Round(x)),
+		int(math.Round(y)),
+		int(math.Round(x+ri.DefaultCropSize[0])),
+		int(math.Round(y+ri.DefaultCropSize[1])),
+	).Intersect(ri.ActiveArea)
+}
+
+// FinalSize returns the size of the final image: the default crop scaled by
+// the default scale.
+func (ri *RawImage) FinalSize() (width, length float64) {
+	return ri.DefaultCropSize[0] * ri.DefaultScale[0], ri.DefaultCropSize[1] * ri.DefaultScale[1]
+}
@@ -0,0 +1,343 @@
+package dng
+
+import (
+	"errors"
+	"image"
+	"math"
+)
+
+const (
+	ErrTrimBoundsAreNotValid = "trim bounds are not valid"
+)
+
+// Maximum degree of the MapPolynomial opcode.
+const maxPolynomialDegree = 8
+
+// TrimBounds crops the image.
+type TrimBounds struct {
+	// Bounds is the rectangle of the image which is kept.
+	Bounds image.Rectangle
+}
+
+// decodeTrimBounds decodes parameters of the TrimBounds opcode.
+func decodeTrimBounds(p *paramReader) Operation {
+	top, left, bottom, right := p.int(), p.int(), p.int(), p.int()
+	return &TrimBounds{Bounds: image.Rect(left, top, right, bottom)}
+}
+
+// Apply crops the raster.
+func (t *TrimBounds) Apply(r *Raster) (err error) {
+	b := t.Bounds.Intersect(image.Rect(0, 0, r.Width, r.Height))
+	if b.Empty() {
+		return errors.New(ErrTrimBoundsAreNotValid)
+	}
+
+	pix := make([]float32, 0, b.Dx()*b.Dy()*r.Planes)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		start := (y*r.Width + b.Min.X) * r.Planes
+		pix = append(pix, r.Pix[start:start+b.Dx()*r.Planes]...)
+	}
+
+	r.Width, r.Height, r.Pix = b.Dx(), b.Dy(), pix
+	return nil
+}
+
+// Area selects samples processed by an opcode: rows and columns of the
+// rectangle, taken with the pitches, of a range of planes.
+type Area struct {
+	Bounds      image.Rectangle
+	Plane       int
+	Planes      int
+	RowPitch    int
+	ColumnPitch int
+}
+
+// readArea reads the area which starts parameters of many opcodes.
+func (p *paramReader) readArea() (a Area) {
+	top, left, bottom, right := p.int(), p.int(), p.int(), p.int()
+	a = Area{
+		Bounds:      image.Rect(left, top, right, bottom),
+		Plane:       p.int(),
+		Planes:      p.int(),
+		RowPitch:    p.int(),
+		ColumnPitch: p.int(),
+	}
+	p.check((a.Planes > 0) && (a.RowPitch > 0) && (a.ColumnPitch > 0), "area")
+
+	return a
+}
+
+// rows returns the number of rows selected by the area.
+func (a Area) rows() int {
+	return (a.Bounds.Dy() + a.RowPitch - 1) / a.RowPitch
+}
+
+// columns returns the number of columns selected by the area.
+func (a Area) columns() int {
+	return (a.Bounds.Dx() + a.ColumnPitch - 1) / a.ColumnPitch
+}
+
+// process calls the function for each sample of the area which lies inside
+// the raster and replaces the sample by the result. The function receives
+// indices of the row and the column in the area, the plane relative to the
+// first plane of the area, and the sample.
+func (a Area) process(r *Raster, fn func(row, column, plane int, v float64) float64) {
+	b := a.Bounds.Intersect(image.Rect(0, 0, r.Width, r.Height))
+
+	for y := a.Bounds.Min.Y; y < b.Max.Y; y += a.RowPitch {
+		if y < b.Min.Y {
+			continue
+		}
+		for x := a.Bounds.Min.X; x < b.Max.X; x += a.ColumnPitch {
+			if x < b.Min.X {
+				continue
+			}
+			for plane := a.Plane; (plane < a.Plane+a.Planes) && (plane < r.Planes); plane++ {
+				row := (y - a.Bounds.Min.Y) / a.RowPitch
+				column := (x - a.Bounds.Min.X) / a.ColumnPitch
+				v := fn(row, column, plane-a.Plane, float64(r.At(x, y, plane)))
+				r.Set(x, y, plane, float32(v))
+			}
+		}
+	}
+}
+
+// MapTable maps samples of the area through a table of 16-bit values.
+type MapTable struct {
+	Area  Area
+	Table []uint16
+}
+
+// decodeMapTable decodes parameters of the MapTable opcode.
+func decodeMapTable(p *paramReader) Operation {
+	m := &MapTable{Area: p.readArea()}
+
+	size := p.count(2)
+	p.check(size > 0, "table size")
+	m.Table = make([]uint16, size)
+	for j := range m.Table {
+		m.Table[j] = p.uint16()
+	}
+
+	return m
+}
+
+// Apply maps samples. Samples beyond the end of the table get its last
+// value.
+func (m *MapTable) Apply(r *Raster) (err error) {
+	last := len(m.Table) - 1
+
+	m.Area.process(r, func(_, _, _ int, v float64) float64 {
+		index := int(clamp(math.Round(v*RasterScale), 0, float64(last)))
+		return float64(m.Table[index]) / RasterScale
+	})
+
+	return nil
+}
+
+// MapPolynomial maps samples of the area through a polynomial.
+type MapPolynomial struct {
+	Area Area
+
+	// Coefficients are coefficients of the polynomial, starting with the
+	// constant term.
+	Coefficients []float64
+}
+
+// decodeMapPolynomial decodes parameters of the MapPolynomial opcode.
+func decodeMapPolynomial(p *paramReader) Operation {
+	m := &MapPolynomial{Area: p.readArea()}
+
+	degree := p.int()
+	p.check(degree <= maxPolynomialDegree, "degree")
+	if p.err != nil {
+		return m
+	}
+
+	m.Coefficients = make([]float64, degree+1)
+	for j := range m.Coefficients {
+		m.Coefficients[j] = p.float64()
+	}
+
+	return m
+}
+
+// Apply maps samples, which are clipped to the range from 0 to 1.
+func (m *MapPolynomial) Apply(r *Raster) (err error) {
+	m.Area.process(r, func(_, _, _ int, v float64) float64 {
+		var result float64
+		for j := len(m.Coefficients) - 1; j >= 0; j-- {
+			result = result*v + m.Coefficients[j]
+		}
+
+		return clamp(result, 0, 1)
+	})
+
+	return nil
+}
+
+// GainMap multiplies samples of the area by gains interpolated from a grid.
+type GainMap struct {
+	Area Area
+
+	// PointsV and PointsH are numbers of rows and columns of the map.
+	PointsV int
+	PointsH int
+
+	// SpacingV and SpacingH are distances between points of the map, and
+	// OriginV and OriginH are coordinates of its first point, relative to
+	// the size of the image.
+	SpacingV float64
+	SpacingH float64
+	OriginV  float64
+	OriginH  float64
+
+	// Planes is the number of planes of the map.
+	Planes int
+
+	// Gains are gains stored by rows, columns and planes.
+	Gains []float32
+}
+
+// decodeGainMap decodes parameters of the GainMap opcode.
+func decodeGainMap(p *paramReader) Operation {
+	g := &GainMap{
+		Area:     p.readArea(),
+		PointsV:  p.int(),
+		PointsH:  p.int(),
+		SpacingV: p.float64(),
+		SpacingH: p.float64(),
+		OriginV:  p.float64(),
+		OriginH:  p.float64(),
+		Planes:   p.int(),
+	}
+	p.check((g.PointsV > 0) && (g.PointsH > 0) && (g.Planes > 0), "size of map")
+	p.check((g.SpacingV > 0) && (g.SpacingH > 0), "spacing of map")
+	remaining := uint64(len(p.data)-p.pos) / 4
+	points := uint64(g.PointsV) * uint64(g.PointsH)
+	p.check((points <= remaining) && (uint64(g.Planes) <= remaining) && (points*uint64(g.Planes) == remaining), "size of map")
+	if p.err != nil {
+		return g
+	}
+
+	g.Gains = make([]float32, g.PointsV*g.PointsH*g.Planes)
+	for j := range g.Gains {
+		g.Gains[j] = p.float32()
+	}
+
+	return g
+}
+
+// gain returns the gain of the map point.
+func (g *GainMap) gain(row, column, plane int) float64 {
+	row = min(max(row, 0), g.PointsV-1)
+	column = min(max(column, 0), g.PointsH-1)
+	return float64(g.Gains[(row*g.PointsH+column)*g.Planes+plane])
+}
+
+// Apply multiplies samples by gains. Gains between points of the map are
+// interpolated bilinearly, gains outside the map are those of its nearest
+// edge. Maps of a single plane are used for all planes of the area.
+func (g *GainMap) Apply(r *Raster) (err error) {
+	g.Area.process(r, func(row, column, plane int, v float64) float64 {
+		y := g.Area.Bounds.Min.Y + row*g.Area.RowPitch
+		x := g.Area.Bounds.Min.X + column*g.Area.ColumnPitch
+		if plane >= g.Planes {
+			plane = g.Planes - 1
+		}
+
+		mapV := ((float64(y)+0.5)/float64(r.Height) - g.OriginV) / g.SpacingV
+		mapH := ((float64(x)+0.5)/float64(r.Width) - g.OriginH) / g.SpacingH
+		mapV = clamp(mapV, 0, float64(g.PointsV-1))
+		mapH = clamp(mapH, 0, float64(g.PointsH-1))
+
+		r0, c0 := int(mapV), int(mapH)
+		fr, fc := mapV-float64(r0), mapH-float64(c0)
+		top := g.gain(r0, c0, plane)*(1-fc) + g.gain(r0, c0+1, plane)*fc
+		bottom := g.gain(r0+1, c0, plane)*(1-fc) + g.gain(r0+1, c0+1, plane)*fc
+
+		return v * (top*(1-fr) + bottom*fr)
+	})
+
+	return nil
+}
+
+// PerRowOrColumn adds a value to, or multiplies by a value, samples of each
+// row or column of the area. It is the DeltaPerRow, DeltaPerColumn,
+// ScalePerRow or ScalePerColumn opcode.
+type PerRowOrColumn struct {
+	Area   Area
+	Values []float32
+
+	// ByColumn tells whether values are of columns rather than of rows.
+	ByColumn bool
+
+	// Scale tells whether samples are multiplied by values rather than
+	// values are added to them.
+	Scale bool
+}
+
+// decodeDeltaPerRow decodes parameters of the DeltaPerRow opcode.
+func decodeDeltaPerRow(p *paramReader) Operation {
+	return p.readPerRowOrColumn(false, false)
+}
+
+// decodeDeltaPerColumn decodes parameters of the DeltaPerColumn opcode.
+func decodeDeltaPerColumn(p *paramReader) Operation {
+	return p.readPerRowOrColumn(true, false)
+}
+
+// decodeScalePerRow decodes parameters of the ScalePerRow opcode.
+func decodeScalePerRow(p *paramReader) Operation {
+	return p.readPerRowOrColumn(false, true)
+}
+
+// decodeScalePerColumn decodes parameters of the ScalePerColumn opcode.
+func decodeScalePerColumn(p *paramReader) Operation {
+	return p.readPerRowOrColumn(true, true)
+}
+
+// readPerRowOrColumn reads parameters of opcodes having a value per row or
+// per column.
+func (p *paramReader) readPerRowOrColumn(byColumn, scale bool) (o *PerRowOrColumn) {
+	o = &PerRowOrColumn{Area: p.readArea(), ByColumn: byColumn, Scale: scale}
+	if p.err != nil {
+		return o
+	}
+
+	expected := o.Area.rows()
+	if byColumn {
+		expected = o.Area.columns()
+	}
+
+	count := p.count(4)
+	p.check(count == expected, "number of values")
+	if p.err != nil {
+		return o
+	}
+
+	o.Values = make([]float32, count)
+	for j := range o.Values {
+		o.Values[j] = p.float32()
+	}
+
+	return o
+}
+
+// Apply changes samples. Scaled samples are clipped to the range from 0 to
+// 1.
+func (o *PerRowOrColumn) Apply(r *Raster) (err error) {
+	o.Area.process(r, func(row, column, _ int, v float64) float64 {
+		index := row
+		if o.ByColumn {
+			index = column
+		}
+
+		if o.Scale {
+			return clamp(v*float64(o.Values[index]), 0, 1)
+		}
+		return v + float64(o.Values[index])
+	})
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package dng
+
+import (
+	"image"
+	"math"
+)
+
+// Maximum Bayer phase of bad pixel opcodes.
+const maxBayerPhase = 3
+
+// FixBadPixelsConstant replaces pixels of a Bayer image having the constant
+// value by the mean of their neighbours of the same colour.
+type FixBadPixelsConstant struct {
+	// Constant is the 16-bit value of bad pixels.
+	Constant uint32
+
+	// BayerPhase is the colour of the top-left pixel: 0 is red, 1 is green
+	// of a red row, 2 is green of a blue row, 3 is blue.
+	BayerPhase uint32
+}
+
+// decodeFixBadPixelsConstant decodes parameters of the FixBadPixelsConstant
+// opcode.
+func decodeFixBadPixelsConstant(p *paramReader) Operation {
+	f := &FixBadPixelsConstant{
+		Constant:   p.uint32(),
+		BayerPhase: p.uint32(),
+	}
+	p.check(f.BayerPhase <= maxBayerPhase, "Bayer phase")
+
+	return f
+}
+
+// Apply replaces bad pixels.
+func (f *FixBadPixelsConstant) Apply(r *Raster) (err error) {
+	bad := func(x, y int) bool {
+		return math.Round(float64(r.At(x, y, 0))*RasterScale) == float64(f.Constant)
+	}
+
+	fixBadPixels(r, f.BayerPhase, bad)
+	return nil
+}
+
+// FixBadPixelsList replaces listed pixels and rectangles of a Bayer image by
+// the mean of their neighbours of the same colour.
+type FixBadPixelsList struct {
+	// BayerPhase is the colour of the top-left pixel: 0 is red, 1 is green
+	// of a red row, 2 is green of a blue row, 3 is blue.
+	BayerPhase uint32
+
+	// Points are bad pixels.
+	Points []image.Point
+
+	// Rectangles are bad areas.
+	Rectangles []image.Rectangle
+}
+
+// decodeFixBadPixelsList decodes parameters of the FixBadPixelsList opcode.
+func decodeFixBadPixelsList(p *paramReader) Operation {
+	f := &FixBadPixelsList{BayerPhase: p.uint32()}
+	p.check(f.BayerPhase <= maxBayerPhase, "Bayer phase")
+
+	points, rectangles := p.uint32(), p.uint32()
+	p.check(uint64(points)*8+uint64(rectangles)*16 == uint64(len(p.data)-p.pos), "number of bad pixels")
+	if p.err != nil {
+		return f
+	}
+
+	f.Points = make([]image.Point, 0, points)
+	for j := 0; j < int(points); j++ {
+		row, column := p.int(), p.int()
+		f.Points = append(f.Points, image.Pt(column, row))
+	}
+
+	f.Rectangles = make([]image.Rectangle, 0, rectangles)
+	for j := 0; j < int(rectangles); j++ {
+		top, left, bottom, right := p.int(), p.int(), p.int(), p.int()
+		f.Rectangles = append(f.Rectangles, image.Rect(left, top, right, bottom))
+	}
+
+	return f
+}
+
+// Apply replaces bad pixels.
+func (f *FixBadPixelsList) Apply(r *Raster) (err error) {
+	isBad := make([]bool, r.Width*r.Height)
+	bounds := image.Rect(0, 0, r.Width, r.Height)
+
+	for _, pt := range f.Points {
+		if pt.In(bounds) {
+			isBad[pt.Y*r.Width+pt.X] = true
+		}
+	}
+	for _, rect := range f.Rectangles {
+		rect = rect.Intersect(bounds)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				isBad[y*r.Width+x] = true
+			}
+		}
+	}
+
+	bad := func(x, y int) bool {
+		return isBad[y*r.Width+x]
+	}
+
+	fixBadPixels(r, f.BayerPhase, bad)
+	return nil
+}
+
+// Offsets of neighbours of the same colour in a Bayer image.
+var (
+	greenNeighbours = []image.Point{
+		{-1, -1}, {1, -1}, {-1, 1}, {1, 1},
+		{-2, 0}, {2, 0}, {0, -2}, {0, 2},
+	}
+	redBlueNeighbours = []image.Point{
+		{-2, 0}, {2, 0}, {0, -2}, {0, 2},
+		{-2, -2}, {2, -2}, {-2, 2}, {2, 2},
+	}
+)
+
+// fixBadPixels replaces bad pixels of all planes by the mean of their good
+// neighbours of the same colour. Pixels without good neighbours are left
+// untouched.
+func fixBadPixels(r *Raster, bayerPhase uint32, bad func(x, y int) bool) {
+	source := r.Clone()
+	phaseX, phaseY := int(bayerPhase&1), int(bayerPhase>>1)
+
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			if !bad(x, y) {
+				continue
+			}
+
+			neighbours := redBlueNeighbours
+			if (x+phaseX+y+phaseY)%2 == 1 {
+				neighbours = greenNeighbours
+			}
+
+			for plane := 0; plane < r.Planes; plane++ {
+				var sum float64
+				var count int
+				for _, n := range neighbours {
+					nx, ny := x+n.X, y+n.Y
+					if (nx < 0) || (ny < 0) || (nx >= r.Width) || (ny >= r.Height) || bad(nx, ny) {
+						continue
+					}
+
+					sum += float64(source.At(nx, ny, plane))
+					count++
+				}
+
+				if count > 0 {
+					r.Set(x, y, plane, float32(sum/float64(count)))
+				}
+			}
+		}
+	}
+}
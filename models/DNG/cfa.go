@@ -0,0 +1,179 @@
+package dng
+
+import (
+	"errors"
+	"fmt"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrImageIsNotCFA          = "image is not a CFA image"
+	ErrCFALayoutIsUnsupported = "CFA layout is not supported: %v"
+	ErrCFAColourIsUnknown     = "CFA pattern has a colour which is not a plane colour: %v"
+)
+
+// Values of the PhotometricInterpretation tag of raw images.
+const (
+	PhotometricCFA       = 32803
+	PhotometricLinearRaw = 34892
+)
+
+// CFALayoutRectangular is the layout of sensors having square pixels on a
+// rectangular grid.
+const CFALayoutRectangular = 1
+
+// CFA describes the colour filter array of a sensor. The pattern starts at
+// the top left corner of the active area.
+type CFA struct {
+	// RepeatRows and RepeatColumns are the size of the pattern.
+	RepeatRows    int
+	RepeatColumns int
+
+	// Pattern are colours of the pattern stored by rows, e.g. 0, 1, 1, 2
+	// for the red-green-green-blue Bayer pattern.
+	Pattern []byte
+
+	// PlaneColor are colours of colour planes. The default colours are red,
+	// green and blue.
+	PlaneColor []byte
+
+	// Layout is the spatial layout of the array.
+	Layout int
+}
+
+// readCFA reads the colour filter array of the raw image.
+func readCFA(r *ifd.EntryReader) (cfa *CFA) {
+	cfa = &CFA{
+		RepeatRows:    1,
+		RepeatColumns: 1,
+		PlaneColor:    []byte{0, 1, 2},
+		Layout:        CFALayoutRectangular,
+	}
+
+	if v := r.Integers(tag.CFARepeatPatternDim); v != nil {
+		if (len(v) != RepeatDimSize) || (v[0] <= 0) || (v[1] <= 0) {
+			r.Fail(tag.CFARepeatPatternDim, fmt.Errorf(ErrValueSize, v))
+			return nil
+		}
+		cfa.RepeatRows, cfa.RepeatColumns = v[0], v[1]
+	}
+
+	cfa.Pattern = r.Bytes(tag.CFAPattern2)
+	if len(cfa.Pattern) != cfa.RepeatRows*cfa.RepeatColumns {
+		r.Fail(tag.CFAPattern2, fmt.Errorf(ErrValueSize, len(cfa.Pattern)))
+		return nil
+	}
+
+	if v := r.Bytes(tag.CFAPlaneColor); v != nil {
+		cfa.PlaneColor = v
+	}
+	if v := r.Integer(tag.CFALayout); v != nil {
+		cfa.Layout = *v
+	}
+
+	for _, colour := range cfa.Pattern {
+		if cfa.planeOfColour(colour) < 0 {
+			r.Fail(tag.CFAPattern2, fmt.Errorf(ErrCFAColourIsUnknown, colour))
+			return nil
+		}
+	}
+
+	return cfa
+}
+
+// planeOfColour returns the index of the colour plane of the colour, or -1.
+func (cfa *CFA) planeOfColour(colour byte) int {
+	for plane, c := range cfa.PlaneColor {
+		if c == colour {
+			return plane
+		}
+	}
+
+	return -1
+}
+
+// PlaneAt returns the colour plane of the pixel. The row and the column are
+// counted from the top left corner of the active area.
+func (cfa *CFA) PlaneAt(row, column int) int {
+	colour := cfa.Pattern[(row%cfa.RepeatRows)*cfa.RepeatColumns+column%cfa.RepeatColumns]
+	return cfa.planeOfColour(colour)
+}
+
+// Demosaic interpolates the missing colours of the linear CFA image of the
+// active area bilinearly: each missing colour of a pixel is the mean of the
+// nearest pixels of the colour around it. The result has a plane for each
+// colour of the array. It is meant for quick previews rather than for the
+// best quality.
+func (ri *RawImage) Demosaic(linear *Raster) (rgb *Raster, err error) {
+	cfa := ri.CFA
+	if cfa == nil {
+		return nil, errors.New(ErrImageIsNotCFA)
+	}
+	if cfa.Layout != CFALayoutRectangular {
+		return nil, fmt.Errorf(ErrCFALayoutIsUnsupported, cfa.Layout)
+	}
+
+	planes := len(cfa.PlaneColor)
+	rgb, err = NewRaster(linear.Width, linear.Height, planes)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make([]float64, planes)
+	counts := make([]int, planes)
+	for y := 0; y < linear.Height; y++ {
+		for x := 0; x < linear.Width; x++ {
+			own := cfa.PlaneAt(y, x)
+			rgb.Set(x, y, own, linear.At(x, y, 0))
+
+			// Neighbours of the 3×3 window, then of the 5×5 window for
+			// colours which are rarer in the pattern.
+			clear(sums)
+			clear(counts)
+			for radius := 1; radius <= 2; radius++ {
+				collectNeighbours(linear, cfa, x, y, radius, sums, counts)
+
+				missing := false
+				for plane := range counts {
+					if (plane != own) && (counts[plane] == 0) {
+						missing = true
+					}
+				}
+				if !missing {
+					break
+				}
+			}
+
+			for plane := range sums {
+				if (plane != own) && (counts[plane] > 0) {
+					rgb.Set(x, y, plane, float32(sums[plane]/float64(counts[plane])))
+				}
+			}
+		}
+	}
+
+	return rgb, nil
+}
+
+// collectNeighbours sums samples of the neighbours of the pixel lying on the
+// border of the square of the radius around it, by their colour planes.
+func collectNeighbours(linear *Raster, cfa *CFA, x, y, radius int, sums []float64, counts []int) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if (max(dx, -dx) != radius) && (max(dy, -dy) != radius) {
+				continue
+			}
+
+			nx, ny := x+dx, y+dy
+			if (nx < 0) || (ny < 0) || (nx >= linear.Width) || (ny >= linear.Height) {
+				continue
+			}
+
+			plane := cfa.PlaneAt(ny, nx)
+			sums[plane] += float64(linear.At(nx, ny, 0))
+			counts[plane]++
+		}
+	}
+}
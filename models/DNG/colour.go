@@ -0,0 +1,239 @@
+package dng
+
+import (
+	"errors"
+	"math"
+
+	exif "github.com/vault-thirteen/TIFFer/models/EXIF"
+)
+
+const (
+	ErrColorMatrixIsMissing   = "ColorMatrix1 is missing"
+	ErrAsShotWhiteIsMissing   = "neither AsShotNeutral nor AsShotWhiteXY is set"
+	ErrTemperatureIsNotValid  = "colour temperature is not valid"
+	ErrChromaticityIsNotValid = "chromaticity is not valid"
+)
+
+// Parameters of the search of the white chromaticity of a neutral colour.
+const (
+	whiteSearchPasses    = 30
+	whiteSearchPrecision = 1e-7
+)
+
+// Chromaticity is a point of the CIE 1931 xy chromaticity diagram.
+type Chromaticity struct {
+	X float64
+	Y float64
+}
+
+// D50 is the chromaticity of the D50 illuminant, which is the white of the
+// profile connection space.
+var D50 = Chromaticity{X: 0.3457, Y: 0.3585}
+
+// XYZ returns XYZ values of the chromaticity with the luminance Y of 1.
+func (c Chromaticity) XYZ() []float64 {
+	return []float64{c.X / c.Y, 1, (1 - c.X - c.Y) / c.Y}
+}
+
+// Temperature returns the correlated colour temperature of the chromaticity
+// in kelvins. McCamy's approximation is used, which is accurate for
+// temperatures between 2000 K and 12500 K.
+func (c Chromaticity) Temperature() float64 {
+	n := (c.X - 0.3320) / (0.1858 - c.Y)
+	return 449*n*n*n + 3525*n*n + 6823.3*n + 5520.33
+}
+
+// chromaticityOfXYZ returns the chromaticity of XYZ values.
+func chromaticityOfXYZ(xyz []float64) (c Chromaticity, err error) {
+	sum := xyz[0] + xyz[1] + xyz[2]
+	if (sum <= 0) || math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return Chromaticity{}, errors.New(ErrChromaticityIsNotValid)
+	}
+
+	return Chromaticity{X: xyz[0] / sum, Y: xyz[1] / sum}, nil
+}
+
+// illuminantTemperatures are correlated colour temperatures of light sources
+// used as calibration illuminants, in kelvins. Temperatures of fluorescent
+// lamps are centres of their ranges.
+var illuminantTemperatures = map[exif.LightSource]float64{
+	exif.LightSourceStandardLightA:       2850,
+	exif.LightSourceTungsten:             2850,
+	exif.LightSourceISOStudioTungsten:    3200,
+	exif.LightSourceD50:                  5000,
+	exif.LightSourceD55:                  5500,
+	exif.LightSourceDaylight:             5500,
+	exif.LightSourceFineWeather:          5500,
+	exif.LightSourceFlash:                5500,
+	exif.LightSourceStandardLightB:       5500,
+	exif.LightSourceD65:                  6500,
+	exif.LightSourceStandardLightC:       6500,
+	exif.LightSourceCloudyWeather:        6500,
+	exif.LightSourceD75:                  7500,
+	exif.LightSourceShade:                7500,
+	exif.LightSourceDaylightFluorescent:  6400,
+	exif.LightSourceDayWhiteFluorescent:  5050,
+	exif.LightSourceCoolWhiteFluorescent: 4150,
+	exif.LightSourceFluorescent:          4150,
+	exif.LightSourceWhiteFluorescent:     3525,
+	exif.LightSourceWarmWhiteFluorescent: 2925,
+}
+
+// IlluminantTemperature returns the colour temperature of the calibration
+// illuminant in kelvins. Unknown and other light sources have no
+// temperature.
+func IlluminantTemperature(ls exif.LightSource) (temperature float64, ok bool) {
+	temperature, ok = illuminantTemperatures[ls]
+	return temperature, ok
+}
+
+// illuminantWeight returns the weight of the first calibration illuminant
+// for the colour temperature. Matrices are interpolated linearly by the
+// inverse of the temperature between the temperatures of the illuminants,
+// and are not extrapolated beyond them. When there is a single usable
+// illuminant, its weight is 1.
+func (d *DNG) illuminantWeight(temperature float64) (weight float64) {
+	if (d.ColorMatrix2 == nil) || (d.CalibrationIlluminant2 == nil) {
+		return 1
+	}
+
+	t1, ok1 := IlluminantTemperature(d.CalibrationIlluminant1)
+	t2, ok2 := IlluminantTemperature(*d.CalibrationIlluminant2)
+	if !ok1 || !ok2 || (t1 == t2) {
+		return 1
+	}
+
+	switch {
+	case temperature <= math.Min(t1, t2):
+		if t1 < t2 {
+			return 1
+		}
+		return 0
+	case temperature >= math.Max(t1, t2):
+		if t1 > t2 {
+			return 1
+		}
+		return 0
+	default:
+		return (1/temperature - 1/t2) / (1/t1 - 1/t2)
+	}
+}
+
+// XYZToCamera returns the matrix converting XYZ values into camera colours
+// for the white of the colour temperature in kelvins. Colour matrices and
+// camera calibrations of the two illuminants are interpolated, and the
+// analog balance is applied:
+//
+//	XYZToCamera = AnalogBalance × CameraCalibration × ColorMatrix.
+func (d *DNG) XYZToCamera(temperature float64) (m *Matrix, err error) {
+	if d.ColorMatrix1 == nil {
+		return nil, errors.New(ErrColorMatrixIsMissing)
+	}
+	if (temperature <= 0) || math.IsNaN(temperature) {
+		return nil, errors.New(ErrTemperatureIsNotValid)
+	}
+
+	weight := d.illuminantWeight(temperature)
+
+	cm := d.ColorMatrix1
+	if weight < 1 {
+		cm, err = interpolateMatrices(d.ColorMatrix1, d.ColorMatrix2, weight)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cc1 := d.CameraCalibration1
+	if cc1 == nil {
+		cc1 = IdentityMatrix(d.ColorPlanes)
+	}
+	cc2 := d.CameraCalibration2
+	if cc2 == nil {
+		cc2 = IdentityMatrix(d.ColorPlanes)
+	}
+
+	var cc *Matrix
+	cc, err = interpolateMatrices(cc1, cc2, weight)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err = DiagonalMatrix(d.AnalogBalance).Multiply(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Multiply(cm)
+}
+
+// CameraToXYZ returns the matrix converting camera colours into XYZ values
+// for the white of the colour temperature in kelvins. It is the inverse of
+// the XYZToCamera matrix.
+func (d *DNG) CameraToXYZ(temperature float64) (m *Matrix, err error) {
+	m, err = d.XYZToCamera(temperature)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Inverse()
+}
+
+// AsShotWhite returns the chromaticity of the white of the shot. When only
+// the neutral camera colour is stored, the chromaticity is searched
+// iteratively: the neutral colour is converted into XYZ values by the matrix
+// of the temperature of the previous guess, starting from D50, until the
+// guess stops changing.
+func (d *DNG) AsShotWhite() (white Chromaticity, err error) {
+	if d.AsShotWhiteXY != nil {
+		return *d.AsShotWhiteXY, nil
+	}
+	if d.AsShotNeutral == nil {
+		return Chromaticity{}, errors.New(ErrAsShotWhiteIsMissing)
+	}
+
+	last := D50
+	for pass := 0; pass < whiteSearchPasses; pass++ {
+		var cameraToXYZ *Matrix
+		cameraToXYZ, err = d.CameraToXYZ(last.Temperature())
+		if err != nil {
+			return Chromaticity{}, err
+		}
+
+		var xyz []float64
+		xyz, err = cameraToXYZ.MultiplyVector(d.AsShotNeutral)
+		if err != nil {
+			return Chromaticity{}, err
+		}
+
+		var next Chromaticity
+		next, err = chromaticityOfXYZ(xyz)
+		if err != nil {
+			return Chromaticity{}, err
+		}
+
+		if math.Abs(next.X-last.X)+math.Abs(next.Y-last.Y) < whiteSearchPrecision {
+			return next, nil
+		}
+
+		// The search may oscillate, the last step is halved.
+		if pass == whiteSearchPasses-1 {
+			next = Chromaticity{X: (last.X + next.X) / 2, Y: (last.Y + next.Y) / 2}
+		}
+
+		last = next
+	}
+
+	return last, nil
+}
+
+// AsShotXYZToCamera returns the matrix converting XYZ values into camera
+// colours for the white of the shot.
+func (d *DNG) AsShotXYZToCamera() (m *Matrix, err error) {
+	var white Chromaticity
+	white, err = d.AsShotWhite()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.XYZToCamera(white.Temperature())
+}
@@ -0,0 +1,182 @@
+package dng
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrDigestSizeIsNotValid         = "digest has %v bytes instead of %v"
+	ErrOriginalRawFileDataIsMissing = "OriginalRawFileData is missing"
+	ErrRawImageIsMissing            = "raw image is missing"
+)
+
+// DigestSize is the size of MD5 digests stored in DNG files.
+const DigestSize = md5.Size
+
+// NewDigestTileSize is the size of square tiles of the image which are
+// digested separately for the NewRawImageDigest tag.
+const NewDigestTileSize = 256
+
+// DigestCheck is the result of the verification of a digest.
+type DigestCheck struct {
+	// Tag is the tag storing the digest.
+	Tag tag.Tag
+
+	// Stored is the digest stored in the file, Computed is the digest of the
+	// data. Computed is nil when the digest could not be computed.
+	Stored   []byte
+	Computed []byte
+
+	// Passed flag is set when the digests are equal.
+	Passed bool
+
+	// Err tells why the digest could not be computed.
+	Err error
+}
+
+// Name returns the name of the tag storing the digest.
+func (dc *DigestCheck) Name() string {
+	return ifd.TagName(dc.Tag)
+}
+
+// VerifyDigests recomputes the digests stored in the file and compares them
+// with the stored ones. Only digests which exist in the file are checked:
+// RawImageDigest and NewRawImageDigest over the raw image data, and
+// OriginalRawFileDigest over the embedded original raw file.
+func (d *DNG) VerifyDigests(file io.ReaderAt) (checks []*DigestCheck) {
+	checks = make([]*DigestCheck, 0)
+
+	digests := []struct {
+		tag     tag.Tag
+		stored  []byte
+		compute func() ([]byte, error)
+	}{
+		{tag.RawImageDigest, d.RawImageDigest, func() ([]byte, error) {
+			if d.Raw == nil {
+				return nil, errors.New(ErrRawImageIsMissing)
+			}
+			return d.Raw.RawImageDigest(file)
+		}},
+		{tag.NewRawImageDigest, d.NewRawImageDigest, func() ([]byte, error) {
+			if d.Raw == nil {
+				return nil, errors.New(ErrRawImageIsMissing)
+			}
+			return d.Raw.NewRawImageDigest(file)
+		}},
+		{tag.OriginalRawFileDigest, d.OriginalRawFileDigest, func() ([]byte, error) {
+			if d.OriginalRawFileData == nil {
+				return nil, errors.New(ErrOriginalRawFileDataIsMissing)
+			}
+			return OriginalRawFileDigest(d.OriginalRawFileData), nil
+		}},
+	}
+
+	for _, digest := range digests {
+		if digest.stored == nil {
+			continue
+		}
+
+		dc := &DigestCheck{Tag: digest.tag, Stored: digest.stored}
+		if len(digest.stored) != DigestSize {
+			dc.Err = fmt.Errorf(ErrDigestSizeIsNotValid, len(digest.stored), DigestSize)
+		} else {
+			dc.Computed, dc.Err = digest.compute()
+			dc.Passed = (dc.Err == nil) && bytes.Equal(dc.Computed, dc.Stored)
+		}
+
+		checks = append(checks, dc)
+	}
+
+	return checks
+}
+
+// RawImageDigest computes the legacy digest of the raw image: the MD5 digest
+// of all stored samples of the image, by rows, then by columns, then by
+// samples of a pixel. Samples of any size are padded to 16 bits and written
+// as little-endian values.
+func (ri *RawImage) RawImageDigest(file io.ReaderAt) (digest []byte, err error) {
+	var samples []uint16
+	samples, err = ri.readStoredSamples(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.Sum(legacySampleBytes(samples))
+	return sum[:], nil
+}
+
+// NewRawImageDigest computes the digest of the raw image introduced in DNG
+// 1.4. The image is divided into tiles of NewDigestTileSize pixels, samples
+// of each tile are digested, and the final digest is the MD5 digest of
+// digests of tiles, by rows of tiles. Unlike in RawImageDigest, samples of
+// up to 8 bits take a byte, other samples are written as 16-bit
+// little-endian values.
+func (ri *RawImage) NewRawImageDigest(file io.ReaderAt) (digest []byte, err error) {
+	var samples []uint16
+	samples, err = ri.readStoredSamples(file)
+	if err != nil {
+		return nil, err
+	}
+
+	spp := ri.SamplesPerPixel
+	tile := make([]uint16, 0, NewDigestTileSize*NewDigestTileSize*spp)
+	final := md5.New()
+	for y0 := 0; y0 < ri.Length; y0 += NewDigestTileSize {
+		for x0 := 0; x0 < ri.Width; x0 += NewDigestTileSize {
+			columns := min(NewDigestTileSize, ri.Width-x0)
+
+			tile = tile[:0]
+			for y := y0; y < min(y0+NewDigestTileSize, ri.Length); y++ {
+				start := (y*ri.Width + x0) * spp
+				tile = append(tile, samples[start:start+columns*spp]...)
+			}
+
+			sum := md5.Sum(ri.sampleBytes(tile))
+			_, _ = final.Write(sum[:])
+		}
+	}
+
+	return final.Sum(nil), nil
+}
+
+// legacySampleBytes converts samples into bytes which are digested by the
+// RawImageDigest.
+func legacySampleBytes(samples []uint16) (ba []byte) {
+	ba = make([]byte, 0, 2*len(samples))
+	for _, v := range samples {
+		ba = binary.LittleEndian.AppendUint16(ba, v)
+	}
+
+	return ba
+}
+
+// sampleBytes converts samples into bytes which are digested by the
+// NewRawImageDigest.
+func (ri *RawImage) sampleBytes(samples []uint16) (ba []byte) {
+	if ri.bitsPerSample(0) <= 8 {
+		ba = make([]byte, len(samples))
+		for j, v := range samples {
+			ba[j] = byte(v)
+		}
+
+		return ba
+	}
+
+	return legacySampleBytes(samples)
+}
+
+// OriginalRawFileDigest computes the digest of the value of the
+// OriginalRawFileData tag, which is the MD5 digest of the value as it is
+// stored.
+func OriginalRawFileDigest(data []byte) (digest []byte) {
+	sum := md5.Sum(data)
+	return sum[:]
+}
@@ -0,0 +1,92 @@
+package dng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// digestImage returns a file with a single strip of a 3×2 image and the raw
+// image stored in it. Samples of 16 bits are stored in the big endian byte
+// order, so that their digests do not depend on the byte order of the file.
+func digestImage(bits int, samples []uint16) (file *bytes.Reader, ri *RawImage) {
+	data := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	for _, v := range samples {
+		if bits <= 8 {
+			data = append(data, byte(v))
+		} else {
+			data = binary.BigEndian.AppendUint16(data, v)
+		}
+	}
+
+	ri = &RawImage{
+		Width:               3,
+		Length:              2,
+		SamplesPerPixel:     1,
+		BitsPerSample:       []int{bits},
+		SampleFormat:        SampleFormatUnsigned,
+		Compression:         CompressionNone,
+		PlanarConfiguration: PlanarConfigurationContiguous,
+		BlockWidth:          3,
+		BlockLength:         2,
+		BlockOffsets:        []int{8},
+		BlockByteCounts:     []int{len(data) - 8},
+	}
+
+	return bytes.NewReader(data), ri
+}
+
+func TestRawImage_Digests(t *testing.T) {
+	tests := []struct {
+		bits      int
+		samples   []uint16
+		legacy    string
+		newDigest string
+	}{
+		{
+			bits:      8,
+			samples:   []uint16{1, 2, 3, 200, 5, 6},
+			legacy:    "4c499342d86d1b35731a4bce0a2aa2cf",
+			newDigest: "50828062533bf149750d0ec7944a72ed",
+		},
+		{
+			bits:      16,
+			samples:   []uint16{0x0102, 0x0304, 0xFFFF, 0x1000, 0x0001, 0x8000},
+			legacy:    "4884d273d5eb621ebc547033d023b977",
+			newDigest: "0165a2a06194b0b5b72fa21c36a48669",
+		},
+	}
+	for _, test := range tests {
+		file, ri := digestImage(test.bits, test.samples)
+
+		digest, err := ri.RawImageDigest(file)
+		if (err != nil) || (hex.EncodeToString(digest) != test.legacy) {
+			t.Errorf("%v bits: RawImageDigest is %x, error %v", test.bits, digest, err)
+		}
+
+		digest, err = ri.NewRawImageDigest(file)
+		if (err != nil) || (hex.EncodeToString(digest) != test.newDigest) {
+			t.Errorf("%v bits: NewRawImageDigest is %x, error %v", test.bits, digest, err)
+		}
+	}
+}
+
+func TestDNG_VerifyDigests(t *testing.T) {
+	file, ri := digestImage(8, []uint16{1, 2, 3, 200, 5, 6})
+
+	// The stored NewRawImageDigest is the legacy digest of the image.
+	legacy, _ := hex.DecodeString("4c499342d86d1b35731a4bce0a2aa2cf")
+	d := &DNG{Raw: ri, RawImageDigest: legacy, NewRawImageDigest: legacy}
+
+	checks := d.VerifyDigests(file)
+	if len(checks) != 2 {
+		t.Fatalf("%v digests are checked", len(checks))
+	}
+	if !checks[0].Passed || (checks[0].Err != nil) {
+		t.Errorf("RawImageDigest is not passed: %x, %v", checks[0].Computed, checks[0].Err)
+	}
+	if checks[1].Passed || (checks[1].Err != nil) || bytes.Equal(checks[1].Computed, checks[1].Stored) {
+		t.Errorf("mismatch of NewRawImageDigest is not detected: %+v", checks[1])
+	}
+}
@@ -0,0 +1,373 @@
+package dng
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrImageDataIsMissing         = "image data is missing"
+	ErrCompressionIsUnsupported   = "compression is not supported: %v"
+	ErrSampleFormatIsUnsupported  = "sample format is not supported: %v"
+	ErrBitsPerSampleIsUnsupported = "bits per sample are not supported: %v"
+	ErrPlanarConfigIsUnsupported  = "planar configuration is not supported: %v"
+	ErrImageIsTooBig              = "image is too big: %vx%v"
+	ErrNotEnoughBlocks            = "there are %v blocks of image data instead of %v"
+	ErrInBlock                    = "error in block #%v: %v"
+	ErrBlockIsTooShort            = "block has %v samples instead of %v"
+	ErrRasterSizeMismatch         = "raster size %vx%v does not match image size %vx%v"
+	ErrByteOrderIsNotValid        = "byte order of the file is not valid"
+	ErrBlockIsOutOfFile           = "block of %v bytes at offset %v is out of the file"
+)
+
+// Values of the Compression tag of raw images.
+const (
+	CompressionNone         = 1
+	CompressionLosslessJPEG = 7
+)
+
+// Other values of tags describing the image data.
+const (
+	PlanarConfigurationContiguous = 1
+	SampleFormatUnsigned          = 1
+)
+
+// maxSamples is the largest number of samples of an image which is read.
+const maxSamples = 1 << 30
+
+// readDataLayout reads tags describing the stored image data.
+func (ri *RawImage) readDataLayout(r *ifd.EntryReader) {
+	ri.Compression = CompressionNone
+	if v := r.Integer(tag.Compression); v != nil {
+		ri.Compression = *v
+	}
+	if v := r.Integer(tag.PhotometricInterpretation); v != nil {
+		ri.PhotometricInterpretation = *v
+	}
+	ri.PlanarConfiguration = PlanarConfigurationContiguous
+	if v := r.Integer(tag.PlanarConfiguration); v != nil {
+		ri.PlanarConfiguration = *v
+	}
+	ri.SampleFormat = SampleFormatUnsigned
+	if v := r.Integer(tag.SampleFormat); v != nil {
+		ri.SampleFormat = *v
+	}
+
+	if _, ri.IsTiled = r.Entries[tag.TileOffsets]; ri.IsTiled {
+		if v := r.Integer(tag.TileWidth); v != nil {
+			ri.BlockWidth = *v
+		}
+		if v := r.Integer(tag.TileLength); v != nil {
+			ri.BlockLength = *v
+		}
+		ri.BlockOffsets = r.Integers(tag.TileOffsets)
+		ri.BlockByteCounts = r.Integers(tag.TileByteCounts)
+	} else {
+		ri.BlockWidth, ri.BlockLength = ri.Width, ri.Length
+		if v := r.Integer(tag.RowsPerStrip); v != nil {
+			ri.BlockLength = min(*v, ri.Length)
+		}
+		ri.BlockOffsets = r.Integers(tag.StripOffsets)
+		ri.BlockByteCounts = r.Integers(tag.StripByteCounts)
+	}
+
+	if ri.PhotometricInterpretation == PhotometricCFA {
+		ri.CFA = readCFA(r)
+	}
+}
+
+// checkDataLayout checks that the stored image data may be read.
+func (ri *RawImage) checkDataLayout() (err error) {
+	if (len(ri.BlockOffsets) == 0) || (ri.BlockWidth <= 0) || (ri.BlockLength <= 0) {
+		return errors.New(ErrImageDataIsMissing)
+	}
+	if (ri.Compression != CompressionNone) && (ri.Compression != CompressionLosslessJPEG) {
+		return fmt.Errorf(ErrCompressionIsUnsupported, ri.Compression)
+	}
+	if ri.SampleFormat != SampleFormatUnsigned {
+		return fmt.Errorf(ErrSampleFormatIsUnsupported, ri.SampleFormat)
+	}
+	if (ri.PlanarConfiguration != PlanarConfigurationContiguous) && (ri.SamplesPerPixel > 1) {
+		return fmt.Errorf(ErrPlanarConfigIsUnsupported, ri.PlanarConfiguration)
+	}
+	for _, bits := range ri.BitsPerSample {
+		if (bits < 1) || (bits > 16) || (bits != ri.BitsPerSample[0]) {
+			return fmt.Errorf(ErrBitsPerSampleIsUnsupported, ri.BitsPerSample)
+		}
+	}
+	if (ri.Width <= 0) || (ri.Length <= 0) || (ri.SamplesPerPixel <= 0) ||
+		(uint64(ri.Width)*uint64(ri.Length)*uint64(ri.SamplesPerPixel) > maxSamples) {
+		return fmt.Errorf(ErrImageIsTooBig, ri.Width, ri.Length)
+	}
+
+	return nil
+}
+
+// ReadStored reads the stored image data of strips or tiles, which are
+// either uncompressed or compressed by lossless JPEG, from the file. Samples
+// of the raster are the stored values divided by RasterScale, so that the
+// first opcode list may be applied to them.
+func (ri *RawImage) ReadStored(file io.ReaderAt) (stored *Raster, err error) {
+	var samples []uint16
+	samples, err = ri.readStoredSamples(file)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err = NewRaster(ri.Width, ri.Length, ri.SamplesPerPixel)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, v := range samples {
+		stored.Pix[j] = float32(v) / RasterScale
+	}
+
+	return stored, nil
+}
+
+// readStoredSamples reads the stored image data. Samples are stored by rows,
+// then by columns, then by samples of a pixel.
+func (ri *RawImage) readStoredSamples(file io.ReaderAt) (samples []uint16, err error) {
+	err = ri.checkDataLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	var byteOrder binary.ByteOrder
+	byteOrder, err = readByteOrder(file)
+	if err != nil {
+		return nil, err
+	}
+
+	across := (ri.Width + ri.BlockWidth - 1) / ri.BlockWidth
+	down := (ri.Length + ri.BlockLength - 1) / ri.BlockLength
+	if (len(ri.BlockOffsets) < across*down) || (len(ri.BlockByteCounts) < across*down) {
+		return nil, fmt.Errorf(ErrNotEnoughBlocks, min(len(ri.BlockOffsets), len(ri.BlockByteCounts)), across*down)
+	}
+
+	spp := ri.SamplesPerPixel
+	samples = make([]uint16, ri.Width*ri.Length*spp)
+	for b := 0; b < across*down; b++ {
+		x0, y0 := (b%across)*ri.BlockWidth, (b/across)*ri.BlockLength
+
+		// Strips at the bottom of the image are shorter, tiles are padded.
+		rows := ri.BlockLength
+		if !ri.IsTiled {
+			rows = min(rows, ri.Length-y0)
+		}
+
+		var block []uint16
+		block, err = ri.readBlock(file, byteOrder, b, rows)
+		if err != nil {
+			return nil, fmt.Errorf(ErrInBlock, b, err.Error())
+		}
+
+		columns := min(ri.BlockWidth, ri.Width-x0)
+		for y := 0; (y < rows) && (y0+y < ri.Length); y++ {
+			src := block[y*ri.BlockWidth*spp : (y*ri.BlockWidth+columns)*spp]
+			copy(samples[((y0+y)*ri.Width+x0)*spp:], src)
+		}
+	}
+
+	return samples, nil
+}
+
+// readBlock reads and decodes samples of the strip or of the tile.
+func (ri *RawImage) readBlock(file io.ReaderAt, byteOrder binary.ByteOrder, b, rows int) (samples []uint16, err error) {
+	size := ri.BlockByteCounts[b]
+	if size <= 0 {
+		return nil, errors.New(ErrImageDataIsMissing)
+	}
+
+	// The last byte of the block is read before the buffer is allocated,
+	// so that a damaged byte count does not cause a huge allocation.
+	offset := int64(ri.BlockOffsets[b])
+	var n int
+	if offset >= 0 {
+		n, _ = file.ReadAt(make([]byte, 1), offset+int64(size)-1)
+	}
+	if n != 1 {
+		return nil, fmt.Errorf(ErrBlockIsOutOfFile, size, offset)
+	}
+
+	data := make([]byte, size)
+	_, err = file.ReadAt(data, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	count := rows * ri.BlockWidth * ri.SamplesPerPixel
+	switch ri.Compression {
+	case CompressionLosslessJPEG:
+		var j *losslessJPEG
+		j, err = decodeLosslessJPEG(data)
+		if err != nil {
+			return nil, err
+		}
+		samples = j.samples
+
+	default:
+		samples = unpackSamples(data, byteOrder, ri.BlockWidth*ri.SamplesPerPixel, rows, ri.BitsPerSample[0])
+	}
+
+	if len(samples) < count {
+		return nil, fmt.Errorf(ErrBlockIsTooShort, len(samples), count)
+	}
+
+	return samples, nil
+}
+
+// unpackSamples unpacks uncompressed samples of rows. Samples of 8 and 16
+// bits are stored in bytes and in words of the byte order of the file, other
+// sizes are packed starting from the most significant bit, each row starting
+// at a byte boundary. Rows which are not complete are not unpacked.
+func unpackSamples(data []byte, byteOrder binary.ByteOrder, rowSize, rows, bits int) (samples []uint16) {
+	rowBytes := (rowSize*bits + 7) / 8
+	rows = min(rows, len(data)/rowBytes)
+	samples = make([]uint16, 0, rows*rowSize)
+
+	for y := 0; y < rows; y++ {
+		row := data[y*rowBytes : (y+1)*rowBytes]
+
+		switch bits {
+		case 8:
+			for _, b := range row {
+				samples = append(samples, uint16(b))
+			}
+		case 16:
+			for x := 0; x < rowSize; x++ {
+				samples = append(samples, byteOrder.Uint16(row[2*x:]))
+			}
+		default:
+			br := &packedBitReader{data: row}
+			for x := 0; x < rowSize; x++ {
+				samples = append(samples, uint16(br.bits(bits)))
+			}
+		}
+	}
+
+	return samples
+}
+
+// packedBitReader reads bits of packed samples starting from the most
+// significant bit.
+type packedBitReader struct {
+	data []byte
+	pos  int // In bits.
+}
+
+// bits reads the number of bits.
+func (br *packedBitReader) bits(n int) (v uint32) {
+	for ; n > 0; n-- {
+		b := br.data[br.pos/8] >> (7 - br.pos%8) & 1
+		v = v<<1 | uint32(b)
+		br.pos++
+	}
+
+	return v
+}
+
+// readByteOrder reads the byte order mark of the file.
+func readByteOrder(file io.ReaderAt) (byteOrder binary.ByteOrder, err error) {
+	mark := make([]byte, bo.ByteOrderMarkSize)
+	_, err = file.ReadAt(mark, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch string(mark) {
+	case bo.LittleEndian.String():
+		return binary.LittleEndian, nil
+	case bo.BigEndian.String():
+		return binary.BigEndian, nil
+	default:
+		return nil, errors.New(ErrByteOrderIsNotValid)
+	}
+}
+
+// LinearRaster converts the stored image into the linear image of the active
+// area: stored values are mapped through the linearization table, black
+// levels are subtracted and the results are divided by the range between
+// black and white levels. Values are not clipped, so that the noise around
+// the black level is kept.
+func (ri *RawImage) LinearRaster(stored *Raster) (linear *Raster, err error) {
+	if (stored.Width != ri.Width) || (stored.Height != ri.Length) || (stored.Planes != ri.SamplesPerPixel) {
+		return nil, fmt.Errorf(ErrRasterSizeMismatch, stored.Width, stored.Height, ri.Width, ri.Length)
+	}
+
+	area := ri.ActiveArea
+	linear, err = NewRaster(area.Dx(), area.Dy(), ri.SamplesPerPixel)
+	if err != nil {
+		return nil, err
+	}
+
+	for row := 0; row < area.Dy(); row++ {
+		for column := 0; column < area.Dx(); column++ {
+			for s := 0; s < ri.SamplesPerPixel; s++ {
+				v := stored.At(area.Min.X+column, area.Min.Y+row, s)
+				value := float64(ri.Linearize(int(math.Round(float64(v) * RasterScale))))
+
+				black := ri.BlackLevelAt(row, column, s)
+				scale := float64(ri.WhiteLevel[s]) - black
+				if scale <= 0 {
+					scale = 1
+				}
+
+				linear.Set(column, row, s, float32((value-black)/scale))
+			}
+		}
+	}
+
+	return linear, nil
+}
+
+// ReadLinear reads the linear image of the active area from the file. The
+// first opcode list is applied to the stored image and the second one is
+// applied to the linear image, as the DNG specification requires.
+func (ri *RawImage) ReadLinear(file io.ReaderAt) (linear *Raster, err error) {
+	var stored *Raster
+	stored, err = ri.ReadStored(file)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ApplyOpcodes(ri.OpcodeList1, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	linear, err = ri.LinearRaster(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ApplyOpcodes(ri.OpcodeList2, linear)
+	if err != nil {
+		return nil, err
+	}
+
+	return linear, nil
+}
+
+// ReadPreview reads the linear image of the active area from the file and
+// demosaics it bilinearly when it is a CFA image.
+func (ri *RawImage) ReadPreview(file io.ReaderAt) (preview *Raster, err error) {
+	preview, err = ri.ReadLinear(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if ri.CFA == nil {
+		return preview, nil
+	}
+
+	return ri.Demosaic(preview)
+}
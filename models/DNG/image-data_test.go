@@ -0,0 +1,42 @@
+package dng
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRawImage_ReadStored(t *testing.T) {
+	file, ri := digestImage(16, []uint16{0x0102, 0x0304, 0xFFFF, 0x1000, 0x0001, 0x8000})
+
+	stored, err := ri.ReadStored(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := float32(0xFFFF) / RasterScale; stored.Pix[2] != want {
+		t.Errorf("sample is %v instead of %v", stored.Pix[2], want)
+	}
+}
+
+func TestRawImage_ReadStored_BlockIsOutOfFile(t *testing.T) {
+	tests := []struct {
+		offset, size int
+	}{
+		// Damaged byte count, block after the end of the file, block which
+		// crosses the end of the file and negative offset.
+		{offset: 8, size: 1 << 40},
+		{offset: 1000, size: 12},
+		{offset: 9, size: 12},
+		{offset: -8, size: 12},
+	}
+	for _, test := range tests {
+		file, ri := digestImage(16, []uint16{1, 2, 3, 4, 5, 6})
+		ri.BlockOffsets[0], ri.BlockByteCounts[0] = test.offset, test.size
+
+		_, err := ri.ReadStored(file)
+		want := fmt.Sprintf(ErrBlockIsOutOfFile, test.size, test.offset)
+		if (err == nil) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%+v: error is %v", test, err)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package dng
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	ErrJPEGIsTruncated           = "lossless JPEG is truncated"
+	ErrJPEGStartIsMissing        = "lossless JPEG has no start of image marker"
+	ErrJPEGIsNotLossless         = "JPEG is not lossless, frame marker: 0x%X"
+	ErrJPEGFrameIsMissing        = "lossless JPEG has no frame header"
+	ErrJPEGFrameIsNotValid       = "lossless JPEG frame header is not valid"
+	ErrJPEGSamplingIsUnsupported = "lossless JPEG sampling factors are not supported: %v"
+	ErrJPEGScanIsNotValid        = "lossless JPEG scan header is not valid"
+	ErrJPEGPredictorIsNotValid   = "lossless JPEG predictor is not valid: %v"
+	ErrJPEGHuffmanTableIsMissing = "lossless JPEG Huffman table is missing: %v"
+	ErrJPEGHuffmanTableNotValid  = "lossless JPEG Huffman table is not valid"
+	ErrJPEGHuffmanCodeIsNotValid = "lossless JPEG Huffman code is not valid"
+)
+
+// JPEG markers used by lossless JPEG images.
+const (
+	markerPrefix = 0xFF
+	markerSOF3   = 0xC3 // Start of frame, lossless, Huffman coding.
+	markerDHT    = 0xC4 // Huffman tables.
+	markerRST0   = 0xD0 // Restart markers are RST0 – RST7.
+	markerRST7   = 0xD7
+	markerSOI    = 0xD8 // Start of image.
+	markerEOI    = 0xD9 // End of image.
+	markerSOS    = 0xDA // Start of scan.
+	markerDRI    = 0xDD // Restart interval.
+)
+
+// Other frame markers. They start frames of lossy and arithmetic coded
+// images, which are not supported.
+var otherFrameMarkers = map[byte]bool{
+	0xC0: true, 0xC1: true, 0xC2: true, 0xC5: true, 0xC6: true, 0xC7: true,
+	0xC9: true, 0xCA: true, 0xCB: true, 0xCD: true, 0xCE: true, 0xCF: true,
+}
+
+// Limits of lossless JPEG images.
+const (
+	maxHuffmanCodeLength  = 16
+	maxHuffmanTables      = 4
+	maxJPEGComponents     = 4
+	maxJPEGPredictor      = 7
+	largestDifferenceSize = 16
+)
+
+// losslessJPEG is a lossless JPEG image (ITU T.81, process 14) as it is used
+// by DNG files: all components have the sampling factors of 1 and the scan
+// contains all of them.
+type losslessJPEG struct {
+	precision  int
+	height     int
+	width      int
+	components int
+
+	// Identifiers of components and their Huffman tables.
+	componentIDs    []byte
+	componentTables []int
+
+	tables          [maxHuffmanTables]*huffmanTable
+	restartInterval int
+	predictor       int
+	pointTransform  int
+
+	// Samples are decoded samples stored by rows, then by columns, then by
+	// components.
+	samples []uint16
+}
+
+// decodeLosslessJPEG decodes the lossless JPEG image.
+func decodeLosslessJPEG(data []byte) (j *losslessJPEG, err error) {
+	if (len(data) < 2) || (data[0] != markerPrefix) || (data[1] != markerSOI) {
+		return nil, errors.New(ErrJPEGStartIsMissing)
+	}
+
+	j = &losslessJPEG{}
+	pos := 2
+	for {
+		// Markers may be preceded by fill bytes.
+		for (pos < len(data)) && (data[pos] == markerPrefix) {
+			pos++
+		}
+		if pos >= len(data) {
+			return nil, errors.New(ErrJPEGIsTruncated)
+		}
+		marker := data[pos]
+		pos++
+
+		if marker == markerEOI {
+			return nil, errors.New(ErrJPEGFrameIsMissing)
+		}
+
+		if pos+2 > len(data) {
+			return nil, errors.New(ErrJPEGIsTruncated)
+		}
+		size := int(binary.BigEndian.Uint16(data[pos:]))
+		if (size < 2) || (pos+size > len(data)) {
+			return nil, errors.New(ErrJPEGIsTruncated)
+		}
+		segment := data[pos+2 : pos+size]
+		pos += size
+
+		switch {
+		case marker == markerSOF3:
+			err = j.readFrame(segment)
+		case marker == markerDHT:
+			err = j.readHuffmanTables(segment)
+		case marker == markerDRI:
+			err = j.readRestartInterval(segment)
+		case marker == markerSOS:
+			err = j.readScan(segment)
+			if err != nil {
+				return nil, err
+			}
+
+			err = j.decodeScan(data[pos:])
+			if err != nil {
+				return nil, err
+			}
+
+			return j, nil
+		case otherFrameMarkers[marker]:
+			return nil, fmt.Errorf(ErrJPEGIsNotLossless, marker)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readFrame reads the frame header.
+func (j *losslessJPEG) readFrame(segment []byte) (err error) {
+	if len(segment) < 6 {
+		return errors.New(ErrJPEGFrameIsNotValid)
+	}
+
+	j.precision = int(segment[0])
+	j.height = int(binary.BigEndian.Uint16(segment[1:]))
+	j.width = int(binary.BigEndian.Uint16(segment[3:]))
+	j.components = int(segment[5])
+	if (j.precision < 2) || (j.precision > 16) || (j.height == 0) || (j.width == 0) ||
+		(j.components == 0) || (j.components > maxJPEGComponents) || (len(segment) != 6+3*j.components) {
+		return errors.New(ErrJPEGFrameIsNotValid)
+	}
+
+	j.componentIDs = make([]byte, j.components)
+	for c := 0; c < j.components; c++ {
+		j.componentIDs[c] = segment[6+3*c]
+
+		sampling := segment[6+3*c+1]
+		if sampling != 0x11 {
+			return fmt.Errorf(ErrJPEGSamplingIsUnsupported, sampling)
+		}
+	}
+
+	return nil
+}
+
+// readHuffmanTables reads the Huffman tables of the segment.
+func (j *losslessJPEG) readHuffmanTables(segment []byte) (err error) {
+	for len(segment) > 0 {
+		if len(segment) < 1+maxHuffmanCodeLength {
+			return errors.New(ErrJPEGHuffmanTableNotValid)
+		}
+
+		class, id := segment[0]>>4, int(segment[0]&0x0F)
+		if (class != 0) || (id >= maxHuffmanTables) {
+			return errors.New(ErrJPEGHuffmanTableNotValid)
+		}
+
+		var counts [maxHuffmanCodeLength]int
+		total := 0
+		for k := range counts {
+			counts[k] = int(segment[1+k])
+			total += counts[k]
+		}
+
+		segment = segment[1+maxHuffmanCodeLength:]
+		if total > len(segment) {
+			return errors.New(ErrJPEGHuffmanTableNotValid)
+		}
+
+		j.tables[id], err = newHuffmanTable(counts, segment[:total])
+		if err != nil {
+			return err
+		}
+		segment = segment[total:]
+	}
+
+	return nil
+}
+
+// readRestartInterval reads the number of pixels between restart markers.
+func (j *losslessJPEG) readRestartInterval(segment []byte) (err error) {
+	if len(segment) != 2 {
+		return errors.New(ErrJPEGIsTruncated)
+	}
+
+	j.restartInterval = int(binary.BigEndian.Uint16(segment))
+	return nil
+}
+
+// readScan reads the scan header.
+func (j *losslessJPEG) readScan(segment []byte) (err error) {
+	if j.components == 0 {
+		return errors.New(ErrJPEGFrameIsMissing)
+	}
+
+	if (len(segment) < 1) || (int(segment[0]) != j.components) || (len(segment) != 1+2*j.components+3) {
+		return errors.New(ErrJPEGScanIsNotValid)
+	}
+
+	j.componentTables = make([]int, j.components)
+	for c := 0; c < j.components; c++ {
+		id, table := segment[1+2*c], int(segment[2+2*c]>>4)
+		if id != j.componentIDs[c] {
+			return errors.New(ErrJPEGScanIsNotValid)
+		}
+		if (table >= maxHuffmanTables) || (j.tables[table] == nil) {
+			return fmt.Errorf(ErrJPEGHuffmanTableIsMissing, table)
+		}
+		j.componentTables[c] = table
+	}
+
+	rest := segment[1+2*j.components:]
+	j.predictor = int(rest[0])
+	j.pointTransform = int(rest[2] & 0x0F)
+	if (j.predictor < 1) || (j.predictor > maxJPEGPredictor) {
+		return fmt.Errorf(ErrJPEGPredictorIsNotValid, j.predictor)
+	}
+	if j.pointTransform >= j.precision {
+		return errors.New(ErrJPEGScanIsNotValid)
+	}
+
+	return nil
+}
+
+// decodeScan decodes the entropy coded data of the scan.
+func (j *losslessJPEG) decodeScan(data []byte) (err error) {
+	br := &bitReader{data: data}
+	j.samples = make([]uint16, j.height*j.width*j.components)
+
+	rowSize := j.width * j.components
+	initial := 1 << (j.precision - j.pointTransform - 1)
+
+	// The first row of the image and the first row after a restart marker
+	// are predicted by the left neighbour only.
+	firstRow := 0
+	pixels := 0
+	for y := 0; y < j.height; y++ {
+		for x := 0; x < j.width; x++ {
+			restarted := false
+			if (j.restartInterval > 0) && (pixels > 0) && (pixels%j.restartInterval == 0) {
+				err = br.restart()
+				if err != nil {
+					return err
+				}
+				restarted = true
+				firstRow = y
+			}
+			pixels++
+
+			for c := 0; c < j.components; c++ {
+				var diff int
+				diff, err = j.tables[j.componentTables[c]].decodeDifference(br)
+				if err != nil {
+					return err
+				}
+
+				idx := y*rowSize + x*j.components + c
+
+				var prediction int
+				switch {
+				case (y == 0 && x == 0) || restarted:
+					prediction = initial
+				case y == firstRow:
+					prediction = int(j.samples[idx-j.components])
+				case x == 0:
+					prediction = int(j.samples[idx-rowSize])
+				default:
+					prediction = j.predict(idx, rowSize)
+				}
+
+				j.samples[idx] = uint16(prediction + diff)
+			}
+		}
+	}
+
+	if j.pointTransform > 0 {
+		for k := range j.samples {
+			j.samples[k] <<= j.pointTransform
+		}
+	}
+
+	return nil
+}
+
+// predict returns the prediction of the sample by the predictor of the scan.
+func (j *losslessJPEG) predict(idx, rowSize int) int {
+	ra := int(j.samples[idx-j.components])
+	rb := int(j.samples[idx-rowSize])
+	rc := int(j.samples[idx-rowSize-j.components])
+
+	switch j.predictor {
+	case 1:
+		return ra
+	case 2:
+		return rb
+	case 3:
+		return rc
+	case 4:
+		return ra + rb - rc
+	case 5:
+		return ra + ((rb - rc) >> 1)
+	case 6:
+		return rb + ((ra - rc) >> 1)
+	default:
+		return (ra + rb) >> 1
+	}
+}
+
+// huffmanTable is a table of canonical Huffman codes of sizes of
+// differences.
+type huffmanTable struct {
+	// minCode, maxCode and valuePointer are the first code, the last code
+	// and the index of the first value of each code length. maxCode is -1
+	// when there are no codes of the length.
+	minCode      [maxHuffmanCodeLength + 1]int
+	maxCode      [maxHuffmanCodeLength + 1]int
+	valuePointer [maxHuffmanCodeLength + 1]int
+	values       []byte
+}
+
+// newHuffmanTable creates the table from numbers of codes of each length and
+// their values.
+func newHuffmanTable(counts [maxHuffmanCodeLength]int, values []byte) (h *huffmanTable, err error) {
+	h = &huffmanTable{values: append([]byte(nil), values...)}
+
+	code, k := 0, 0
+	for length := 1; length <= maxHuffmanCodeLength; length++ {
+		count := counts[length-1]
+		h.valuePointer[length] = k
+		h.minCode[length] = code
+		h.maxCode[length] = -1
+		if count > 0 {
+			h.maxCode[length] = code + count - 1
+		}
+
+		code += count
+		k += count
+		if code > 1<<length {
+			return nil, errors.New(ErrJPEGHuffmanTableNotValid)
+		}
+		code <<= 1
+	}
+
+	for _, v := range h.values {
+		if v > largestDifferenceSize {
+			return nil, errors.New(ErrJPEGHuffmanTableNotValid)
+		}
+	}
+
+	return h, nil
+}
+
+// decodeDifference reads the difference of a sample from its prediction.
+func (h *huffmanTable) decodeDifference(br *bitReader) (diff int, err error) {
+	code := 0
+	size := -1
+	for length := 1; length <= maxHuffmanCodeLength; length++ {
+		code = code<<1 | br.bit()
+		if code <= h.maxCode[length] {
+			size = int(h.values[h.valuePointer[length]+code-h.minCode[length]])
+			break
+		}
+	}
+	if size < 0 {
+		return 0, errors.New(ErrJPEGHuffmanCodeIsNotValid)
+	}
+
+	switch size {
+	case 0:
+		return 0, nil
+	case largestDifferenceSize:
+		// The difference of 32768 has no additional bits.
+		return 1 << 15, nil
+	}
+
+	diff = br.bits(size)
+	if diff < 1<<(size-1) {
+		diff -= 1<<size - 1
+	}
+
+	return diff, nil
+}
+
+// bitReader reads bits of entropy coded data. Stuffed zero bytes after
+// 0xFF bytes are skipped. Reading stops at markers: zero bits are returned
+// after a marker or after the end of data.
+type bitReader struct {
+	data  []byte
+	pos   int
+	cache uint32
+	count int
+}
+
+// fill loads the next byte into the cache.
+func (br *bitReader) fill() {
+	b := byte(0)
+	if br.pos < len(br.data) {
+		b = br.data[br.pos]
+		if b == markerPrefix {
+			if (br.pos+1 < len(br.data)) && (br.data[br.pos+1] == 0) {
+				br.pos += 2
+			} else {
+				// A marker, which is not consumed.
+				b = 0
+			}
+		} else {
+			br.pos++
+		}
+	}
+
+	br.cache = br.cache<<8 | uint32(b)
+	br.count += 8
+}
+
+// bit reads a single bit.
+func (br *bitReader) bit() int {
+	return br.bits(1)
+}
+
+// bits reads the number of bits, up to 16.
+func (br *bitReader) bits(n int) int {
+	for br.count < n {
+		br.fill()
+	}
+
+	br.count -= n
+	return int(br.cache>>br.count) & (1<<n - 1)
+}
+
+// restart skips the rest of the byte and the restart marker.
+func (br *bitReader) restart() (err error) {
+	br.cache, br.count = 0, 0
+
+	if (br.pos+1 >= len(br.data)) || (br.data[br.pos] != markerPrefix) ||
+		(br.data[br.pos+1] < markerRST0) || (br.data[br.pos+1] > markerRST7) {
+		return errors.New(ErrJPEGIsTruncated)
+	}
+
+	br.pos += 2
+	return nil
+}
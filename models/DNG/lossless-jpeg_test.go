@@ -0,0 +1,199 @@
+package dng
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"testing"
+)
+
+// bitWriter writes entropy coded data, stuffing zero bytes after 0xFF bytes.
+type bitWriter struct {
+	out   []byte
+	cache byte
+	count int
+}
+
+func (bw *bitWriter) put(v int, n int) {
+	for k := n - 1; k >= 0; k-- {
+		bw.cache = bw.cache<<1 | byte(v>>k&1)
+		bw.count++
+		if bw.count == 8 {
+			bw.out = append(bw.out, bw.cache)
+			if bw.cache == markerPrefix {
+				bw.out = append(bw.out, 0)
+			}
+			bw.cache, bw.count = 0, 0
+		}
+	}
+}
+
+// flush pads the last byte with ones.
+func (bw *bitWriter) flush() {
+	if bw.count > 0 {
+		bw.put(1<<(8-bw.count)-1, 8-bw.count)
+	}
+}
+
+// testPrediction returns the prediction of a sample of an image by the
+// predictor, the same way as the decoder does.
+func testPrediction(samples []int, idx, rowSize, components, predictor int) int {
+	ra, rb, rc := samples[idx-components], samples[idx-rowSize], samples[idx-rowSize-components]
+
+	return [...]int{
+		1: ra,
+		2: rb,
+		3: rc,
+		4: ra + rb - rc,
+		5: ra + (rb-rc)>>1,
+		6: rb + (ra-rc)>>1,
+		7: (ra + rb) >> 1,
+	}[predictor]
+}
+
+// encodeLosslessJPEG encodes samples with 12-bit precision into a lossless
+// JPEG image. All sizes of differences are coded by the Huffman table with
+// 5-bit codes, where the code of the size is the size itself.
+func encodeLosslessJPEG(samples []int, width, height, components, predictor, restartInterval int) (data []byte) {
+	data = []byte{markerPrefix, markerSOI}
+
+	segment := func(marker byte, payload ...byte) {
+		data = append(data, markerPrefix, marker)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(payload)+2))
+		data = append(data, payload...)
+	}
+
+	frame := []byte{12, byte(height >> 8), byte(height), byte(width >> 8), byte(width), byte(components)}
+	for c := 0; c < components; c++ {
+		frame = append(frame, byte(c+1), 0x11, 0)
+	}
+	segment(markerSOF3, frame...)
+
+	table := make([]byte, 1+maxHuffmanCodeLength)
+	table[5] = largestDifferenceSize + 1
+	for size := 0; size <= largestDifferenceSize; size++ {
+		table = append(table, byte(size))
+	}
+	segment(markerDHT, table...)
+
+	if restartInterval > 0 {
+		segment(markerDRI, byte(restartInterval>>8), byte(restartInterval))
+	}
+
+	scan := []byte{byte(components)}
+	for c := 0; c < components; c++ {
+		scan = append(scan, byte(c+1), 0)
+	}
+	segment(markerSOS, append(scan, byte(predictor), 0, 0)...)
+
+	bw := &bitWriter{}
+	rowSize := width * components
+	firstRow, pixels, restarts := 0, 0, 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			restarted := false
+			if (restartInterval > 0) && (pixels > 0) && (pixels%restartInterval == 0) {
+				bw.flush()
+				data = append(data, bw.out...)
+				data = append(data, markerPrefix, markerRST0+byte(restarts%8))
+				bw = &bitWriter{}
+				restarts++
+				restarted = true
+				firstRow = y
+			}
+			pixels++
+
+			for c := 0; c < components; c++ {
+				idx := y*rowSize + x*components + c
+
+				var prediction int
+				switch {
+				case (y == 0 && x == 0) || restarted:
+					prediction = 1 << 11
+				case y == firstRow:
+					prediction = samples[idx-components]
+				case x == 0:
+					prediction = samples[idx-rowSize]
+				default:
+					prediction = testPrediction(samples, idx, rowSize, components, predictor)
+				}
+
+				diff := samples[idx] - prediction
+				size := bits.Len(uint(max(diff, -diff)))
+				bw.put(size, 5)
+				if diff < 0 {
+					diff += 1<<size - 1
+				}
+				bw.put(diff, size)
+			}
+		}
+	}
+	bw.flush()
+
+	data = append(data, bw.out...)
+	return append(data, markerPrefix, markerEOI)
+}
+
+// testSamples returns 12-bit samples of an image.
+func testSamples(width, height, components int) (samples []int) {
+	samples = make([]int, width*height*components)
+	for j := range samples {
+		samples[j] = (100 + 37*j + 211*(j/width) + j%7*j) % 4096
+	}
+
+	return samples
+}
+
+func TestDecodeLosslessJPEG(t *testing.T) {
+	tests := []struct {
+		width, height, components, predictor, restartInterval int
+	}{
+		{width: 8, height: 6, components: 1, predictor: 1},
+		{width: 8, height: 6, components: 2, predictor: 6},
+		{width: 5, height: 4, components: 4, predictor: 4},
+		{width: 300, height: 3, components: 2, predictor: 7},
+		{width: 8, height: 6, components: 2, predictor: 5, restartInterval: 8},
+		{width: 7, height: 5, components: 1, predictor: 3, restartInterval: 3},
+	}
+	for _, test := range tests {
+		samples := testSamples(test.width, test.height, test.components)
+		data := encodeLosslessJPEG(samples, test.width, test.height, test.components, test.predictor, test.restartInterval)
+
+		j, err := decodeLosslessJPEG(data)
+		if err != nil {
+			t.Errorf("%+v: %v", test, err)
+			continue
+		}
+		if (j.width != test.width) || (j.height != test.height) || (j.components != test.components) {
+			t.Errorf("%+v: image is %vx%vx%v", test, j.width, j.height, j.components)
+			continue
+		}
+		for k, want := range samples {
+			if int(j.samples[k]) != want {
+				t.Errorf("%+v: sample %v is %v instead of %v", test, k, j.samples[k], want)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeLosslessJPEG_Errors(t *testing.T) {
+	data := encodeLosslessJPEG(testSamples(8, 6, 1), 8, 6, 1, 1, 0)
+
+	// The frame marker follows the start of the image.
+	lossy := append([]byte(nil), data...)
+	lossy[3] = 0xC0
+
+	tests := map[string][]byte{
+		"no start":  data[2:],
+		"truncated": data[:30],
+		"no frame":  {markerPrefix, markerSOI, markerPrefix, markerEOI},
+		"lossy":     lossy,
+		"predictor": encodeLosslessJPEG(testSamples(8, 6, 1), 8, 6, 1, 0, 0),
+	}
+	for name, data := range tests {
+		_, err := decodeLosslessJPEG(data)
+		if err == nil {
+			t.Errorf("%v: image is decoded", name)
+		}
+	}
+}
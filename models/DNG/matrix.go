@@ -0,0 +1,240 @@
+package dng
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrMatrixSizeIsNotValid   = "matrix of %v values can not have %v rows and %v columns"
+	ErrMatrixSizesMismatch    = "sizes of matrices do not match: %vx%v and %vx%v"
+	ErrVectorSizeMismatch     = "size of vector %v does not match %v columns of matrix"
+	ErrMatrixIsSingular       = "matrix is singular"
+	ErrMatrixCanNotBeInverted = "matrix of %v rows and %v columns can not be inverted"
+)
+
+// singularityThreshold is the smallest absolute value of a pivot of a matrix
+// being inverted.
+const singularityThreshold = 1e-12
+
+// Matrix is a matrix of real numbers. Values are stored by rows, as in DNG
+// tags.
+type Matrix struct {
+	Rows    int
+	Columns int
+	Values  []float64
+}
+
+// NewMatrix creates a matrix of the values stored by rows.
+func NewMatrix(rows, columns int, values []float64) (m *Matrix, err error) {
+	if (rows <= 0) || (columns <= 0) || (len(values) != rows*columns) {
+		return nil, fmt.Errorf(ErrMatrixSizeIsNotValid, len(values), rows, columns)
+	}
+
+	return &Matrix{
+		Rows:    rows,
+		Columns: columns,
+		Values:  append([]float64(nil), values...),
+	}, nil
+}
+
+// readMatrix reads the value as a matrix of the number of rows. Columns are
+// counted by the number of values.
+func readMatrix(r *ifd.EntryReader, tg tag.Tag, rows int) (m *Matrix) {
+	numbers := r.Numbers(tg)
+	if (len(numbers) == 0) || (rows <= 0) {
+		return nil
+	}
+
+	m, err := NewMatrix(rows, len(numbers)/rows, numbers)
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	return m
+}
+
+// IdentityMatrix creates an identity matrix of the size.
+func IdentityMatrix(size int) (m *Matrix) {
+	m = &Matrix{Rows: size, Columns: size, Values: make([]float64, size*size)}
+	for j := 0; j < size; j++ {
+		m.Values[j*size+j] = 1
+	}
+
+	return m
+}
+
+// DiagonalMatrix creates a square matrix with the values on its diagonal.
+func DiagonalMatrix(values []float64) (m *Matrix) {
+	m = IdentityMatrix(len(values))
+	for j, v := range values {
+		m.Values[j*m.Columns+j] = v
+	}
+
+	return m
+}
+
+// At returns the value of the row and the column.
+func (m *Matrix) At(row, column int) float64 {
+	return m.Values[row*m.Columns+column]
+}
+
+// String returns rows of the matrix separated by semicolons.
+func (m *Matrix) String() string {
+	rows := make([]string, 0, m.Rows)
+	for r := 0; r < m.Rows; r++ {
+		rows = append(rows, fmt.Sprint(m.Values[r*m.Columns:(r+1)*m.Columns]))
+	}
+
+	return strings.Join(rows, "; ")
+}
+
+// Multiply returns the product of the matrix and the other matrix.
+func (m *Matrix) Multiply(other *Matrix) (p *Matrix, err error) {
+	if m.Columns != other.Rows {
+		return nil, fmt.Errorf(ErrMatrixSizesMismatch, m.Rows, m.Columns, other.Rows, other.Columns)
+	}
+
+	p = &Matrix{Rows: m.Rows, Columns: other.Columns, Values: make([]float64, m.Rows*other.Columns)}
+	for r := 0; r < m.Rows; r++ {
+		for c := 0; c < other.Columns; c++ {
+			var sum float64
+			for k := 0; k < m.Columns; k++ {
+				sum += m.At(r, k) * other.At(k, c)
+			}
+			p.Values[r*p.Columns+c] = sum
+		}
+	}
+
+	return p, nil
+}
+
+// MultiplyVector returns the product of the matrix and the column vector.
+func (m *Matrix) MultiplyVector(v []float64) (p []float64, err error) {
+	if len(v) != m.Columns {
+		return nil, fmt.Errorf(ErrVectorSizeMismatch, len(v), m.Columns)
+	}
+
+	p = make([]float64, m.Rows)
+	for r := 0; r < m.Rows; r++ {
+		for c := 0; c < m.Columns; c++ {
+			p[r] += m.At(r, c) * v[c]
+		}
+	}
+
+	return p, nil
+}
+
+// Transpose returns the transposed matrix.
+func (m *Matrix) Transpose() (t *Matrix) {
+	t = &Matrix{Rows: m.Columns, Columns: m.Rows, Values: make([]float64, len(m.Values))}
+	for r := 0; r < m.Rows; r++ {
+		for c := 0; c < m.Columns; c++ {
+			t.Values[c*t.Columns+r] = m.At(r, c)
+		}
+	}
+
+	return t
+}
+
+// Inverse returns the inverse of a square matrix. Matrices having more rows
+// than columns, such as colour matrices of cameras with four colour planes,
+// get the pseudo-inverse, which is the inverse in the least squares sense.
+func (m *Matrix) Inverse() (i *Matrix, err error) {
+	switch {
+	case m.Rows == m.Columns:
+		return m.invertSquare()
+
+	case m.Rows > m.Columns:
+		// (Mᵀ × M)⁻¹ × Mᵀ.
+		t := m.Transpose()
+
+		var tm *Matrix
+		tm, err = t.Multiply(m)
+		if err != nil {
+			return nil, err
+		}
+
+		tm, err = tm.invertSquare()
+		if err != nil {
+			return nil, err
+		}
+
+		return tm.Multiply(t)
+
+	default:
+		return nil, fmt.Errorf(ErrMatrixCanNotBeInverted, m.Rows, m.Columns)
+	}
+}
+
+// invertSquare inverts the square matrix by the Gauss–Jordan elimination.
+func (m *Matrix) invertSquare() (i *Matrix, err error) {
+	n := m.Rows
+	a := append([]float64(nil), m.Values...)
+	i = IdentityMatrix(n)
+
+	for c := 0; c < n; c++ {
+		// Partial pivoting.
+		pivot := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(a[r*n+c]) > math.Abs(a[pivot*n+c]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot*n+c]) < singularityThreshold {
+			return nil, errors.New(ErrMatrixIsSingular)
+		}
+		if pivot != c {
+			swapRows(a, n, pivot, c)
+			swapRows(i.Values, n, pivot, c)
+		}
+
+		k := a[c*n+c]
+		for j := 0; j < n; j++ {
+			a[c*n+j] /= k
+			i.Values[c*n+j] /= k
+		}
+
+		for r := 0; r < n; r++ {
+			if r == c {
+				continue
+			}
+
+			f := a[r*n+c]
+			for j := 0; j < n; j++ {
+				a[r*n+j] -= f * a[c*n+j]
+				i.Values[r*n+j] -= f * i.Values[c*n+j]
+			}
+		}
+	}
+
+	return i, nil
+}
+
+// swapRows swaps two rows of the square matrix of the size stored by rows.
+func swapRows(values []float64, size, r1, r2 int) {
+	for j := 0; j < size; j++ {
+		values[r1*size+j], values[r2*size+j] = values[r2*size+j], values[r1*size+j]
+	}
+}
+
+// interpolateMatrices returns the weighted mean of two matrices of the same
+// size: weight × m1 + (1 - weight) × m2.
+func interpolateMatrices(m1, m2 *Matrix, weight float64) (m *Matrix, err error) {
+	if (m1.Rows != m2.Rows) || (m1.Columns != m2.Columns) {
+		return nil, fmt.Errorf(ErrMatrixSizesMismatch, m1.Rows, m1.Columns, m2.Rows, m2.Columns)
+	}
+
+	m = &Matrix{Rows: m1.Rows, Columns: m1.Columns, Values: make([]float64, len(m1.Values))}
+	for j := range m.Values {
+		m.Values[j] = weight*m1.Values[j] + (1-weight)*m2.Values[j]
+	}
+
+	return m, nil
+}
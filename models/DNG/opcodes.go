@@ -0,0 +1,323 @@
+package dng
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrOpcodeListIsTruncated    = "opcode list is truncated"
+	ErrOpcodeIsTruncated        = "opcode #%v is truncated"
+	ErrInOpcode                 = "error in opcode #%v (%v): %v"
+	ErrParametersAreTruncated   = "parameters are truncated"
+	ErrParametersAreNotValid    = "parameters are not valid: %v"
+	ErrOpcodeIsNotSupported     = "opcode is not supported: %v"
+	ErrTooManyOpcodeListEntries = "too many entries: %v"
+)
+
+// Sizes of parts of opcode lists.
+const (
+	OpcodeCountSize  = 4
+	OpcodeHeaderSize = 16 // ID, version, flags, size of parameters.
+)
+
+// OpcodeID is the identifier of an opcode.
+type OpcodeID uint32
+
+const (
+	OpcodeWarpRectilinear      = OpcodeID(1)
+	OpcodeWarpFisheye          = OpcodeID(2)
+	OpcodeFixVignetteRadial    = OpcodeID(3)
+	OpcodeFixBadPixelsConstant = OpcodeID(4)
+	OpcodeFixBadPixelsList     = OpcodeID(5)
+	OpcodeTrimBounds           = OpcodeID(6)
+	OpcodeMapTable             = OpcodeID(7)
+	OpcodeMapPolynomial        = OpcodeID(8)
+	OpcodeGainMap              = OpcodeID(9)
+	OpcodeDeltaPerRow          = OpcodeID(10)
+	OpcodeDeltaPerColumn       = OpcodeID(11)
+	OpcodeScalePerRow          = OpcodeID(12)
+	OpcodeScalePerColumn       = OpcodeID(13)
+	OpcodeWarpRectilinear2     = OpcodeID(14)
+)
+
+var opcodeNames = map[OpcodeID]string{
+	OpcodeWarpRectilinear:      "WarpRectilinear",
+	OpcodeWarpFisheye:          "WarpFisheye",
+	OpcodeFixVignetteRadial:    "FixVignetteRadial",
+	OpcodeFixBadPixelsConstant: "FixBadPixelsConstant",
+	OpcodeFixBadPixelsList:     "FixBadPixelsList",
+	OpcodeTrimBounds:           "TrimBounds",
+	OpcodeMapTable:             "MapTable",
+	OpcodeMapPolynomial:        "MapPolynomial",
+	OpcodeGainMap:              "GainMap",
+	OpcodeDeltaPerRow:          "DeltaPerRow",
+	OpcodeDeltaPerColumn:       "DeltaPerColumn",
+	OpcodeScalePerRow:          "ScalePerRow",
+	OpcodeScalePerColumn:       "ScalePerColumn",
+	OpcodeWarpRectilinear2:     "WarpRectilinear2",
+}
+
+// String returns the name of the opcode.
+func (id OpcodeID) String() string {
+	name, ok := opcodeNames[id]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprintf("Unknown (%d)", uint32(id))
+}
+
+// Flags of opcodes.
+const (
+	// OpcodeFlagOptional is set for opcodes which may be skipped by readers
+	// not supporting them.
+	OpcodeFlagOptional = 1
+
+	// OpcodeFlagPreviewSkip is set for opcodes which may be skipped when a
+	// preview quality image is made.
+	OpcodeFlagPreviewSkip = 2
+)
+
+// Opcode is an image processing step of an opcode list.
+type Opcode struct {
+	// ID is the identifier of the opcode.
+	ID OpcodeID
+
+	// Version is the oldest version of the DNG specification describing the
+	// opcode.
+	Version Version
+
+	// Flags of the opcode.
+	Flags uint32
+
+	// Parameters are the raw parameters of the opcode.
+	Parameters []byte
+
+	// Operation is the decoded opcode. It is nil for opcodes which are not
+	// supported.
+	Operation Operation
+}
+
+// Operation is a decoded opcode, which is able to process an image.
+type Operation interface {
+	// Apply processes the image.
+	Apply(r *Raster) (err error)
+}
+
+// IsOptional tells whether the opcode may be skipped by readers which do
+// not support it.
+func (o *Opcode) IsOptional() bool {
+	return o.Flags&OpcodeFlagOptional != 0
+}
+
+// CanBeSkippedForPreview tells whether the opcode may be skipped when a
+// preview quality image is made.
+func (o *Opcode) CanBeSkippedForPreview() bool {
+	return o.Flags&OpcodeFlagPreviewSkip != 0
+}
+
+// String returns the name of the opcode with its flags.
+func (o *Opcode) String() string {
+	s := o.ID.String()
+	if o.IsOptional() {
+		s += " (optional)"
+	}
+
+	return s
+}
+
+// operationDecoders decode parameters of supported opcodes.
+var operationDecoders = map[OpcodeID]func(p *paramReader) Operation{
+	OpcodeWarpRectilinear:      decodeWarpRectilinear,
+	OpcodeWarpFisheye:          decodeWarpFisheye,
+	OpcodeFixVignetteRadial:    decodeFixVignetteRadial,
+	OpcodeFixBadPixelsConstant: decodeFixBadPixelsConstant,
+	OpcodeFixBadPixelsList:     decodeFixBadPixelsList,
+	OpcodeTrimBounds:           decodeTrimBounds,
+	OpcodeMapTable:             decodeMapTable,
+	OpcodeMapPolynomial:        decodeMapPolynomial,
+	OpcodeGainMap:              decodeGainMap,
+	OpcodeDeltaPerRow:          decodeDeltaPerRow,
+	OpcodeDeltaPerColumn:       decodeDeltaPerColumn,
+	OpcodeScalePerRow:          decodeScalePerRow,
+	OpcodeScalePerColumn:       decodeScalePerColumn,
+}
+
+// ParseOpcodeList parses the value of the OpcodeList1, OpcodeList2 or
+// OpcodeList3 tag. Opcode lists are always stored in the big endian byte
+// order, whatever the byte order of the file is.
+func ParseOpcodeList(data []byte) (opcodes []*Opcode, err error) {
+	if len(data) < OpcodeCountSize {
+		return nil, errors.New(ErrOpcodeListIsTruncated)
+	}
+
+	count := binary.BigEndian.Uint32(data)
+	if uint64(count)*OpcodeHeaderSize > uint64(len(data)-OpcodeCountSize) {
+		return nil, fmt.Errorf(ErrTooManyOpcodeListEntries, count)
+	}
+
+	opcodes = make([]*Opcode, 0, count)
+	pos := OpcodeCountSize
+	for j := 1; j <= int(count); j++ {
+		if pos+OpcodeHeaderSize > len(data) {
+			return nil, fmt.Errorf(ErrOpcodeIsTruncated, j)
+		}
+
+		o := &Opcode{
+			ID:    OpcodeID(binary.BigEndian.Uint32(data[pos:])),
+			Flags: binary.BigEndian.Uint32(data[pos+8:]),
+		}
+		copy(o.Version[:], data[pos+4:pos+8])
+		size := int(binary.BigEndian.Uint32(data[pos+12:]))
+		pos += OpcodeHeaderSize
+
+		if (size < 0) || (size > len(data)-pos) {
+			return nil, fmt.Errorf(ErrOpcodeIsTruncated, j)
+		}
+		o.Parameters = data[pos : pos+size]
+		pos += size
+
+		decode, ok := operationDecoders[o.ID]
+		if ok {
+			p := &paramReader{data: o.Parameters}
+			o.Operation = decode(p)
+			if p.err != nil {
+				return nil, fmt.Errorf(ErrInOpcode, j, o.ID, p.err.Error())
+			}
+		}
+
+		opcodes = append(opcodes, o)
+	}
+
+	return opcodes, nil
+}
+
+// readOpcodes reads the value as an opcode list.
+func readOpcodes(r *ifd.EntryReader, tg tag.Tag) (opcodes []*Opcode) {
+	ba := r.Bytes(tg)
+	if ba == nil {
+		return nil
+	}
+
+	opcodes, err := ParseOpcodeList(ba)
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	return opcodes
+}
+
+// ApplyOpcodes applies the opcodes to the image in their order. Opcodes
+// which are not supported are skipped when they are optional.
+func ApplyOpcodes(opcodes []*Opcode, r *Raster) (err error) {
+	for j, o := range opcodes {
+		if o.Operation == nil {
+			if o.IsOptional() {
+				continue
+			}
+			return fmt.Errorf(ErrInOpcode, j+1, o.ID, fmt.Errorf(ErrOpcodeIsNotSupported, o.ID))
+		}
+
+		err = o.Operation.Apply(r)
+		if err != nil {
+			return fmt.Errorf(ErrInOpcode, j+1, o.ID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// paramReader reads parameters of opcodes, which are stored in the big
+// endian byte order. The first error is saved and all further reads return
+// zero values.
+type paramReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// next returns the next bytes of the size, or nil.
+func (p *paramReader) next(size int) (ba []byte) {
+	if p.err != nil {
+		return nil
+	}
+
+	if size > len(p.data)-p.pos {
+		p.err = errors.New(ErrParametersAreTruncated)
+		return nil
+	}
+
+	ba = p.data[p.pos : p.pos+size]
+	p.pos += size
+	return ba
+}
+
+// uint16 reads an unsigned integer of 16 bits.
+func (p *paramReader) uint16() uint16 {
+	ba := p.next(2)
+	if ba == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint16(ba)
+}
+
+// uint32 reads an unsigned integer of 32 bits.
+func (p *paramReader) uint32() uint32 {
+	ba := p.next(4)
+	if ba == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(ba)
+}
+
+// int reads an unsigned integer of 32 bits as an integer.
+func (p *paramReader) int() int {
+	return int(p.uint32())
+}
+
+// float32 reads a floating point number of 32 bits.
+func (p *paramReader) float32() float32 {
+	return math.Float32frombits(p.uint32())
+}
+
+// float64 reads a floating point number of 64 bits.
+func (p *paramReader) float64() float64 {
+	ba := p.next(8)
+	if ba == nil {
+		return 0
+	}
+
+	return math.Float64frombits(binary.BigEndian.Uint64(ba))
+}
+
+// count reads the number of items of the size which follow, checking that
+// they fit into the parameters.
+func (p *paramReader) count(itemSize int) int {
+	n := p.uint32()
+	if p.err != nil {
+		return 0
+	}
+
+	if uint64(n)*uint64(itemSize) > uint64(len(p.data)-p.pos) {
+		p.err = errors.New(ErrParametersAreTruncated)
+		return 0
+	}
+
+	return int(n)
+}
+
+// check saves the error when the condition is not met.
+func (p *paramReader) check(ok bool, what string) {
+	if (p.err == nil) && !ok {
+		p.err = fmt.Errorf(ErrParametersAreNotValid, what)
+	}
+}
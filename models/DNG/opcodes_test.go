@@ -0,0 +1,128 @@
+package dng
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// opcodeList builds an opcode list of the opcodes.
+func opcodeList(opcodes ...[]byte) (data []byte) {
+	data = binary.BigEndian.AppendUint32(nil, uint32(len(opcodes)))
+	for _, o := range opcodes {
+		data = append(data, o...)
+	}
+
+	return data
+}
+
+// opcode builds an opcode with parameters made of 32-bit integers and
+// doubles.
+func opcode(id OpcodeID, flags uint32, params ...any) (data []byte) {
+	var p []byte
+	for _, param := range params {
+		switch v := param.(type) {
+		case int:
+			p = binary.BigEndian.AppendUint32(p, uint32(v))
+		case float64:
+			p = binary.BigEndian.AppendUint64(p, math.Float64bits(v))
+		}
+	}
+
+	data = binary.BigEndian.AppendUint32(nil, uint32(id))
+	data = append(data, 1, 3, 0, 0)
+	data = binary.BigEndian.AppendUint32(data, flags)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(p)))
+	return append(data, p...)
+}
+
+func TestParseOpcodeList(t *testing.T) {
+	data := opcodeList(
+		// Top, left, bottom, right, plane, planes, row and column pitches,
+		// degree and coefficients.
+		opcode(OpcodeMapPolynomial, 0, 0, 0, 2, 4, 0, 1, 1, 1, 1, 0.25, 0.5),
+		opcode(OpcodeTrimBounds, 0, 1, 1, 2, 3),
+		opcode(OpcodeID(100), OpcodeFlagOptional, 1, 2, 3),
+	)
+
+	opcodes, err := ParseOpcodeList(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opcodes) != 3 {
+		t.Fatalf("%v opcodes are read", len(opcodes))
+	}
+
+	mp, ok := opcodes[0].Operation.(*MapPolynomial)
+	if !ok || (len(mp.Coefficients) != 2) || (mp.Coefficients[1] != 0.5) || (mp.Area.Bounds.Dx() != 4) {
+		t.Errorf("MapPolynomial is read as %+v", opcodes[0].Operation)
+	}
+	if (opcodes[0].Version != Version{1, 3, 0, 0}) || opcodes[0].IsOptional() {
+		t.Errorf("header of the opcode is read as %+v", opcodes[0])
+	}
+	if _, ok = opcodes[1].Operation.(*TrimBounds); !ok {
+		t.Errorf("TrimBounds is read as %+v", opcodes[1].Operation)
+	}
+	if (opcodes[2].Operation != nil) || !opcodes[2].IsOptional() || (len(opcodes[2].Parameters) != 12) {
+		t.Errorf("unknown opcode is read as %+v", opcodes[2])
+	}
+
+	// Samples of the first two rows are mapped, then the image is cropped,
+	// the unknown optional opcode is skipped.
+	var r *Raster
+	r, err = NewRaster(4, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range r.Pix {
+		r.Pix[j] = float32(j) / 20
+	}
+
+	err = ApplyOpcodes(opcodes, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (r.Width != 2) || (r.Height != 1) {
+		t.Fatalf("raster is %vx%v", r.Width, r.Height)
+	}
+	for j, want := range []float64{0.375, 0.4} {
+		if math.Abs(float64(r.Pix[j])-want) > 1e-6 {
+			t.Errorf("sample %v is %v instead of %v", j, r.Pix[j], want)
+		}
+	}
+}
+
+func TestParseOpcodeList_Errors(t *testing.T) {
+	polynomial := opcode(OpcodeMapPolynomial, 0, 0, 0, 2, 4, 0, 1, 1, 1, 1, 0.25, 0.5)
+
+	tests := map[string][]byte{
+		"empty":           {0, 0},
+		"too many":        append([]byte{0, 0, 0, 5}, polynomial...),
+		"header only":     opcodeList(polynomial)[:OpcodeCountSize+OpcodeHeaderSize],
+		"truncated":       opcodeList(polynomial)[:OpcodeCountSize+len(polynomial)-1],
+		"short params":    opcodeList(opcode(OpcodeTrimBounds, 0, 1, 1, 2)),
+		"zero pitch":      opcodeList(opcode(OpcodeMapPolynomial, 0, 0, 0, 2, 4, 0, 1, 0, 1, 0, 0.25)),
+		"too high degree": opcodeList(opcode(OpcodeMapPolynomial, 0, 0, 0, 2, 4, 0, 1, 1, 1, 9)),
+	}
+	for name, data := range tests {
+		_, err := ParseOpcodeList(data)
+		if err == nil {
+			t.Errorf("%v: list is parsed", name)
+		}
+	}
+
+	// Unknown opcodes which are not optional are not applied.
+	opcodes, err := ParseOpcodeList(opcodeList(opcode(OpcodeID(100), 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r *Raster
+	r, err = NewRaster(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ApplyOpcodes(opcodes, r) == nil {
+		t.Error("unknown opcode is applied")
+	}
+}
@@ -0,0 +1,105 @@
+package dng
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	ErrRasterSizeIsNotValid = "raster size is not valid"
+)
+
+// RasterScale is the number of steps of values of rasters. Values of
+// rasters are normalised to the range from 0 to 1, while opcodes working
+// with integer values, such as MapTable and FixBadPixelsConstant, see them
+// as 16-bit integers.
+const RasterScale = math.MaxUint16
+
+// Raster is an image of floating point values processed by opcodes. Samples
+// of all planes of a pixel are stored together, pixels are stored by rows.
+type Raster struct {
+	Width  int
+	Height int
+	Planes int
+	Pix    []float32
+}
+
+// NewRaster creates a black raster of the size.
+func NewRaster(width, height, planes int) (r *Raster, err error) {
+	if (width <= 0) || (height <= 0) || (planes <= 0) {
+		return nil, errors.New(ErrRasterSizeIsNotValid)
+	}
+
+	return &Raster{
+		Width:  width,
+		Height: height,
+		Planes: planes,
+		Pix:    make([]float32, width*height*planes),
+	}, nil
+}
+
+// At returns the sample of the pixel.
+func (r *Raster) At(x, y, plane int) float32 {
+	return r.Pix[(y*r.Width+x)*r.Planes+plane]
+}
+
+// Set changes the sample of the pixel.
+func (r *Raster) Set(x, y, plane int, v float32) {
+	r.Pix[(y*r.Width+x)*r.Planes+plane] = v
+}
+
+// Clone returns a copy of the raster.
+func (r *Raster) Clone() (c *Raster) {
+	return &Raster{
+		Width:  r.Width,
+		Height: r.Height,
+		Planes: r.Planes,
+		Pix:    append([]float32(nil), r.Pix...),
+	}
+}
+
+// sample returns the sample at the point by the bilinear interpolation.
+// Points outside the raster get samples of the nearest edge.
+func (r *Raster) sample(x, y float64, plane int) float64 {
+	x = clamp(x, 0, float64(r.Width-1))
+	y = clamp(y, 0, float64(r.Height-1))
+
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, r.Width-1), min(y0+1, r.Height-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	top := float64(r.At(x0, y0, plane))*(1-fx) + float64(r.At(x1, y0, plane))*fx
+	bottom := float64(r.At(x0, y1, plane))*(1-fx) + float64(r.At(x1, y1, plane))*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// opticalCentre converts the optical centre in relative coordinates, where
+// (0, 0) is the top-left pixel and (1, 1) is the bottom-right pixel, into
+// pixel coordinates. It also returns the largest distance from the centre to
+// corners of the raster, by which radial distances of opcodes are
+// normalised.
+func (r *Raster) opticalCentre(cx, cy float64) (x, y, radius float64) {
+	x = cx * float64(r.Width-1)
+	y = cy * float64(r.Height-1)
+
+	for _, corner := range [][2]float64{
+		{0, 0},
+		{float64(r.Width - 1), 0},
+		{0, float64(r.Height - 1)},
+		{float64(r.Width - 1), float64(r.Height - 1)},
+	} {
+		radius = math.Max(radius, math.Hypot(corner[0]-x, corner[1]-y))
+	}
+
+	// A raster of one pixel.
+	if radius == 0 {
+		radius = 1
+	}
+
+	return x, y, radius
+}
+
+// clamp limits the value by the range.
+func clamp(v, low, high float64) float64 {
+	return math.Max(low, math.Min(high, v))
+}
@@ -0,0 +1,219 @@
+package dng
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	ErrPlanesMismatch = "opcode has coefficients of %v planes, but image has %v planes"
+)
+
+// Maximum number of planes of warp opcodes.
+const maxWarpPlanes = 4
+
+// WarpRectilinear corrects the geometric distortion and the lateral
+// chromatic aberration of rectilinear lenses. Each plane has its own radial
+// and tangential coefficients, or a single set of coefficients is used for
+// all planes.
+type WarpRectilinear struct {
+	// Radial are coefficients kr0 – kr3 of the radial distortion of planes.
+	Radial [][4]float64
+
+	// Tangential are coefficients kt0 and kt1 of the tangential distortion
+	// of planes.
+	Tangential [][2]float64
+
+	// CentreX and CentreY are relative coordinates of the optical centre.
+	CentreX float64
+	CentreY float64
+}
+
+// decodeWarpRectilinear decodes parameters of the WarpRectilinear opcode.
+func decodeWarpRectilinear(p *paramReader) Operation {
+	planes := p.count(6 * 8)
+	p.check((planes >= 1) && (planes <= maxWarpPlanes), "number of planes")
+
+	w := &WarpRectilinear{
+		Radial:     make([][4]float64, planes),
+		Tangential: make([][2]float64, planes),
+	}
+	for j := 0; j < planes; j++ {
+		for k := range w.Radial[j] {
+			w.Radial[j][k] = p.float64()
+		}
+		for k := range w.Tangential[j] {
+			w.Tangential[j][k] = p.float64()
+		}
+	}
+	w.CentreX = p.float64()
+	w.CentreY = p.float64()
+
+	return w
+}
+
+// Apply moves each pixel from the distorted point of the image. The source
+// point of a pixel at the normalised distance (dx, dy) from the optical
+// centre, where r² = dx² + dy², is
+//
+//	f = kr0 + kr1 r² + kr2 r⁴ + kr3 r⁶,
+//	x' = f dx + kt0 2 dx dy + kt1 (r² + 2 dx²),
+//	y' = f dy + kt1 2 dx dy + kt0 (r² + 2 dy²).
+func (w *WarpRectilinear) Apply(r *Raster) (err error) {
+	err = checkPlanes(len(w.Radial), r)
+	if err != nil {
+		return err
+	}
+
+	warp(r, w.CentreX, w.CentreY, func(plane int, dx, dy float64) (float64, float64) {
+		if len(w.Radial) == 1 {
+			plane = 0
+		}
+		kr, kt := w.Radial[plane], w.Tangential[plane]
+
+		r2 := dx*dx + dy*dy
+		f := kr[0] + r2*(kr[1]+r2*(kr[2]+r2*kr[3]))
+		sx := f*dx + kt[0]*2*dx*dy + kt[1]*(r2+2*dx*dx)
+		sy := f*dy + kt[1]*2*dx*dy + kt[0]*(r2+2*dy*dy)
+		return sx, sy
+	})
+
+	return nil
+}
+
+// WarpFisheye unwraps the image of a fisheye lens into a rectilinear
+// projection. Each plane has its own radial coefficients, or a single set of
+// coefficients is used for all planes.
+type WarpFisheye struct {
+	// Radial are coefficients kr0 – kr3 of planes.
+	Radial [][4]float64
+
+	// CentreX and CentreY are relative coordinates of the optical centre.
+	CentreX float64
+	CentreY float64
+}
+
+// decodeWarpFisheye decodes parameters of the WarpFisheye opcode.
+func decodeWarpFisheye(p *paramReader) Operation {
+	planes := p.count(4 * 8)
+	p.check((planes >= 1) && (planes <= maxWarpPlanes), "number of planes")
+
+	w := &WarpFisheye{Radial: make([][4]float64, planes)}
+	for j := 0; j < planes; j++ {
+		for k := range w.Radial[j] {
+			w.Radial[j][k] = p.float64()
+		}
+	}
+	w.CentreX = p.float64()
+	w.CentreY = p.float64()
+
+	return w
+}
+
+// Apply moves each pixel from the distorted point of the image. The source
+// point of a pixel at the normalised distance r from the optical centre lies
+// on the same ray at the distance
+//
+//	θ = atan(r),
+//	r' = kr0 θ + kr1 θ³ + kr2 θ⁵ + kr3 θ⁷.
+func (w *WarpFisheye) Apply(r *Raster) (err error) {
+	err = checkPlanes(len(w.Radial), r)
+	if err != nil {
+		return err
+	}
+
+	warp(r, w.CentreX, w.CentreY, func(plane int, dx, dy float64) (float64, float64) {
+		if len(w.Radial) == 1 {
+			plane = 0
+		}
+		kr := w.Radial[plane]
+
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			return 0, 0
+		}
+
+		t := math.Atan(d)
+		t2 := t * t
+		s := t * (kr[0] + t2*(kr[1]+t2*(kr[2]+t2*kr[3]))) / d
+		return s * dx, s * dy
+	})
+
+	return nil
+}
+
+// FixVignetteRadial corrects the vignetting by a radial gain function.
+type FixVignetteRadial struct {
+	// K are coefficients k0 – k4 of the gain function.
+	K [5]float64
+
+	// CentreX and CentreY are relative coordinates of the optical centre.
+	CentreX float64
+	CentreY float64
+}
+
+// decodeFixVignetteRadial decodes parameters of the FixVignetteRadial
+// opcode.
+func decodeFixVignetteRadial(p *paramReader) Operation {
+	v := &FixVignetteRadial{}
+	for j := range v.K {
+		v.K[j] = p.float64()
+	}
+	v.CentreX = p.float64()
+	v.CentreY = p.float64()
+
+	return v
+}
+
+// Apply multiplies samples of all planes by the gain of the normalised
+// distance r from the optical centre:
+//
+//	g = 1 + k0 r² + k1 r⁴ + k2 r⁶ + k3 r⁸ + k4 r¹⁰.
+func (v *FixVignetteRadial) Apply(r *Raster) (err error) {
+	cx, cy, radius := r.opticalCentre(v.CentreX, v.CentreY)
+	k := v.K
+
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			dx, dy := (float64(x)-cx)/radius, (float64(y)-cy)/radius
+			r2 := dx*dx + dy*dy
+			g := 1 + r2*(k[0]+r2*(k[1]+r2*(k[2]+r2*(k[3]+r2*k[4]))))
+
+			for plane := 0; plane < r.Planes; plane++ {
+				r.Set(x, y, plane, float32(float64(r.At(x, y, plane))*g))
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPlanes checks that coefficients of the number of planes may be
+// applied to the raster.
+func checkPlanes(planes int, r *Raster) (err error) {
+	if (planes != 1) && (planes != r.Planes) {
+		return fmt.Errorf(ErrPlanesMismatch, planes, r.Planes)
+	}
+
+	return nil
+}
+
+// warp resamples the raster. The mapping function receives the normalised
+// distance of a pixel from the optical centre and returns the normalised
+// distance of its source point.
+func warp(r *Raster, centreX, centreY float64, mapping func(plane int, dx, dy float64) (sx, sy float64)) {
+	source := r.Clone()
+	cx, cy, radius := r.opticalCentre(centreX, centreY)
+
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			dx, dy := (float64(x)-cx)/radius, (float64(y)-cy)/radius
+
+			for plane := 0; plane < r.Planes; plane++ {
+				sx, sy := mapping(plane, dx, dy)
+				v := source.sample(cx+sx*radius, cy+sy*radius, plane)
+				r.Set(x, y, plane, float32(v))
+			}
+		}
+	}
+}
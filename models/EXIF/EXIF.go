@@ -0,0 +1,225 @@
+package exif
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrTIFFHasNoIFD     = "TIFF has no IFD"
+	ErrExifIFDIsMissing = "Exif IFD is missing"
+)
+
+// EXIF is a typed model of the EXIF private IFD.
+//
+// Optional values which are not stored in the file are left nil. Rational
+// values are converted into floating point numbers, APEX values are converted
+// into seconds and f-numbers, enumerations are converted into named values.
+//
+// Exif specification (Version 2.32):
+// https://www.cipa.jp/std/documents/download_e.html?DC-008-Translation-2019-E
+type EXIF struct {
+	// Versions.
+	ExifVersion     *Version
+	FlashpixVersion *Version
+
+	// ComponentsConfiguration is the channel order of compressed data.
+	ComponentsConfiguration ComponentsConfiguration
+
+	// Date and time values are combined with sub-second values and time
+	// offsets. When the time offset is not recorded, the time is put into the
+	// UTC location.
+	DateTime          *time.Time
+	DateTimeOriginal  *time.Time
+	DateTimeDigitized *time.Time
+
+	// ExposureTime is the exposure time in seconds.
+	ExposureTime *float64
+
+	// FNumber is the F number.
+	FNumber *float64
+
+	// ShutterSpeed is the shutter speed in seconds, decoded from the APEX
+	// ShutterSpeedValue.
+	ShutterSpeed *float64
+
+	// Aperture is the lens aperture as an F number, decoded from the APEX
+	// ApertureValue.
+	Aperture *float64
+
+	// MaxAperture is the smallest F number of the lens, decoded from the APEX
+	// MaxApertureValue.
+	MaxAperture *float64
+
+	// BrightnessValue is the APEX brightness value.
+	BrightnessValue *float64
+
+	// ExposureBias is the exposure bias in EV.
+	ExposureBias *float64
+
+	// SubjectDistance is the distance to the subject in metres. Positive
+	// infinity means infinite distance.
+	SubjectDistance *float64
+
+	// FocalLength is the focal length of the lens in millimetres.
+	FocalLength *float64
+
+	// FocalLengthIn35mmFilm is the equivalent focal length for a 35 mm film
+	// camera in millimetres.
+	FocalLengthIn35mmFilm *int
+
+	// Sensitivity and exposure.
+	ISOSpeedRatings []int
+	ExposureIndex   *float64
+	ExposureProgram *ExposureProgram
+	ExposureMode    *ExposureMode
+	MeteringMode    *MeteringMode
+	LightSource     *LightSource
+	Flash           *Flash
+	FlashEnergy     *float64
+
+	// Image processing.
+	ColorSpace             *ColorSpace
+	CompressedBitsPerPixel *float64
+	CustomRendered         *CustomRendered
+	WhiteBalance           *WhiteBalance
+	DigitalZoomRatio       *float64
+	SceneCaptureType       *SceneCaptureType
+	GainControl            *GainControl
+	Contrast               *Contrast
+	Saturation             *Saturation
+	Sharpness              *Sharpness
+	SubjectDistanceRange   *SubjectDistanceRange
+	SensingMethod          *SensingMethod
+	FileSource             *FileSource
+	SceneType              *SceneType
+
+	// Image geometry.
+	PixelXDimension          *int
+	PixelYDimension          *int
+	FocalPlaneXResolution    *float64
+	FocalPlaneYResolution    *float64
+	FocalPlaneResolutionUnit *ResolutionUnit
+	SubjectArea              []int
+	SubjectLocation          []int
+
+	// Text values.
+	SpectralSensitivity string
+	UserComment         string
+	RelatedSoundFile    string
+	ImageUniqueID       string
+
+	// Errors are errors of values which could not be read. Fields of such
+	// values are left empty, other fields are read as usual.
+	Errors []error
+}
+
+// New constructs the EXIF model from the Exif IFD of the first IFD of the
+// TIFF.
+func New(t *tiff.TIFF) (e *EXIF, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0], t.Header().ByteOrder)
+}
+
+// NewFromIFD constructs the EXIF model from the Exif IFD of the specified
+// IFD. Some date and time values are stored in the IFD itself, that is why
+// the IFD is used and not the Exif IFD.
+func NewFromIFD(i *ifd.IFD, byteOrder bo.ByteOrder) (e *EXIF, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[tag.ExifIFD]
+	if !ok || (de.SubIFD == nil) {
+		return nil, errors.New(ErrExifIFDIsMissing)
+	}
+
+	e = &EXIF{}
+	e.readExifIFD(de.SubIFD.DirectoryEntriesByTagNumber, byteOrder)
+
+	// Date and time of the IFD use sub-seconds and offsets of the Exif IFD.
+	e.DateTime, err = readDateTime(
+		i.DirectoryEntriesByTagNumber[tag.DateTime],
+		de.SubIFD.DirectoryEntriesByTagNumber[tag.SubsecTime],
+		de.SubIFD.DirectoryEntriesByTagNumber[tag.OffsetTime],
+	)
+	if err != nil {
+		e.Errors = append(e.Errors, fmt.Errorf(ifd.ErrInTag, ifd.TagName(tag.DateTime), err.Error()))
+	}
+
+	return e, nil
+}
+
+// readExifIFD reads the values of the Exif IFD.
+func (e *EXIF) readExifIFD(entries map[tag.Tag]*ifd.DirectoryEntry, byteOrder bo.ByteOrder) {
+	r := ifd.NewEntryReader(entries)
+
+	// Versions.
+	e.ExifVersion = readVersion(r, tag.ExifVersion)
+	e.FlashpixVersion = readVersion(r, tag.FlashpixVersion)
+	e.ComponentsConfiguration = readComponentsConfiguration(r, tag.ComponentsConfiguration)
+
+	// Date and time.
+	e.DateTimeOriginal = readDateTimeTags(r, tag.DateTimeOriginal, tag.SubsecTimeOriginal, tag.OffsetTimeOriginal)
+	e.DateTimeDigitized = readDateTimeTags(r, tag.DateTimeDigitized, tag.SubsecTimeDigitized, tag.OffsetTimeDigitized)
+
+	// Exposure.
+	e.ExposureTime = r.Number(tag.ExposureTime)
+	e.FNumber = r.Number(tag.FNumber)
+	e.ShutterSpeed = apexToExposureTime(r.Number(tag.ShutterSpeedValue))
+	e.Aperture = apexToFNumber(r.Number(tag.ApertureValue))
+	e.MaxAperture = apexToFNumber(r.Number(tag.MaxApertureValue))
+	e.BrightnessValue = r.Number(tag.BrightnessValue)
+	e.ExposureBias = r.Number(tag.ExposureBiasValue)
+	e.SubjectDistance = subjectDistance(r.Rational(tag.SubjectDistance))
+	e.FocalLength = r.Number(tag.FocalLength)
+	e.FocalLengthIn35mmFilm = r.Integer(tag.FocalLengthIn35mmFilm)
+
+	// Sensitivity.
+	e.ISOSpeedRatings = r.Integers(tag.ISOSpeedRatings)
+	e.ExposureIndex = r.Number(tag.ExposureIndex)
+	e.ExposureProgram = ifd.Enumeration[ExposureProgram](r, tag.ExposureProgram)
+	e.ExposureMode = ifd.Enumeration[ExposureMode](r, tag.ExposureMode)
+	e.MeteringMode = ifd.Enumeration[MeteringMode](r, tag.MeteringMode)
+	e.LightSource = ifd.Enumeration[LightSource](r, tag.LightSource)
+	e.Flash = newFlash(r.Integer(tag.Flash))
+	e.FlashEnergy = r.Number(tag.FlashEnergy)
+
+	// Image processing.
+	e.ColorSpace = ifd.Enumeration[ColorSpace](r, tag.ColorSpace)
+	e.CompressedBitsPerPixel = r.Number(tag.CompressedBitsPerPixel)
+	e.CustomRendered = ifd.Enumeration[CustomRendered](r, tag.CustomRendered)
+	e.WhiteBalance = ifd.Enumeration[WhiteBalance](r, tag.WhiteBalance)
+	e.DigitalZoomRatio = r.Number(tag.DigitalZoomRatio)
+	e.SceneCaptureType = ifd.Enumeration[SceneCaptureType](r, tag.SceneCaptureType)
+	e.GainControl = ifd.Enumeration[GainControl](r, tag.GainControl)
+	e.Contrast = ifd.Enumeration[Contrast](r, tag.Contrast)
+	e.Saturation = ifd.Enumeration[Saturation](r, tag.Saturation)
+	e.Sharpness = ifd.Enumeration[Sharpness](r, tag.Sharpness)
+	e.SubjectDistanceRange = ifd.Enumeration[SubjectDistanceRange](r, tag.SubjectDistanceRange)
+	e.SensingMethod = ifd.Enumeration[SensingMethod](r, tag.SensingMethod)
+	e.FileSource = ifd.Enumeration[FileSource](r, tag.FileSource)
+	e.SceneType = ifd.Enumeration[SceneType](r, tag.SceneType)
+
+	// Image geometry.
+	e.PixelXDimension = r.Integer(tag.PixelXDimension)
+	e.PixelYDimension = r.Integer(tag.PixelYDimension)
+	e.FocalPlaneXResolution = r.Number(tag.FocalPlaneXResolution)
+	e.FocalPlaneYResolution = r.Number(tag.FocalPlaneYResolution)
+	e.FocalPlaneResolutionUnit = ifd.Enumeration[ResolutionUnit](r, tag.FocalPlaneResolutionUnit)
+	e.SubjectArea = r.Integers(tag.SubjectArea)
+	e.SubjectLocation = r.Integers(tag.SubjectLocation)
+
+	// Text values.
+	e.SpectralSensitivity = r.String(tag.SpectralSensitivity)
+	e.UserComment = readUserComment(r, tag.UserComment, byteOrder)
+	e.RelatedSoundFile = r.String(tag.RelatedSoundFile)
+	e.ImageUniqueID = r.String(tag.ImageUniqueID)
+
+	e.Errors = r.Errors
+}
@@ -0,0 +1,57 @@
+package exif
+
+import (
+	"math"
+	"math/big"
+)
+
+// SubjectDistanceInfinityNumerator is the numerator of the SubjectDistance
+// value, which is used for infinite distances.
+const SubjectDistanceInfinityNumerator = 0xFFFFFFFF
+
+// APEX is the Additive System of Photographic Exposure. Values of this system
+// are logarithms of the usual units with base 2.
+
+// apexToExposureTime converts the APEX time value (Tv) into seconds.
+// T = 2^(-Tv).
+func apexToExposureTime(tv *float64) (seconds *float64) {
+	if tv == nil {
+		return nil
+	}
+
+	x := math.Pow(2, -*tv)
+	return &x
+}
+
+// apexToFNumber converts the APEX aperture value (Av) into the F number.
+// N = 2^(Av/2).
+func apexToFNumber(av *float64) (fNumber *float64) {
+	if av == nil {
+		return nil
+	}
+
+	x := math.Pow(2, *av/2)
+	return &x
+}
+
+// subjectDistance converts the SubjectDistance value into metres, taking into
+// account the special value of infinity. Zero means that the distance is
+// unknown.
+func subjectDistance(rat *big.Rat) (metres *float64) {
+	if rat == nil {
+		return nil
+	}
+
+	var x float64
+	if rat.Num().IsUint64() && (rat.Num().Uint64() == SubjectDistanceInfinityNumerator) {
+		x = math.Inf(1)
+		return &x
+	}
+
+	if rat.Sign() == 0 {
+		return nil
+	}
+
+	x, _ = rat.Float64()
+	return &x
+}
@@ -0,0 +1,376 @@
+package exif
+
+import "fmt"
+
+// ExposureProgram is the class of the program used by the camera to set
+// exposure when the picture is taken.
+type ExposureProgram int
+
+const (
+	ExposureProgramNotDefined       = ExposureProgram(0)
+	ExposureProgramManual           = ExposureProgram(1)
+	ExposureProgramNormal           = ExposureProgram(2)
+	ExposureProgramAperturePriority = ExposureProgram(3)
+	ExposureProgramShutterPriority  = ExposureProgram(4)
+	ExposureProgramCreative         = ExposureProgram(5)
+	ExposureProgramAction           = ExposureProgram(6)
+	ExposureProgramPortrait         = ExposureProgram(7)
+	ExposureProgramLandscape        = ExposureProgram(8)
+)
+
+var exposureProgramNames = map[ExposureProgram]string{
+	ExposureProgramNotDefined:       "Not defined",
+	ExposureProgramManual:           "Manual",
+	ExposureProgramNormal:           "Normal program",
+	ExposureProgramAperturePriority: "Aperture priority",
+	ExposureProgramShutterPriority:  "Shutter priority",
+	ExposureProgramCreative:         "Creative program",
+	ExposureProgramAction:           "Action program",
+	ExposureProgramPortrait:         "Portrait mode",
+	ExposureProgramLandscape:        "Landscape mode",
+}
+
+func (x ExposureProgram) String() string { return enumerationName(exposureProgramNames, x) }
+
+// ExposureMode is the exposure mode set when the image was shot.
+type ExposureMode int
+
+const (
+	ExposureModeAuto        = ExposureMode(0)
+	ExposureModeManual      = ExposureMode(1)
+	ExposureModeAutoBracket = ExposureMode(2)
+)
+
+var exposureModeNames = map[ExposureMode]string{
+	ExposureModeAuto:        "Auto exposure",
+	ExposureModeManual:      "Manual exposure",
+	ExposureModeAutoBracket: "Auto bracket",
+}
+
+func (x ExposureMode) String() string { return enumerationName(exposureModeNames, x) }
+
+// MeteringMode is the metering mode.
+type MeteringMode int
+
+const (
+	MeteringModeUnknown               = MeteringMode(0)
+	MeteringModeAverage               = MeteringMode(1)
+	MeteringModeCenterWeightedAverage = MeteringMode(2)
+	MeteringModeSpot                  = MeteringMode(3)
+	MeteringModeMultiSpot             = MeteringMode(4)
+	MeteringModePattern               = MeteringMode(5)
+	MeteringModePartial               = MeteringMode(6)
+	MeteringModeOther                 = MeteringMode(255)
+)
+
+var meteringModeNames = map[MeteringMode]string{
+	MeteringModeUnknown:               "Unknown",
+	MeteringModeAverage:               "Average",
+	MeteringModeCenterWeightedAverage: "Center weighted average",
+	MeteringModeSpot:                  "Spot",
+	MeteringModeMultiSpot:             "Multi-spot",
+	MeteringModePattern:               "Pattern",
+	MeteringModePartial:               "Partial",
+	MeteringModeOther:                 "Other",
+}
+
+func (x MeteringMode) String() string { return enumerationName(meteringModeNames, x) }
+
+// LightSource is the kind of light source.
+type LightSource int
+
+const (
+	LightSourceUnknown              = LightSource(0)
+	LightSourceDaylight             = LightSource(1)
+	LightSourceFluorescent          = LightSource(2)
+	LightSourceTungsten             = LightSource(3)
+	LightSourceFlash                = LightSource(4)
+	LightSourceFineWeather          = LightSource(9)
+	LightSourceCloudyWeather        = LightSource(10)
+	LightSourceShade                = LightSource(11)
+	LightSourceDaylightFluorescent  = LightSource(12)
+	LightSourceDayWhiteFluorescent  = LightSource(13)
+	LightSourceCoolWhiteFluorescent = LightSource(14)
+	LightSourceWhiteFluorescent     = LightSource(15)
+	LightSourceWarmWhiteFluorescent = LightSource(16)
+	LightSourceStandardLightA       = LightSource(17)
+	LightSourceStandardLightB       = LightSource(18)
+	LightSourceStandardLightC       = LightSource(19)
+	LightSourceD55                  = LightSource(20)
+	LightSourceD65                  = LightSource(21)
+	LightSourceD75                  = LightSource(22)
+	LightSourceD50                  = LightSource(23)
+	LightSourceISOStudioTungsten    = LightSource(24)
+	LightSourceOther                = LightSource(255)
+)
+
+var lightSourceNames = map[LightSource]string{
+	LightSourceUnknown:              "Unknown",
+	LightSourceDaylight:             "Daylight",
+	LightSourceFluorescent:          "Fluorescent",
+	LightSourceTungsten:             "Tungsten (incandescent light)",
+	LightSourceFlash:                "Flash",
+	LightSourceFineWeather:          "Fine weather",
+	LightSourceCloudyWeather:        "Cloudy weather",
+	LightSourceShade:                "Shade",
+	LightSourceDaylightFluorescent:  "Daylight fluorescent (D 5700 – 7100K)",
+	LightSourceDayWhiteFluorescent:  "Day white fluorescent (N 4600 – 5500K)",
+	LightSourceCoolWhiteFluorescent: "Cool white fluorescent (W 3800 – 4500K)",
+	LightSourceWhiteFluorescent:     "White fluorescent (WW 3250 – 3800K)",
+	LightSourceWarmWhiteFluorescent: "Warm white fluorescent (L 2600 – 3250K)",
+	LightSourceStandardLightA:       "Standard light A",
+	LightSourceStandardLightB:       "Standard light B",
+	LightSourceStandardLightC:       "Standard light C",
+	LightSourceD55:                  "D55",
+	LightSourceD65:                  "D65",
+	LightSourceD75:                  "D75",
+	LightSourceD50:                  "D50",
+	LightSourceISOStudioTungsten:    "ISO studio tungsten",
+	LightSourceOther:                "Other light source",
+}
+
+func (x LightSource) String() string { return enumerationName(lightSourceNames, x) }
+
+// ColorSpace is the colour space information.
+type ColorSpace int
+
+const (
+	ColorSpaceSRGB         = ColorSpace(1)
+	ColorSpaceAdobeRGB     = ColorSpace(2) // Not official, but widely used.
+	ColorSpaceUncalibrated = ColorSpace(0xFFFF)
+)
+
+var colorSpaceNames = map[ColorSpace]string{
+	ColorSpaceSRGB:         "sRGB",
+	ColorSpaceAdobeRGB:     "Adobe RGB",
+	ColorSpaceUncalibrated: "Uncalibrated",
+}
+
+func (x ColorSpace) String() string { return enumerationName(colorSpaceNames, x) }
+
+// CustomRendered indicates the use of special processing on image data.
+type CustomRendered int
+
+const (
+	CustomRenderedNormal = CustomRendered(0)
+	CustomRenderedCustom = CustomRendered(1)
+)
+
+var customRenderedNames = map[CustomRendered]string{
+	CustomRenderedNormal: "Normal process",
+	CustomRenderedCustom: "Custom process",
+}
+
+func (x CustomRendered) String() string { return enumerationName(customRenderedNames, x) }
+
+// WhiteBalance is the white balance mode set when the image was shot.
+type WhiteBalance int
+
+const (
+	WhiteBalanceAuto   = WhiteBalance(0)
+	WhiteBalanceManual = WhiteBalance(1)
+)
+
+var whiteBalanceNames = map[WhiteBalance]string{
+	WhiteBalanceAuto:   "Auto white balance",
+	WhiteBalanceManual: "Manual white balance",
+}
+
+func (x WhiteBalance) String() string { return enumerationName(whiteBalanceNames, x) }
+
+// SceneCaptureType is the type of scene that was shot.
+type SceneCaptureType int
+
+const (
+	SceneCaptureTypeStandard   = SceneCaptureType(0)
+	SceneCaptureTypeLandscape  = SceneCaptureType(1)
+	SceneCaptureTypePortrait   = SceneCaptureType(2)
+	SceneCaptureTypeNightScene = SceneCaptureType(3)
+)
+
+var sceneCaptureTypeNames = map[SceneCaptureType]string{
+	SceneCaptureTypeStandard:   "Standard",
+	SceneCaptureTypeLandscape:  "Landscape",
+	SceneCaptureTypePortrait:   "Portrait",
+	SceneCaptureTypeNightScene: "Night scene",
+}
+
+func (x SceneCaptureType) String() string { return enumerationName(sceneCaptureTypeNames, x) }
+
+// GainControl is the degree of overall image gain adjustment.
+type GainControl int
+
+const (
+	GainControlNone         = GainControl(0)
+	GainControlLowGainUp    = GainControl(1)
+	GainControlHighGainUp   = GainControl(2)
+	GainControlLowGainDown  = GainControl(3)
+	GainControlHighGainDown = GainControl(4)
+)
+
+var gainControlNames = map[GainControl]string{
+	GainControlNone:         "None",
+	GainControlLowGainUp:    "Low gain up",
+	GainControlHighGainUp:   "High gain up",
+	GainControlLowGainDown:  "Low gain down",
+	GainControlHighGainDown: "High gain down",
+}
+
+func (x GainControl) String() string { return enumerationName(gainControlNames, x) }
+
+// Contrast is the direction of contrast processing applied by the camera.
+type Contrast int
+
+const (
+	ContrastNormal = Contrast(0)
+	ContrastSoft   = Contrast(1)
+	ContrastHard   = Contrast(2)
+)
+
+var contrastNames = map[Contrast]string{
+	ContrastNormal: "Normal",
+	ContrastSoft:   "Soft",
+	ContrastHard:   "Hard",
+}
+
+func (x Contrast) String() string { return enumerationName(contrastNames, x) }
+
+// Saturation is the direction of saturation processing applied by the camera.
+type Saturation int
+
+const (
+	SaturationNormal = Saturation(0)
+	SaturationLow    = Saturation(1)
+	SaturationHigh   = Saturation(2)
+)
+
+var saturationNames = map[Saturation]string{
+	SaturationNormal: "Normal",
+	SaturationLow:    "Low saturation",
+	SaturationHigh:   "High saturation",
+}
+
+func (x Saturation) String() string { return enumerationName(saturationNames, x) }
+
+// Sharpness is the direction of sharpness processing applied by the camera.
+type Sharpness int
+
+const (
+	SharpnessNormal = Sharpness(0)
+	SharpnessSoft   = Sharpness(1)
+	SharpnessHard   = Sharpness(2)
+)
+
+var sharpnessNames = map[Sharpness]string{
+	SharpnessNormal: "Normal",
+	SharpnessSoft:   "Soft",
+	SharpnessHard:   "Hard",
+}
+
+func (x Sharpness) String() string { return enumerationName(sharpnessNames, x) }
+
+// SubjectDistanceRange is the distance to the subject.
+type SubjectDistanceRange int
+
+const (
+	SubjectDistanceRangeUnknown     = SubjectDistanceRange(0)
+	SubjectDistanceRangeMacro       = SubjectDistanceRange(1)
+	SubjectDistanceRangeCloseView   = SubjectDistanceRange(2)
+	SubjectDistanceRangeDistantView = SubjectDistanceRange(3)
+)
+
+var subjectDistanceRangeNames = map[SubjectDistanceRange]string{
+	SubjectDistanceRangeUnknown:     "Unknown",
+	SubjectDistanceRangeMacro:       "Macro",
+	SubjectDistanceRangeCloseView:   "Close view",
+	SubjectDistanceRangeDistantView: "Distant view",
+}
+
+func (x SubjectDistanceRange) String() string {
+	return enumerationName(subjectDistanceRangeNames, x)
+}
+
+// SensingMethod is the image sensor type on the camera or input device.
+type SensingMethod int
+
+const (
+	SensingMethodNotDefined            = SensingMethod(1)
+	SensingMethodOneChipColorArea      = SensingMethod(2)
+	SensingMethodTwoChipColorArea      = SensingMethod(3)
+	SensingMethodThreeChipColorArea    = SensingMethod(4)
+	SensingMethodColorSequentialArea   = SensingMethod(5)
+	SensingMethodTrilinear             = SensingMethod(7)
+	SensingMethodColorSequentialLinear = SensingMethod(8)
+)
+
+var sensingMethodNames = map[SensingMethod]string{
+	SensingMethodNotDefined:            "Not defined",
+	SensingMethodOneChipColorArea:      "One-chip colour area sensor",
+	SensingMethodTwoChipColorArea:      "Two-chip colour area sensor",
+	SensingMethodThreeChipColorArea:    "Three-chip colour area sensor",
+	SensingMethodColorSequentialArea:   "Colour sequential area sensor",
+	SensingMethodTrilinear:             "Trilinear sensor",
+	SensingMethodColorSequentialLinear: "Colour sequential linear sensor",
+}
+
+func (x SensingMethod) String() string { return enumerationName(sensingMethodNames, x) }
+
+// FileSource is the image source.
+type FileSource int
+
+const (
+	FileSourceOthers             = FileSource(0)
+	FileSourceTransparentScanner = FileSource(1)
+	FileSourceReflexScanner      = FileSource(2)
+	FileSourceDigitalStillCamera = FileSource(3)
+)
+
+var fileSourceNames = map[FileSource]string{
+	FileSourceOthers:             "Others",
+	FileSourceTransparentScanner: "Scanner of transparent type",
+	FileSourceReflexScanner:      "Scanner of reflex type",
+	FileSourceDigitalStillCamera: "Digital still camera",
+}
+
+func (x FileSource) String() string { return enumerationName(fileSourceNames, x) }
+
+// SceneType is the type of scene.
+type SceneType int
+
+const (
+	SceneTypeDirectlyPhotographed = SceneType(1)
+)
+
+var sceneTypeNames = map[SceneType]string{
+	SceneTypeDirectlyPhotographed: "Directly photographed image",
+}
+
+func (x SceneType) String() string { return enumerationName(sceneTypeNames, x) }
+
+// ResolutionUnit is the unit of resolution.
+type ResolutionUnit int
+
+const (
+	ResolutionUnitNone       = ResolutionUnit(1)
+	ResolutionUnitInch       = ResolutionUnit(2)
+	ResolutionUnitCentimeter = ResolutionUnit(3)
+)
+
+var resolutionUnitNames = map[ResolutionUnit]string{
+	ResolutionUnitNone:       "No absolute unit",
+	ResolutionUnitInch:       "Inch",
+	ResolutionUnitCentimeter: "Centimeter",
+}
+
+func (x ResolutionUnit) String() string { return enumerationName(resolutionUnitNames, x) }
+
+// enumerationName returns the name of the enumeration value. Values which are
+// not known are shown as numbers.
+func enumerationName[T ~int](names map[T]string, x T) string {
+	name, ok := names[x]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprintf("Unknown (%d)", int(x))
+}
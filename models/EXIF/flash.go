@@ -0,0 +1,117 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bits of the Flash tag value.
+const (
+	FlashBitFired           = 0x01
+	FlashMaskReturn         = 0x06
+	FlashShiftReturn        = 1
+	FlashMaskMode           = 0x18
+	FlashShiftMode          = 3
+	FlashBitNoFunction      = 0x20
+	FlashBitRedEyeReduction = 0x40
+)
+
+// FlashReturn is the status of returned light of the flash.
+type FlashReturn int
+
+const (
+	FlashReturnNoDetectionFunction = FlashReturn(0)
+	FlashReturnReserved            = FlashReturn(1)
+	FlashReturnNotDetected         = FlashReturn(2)
+	FlashReturnDetected            = FlashReturn(3)
+)
+
+var flashReturnNames = map[FlashReturn]string{
+	FlashReturnNoDetectionFunction: "No strobe return detection function",
+	FlashReturnReserved:            "Reserved",
+	FlashReturnNotDetected:         "Strobe return light not detected",
+	FlashReturnDetected:            "Strobe return light detected",
+}
+
+func (x FlashReturn) String() string { return enumerationName(flashReturnNames, x) }
+
+// FlashMode is the camera's flash mode.
+type FlashMode int
+
+const (
+	FlashModeUnknown               = FlashMode(0)
+	FlashModeCompulsoryFiring      = FlashMode(1)
+	FlashModeCompulsorySuppression = FlashMode(2)
+	FlashModeAuto                  = FlashMode(3)
+)
+
+var flashModeNames = map[FlashMode]string{
+	FlashModeUnknown:               "Unknown",
+	FlashModeCompulsoryFiring:      "Compulsory flash firing",
+	FlashModeCompulsorySuppression: "Compulsory flash suppression",
+	FlashModeAuto:                  "Auto mode",
+}
+
+func (x FlashMode) String() string { return enumerationName(flashModeNames, x) }
+
+// Flash is the decoded value of the Flash tag, which is a set of bits.
+type Flash struct {
+	// Raw is the raw value of the tag.
+	Raw int
+
+	// Fired shows whether the flash fired.
+	Fired bool
+
+	// Return is the status of returned light.
+	Return FlashReturn
+
+	// Mode is the flash mode.
+	Mode FlashMode
+
+	// HasFunction shows whether the flash function is present.
+	HasFunction bool
+
+	// RedEyeReduction shows whether the red-eye reduction mode was used.
+	RedEyeReduction bool
+}
+
+// newFlash decodes the raw value of the Flash tag.
+func newFlash(raw *int) (f *Flash) {
+	if raw == nil {
+		return nil
+	}
+
+	return &Flash{
+		Raw:             *raw,
+		Fired:           *raw&FlashBitFired != 0,
+		Return:          FlashReturn((*raw & FlashMaskReturn) >> FlashShiftReturn),
+		Mode:            FlashMode((*raw & FlashMaskMode) >> FlashShiftMode),
+		HasFunction:     *raw&FlashBitNoFunction == 0,
+		RedEyeReduction: *raw&FlashBitRedEyeReduction != 0,
+	}
+}
+
+// String returns a human-readable description of the flash.
+func (f *Flash) String() string {
+	if !f.HasFunction {
+		return "No flash function"
+	}
+
+	var parts = make([]string, 0, 4)
+	if f.Fired {
+		parts = append(parts, "Fired")
+	} else {
+		parts = append(parts, "Did not fire")
+	}
+	if f.Mode != FlashModeUnknown {
+		parts = append(parts, f.Mode.String())
+	}
+	if f.Return != FlashReturnNoDetectionFunction {
+		parts = append(parts, f.Return.String())
+	}
+	if f.RedEyeReduction {
+		parts = append(parts, "Red-eye reduction")
+	}
+
+	return fmt.Sprintf("%s (0x%02X)", strings.Join(parts, ", "), f.Raw)
+}
@@ -0,0 +1,147 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// DateTimeLayout is the format of date and time values.
+// Example: "2023:03:19 19:49:34".
+const DateTimeLayout = "2006:01:02 15:04:05"
+
+// OffsetTimeLayout is the format of time offset values.
+// Example: "+03:00".
+const OffsetTimeLayout = "-07:00"
+
+const (
+	ErrDateTimeIsNotValid   = "date and time is not valid: %v"
+	ErrSubSecTimeIsNotValid = "sub-second time is not valid: %v"
+	ErrOffsetTimeIsNotValid = "time offset is not valid: %v"
+)
+
+// NanosecondDigits is the number of decimal digits in nanoseconds.
+const NanosecondDigits = 9
+
+// readDateTimeTags reads the date and time value combined with sub-seconds
+// and the time offset by their tags.
+func readDateTimeTags(r *ifd.EntryReader, dateTimeTag, subSecTimeTag, offsetTimeTag tag.Tag) (dt *time.Time) {
+	dt, err := readDateTime(r.Entries[dateTimeTag], r.Entries[subSecTimeTag], r.Entries[offsetTimeTag])
+	if err != nil {
+		r.Fail(dateTimeTag, err)
+		return nil
+	}
+
+	return dt
+}
+
+// readDateTime reads the date and time value combined with sub-seconds and
+// the time offset. Sub-seconds and the time offset are optional.
+func readDateTime(dateTimeDE, subSecTimeDE, offsetTimeDE *ifd.DirectoryEntry) (dt *time.Time, err error) {
+	if dateTimeDE == nil {
+		return nil, nil
+	}
+
+	var dateTime, subSecTime, offsetTime string
+	dateTime, err = dateTimeDE.ValueAsString()
+	if err != nil {
+		return nil, err
+	}
+
+	if subSecTimeDE != nil {
+		subSecTime, err = subSecTimeDE.ValueAsString()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if offsetTimeDE != nil {
+		offsetTime, err = offsetTimeDE.ValueAsString()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parseDateTime(dateTime, subSecTime, offsetTime)
+}
+
+// parseDateTime parses date and time combined with sub-seconds and the time
+// offset. Unknown dates, which are written as empty strings, spaces or
+// zeroes, are returned as nil.
+func parseDateTime(dateTime, subSecTime, offsetTime string) (dt *time.Time, err error) {
+	if isUnknownDateTime(dateTime) {
+		return nil, nil
+	}
+
+	var location = time.UTC
+	offsetTime = strings.TrimSpace(offsetTime)
+	if len(offsetTime) > 0 {
+		var offset time.Time
+		offset, err = time.Parse(OffsetTimeLayout, offsetTime)
+		if err != nil {
+			return nil, fmt.Errorf(ErrOffsetTimeIsNotValid, offsetTime)
+		}
+
+		_, seconds := offset.Zone()
+		location = time.FixedZone(offsetTime, seconds)
+	}
+
+	var t time.Time
+	t, err = time.ParseInLocation(DateTimeLayout, strings.TrimSpace(dateTime), location)
+	if err != nil {
+		return nil, fmt.Errorf(ErrDateTimeIsNotValid, dateTime)
+	}
+
+	var nanoseconds int
+	nanoseconds, err = parseSubSecTime(subSecTime)
+	if err != nil {
+		return nil, err
+	}
+	t = t.Add(time.Duration(nanoseconds))
+
+	return &t, nil
+}
+
+// parseSubSecTime converts sub-second digits into nanoseconds.
+// Example: "25" is 0.25 seconds, i.e. 250 000 000 nanoseconds.
+func parseSubSecTime(subSecTime string) (nanoseconds int, err error) {
+	subSecTime = strings.TrimSpace(subSecTime)
+	if len(subSecTime) > NanosecondDigits {
+		subSecTime = subSecTime[:NanosecondDigits]
+	}
+
+	for i := 0; i < NanosecondDigits; i++ {
+		nanoseconds *= 10
+
+		if i >= len(subSecTime) {
+			continue
+		}
+
+		c := subSecTime[i]
+		if (c < '0') || (c > '9') {
+			return 0, fmt.Errorf(ErrSubSecTimeIsNotValid, subSecTime)
+		}
+		nanoseconds += int(c - '0')
+	}
+
+	return nanoseconds, nil
+}
+
+// isUnknownDateTime checks whether the date is unknown. The Exif
+// specification allows to replace unknown characters with spaces, while some
+// cameras write zeroes instead.
+func isUnknownDateTime(dateTime string) bool {
+	for _, c := range dateTime {
+		switch c {
+		case ' ', ':', '0':
+			continue
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// UserCommentCodeSize is the size of the character code prefix of the
+// UserComment value and of other character-coded texts.
+const UserCommentCodeSize = 8
+
+// Character codes of the UserComment value.
+var (
+	UserCommentCodeASCII     = []byte{'A', 'S', 'C', 'I', 'I', 0, 0, 0}
+	UserCommentCodeJIS       = []byte{'J', 'I', 'S', 0, 0, 0, 0, 0}
+	UserCommentCodeUnicode   = []byte{'U', 'N', 'I', 'C', 'O', 'D', 'E', 0}
+	UserCommentCodeUndefined = []byte{0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+// readUserComment reads the UserComment value.
+func readUserComment(r *ifd.EntryReader, tg tag.Tag, byteOrder bo.ByteOrder) (s string) {
+	return DecodeCharacterCodedText(r.Bytes(tg), byteOrder)
+}
+
+// DecodeCharacterCodedText decodes a text which starts with an 8-byte
+// character code, such as values of UserComment, GPSProcessingMethod and
+// GPSAreaInformation tags. Unicode texts are stored as UCS-2 using the byte
+// order of the TIFF file. JIS texts are not decoded and are returned as is.
+func DecodeCharacterCodedText(ba []byte, byteOrder bo.ByteOrder) (s string) {
+	if len(ba) < UserCommentCodeSize {
+		return ""
+	}
+
+	code, text := ba[:UserCommentCodeSize], ba[UserCommentCodeSize:]
+	if bytes.Equal(code, UserCommentCodeUnicode) {
+		return decodeUCS2(text, byteOrder)
+	}
+
+	return strings.TrimRight(string(text), " \x00")
+}
+
+// decodeUCS2 decodes a UCS-2 text. A byte order mark, if present, has
+// priority over the byte order of the file.
+func decodeUCS2(ba []byte, byteOrder bo.ByteOrder) string {
+	var order binary.ByteOrder = binary.LittleEndian
+	if byteOrder == bo.BigEndian {
+		order = binary.BigEndian
+	}
+
+	if len(ba) >= 2 {
+		switch {
+		case (ba[0] == 0xFE) && (ba[1] == 0xFF):
+			order, ba = binary.BigEndian, ba[2:]
+		case (ba[0] == 0xFF) && (ba[1] == 0xFE):
+			order, ba = binary.LittleEndian, ba[2:]
+		}
+	}
+
+	units := make([]uint16, 0, len(ba)/2)
+	for i := 0; i+1 < len(ba); i += 2 {
+		units = append(units, order.Uint16(ba[i:]))
+	}
+
+	return strings.TrimRight(string(utf16.Decode(units)), " \x00")
+}
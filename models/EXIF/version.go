@@ -0,0 +1,135 @@
+package exif
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// VersionSize is the size of version values, such as ExifVersion and
+// FlashpixVersion. Version is stored as four ASCII digits, e.g. "0232".
+const VersionSize = 4
+
+const ErrVersionIsNotValid = "version is not valid: %v"
+
+// Version is a version of the Exif or Flashpix standard.
+type Version struct {
+	Major int
+	Minor int
+}
+
+// String returns the version in its usual form, e.g. "2.32".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%02d", v.Major, v.Minor)
+}
+
+// parseVersion parses a version stored as four ASCII digits.
+func parseVersion(ba []byte) (v *Version, err error) {
+	if len(ba) != VersionSize {
+		return nil, fmt.Errorf(ErrVersionIsNotValid, ba)
+	}
+
+	var major, minor int
+	major, err = strconv.Atoi(string(ba[:2]))
+	if err != nil {
+		return nil, fmt.Errorf(ErrVersionIsNotValid, ba)
+	}
+
+	minor, err = strconv.Atoi(string(ba[2:]))
+	if err != nil {
+		return nil, fmt.Errorf(ErrVersionIsNotValid, ba)
+	}
+
+	return &Version{Major: major, Minor: minor}, nil
+}
+
+// readVersion reads the version value.
+func readVersion(r *ifd.EntryReader, tg tag.Tag) (v *Version) {
+	ba := r.Bytes(tg)
+	if len(ba) == 0 {
+		return nil
+	}
+
+	// Some tools write the version as a null-terminated ASCII string.
+	if (len(ba) == VersionSize+1) && (ba[VersionSize] == 0) {
+		ba = ba[:VersionSize]
+	}
+
+	v, err := parseVersion(ba)
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	return v
+}
+
+// Component is a channel of the compressed data.
+type Component int
+
+const (
+	ComponentDoesNotExist = Component(0)
+	ComponentY            = Component(1)
+	ComponentCb           = Component(2)
+	ComponentCr           = Component(3)
+	ComponentR            = Component(4)
+	ComponentG            = Component(5)
+	ComponentB            = Component(6)
+)
+
+var componentNames = map[Component]string{
+	ComponentDoesNotExist: "-",
+	ComponentY:            "Y",
+	ComponentCb:           "Cb",
+	ComponentCr:           "Cr",
+	ComponentR:            "R",
+	ComponentG:            "G",
+	ComponentB:            "B",
+}
+
+func (x Component) String() string { return enumerationName(componentNames, x) }
+
+// ComponentsConfiguration is the order of channels of the compressed data.
+// Channels which do not exist are not included.
+type ComponentsConfiguration []Component
+
+const ErrComponentsConfigurationIsNotValid = "components configuration is not valid: %v"
+
+// String returns the channel order, e.g. "YCbCr" or "RGB".
+func (cc ComponentsConfiguration) String() string {
+	var sb strings.Builder
+	for _, c := range cc {
+		sb.WriteString(c.String())
+	}
+
+	return sb.String()
+}
+
+// readComponentsConfiguration reads the ComponentsConfiguration value.
+func readComponentsConfiguration(r *ifd.EntryReader, tg tag.Tag) (cc ComponentsConfiguration) {
+	ba := r.Bytes(tg)
+	if ba == nil {
+		return nil
+	}
+
+	cc = make(ComponentsConfiguration, 0, len(ba))
+	for _, b := range ba {
+		c := Component(b)
+		if c == ComponentDoesNotExist {
+			continue
+		}
+
+		_, isKnown := componentNames[c]
+		if !isKnown {
+			r.Fail(tg, fmt.Errorf(ErrComponentsConfigurationIsNotValid, ba))
+			return nil
+		}
+
+		cc = append(cc, c)
+	}
+
+	return cc
+}
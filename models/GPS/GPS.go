@@ -0,0 +1,227 @@
+package gps
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	exif "github.com/vault-thirteen/TIFFer/models/EXIF"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrTIFFHasNoIFD     = "TIFF has no IFD"
+	ErrGPSIFDIsMissing  = "GPS IFD is missing"
+	ErrReference        = "unknown reference: %v"
+	ErrDateIsNotValid   = "date is not valid: %v"
+	ErrTimeIsNotValid   = "time is not valid: %v"
+	ErrCoordinateRange  = "coordinate is out of range: %v"
+	ErrVersionIsInvalid = "version is not valid: %v"
+)
+
+// Sizes of values.
+const (
+	CoordinateSize = 3 // Degrees, minutes, seconds.
+	TimeStampSize  = 3 // Hours, minutes, seconds.
+	VersionIDSize  = 4
+)
+
+// DateStampLayout is the format of the GPSDateStamp value.
+// Example: "2023:03:19".
+const DateStampLayout = "2006:01:02"
+
+// GPS is a typed model of the GPS private IFD.
+//
+// Coordinates are converted into signed decimal degrees, where northern
+// latitudes and eastern longitudes are positive. Optional values which are
+// not stored in the file are left nil.
+type GPS struct {
+	// VersionID is the version of the GPS IFD, e.g. "2.3.0.0".
+	VersionID string
+
+	// Latitude is the latitude in signed decimal degrees.
+	Latitude *float64
+
+	// Longitude is the longitude in signed decimal degrees.
+	Longitude *float64
+
+	// Altitude is the altitude in metres. Altitudes below the sea level are
+	// negative.
+	Altitude *float64
+
+	// Time is the UTC time composed of GPSDateStamp and GPSTimeStamp.
+	Time *time.Time
+
+	// Satellites used for measurement.
+	Satellites string
+
+	// Status of the receiver.
+	Status *Status
+
+	// MeasureMode is the measurement mode.
+	MeasureMode *MeasureMode
+
+	// DOP is the data degree of precision.
+	DOP *float64
+
+	// Speed is the speed of the receiver.
+	Speed *Speed
+
+	// Track is the direction of the movement of the receiver.
+	Track *Direction
+
+	// ImgDirection is the direction of the image when it was captured.
+	ImgDirection *Direction
+
+	// MapDatum is the geodetic survey data, e.g. "WGS-84".
+	MapDatum string
+
+	// Destination point.
+	DestLatitude  *float64
+	DestLongitude *float64
+	DestBearing   *Direction
+	DestDistance  *Distance
+
+	// ProcessingMethod is the name of the method used for location finding.
+	ProcessingMethod string
+
+	// AreaInformation is the name of the GPS area.
+	AreaInformation string
+
+	// Differential shows whether differential correction was applied.
+	Differential *bool
+
+	// Errors are errors of values which could not be read. Fields of such
+	// values are left empty, other fields are read as usual.
+	Errors []error
+}
+
+// New constructs the GPS model from the GPS IFD of the first IFD of the TIFF.
+func New(t *tiff.TIFF) (g *GPS, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0], t.Header().ByteOrder)
+}
+
+// NewFromIFD constructs the GPS model from the GPS IFD of the specified IFD.
+func NewFromIFD(i *ifd.IFD, byteOrder bo.ByteOrder) (g *GPS, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[tag.GPSIFD]
+	if !ok || (de.SubIFD == nil) {
+		return nil, errors.New(ErrGPSIFDIsMissing)
+	}
+
+	return NewFromEntries(de.SubIFD.DirectoryEntriesByTagNumber, byteOrder)
+}
+
+// NewFromEntries constructs the GPS model from directory entries of the GPS
+// IFD.
+func NewFromEntries(entries map[tag.Tag]*ifd.DirectoryEntry, byteOrder bo.ByteOrder) (g *GPS, err error) {
+	g = &GPS{}
+	r := ifd.NewEntryReader(entries)
+
+	g.VersionID = readVersionID(r, tag.GPSVersionID)
+
+	// Position.
+	g.Latitude = readCoordinate(r, tag.GPSLatitude, tag.GPSLatitudeRef, latitudeReferences, MaxLatitude)
+	g.Longitude = readCoordinate(r, tag.GPSLongitude, tag.GPSLongitudeRef, longitudeReferences, MaxLongitude)
+	g.Altitude = readAltitude(r, tag.GPSAltitude, tag.GPSAltitudeRef)
+	g.Time = readTime(r, tag.GPSDateStamp, tag.GPSTimeStamp)
+
+	// Receiver.
+	g.Satellites = r.String(tag.GPSSatellites)
+	g.Status = reference(r, tag.GPSStatus, statusNames)
+	g.MeasureMode = reference(r, tag.GPSMeasureMode, measureModeNames)
+	g.DOP = r.Number(tag.GPSDOP)
+	g.MapDatum = r.String(tag.GPSMapDatum)
+	g.ProcessingMethod = exif.DecodeCharacterCodedText(r.Bytes(tag.GPSProcessingMethod), byteOrder)
+	g.AreaInformation = exif.DecodeCharacterCodedText(r.Bytes(tag.GPSAreaInformation), byteOrder)
+	g.Differential = readDifferential(r, tag.GPSDifferential)
+
+	// Movement and direction.
+	g.Speed = readSpeed(r, tag.GPSSpeed, tag.GPSSpeedRef)
+	g.Track = readDirection(r, tag.GPSTrack, tag.GPSTrackRef)
+	g.ImgDirection = readDirection(r, tag.GPSImgDirection, tag.GPSImgDirectionRef)
+
+	// Destination.
+	g.DestLatitude = readCoordinate(r, tag.GPSDestLatitude, tag.GPSDestLatitudeRef, latitudeReferences, MaxLatitude)
+	g.DestLongitude = readCoordinate(r, tag.GPSDestLongitude, tag.GPSDestLongitudeRef, longitudeReferences, MaxLongitude)
+	g.DestBearing = readDirection(r, tag.GPSDestBearing, tag.GPSDestBearingRef)
+	g.DestDistance = readDistance(r, tag.GPSDestDistance, tag.GPSDestDistanceRef)
+
+	g.Errors = r.Errors
+
+	return g, nil
+}
+
+// HasPosition tells whether both latitude and longitude are known.
+func (g *GPS) HasPosition() bool {
+	return (g.Latitude != nil) && (g.Longitude != nil)
+}
+
+// readVersionID reads the GPSVersionID value.
+func readVersionID(r *ifd.EntryReader, tg tag.Tag) (s string) {
+	ba := r.Bytes(tg)
+	if ba == nil {
+		return ""
+	}
+	if len(ba) != VersionIDSize {
+		r.Fail(tg, fmt.Errorf(ErrVersionIsInvalid, ba))
+		return ""
+	}
+
+	parts := make([]string, 0, VersionIDSize)
+	for _, b := range ba {
+		parts = append(parts, fmt.Sprint(b))
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// readDifferential reads the GPSDifferential value.
+func readDifferential(r *ifd.EntryReader, tg tag.Tag) (isApplied *bool) {
+	numbers := r.Numbers(tg)
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	x := numbers[0] == 1
+	return &x
+}
+
+// readTime reads the GPSDateStamp and GPSTimeStamp values and combines them
+// into the UTC time. Time can not be composed without the date.
+func readTime(r *ifd.EntryReader, dateTag, timeTag tag.Tag) (t *time.Time) {
+	date := r.String(dateTag)
+	if len(date) == 0 {
+		return nil
+	}
+
+	d, err := time.ParseInLocation(DateStampLayout, date, time.UTC)
+	if err != nil {
+		r.Fail(dateTag, fmt.Errorf(ErrDateIsNotValid, date))
+		return nil
+	}
+
+	if r.Entry(timeTag) == nil {
+		return &d
+	}
+	hms := r.NumbersOfCount(timeTag, TimeStampSize)
+	if hms == nil {
+		return nil
+	}
+	if (hms[0] < 0) || (hms[0] >= 24) || (hms[1] < 0) || (hms[1] >= 60) || (hms[2] < 0) || (hms[2] > 61) {
+		r.Fail(timeTag, fmt.Errorf(ErrTimeIsNotValid, hms))
+		return nil
+	}
+
+	seconds := hms[0]*3600 + hms[1]*60 + hms[2]
+	x := d.Add(time.Duration(math.Round(seconds * float64(time.Second))))
+	return &x
+}
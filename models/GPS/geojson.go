@@ -0,0 +1,90 @@
+package gps
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const ErrPositionIsUnknown = "position is unknown"
+
+// GeoJSON object types.
+// https://datatracker.ietf.org/doc/html/rfc7946
+const (
+	GeoJSONTypeFeature = "Feature"
+	GeoJSONTypePoint   = "Point"
+)
+
+// Feature is a GeoJSON Feature object.
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   *Point         `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Point is a GeoJSON Point geometry. Coordinates are longitude, latitude and
+// an optional altitude, in this order.
+type Point struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// Feature creates a GeoJSON Point Feature of the position. Other known values
+// are stored as properties of the feature.
+func (g *GPS) Feature() (f *Feature, err error) {
+	if !g.HasPosition() {
+		return nil, errors.New(ErrPositionIsUnknown)
+	}
+
+	coordinates := []float64{*g.Longitude, *g.Latitude}
+	if g.Altitude != nil {
+		coordinates = append(coordinates, *g.Altitude)
+	}
+
+	f = &Feature{
+		Type: GeoJSONTypeFeature,
+		Geometry: &Point{
+			Type:        GeoJSONTypePoint,
+			Coordinates: coordinates,
+		},
+		Properties: make(map[string]any),
+	}
+
+	if g.Time != nil {
+		f.Properties["time"] = g.Time.Format(time.RFC3339Nano)
+	}
+	if len(g.MapDatum) > 0 {
+		f.Properties["mapDatum"] = g.MapDatum
+	}
+	if g.DOP != nil {
+		f.Properties["dop"] = *g.DOP
+	}
+	if g.Speed != nil {
+		f.Properties["speed"] = g.Speed.MetresPerSecond()
+	}
+	if g.Track != nil {
+		f.Properties["track"] = g.Track.Degrees
+		f.Properties["trackRef"] = string(g.Track.Reference)
+	}
+	if g.ImgDirection != nil {
+		f.Properties["imgDirection"] = g.ImgDirection.Degrees
+		f.Properties["imgDirectionRef"] = string(g.ImgDirection.Reference)
+	}
+	if len(g.ProcessingMethod) > 0 {
+		f.Properties["processingMethod"] = g.ProcessingMethod
+	}
+
+	return f, nil
+}
+
+// GeoJSON returns the position as a GeoJSON Point Feature encoded in JSON.
+// Speed is stored in metres per second, directions are stored in degrees.
+func (g *GPS) GeoJSON() (ba []byte, err error) {
+	var f *Feature
+	f, err = g.Feature()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(f)
+}
@@ -0,0 +1,300 @@
+package gps
+
+import (
+	"fmt"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Coordinate limits.
+const (
+	MaxLatitude  = 90
+	MaxLongitude = 180
+)
+
+// Coordinate references and their signs.
+var (
+	latitudeReferences = map[string]float64{
+		"N": 1,
+		"S": -1,
+	}
+	longitudeReferences = map[string]float64{
+		"E": 1,
+		"W": -1,
+	}
+)
+
+// Altitude references.
+const (
+	AltitudeRefAboveSeaLevel = 0
+	AltitudeRefBelowSeaLevel = 1
+)
+
+// Status is the status of the GPS receiver when the image was recorded.
+type Status string
+
+const (
+	StatusMeasurementInProgress  = Status("A")
+	StatusMeasurementInterrupted = Status("V")
+)
+
+var statusNames = map[Status]string{
+	StatusMeasurementInProgress:  "Measurement in progress",
+	StatusMeasurementInterrupted: "Measurement interrupted",
+}
+
+func (x Status) String() string { return statusNames[x] }
+
+// MeasureMode is the GPS measurement mode.
+type MeasureMode string
+
+const (
+	MeasureMode2D = MeasureMode("2")
+	MeasureMode3D = MeasureMode("3")
+)
+
+var measureModeNames = map[MeasureMode]string{
+	MeasureMode2D: "2-dimensional measurement",
+	MeasureMode3D: "3-dimensional measurement",
+}
+
+func (x MeasureMode) String() string { return measureModeNames[x] }
+
+// SpeedUnit is the unit of speed.
+type SpeedUnit string
+
+const (
+	SpeedUnitKilometresPerHour = SpeedUnit("K")
+	SpeedUnitMilesPerHour      = SpeedUnit("M")
+	SpeedUnitKnots             = SpeedUnit("N")
+)
+
+var speedUnitNames = map[SpeedUnit]string{
+	SpeedUnitKilometresPerHour: "km/h",
+	SpeedUnitMilesPerHour:      "mph",
+	SpeedUnitKnots:             "knots",
+}
+
+// speedUnitInMetresPerSecond is the value of each speed unit in metres per
+// second.
+var speedUnitInMetresPerSecond = map[SpeedUnit]float64{
+	SpeedUnitKilometresPerHour: 1000.0 / 3600.0,
+	SpeedUnitMilesPerHour:      1609.344 / 3600.0,
+	SpeedUnitKnots:             1852.0 / 3600.0,
+}
+
+func (x SpeedUnit) String() string { return speedUnitNames[x] }
+
+// Speed is a speed value with its unit.
+type Speed struct {
+	Value float64
+	Unit  SpeedUnit
+}
+
+// MetresPerSecond returns the speed in metres per second.
+func (s *Speed) MetresPerSecond() float64 {
+	return s.Value * speedUnitInMetresPerSecond[s.Unit]
+}
+
+// String returns the speed with its unit, e.g. "12.5 km/h".
+func (s *Speed) String() string {
+	return fmt.Sprintf("%g %s", s.Value, s.Unit)
+}
+
+// DirectionReference is the reference of directions.
+type DirectionReference string
+
+const (
+	DirectionReferenceTrueNorth     = DirectionReference("T")
+	DirectionReferenceMagneticNorth = DirectionReference("M")
+)
+
+var directionReferenceNames = map[DirectionReference]string{
+	DirectionReferenceTrueNorth:     "true north",
+	DirectionReferenceMagneticNorth: "magnetic north",
+}
+
+func (x DirectionReference) String() string { return directionReferenceNames[x] }
+
+// Direction is a direction in degrees from 0 to 359.99 with its reference.
+type Direction struct {
+	Degrees   float64
+	Reference DirectionReference
+}
+
+// String returns the direction with its reference, e.g. "90° (true north)".
+func (d *Direction) String() string {
+	return fmt.Sprintf("%g° (%s)", d.Degrees, d.Reference)
+}
+
+// DistanceUnit is the unit of distance.
+type DistanceUnit string
+
+const (
+	DistanceUnitKilometres    = DistanceUnit("K")
+	DistanceUnitMiles         = DistanceUnit("M")
+	DistanceUnitNauticalMiles = DistanceUnit("N")
+)
+
+var distanceUnitNames = map[DistanceUnit]string{
+	DistanceUnitKilometres:    "km",
+	DistanceUnitMiles:         "mi",
+	DistanceUnitNauticalMiles: "nmi",
+}
+
+// distanceUnitInMetres is the value of each distance unit in metres.
+var distanceUnitInMetres = map[DistanceUnit]float64{
+	DistanceUnitKilometres:    1000,
+	DistanceUnitMiles:         1609.344,
+	DistanceUnitNauticalMiles: 1852,
+}
+
+func (x DistanceUnit) String() string { return distanceUnitNames[x] }
+
+// Distance is a distance value with its unit.
+type Distance struct {
+	Value float64
+	Unit  DistanceUnit
+}
+
+// Metres returns the distance in metres.
+func (d *Distance) Metres() float64 {
+	return d.Value * distanceUnitInMetres[d.Unit]
+}
+
+// String returns the distance with its unit, e.g. "1.5 km".
+func (d *Distance) String() string {
+	return fmt.Sprintf("%g %s", d.Value, d.Unit)
+}
+
+// reference reads a reference value which must be one of the known values.
+func reference[T ~string](r *ifd.EntryReader, tg tag.Tag, names map[T]string) (v *T) {
+	s := r.String(tg)
+	if len(s) == 0 {
+		return nil
+	}
+
+	x := T(s)
+	_, isKnown := names[x]
+	if !isKnown {
+		r.Fail(tg, fmt.Errorf(ErrReference, s))
+		return nil
+	}
+
+	return &x
+}
+
+// readCoordinate reads the coordinate stored as degrees, minutes and seconds
+// and converts it into signed decimal degrees using its reference. When the
+// reference is missing, the coordinate is considered positive.
+func readCoordinate(r *ifd.EntryReader, valueTag, refTag tag.Tag, references map[string]float64, limit float64) (degrees *float64) {
+	dms := r.NumbersOfCount(valueTag, CoordinateSize)
+	if dms == nil {
+		return nil
+	}
+
+	var sign float64 = 1
+	ref := r.String(refTag)
+	if len(ref) > 0 {
+		var isKnown bool
+		sign, isKnown = references[ref]
+		if !isKnown {
+			r.Fail(refTag, fmt.Errorf(ErrReference, ref))
+			return nil
+		}
+	}
+
+	x := dms[0] + dms[1]/60 + dms[2]/3600
+	if (x < 0) || (x > limit) {
+		r.Fail(valueTag, fmt.Errorf(ErrCoordinateRange, x))
+		return nil
+	}
+
+	x *= sign
+	return &x
+}
+
+// readAltitude reads the altitude and applies its reference.
+func readAltitude(r *ifd.EntryReader, valueTag, refTag tag.Tag) (metres *float64) {
+	x := r.Number(valueTag)
+	if x == nil {
+		return nil
+	}
+
+	ref := r.Numbers(refTag)
+	if len(ref) == 0 {
+		return x
+	}
+
+	switch ref[0] {
+	case AltitudeRefAboveSeaLevel:
+	case AltitudeRefBelowSeaLevel:
+		*x = -*x
+	default:
+		r.Fail(refTag, fmt.Errorf(ErrReference, ref[0]))
+		return nil
+	}
+
+	return x
+}
+
+// readSpeed reads the speed with its unit. Kilometres per hour are used when
+// the unit is missing, as stated in the specification.
+func readSpeed(r *ifd.EntryReader, valueTag, refTag tag.Tag) (s *Speed) {
+	x := r.Number(valueTag)
+	if x == nil {
+		return nil
+	}
+
+	unit := reference(r, refTag, speedUnitNames)
+	if unit == nil {
+		if r.Entry(refTag) != nil {
+			return nil
+		}
+		u := SpeedUnitKilometresPerHour
+		unit = &u
+	}
+
+	return &Speed{Value: *x, Unit: *unit}
+}
+
+// readDirection reads the direction with its reference. True north is used
+// when the reference is missing, as stated in the specification.
+func readDirection(r *ifd.EntryReader, valueTag, refTag tag.Tag) (d *Direction) {
+	x := r.Number(valueTag)
+	if x == nil {
+		return nil
+	}
+
+	ref := reference(r, refTag, directionReferenceNames)
+	if ref == nil {
+		if r.Entry(refTag) != nil {
+			return nil
+		}
+		tn := DirectionReferenceTrueNorth
+		ref = &tn
+	}
+
+	return &Direction{Degrees: *x, Reference: *ref}
+}
+
+// readDistance reads the distance with its unit. Kilometres are used when the
+// unit is missing, as stated in the specification.
+func readDistance(r *ifd.EntryReader, valueTag, refTag tag.Tag) (d *Distance) {
+	x := r.Number(valueTag)
+	if x == nil {
+		return nil
+	}
+
+	unit := reference(r, refTag, distanceUnitNames)
+	if unit == nil {
+		if r.Entry(refTag) != nil {
+			return nil
+		}
+		u := DistanceUnitKilometres
+		unit = &u
+	}
+
+	return &Distance{Value: *x, Unit: *unit}
+}
@@ -0,0 +1,254 @@
+package geotiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Sizes of the GeoKey directory parts, in Shorts.
+const (
+	HeaderSize   = 4
+	KeyEntrySize = 4
+)
+
+// KeyDirectoryVersion is the only known version of the GeoKey directory.
+const KeyDirectoryVersion = 1
+
+// GeoAsciiParamsSeparator terminates each string stored in GeoAsciiParams.
+const GeoAsciiParamsSeparator = '|'
+
+const (
+	ErrGeoKeyDirectoryIsMissing = "GeoKeyDirectory is missing"
+	ErrHeaderIsTooShort         = "GeoKey directory header is too short"
+	ErrUnsupportedVersion       = "unsupported GeoKey directory version: %v"
+	ErrKeyDirectoryIsTooShort   = "GeoKey directory is too short: %v keys declared, %v keys stored"
+	ErrInKey                    = "error in GeoKey %v: %v"
+	ErrParamsAreMissing         = "referenced tag is missing: %v"
+	ErrParamsOutOfBounds        = "value is out of bounds: offset=%v, count=%v, size=%v"
+	ErrUnsupportedLocation      = "unsupported TIFF tag location: %v"
+	ErrDuplicateKey             = "duplicate GeoKey: %v"
+	ErrKeyTypeMismatch          = "GeoKey %v is not %v"
+)
+
+// KeyDirectory is the GeoKey directory stored in the GeoKeyDirectory tag,
+// with values of the keys resolved from GeoDoubleParams and GeoAsciiParams
+// tags.
+//
+// GeoTIFF specification (OGC GeoTIFF Standard 1.1):
+// https://docs.ogc.org/is/19-008r4/19-008r4.html
+type KeyDirectory struct {
+	// Version of the key directory. It is always 1.
+	Version uint16
+
+	// Revision of the key set, e.g. 1.1 is KeyRevision=1 and MinorRevision=1.
+	KeyRevision   uint16
+	MinorRevision uint16
+
+	// Keys is an array of keys in the order of their appearance.
+	Keys []*Key
+
+	// KeysByID are keys by their identifiers.
+	KeysByID map[KeyID]*Key
+}
+
+// Key is a single GeoKey.
+type Key struct {
+	// ID is the identifier of the key.
+	ID KeyID
+
+	// TIFFTagLocation is the tag where the value is stored. Zero means that
+	// the value is stored in the key itself.
+	TIFFTagLocation tag.Tag
+
+	// Count is the number of values.
+	Count uint16
+
+	// ValueOffset is either the value or the index of the first value in the
+	// tag where it is stored.
+	ValueOffset uint16
+
+	// Value is the resolved value. It is an array of shorts ([]uint16), an
+	// array of doubles ([]float64) or a string.
+	Value any
+}
+
+// NewKeyDirectory reads the GeoKey directory of the IFD.
+func NewKeyDirectory(i *ifd.IFD) (kd *KeyDirectory, err error) {
+	return NewKeyDirectoryFromEntries(i.DirectoryEntriesByTagNumber)
+}
+
+// NewKeyDirectoryFromEntries reads the GeoKey directory from directory
+// entries.
+func NewKeyDirectoryFromEntries(entries map[tag.Tag]*ifd.DirectoryEntry) (kd *KeyDirectory, err error) {
+	de, ok := entries[tag.GeoKeyDirectory]
+	if !ok {
+		return nil, errors.New(ErrGeoKeyDirectoryIsMissing)
+	}
+
+	var shorts []uint16
+	shorts, err = de.ValueAsArrayOfShort()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shorts) < HeaderSize {
+		return nil, errors.New(ErrHeaderIsTooShort)
+	}
+
+	kd = &KeyDirectory{
+		Version:       shorts[0],
+		KeyRevision:   shorts[1],
+		MinorRevision: shorts[2],
+		KeysByID:      make(map[KeyID]*Key),
+	}
+
+	if kd.Version != KeyDirectoryVersion {
+		return nil, fmt.Errorf(ErrUnsupportedVersion, kd.Version)
+	}
+
+	numberOfKeys := int(shorts[3])
+	if len(shorts) < HeaderSize+numberOfKeys*KeyEntrySize {
+		return nil, fmt.Errorf(ErrKeyDirectoryIsTooShort, numberOfKeys, (len(shorts)-HeaderSize)/KeyEntrySize)
+	}
+
+	var params *params
+	params, err = newParams(entries, shorts)
+	if err != nil {
+		return nil, err
+	}
+
+	kd.Keys = make([]*Key, 0, numberOfKeys)
+	for j := 0; j < numberOfKeys; j++ {
+		entry := shorts[HeaderSize+j*KeyEntrySize : HeaderSize+(j+1)*KeyEntrySize]
+		k := &Key{
+			ID:              KeyID(entry[0]),
+			TIFFTagLocation: entry[1],
+			Count:           entry[2],
+			ValueOffset:     entry[3],
+		}
+
+		k.Value, err = params.resolve(k)
+		if err != nil {
+			return nil, fmt.Errorf(ErrInKey, k.ID, err.Error())
+		}
+
+		_, isDuplicate := kd.KeysByID[k.ID]
+		if isDuplicate {
+			return nil, fmt.Errorf(ErrDuplicateKey, k.ID)
+		}
+
+		kd.Keys = append(kd.Keys, k)
+		kd.KeysByID[k.ID] = k
+	}
+
+	return kd, nil
+}
+
+// params holds the values of tags referenced by the keys.
+type params struct {
+	shorts    []uint16
+	doubles   []float64
+	ascii     []byte
+	hasDouble bool
+	hasASCII  bool
+}
+
+// newParams reads the values of tags which may be referenced by the keys.
+func newParams(entries map[tag.Tag]*ifd.DirectoryEntry, shorts []uint16) (p *params, err error) {
+	p = &params{shorts: shorts}
+
+	de, ok := entries[tag.GeoDoubleParams]
+	if ok {
+		p.doubles, err = de.ValueAsArrayOfDouble()
+		if err != nil {
+			return nil, err
+		}
+		p.hasDouble = true
+	}
+
+	de, ok = entries[tag.GeoAsciiParams]
+	if ok {
+		p.ascii, err = de.ValueAsArrayOfByte()
+		if err != nil {
+			return nil, err
+		}
+		p.hasASCII = true
+	}
+
+	return p, nil
+}
+
+// resolve returns the value of the key.
+func (p *params) resolve(k *Key) (v any, err error) {
+	start, end := int(k.ValueOffset), int(k.ValueOffset)+int(k.Count)
+
+	switch k.TIFFTagLocation {
+	case 0:
+		return []uint16{k.ValueOffset}, nil
+
+	case tag.GeoKeyDirectory:
+		if end > len(p.shorts) {
+			return nil, fmt.Errorf(ErrParamsOutOfBounds, start, k.Count, len(p.shorts))
+		}
+		return p.shorts[start:end], nil
+
+	case tag.GeoDoubleParams:
+		if !p.hasDouble {
+			return nil, fmt.Errorf(ErrParamsAreMissing, k.TIFFTagLocation)
+		}
+		if end > len(p.doubles) {
+			return nil, fmt.Errorf(ErrParamsOutOfBounds, start, k.Count, len(p.doubles))
+		}
+		return p.doubles[start:end], nil
+
+	case tag.GeoAsciiParams:
+		if !p.hasASCII {
+			return nil, fmt.Errorf(ErrParamsAreMissing, k.TIFFTagLocation)
+		}
+		if end > len(p.ascii) {
+			return nil, fmt.Errorf(ErrParamsOutOfBounds, start, k.Count, len(p.ascii))
+		}
+
+		// The count includes the separator.
+		s := string(p.ascii[start:end])
+		s = strings.TrimRight(s, string([]byte{GeoAsciiParamsSeparator, 0}))
+		return s, nil
+
+	default:
+		return nil, fmt.Errorf(ErrUnsupportedLocation, k.TIFFTagLocation)
+	}
+}
+
+// Short returns the value of the key as a single short.
+func (k *Key) Short() (v uint16, err error) {
+	shorts, ok := k.Value.([]uint16)
+	if !ok || (len(shorts) == 0) {
+		return 0, fmt.Errorf(ErrKeyTypeMismatch, k.ID, "a short")
+	}
+
+	return shorts[0], nil
+}
+
+// Doubles returns the value of the key as an array of doubles.
+func (k *Key) Doubles() (v []float64, err error) {
+	v, ok := k.Value.([]float64)
+	if !ok {
+		return nil, fmt.Errorf(ErrKeyTypeMismatch, k.ID, "an array of doubles")
+	}
+
+	return v, nil
+}
+
+// ASCII returns the value of the key as a string.
+func (k *Key) ASCII() (v string, err error) {
+	v, ok := k.Value.(string)
+	if !ok {
+		return "", fmt.Errorf(ErrKeyTypeMismatch, k.ID, "a string")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,153 @@
+package geotiff
+
+import "fmt"
+
+// Special values of GeoKeys which store codes.
+const (
+	CodeUndefined   = 0
+	CodeUserDefined = 32767
+)
+
+// ModelType is the type of the model coordinate reference system.
+type ModelType uint16
+
+const (
+	ModelTypeProjected  = ModelType(1)
+	ModelTypeGeographic = ModelType(2)
+	ModelTypeGeocentric = ModelType(3)
+)
+
+var modelTypeNames = map[ModelType]string{
+	ModelTypeProjected:  "Projected",
+	ModelTypeGeographic: "Geographic",
+	ModelTypeGeocentric: "Geocentric",
+	CodeUserDefined:     "User-defined",
+}
+
+func (x ModelType) String() string { return codeName(modelTypeNames, x) }
+
+// RasterType is the raster space, i.e. the way how pixels are mapped to the
+// model space.
+type RasterType uint16
+
+const (
+	RasterPixelIsArea  = RasterType(1)
+	RasterPixelIsPoint = RasterType(2)
+)
+
+var rasterTypeNames = map[RasterType]string{
+	RasterPixelIsArea:  "PixelIsArea",
+	RasterPixelIsPoint: "PixelIsPoint",
+	CodeUserDefined:    "User-defined",
+}
+
+func (x RasterType) String() string { return codeName(rasterTypeNames, x) }
+
+// Unit is a unit of measure, identified by its EPSG code.
+type Unit uint16
+
+// Common units of measure.
+const (
+	// Linear units.
+	UnitMetre          = Unit(9001)
+	UnitFoot           = Unit(9002)
+	UnitUSSurveyFoot   = Unit(9003)
+	UnitModifiedAmFoot = Unit(9004)
+	UnitClarkeFoot     = Unit(9005)
+	UnitIndianFoot     = Unit(9006)
+	UnitLink           = Unit(9007)
+	UnitLinkBenoit     = Unit(9008)
+	UnitLinkSears      = Unit(9009)
+	UnitChainBenoit    = Unit(9010)
+	UnitChainSears     = Unit(9011)
+	UnitYardSears      = Unit(9012)
+	UnitYardIndian     = Unit(9013)
+	UnitFathom         = Unit(9014)
+	UnitNauticalMile   = Unit(9015)
+	UnitKilometre      = Unit(9036)
+
+	// Angular units.
+	UnitRadian        = Unit(9101)
+	UnitDegree        = Unit(9102)
+	UnitArcMinute     = Unit(9103)
+	UnitArcSecond     = Unit(9104)
+	UnitGrad          = Unit(9105)
+	UnitGon           = Unit(9106)
+	UnitDMS           = Unit(9107)
+	UnitDMSHemisphere = Unit(9108)
+)
+
+var unitNames = map[Unit]string{
+	UnitMetre:          "metre",
+	UnitFoot:           "foot",
+	UnitUSSurveyFoot:   "US survey foot",
+	UnitModifiedAmFoot: "modified American foot",
+	UnitClarkeFoot:     "Clarke's foot",
+	UnitIndianFoot:     "Indian foot",
+	UnitLink:           "link",
+	UnitLinkBenoit:     "link (Benoit)",
+	UnitLinkSears:      "link (Sears)",
+	UnitChainBenoit:    "chain (Benoit)",
+	UnitChainSears:     "chain (Sears)",
+	UnitYardSears:      "yard (Sears)",
+	UnitYardIndian:     "yard (Indian)",
+	UnitFathom:         "fathom",
+	UnitNauticalMile:   "international nautical mile",
+	UnitKilometre:      "kilometre",
+	UnitRadian:         "radian",
+	UnitDegree:         "degree",
+	UnitArcMinute:      "arc-minute",
+	UnitArcSecond:      "arc-second",
+	UnitGrad:           "grad",
+	UnitGon:            "gon",
+	UnitDMS:            "DMS",
+	UnitDMSHemisphere:  "DMS hemisphere",
+	CodeUserDefined:    "User-defined",
+}
+
+// unitSizes are sizes of linear units in metres and of angular units in
+// radians.
+var unitSizes = map[Unit]float64{
+	UnitMetre:          1,
+	UnitFoot:           0.3048,
+	UnitUSSurveyFoot:   1200.0 / 3937.0,
+	UnitModifiedAmFoot: 0.3048122530,
+	UnitClarkeFoot:     0.3047972654,
+	UnitIndianFoot:     0.3047995102,
+	UnitLink:           0.201168,
+	UnitLinkBenoit:     0.2011678249,
+	UnitLinkSears:      0.2011676512,
+	UnitChainBenoit:    20.1167824944,
+	UnitChainSears:     20.1167651216,
+	UnitYardSears:      0.9143984146,
+	UnitYardIndian:     0.9143985307,
+	UnitFathom:         1.8288,
+	UnitNauticalMile:   1852,
+	UnitKilometre:      1000,
+	UnitRadian:         1,
+	UnitDegree:         0.0174532925199433,
+	UnitArcMinute:      0.000290888208665722,
+	UnitArcSecond:      4.84813681109536e-06,
+	UnitGrad:           0.015707963267949,
+	UnitGon:            0.015707963267949,
+}
+
+func (x Unit) String() string { return codeName(unitNames, x) }
+
+// Size returns the size of a linear unit in metres or the size of an angular
+// unit in radians. False is returned for unknown units.
+func (x Unit) Size() (size float64, ok bool) {
+	size, ok = unitSizes[x]
+	return size, ok
+}
+
+// codeName returns the name of the code. Codes which are not known are shown
+// as numbers, which are normally the EPSG codes.
+func codeName[T ~uint16](names map[T]string, x T) string {
+	name, ok := names[x]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprintf("%d", uint16(x))
+}
@@ -0,0 +1,280 @@
+package geotiff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Sizes of the model tags, in Doubles.
+const (
+	ModelPixelScaleSize     = 3
+	ModelTiepointSize       = 6
+	ModelTransformationSize = 16
+)
+
+const (
+	ErrImageSizeIsMissing       = "image size is missing"
+	ErrGeoreferencingIsMissing  = "georeferencing tags are missing"
+	ErrTagIsTooShort            = "tag %v is too short: %v"
+	ErrPixelScaleIsMissing      = "ModelPixelScale is missing, tiepoints alone are not supported"
+	ErrTransformIsNotInvertible = "transform is not invertible"
+	ErrUnsupportedRasterType    = "unsupported raster type: %v"
+)
+
+// Affine is an affine transform from the raster space into the model space:
+//
+//	X = A*Column + B*Row + C
+//	Y = D*Column + E*Row + F
+type Affine struct {
+	A, B, C float64
+	D, E, F float64
+}
+
+// Apply applies the transform to the point.
+func (a Affine) Apply(column, row float64) (x, y float64) {
+	return a.A*column + a.B*row + a.C, a.D*column + a.E*row + a.F
+}
+
+// Invert returns the inverse transform.
+func (a Affine) Invert() (inv Affine, err error) {
+	det := a.A*a.E - a.B*a.D
+	if (det == 0) || math.IsNaN(det) || math.IsInf(det, 0) {
+		return inv, errors.New(ErrTransformIsNotInvertible)
+	}
+
+	inv = Affine{
+		A: a.E / det,
+		B: -a.B / det,
+		D: -a.D / det,
+		E: a.A / det,
+	}
+	inv.C = -(inv.A*a.C + inv.B*a.F)
+	inv.F = -(inv.D*a.C + inv.E*a.F)
+
+	return inv, nil
+}
+
+// BoundingBox is an axis-aligned rectangle in the model space.
+type BoundingBox struct {
+	MinX, MinY float64
+	MaxX, MaxY float64
+}
+
+// Georeferencing is an affine georeferencing model of an image.
+//
+// Raster coordinates used by the methods always follow the PixelIsArea
+// convention: point (0, 0) is the upper-left corner of the upper-left pixel,
+// and point (0.5, 0.5) is its centre. Images with the PixelIsPoint raster type
+// are shifted by half a pixel during construction, so that their pixels cover
+// the same area as they do in the model space.
+type Georeferencing struct {
+	// Width and Height are the size of the image in pixels.
+	Width  uint32
+	Height uint32
+
+	// RasterType is the raster type declared by GeoKeys. Missing raster type
+	// is treated as PixelIsArea, which is the default one.
+	RasterType RasterType
+
+	// Transform maps raster coordinates into model coordinates.
+	Transform Affine
+
+	// inverse maps model coordinates into raster coordinates.
+	inverse Affine
+}
+
+// NewGeoreferencing creates a georeferencing model of the IFD.
+func NewGeoreferencing(i *ifd.IFD) (g *Georeferencing, err error) {
+	return NewGeoreferencingFromEntries(i.DirectoryEntriesByTagNumber)
+}
+
+// NewGeoreferencingFromEntries creates a georeferencing model from directory
+// entries. The transform is built either from the ModelTransformation tag or
+// from the ModelPixelScale and ModelTiepoint tags. The raster type is taken
+// from the GeoKey directory when it is present.
+func NewGeoreferencingFromEntries(entries map[tag.Tag]*ifd.DirectoryEntry) (g *Georeferencing, err error) {
+	g = &Georeferencing{
+		RasterType: RasterPixelIsArea,
+	}
+
+	g.Width, g.Height, err = imageSize(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasKeyDirectory := entries[tag.GeoKeyDirectory]
+	if hasKeyDirectory {
+		var kd *KeyDirectory
+		kd, err = NewKeyDirectoryFromEntries(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		rasterType, ok := kd.RasterType()
+		if ok {
+			g.RasterType = rasterType
+		}
+	}
+
+	g.Transform, err = modelTransform(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	switch g.RasterType {
+	case RasterPixelIsArea:
+	case RasterPixelIsPoint:
+		// Raster coordinates of the tags refer to centres of pixels.
+		g.Transform.C, g.Transform.F = g.Transform.Apply(-0.5, -0.5)
+	default:
+		return nil, fmt.Errorf(ErrUnsupportedRasterType, g.RasterType)
+	}
+
+	g.inverse, err = g.Transform.Invert()
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// imageSize reads the size of the image.
+func imageSize(entries map[tag.Tag]*ifd.DirectoryEntry) (width, height uint32, err error) {
+	var sizes [2]uint32
+	for j, tg := range []tag.Tag{tag.ImageWidth, tag.ImageLength} {
+		de, ok := entries[tg]
+		if !ok {
+			return 0, 0, errors.New(ErrImageSizeIsMissing)
+		}
+
+		var values []int64
+		values, err = de.ValueAsArrayOfInteger()
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(values) == 0 {
+			return 0, 0, errors.New(ErrImageSizeIsMissing)
+		}
+
+		sizes[j] = uint32(values[0])
+	}
+
+	return sizes[0], sizes[1], nil
+}
+
+// modelTransform builds the transform stored in the model tags. Raster
+// coordinates of the transform are those used by the tags, i.e. they depend on
+// the raster type.
+func modelTransform(entries map[tag.Tag]*ifd.DirectoryEntry) (a Affine, err error) {
+	var m []float64
+	m, err = doubles(entries, tag.ModelTransformation, ModelTransformationSize)
+	if err != nil {
+		return a, err
+	}
+	if m != nil {
+		// The Z axis of the 4x4 matrix is not used by images.
+		return Affine{
+			A: m[0], B: m[1], C: m[3],
+			D: m[4], E: m[5], F: m[7],
+		}, nil
+	}
+
+	var tiepoint []float64
+	tiepoint, err = doubles(entries, tag.ModelTiepoint, ModelTiepointSize)
+	if err != nil {
+		return a, err
+	}
+
+	var scale []float64
+	scale, err = doubles(entries, tag.ModelPixelScale, ModelPixelScaleSize)
+	if err != nil {
+		return a, err
+	}
+
+	if tiepoint == nil {
+		return a, errors.New(ErrGeoreferencingIsMissing)
+	}
+	if scale == nil {
+		return a, errors.New(ErrPixelScaleIsMissing)
+	}
+
+	// Only the first tiepoint is used when the scale is known.
+	// The Y axis of the model space is directed up, while rows are directed
+	// down.
+	i, j, x, y := tiepoint[0], tiepoint[1], tiepoint[3], tiepoint[4]
+	sx, sy := scale[0], scale[1]
+
+	return Affine{
+		A: sx, B: 0, C: x - i*sx,
+		D: 0, E: -sy, F: y + j*sy,
+	}, nil
+}
+
+// doubles returns values of the tag which must have at least the specified
+// number of values. Nil is returned if the tag is missing.
+func doubles(entries map[tag.Tag]*ifd.DirectoryEntry, tg tag.Tag, minSize int) (v []float64, err error) {
+	de, ok := entries[tg]
+	if !ok {
+		return nil, nil
+	}
+
+	v, err = de.ValueAsArrayOfNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v) < minSize {
+		return nil, fmt.Errorf(ErrTagIsTooShort, de.TagName, len(v))
+	}
+
+	return v, nil
+}
+
+// PixelToModel converts raster coordinates into model coordinates.
+func (g *Georeferencing) PixelToModel(column, row float64) (x, y float64) {
+	return g.Transform.Apply(column, row)
+}
+
+// ModelToPixel converts model coordinates into raster coordinates.
+func (g *Georeferencing) ModelToPixel(x, y float64) (column, row float64) {
+	return g.inverse.Apply(x, y)
+}
+
+// Corners returns model coordinates of the corners of the image in the
+// following order: upper-left, upper-right, lower-right, lower-left.
+func (g *Georeferencing) Corners() (corners [4][2]float64) {
+	w, h := float64(g.Width), float64(g.Height)
+	for j, p := range [4][2]float64{{0, 0}, {w, 0}, {w, h}, {0, h}} {
+		corners[j][0], corners[j][1] = g.Transform.Apply(p[0], p[1])
+	}
+
+	return corners
+}
+
+// BoundingBox returns the bounding box of the image in the model space.
+func (g *Georeferencing) BoundingBox() (bb BoundingBox) {
+	corners := g.Corners()
+
+	bb = BoundingBox{
+		MinX: corners[0][0], MinY: corners[0][1],
+		MaxX: corners[0][0], MaxY: corners[0][1],
+	}
+	for _, c := range corners[1:] {
+		bb.MinX = math.Min(bb.MinX, c[0])
+		bb.MinY = math.Min(bb.MinY, c[1])
+		bb.MaxX = math.Max(bb.MaxX, c[0])
+		bb.MaxY = math.Max(bb.MaxY, c[1])
+	}
+
+	return bb
+}
+
+// Resolution returns the size of a pixel in model units along the columns and
+// along the rows of the image.
+func (g *Georeferencing) Resolution() (x, y float64) {
+	return math.Hypot(g.Transform.A, g.Transform.D), math.Hypot(g.Transform.B, g.Transform.E)
+}
@@ -0,0 +1,129 @@
+package geotiff
+
+import "fmt"
+
+// KeyID is the identifier of a GeoKey.
+type KeyID uint16
+
+// GeoKeys as per GeoTIFF 1.1 Specification (OGC 19-008r4).
+const (
+	// GeoTIFF Configuration GeoKeys.
+	GTModelTypeGeoKey  = KeyID(1024)
+	GTRasterTypeGeoKey = KeyID(1025)
+	GTCitationGeoKey   = KeyID(1026)
+
+	// Geodetic CRS Parameter GeoKeys.
+	GeographicTypeGeoKey        = KeyID(2048)
+	GeogCitationGeoKey          = KeyID(2049)
+	GeogGeodeticDatumGeoKey     = KeyID(2050)
+	GeogPrimeMeridianGeoKey     = KeyID(2051)
+	GeogLinearUnitsGeoKey       = KeyID(2052)
+	GeogLinearUnitSizeGeoKey    = KeyID(2053)
+	GeogAngularUnitsGeoKey      = KeyID(2054)
+	GeogAngularUnitSizeGeoKey   = KeyID(2055)
+	GeogEllipsoidGeoKey         = KeyID(2056)
+	GeogSemiMajorAxisGeoKey     = KeyID(2057)
+	GeogSemiMinorAxisGeoKey     = KeyID(2058)
+	GeogInvFlatteningGeoKey     = KeyID(2059)
+	GeogAzimuthUnitsGeoKey      = KeyID(2060)
+	GeogPrimeMeridianLongGeoKey = KeyID(2061)
+	GeogTOWGS84GeoKey           = KeyID(2062)
+
+	// Projected CRS Parameter GeoKeys.
+	ProjectedCSTypeGeoKey          = KeyID(3072)
+	PCSCitationGeoKey              = KeyID(3073)
+	ProjectionGeoKey               = KeyID(3074)
+	ProjCoordTransGeoKey           = KeyID(3075)
+	ProjLinearUnitsGeoKey          = KeyID(3076)
+	ProjLinearUnitSizeGeoKey       = KeyID(3077)
+	ProjStdParallel1GeoKey         = KeyID(3078)
+	ProjStdParallel2GeoKey         = KeyID(3079)
+	ProjNatOriginLongGeoKey        = KeyID(3080)
+	ProjNatOriginLatGeoKey         = KeyID(3081)
+	ProjFalseEastingGeoKey         = KeyID(3082)
+	ProjFalseNorthingGeoKey        = KeyID(3083)
+	ProjFalseOriginLongGeoKey      = KeyID(3084)
+	ProjFalseOriginLatGeoKey       = KeyID(3085)
+	ProjFalseOriginEastingGeoKey   = KeyID(3086)
+	ProjFalseOriginNorthingGeoKey  = KeyID(3087)
+	ProjCenterLongGeoKey           = KeyID(3088)
+	ProjCenterLatGeoKey            = KeyID(3089)
+	ProjCenterEastingGeoKey        = KeyID(3090)
+	ProjCenterNorthingGeoKey       = KeyID(3091)
+	ProjScaleAtNatOriginGeoKey     = KeyID(3092)
+	ProjScaleAtCenterGeoKey        = KeyID(3093)
+	ProjAzimuthAngleGeoKey         = KeyID(3094)
+	ProjStraightVertPoleLongGeoKey = KeyID(3095)
+	ProjRectifiedGridAngleGeoKey   = KeyID(3096)
+
+	// Vertical CRS Parameter Keys.
+	VerticalCSTypeGeoKey   = KeyID(4096)
+	VerticalCitationGeoKey = KeyID(4097)
+	VerticalDatumGeoKey    = KeyID(4098)
+	VerticalUnitsGeoKey    = KeyID(4099)
+)
+
+var keyNames = map[KeyID]string{
+	GTModelTypeGeoKey:              "GTModelTypeGeoKey",
+	GTRasterTypeGeoKey:             "GTRasterTypeGeoKey",
+	GTCitationGeoKey:               "GTCitationGeoKey",
+	GeographicTypeGeoKey:           "GeographicTypeGeoKey",
+	GeogCitationGeoKey:             "GeogCitationGeoKey",
+	GeogGeodeticDatumGeoKey:        "GeogGeodeticDatumGeoKey",
+	GeogPrimeMeridianGeoKey:        "GeogPrimeMeridianGeoKey",
+	GeogLinearUnitsGeoKey:          "GeogLinearUnitsGeoKey",
+	GeogLinearUnitSizeGeoKey:       "GeogLinearUnitSizeGeoKey",
+	GeogAngularUnitsGeoKey:         "GeogAngularUnitsGeoKey",
+	GeogAngularUnitSizeGeoKey:      "GeogAngularUnitSizeGeoKey",
+	GeogEllipsoidGeoKey:            "GeogEllipsoidGeoKey",
+	GeogSemiMajorAxisGeoKey:        "GeogSemiMajorAxisGeoKey",
+	GeogSemiMinorAxisGeoKey:        "GeogSemiMinorAxisGeoKey",
+	GeogInvFlatteningGeoKey:        "GeogInvFlatteningGeoKey",
+	GeogAzimuthUnitsGeoKey:         "GeogAzimuthUnitsGeoKey",
+	GeogPrimeMeridianLongGeoKey:    "GeogPrimeMeridianLongGeoKey",
+	GeogTOWGS84GeoKey:              "GeogTOWGS84GeoKey",
+	ProjectedCSTypeGeoKey:          "ProjectedCSTypeGeoKey",
+	PCSCitationGeoKey:              "PCSCitationGeoKey",
+	ProjectionGeoKey:               "ProjectionGeoKey",
+	ProjCoordTransGeoKey:           "ProjCoordTransGeoKey",
+	ProjLinearUnitsGeoKey:          "ProjLinearUnitsGeoKey",
+	ProjLinearUnitSizeGeoKey:       "ProjLinearUnitSizeGeoKey",
+	ProjStdParallel1GeoKey:         "ProjStdParallel1GeoKey",
+	ProjStdParallel2GeoKey:         "ProjStdParallel2GeoKey",
+	ProjNatOriginLongGeoKey:        "ProjNatOriginLongGeoKey",
+	ProjNatOriginLatGeoKey:         "ProjNatOriginLatGeoKey",
+	ProjFalseEastingGeoKey:         "ProjFalseEastingGeoKey",
+	ProjFalseNorthingGeoKey:        "ProjFalseNorthingGeoKey",
+	ProjFalseOriginLongGeoKey:      "ProjFalseOriginLongGeoKey",
+	ProjFalseOriginLatGeoKey:       "ProjFalseOriginLatGeoKey",
+	ProjFalseOriginEastingGeoKey:   "ProjFalseOriginEastingGeoKey",
+	ProjFalseOriginNorthingGeoKey:  "ProjFalseOriginNorthingGeoKey",
+	ProjCenterLongGeoKey:           "ProjCenterLongGeoKey",
+	ProjCenterLatGeoKey:            "ProjCenterLatGeoKey",
+	ProjCenterEastingGeoKey:        "ProjCenterEastingGeoKey",
+	ProjCenterNorthingGeoKey:       "ProjCenterNorthingGeoKey",
+	ProjScaleAtNatOriginGeoKey:     "ProjScaleAtNatOriginGeoKey",
+	ProjScaleAtCenterGeoKey:        "ProjScaleAtCenterGeoKey",
+	ProjAzimuthAngleGeoKey:         "ProjAzimuthAngleGeoKey",
+	ProjStraightVertPoleLongGeoKey: "ProjStraightVertPoleLongGeoKey",
+	ProjRectifiedGridAngleGeoKey:   "ProjRectifiedGridAngleGeoKey",
+	VerticalCSTypeGeoKey:           "VerticalCSTypeGeoKey",
+	VerticalCitationGeoKey:         "VerticalCitationGeoKey",
+	VerticalDatumGeoKey:            "VerticalDatumGeoKey",
+	VerticalUnitsGeoKey:            "VerticalUnitsGeoKey",
+}
+
+// KeyNames shows a list of all known GeoKey names.
+func KeyNames() map[KeyID]string {
+	return keyNames
+}
+
+// String returns the name of the GeoKey.
+func (k KeyID) String() string {
+	name, ok := keyNames[k]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprintf("UnknownGeoKey(%d)", uint16(k))
+}
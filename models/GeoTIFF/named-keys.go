@@ -0,0 +1,196 @@
+package geotiff
+
+// Short returns the single short value of the key.
+// False is returned if the key does not exist or is not a short.
+func (kd *KeyDirectory) Short(id KeyID) (v uint16, ok bool) {
+	k, ok := kd.KeysByID[id]
+	if !ok {
+		return 0, false
+	}
+
+	v, err := k.Short()
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// Doubles returns the array of doubles of the key.
+// False is returned if the key does not exist or is not an array of doubles.
+func (kd *KeyDirectory) Doubles(id KeyID) (v []float64, ok bool) {
+	k, ok := kd.KeysByID[id]
+	if !ok {
+		return nil, false
+	}
+
+	v, err := k.Doubles()
+	if err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// Double returns the first double of the key.
+// False is returned if the key does not exist or is not an array of doubles.
+func (kd *KeyDirectory) Double(id KeyID) (v float64, ok bool) {
+	doubles, ok := kd.Doubles(id)
+	if !ok || (len(doubles) == 0) {
+		return 0, false
+	}
+
+	return doubles[0], true
+}
+
+// ASCII returns the string value of the key.
+// False is returned if the key does not exist or is not a string.
+func (kd *KeyDirectory) ASCII(id KeyID) (v string, ok bool) {
+	k, ok := kd.KeysByID[id]
+	if !ok {
+		return "", false
+	}
+
+	v, err := k.ASCII()
+	if err != nil {
+		return "", false
+	}
+
+	return v, true
+}
+
+// code returns the value of the key which stores a code. Undefined codes are
+// treated as missing.
+func (kd *KeyDirectory) code(id KeyID) (v uint16, ok bool) {
+	v, ok = kd.Short(id)
+	if !ok || (v == CodeUndefined) {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// ModelType returns the value of the GTModelTypeGeoKey.
+func (kd *KeyDirectory) ModelType() (v ModelType, ok bool) {
+	x, ok := kd.code(GTModelTypeGeoKey)
+	return ModelType(x), ok
+}
+
+// RasterType returns the value of the GTRasterTypeGeoKey.
+func (kd *KeyDirectory) RasterType() (v RasterType, ok bool) {
+	x, ok := kd.code(GTRasterTypeGeoKey)
+	return RasterType(x), ok
+}
+
+// Citation returns the value of the GTCitationGeoKey.
+func (kd *KeyDirectory) Citation() (v string, ok bool) {
+	return kd.ASCII(GTCitationGeoKey)
+}
+
+// GeographicType returns the EPSG code of the geodetic CRS stored in the
+// GeographicTypeGeoKey.
+func (kd *KeyDirectory) GeographicType() (v uint16, ok bool) {
+	return kd.code(GeographicTypeGeoKey)
+}
+
+// GeogCitation returns the value of the GeogCitationGeoKey.
+func (kd *KeyDirectory) GeogCitation() (v string, ok bool) {
+	return kd.ASCII(GeogCitationGeoKey)
+}
+
+// GeogGeodeticDatum returns the EPSG code of the geodetic datum.
+func (kd *KeyDirectory) GeogGeodeticDatum() (v uint16, ok bool) {
+	return kd.code(GeogGeodeticDatumGeoKey)
+}
+
+// GeogEllipsoid returns the EPSG code of the ellipsoid.
+func (kd *KeyDirectory) GeogEllipsoid() (v uint16, ok bool) {
+	return kd.code(GeogEllipsoidGeoKey)
+}
+
+// GeogLinearUnits returns the linear units of the geodetic CRS.
+func (kd *KeyDirectory) GeogLinearUnits() (v Unit, ok bool) {
+	x, ok := kd.code(GeogLinearUnitsGeoKey)
+	return Unit(x), ok
+}
+
+// GeogAngularUnits returns the angular units of the geodetic CRS.
+func (kd *KeyDirectory) GeogAngularUnits() (v Unit, ok bool) {
+	x, ok := kd.code(GeogAngularUnitsGeoKey)
+	return Unit(x), ok
+}
+
+// GeogAzimuthUnits returns the units of azimuth angles.
+func (kd *KeyDirectory) GeogAzimuthUnits() (v Unit, ok bool) {
+	x, ok := kd.code(GeogAzimuthUnitsGeoKey)
+	return Unit(x), ok
+}
+
+// ProjectedCSType returns the EPSG code of the projected CRS stored in the
+// ProjectedCSTypeGeoKey.
+func (kd *KeyDirectory) ProjectedCSType() (v uint16, ok bool) {
+	return kd.code(ProjectedCSTypeGeoKey)
+}
+
+// PCSCitation returns the value of the PCSCitationGeoKey.
+func (kd *KeyDirectory) PCSCitation() (v string, ok bool) {
+	return kd.ASCII(PCSCitationGeoKey)
+}
+
+// Projection returns the EPSG code of the projection (map conversion).
+func (kd *KeyDirectory) Projection() (v uint16, ok bool) {
+	return kd.code(ProjectionGeoKey)
+}
+
+// ProjCoordTrans returns the code of the coordinate transformation method.
+func (kd *KeyDirectory) ProjCoordTrans() (v uint16, ok bool) {
+	return kd.code(ProjCoordTransGeoKey)
+}
+
+// ProjLinearUnits returns the linear units of the projected CRS.
+func (kd *KeyDirectory) ProjLinearUnits() (v Unit, ok bool) {
+	x, ok := kd.code(ProjLinearUnitsGeoKey)
+	return Unit(x), ok
+}
+
+// VerticalCSType returns the EPSG code of the vertical CRS.
+func (kd *KeyDirectory) VerticalCSType() (v uint16, ok bool) {
+	return kd.code(VerticalCSTypeGeoKey)
+}
+
+// VerticalCitation returns the value of the VerticalCitationGeoKey.
+func (kd *KeyDirectory) VerticalCitation() (v string, ok bool) {
+	return kd.ASCII(VerticalCitationGeoKey)
+}
+
+// VerticalDatum returns the EPSG code of the vertical datum.
+func (kd *KeyDirectory) VerticalDatum() (v uint16, ok bool) {
+	return kd.code(VerticalDatumGeoKey)
+}
+
+// VerticalUnits returns the units of the vertical CRS.
+func (kd *KeyDirectory) VerticalUnits() (v Unit, ok bool) {
+	x, ok := kd.code(VerticalUnitsGeoKey)
+	return Unit(x), ok
+}
+
+// EPSG returns the EPSG code of the horizontal coordinate reference system.
+// For projected models it is the code of the projected CRS, for geographic
+// and geocentric models it is the code of the geodetic CRS. User-defined
+// systems have no EPSG code.
+func (kd *KeyDirectory) EPSG() (code uint16, ok bool) {
+	modelType, _ := kd.ModelType()
+
+	switch modelType {
+	case ModelTypeProjected:
+		code, ok = kd.ProjectedCSType()
+	default:
+		code, ok = kd.GeographicType()
+	}
+
+	if !ok || (code == CodeUserDefined) {
+		return 0, false
+	}
+
+	return code, true
+}
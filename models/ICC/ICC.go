@@ -0,0 +1,330 @@
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// ProfileFileSignature is the signature of ICC profiles stored in the header.
+const ProfileFileSignature = "acsp"
+
+// Sizes of the parts of a profile.
+const (
+	HeaderSize      = 128
+	TagCountSize    = 4
+	TagEntrySize    = 12
+	SignatureSize   = 4
+	ProfileIDSize   = 16
+	XYZNumberSize   = 12
+	tagTypeDataSize = 8
+)
+
+// Device classes.
+const (
+	DeviceClassInput      = "scnr"
+	DeviceClassDisplay    = "mntr"
+	DeviceClassOutput     = "prtr"
+	DeviceClassLink       = "link"
+	DeviceClassColorSpace = "spac"
+	DeviceClassAbstract   = "abst"
+	DeviceClassNamedColor = "nmcl"
+)
+
+// Colour spaces of data and profile connection spaces. Signatures are
+// padded with spaces to 4 characters.
+const (
+	ColourSpaceXYZ   = "XYZ "
+	ColourSpaceLab   = "Lab "
+	ColourSpaceLuv   = "Luv "
+	ColourSpaceYCbCr = "YCbr"
+	ColourSpaceYxy   = "Yxy "
+	ColourSpaceRGB   = "RGB "
+	ColourSpaceGray  = "GRAY"
+	ColourSpaceHSV   = "HSV "
+	ColourSpaceHLS   = "HLS "
+	ColourSpaceCMYK  = "CMYK"
+	ColourSpaceCMY   = "CMY "
+)
+
+// Signatures of tags.
+const (
+	TagProfileDescription     = "desc"
+	TagCopyright              = "cprt"
+	TagMediaWhitePoint        = "wtpt"
+	TagRedColorant            = "rXYZ"
+	TagGreenColorant          = "gXYZ"
+	TagBlueColorant           = "bXYZ"
+	TagRedTRC                 = "rTRC"
+	TagGreenTRC               = "gTRC"
+	TagBlueTRC                = "bTRC"
+	TagGrayTRC                = "kTRC"
+	TagChromaticAdaptation    = "chad"
+	TagMediaBlackPoint        = "bkpt"
+	TagDeviceManufacturerDesc = "dmnd"
+	TagDeviceModelDesc        = "dmdd"
+)
+
+const (
+	ErrTIFFHasNoIFD             = "TIFF has no IFD"
+	ErrProfileTagIsMissing      = "profile tag is missing: %v"
+	ErrProfileIsTooShort        = "profile is too short: %v bytes"
+	ErrProfileSignatureIsWrong  = "profile signature is wrong: %q"
+	ErrTagTableIsTruncated      = "tag table is truncated: %v tags"
+	ErrTagIsOutOfBounds         = "tag %v is out of bounds: offset %v, size %v"
+	ErrTagIsTooShort            = "tag is too short: %v bytes"
+	ErrTagTypeIsNotSupported    = "tag type is not supported: %q"
+	ErrInTag                    = "error in tag %v: %v"
+	ErrTextIsNotTerminated      = "text is not terminated"
+	ErrCurveTypeIsNotSupported  = "parametric curve function type is not supported: %v"
+	ErrValueTypeIsNotBinary     = "value of the type %T can not be read as a profile"
+	ErrMultiLocalizedRecordSize = "record of multi-localized Unicode text is out of bounds"
+)
+
+// ProfileTags are tags of TIFF and DNG which store ICC profiles.
+var ProfileTags = []tag.Tag{
+	tag.ICCProfile,
+	tag.AsShotICCProfile,
+	tag.CurrentICCProfile,
+}
+
+// Version is the version of the profile format.
+type Version struct {
+	Major  byte
+	Minor  byte
+	Bugfix byte
+}
+
+// String returns the version in the 'major.minor.bugfix' form.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Bugfix)
+}
+
+// TagEntry is an entry of the tag table.
+type TagEntry struct {
+	// Signature is the signature of the tag, e.g. 'desc'.
+	Signature string
+
+	// Offset is the position of the data of the tag in the profile.
+	Offset uint32
+
+	// Size is the size of the data of the tag.
+	Size uint32
+
+	// Data is the raw data of the tag, starting with the signature of its
+	// type.
+	Data []byte
+}
+
+// Type returns the signature of the type of the tag data, e.g. 'XYZ '.
+func (te *TagEntry) Type() string {
+	if len(te.Data) < SignatureSize {
+		return ""
+	}
+
+	return string(te.Data[:SignatureSize])
+}
+
+// Profile is a parsed ICC profile.
+//
+// The header and the tag table are parsed, data of common tags is decoded
+// into typed fields. All tags are available as raw data.
+//
+// Specification ICC.1:2022 (Profile version 4.4.0.0):
+// https://www.color.org/specification/ICC.1-2022-05.pdf
+type Profile struct {
+	// Header.
+	Size               uint32
+	PreferredCMM       string
+	Version            Version
+	DeviceClass        string
+	ColourSpace        string
+	PCS                string
+	CreationDate       time.Time
+	Platform           string
+	Flags              uint32
+	DeviceManufacturer string
+	DeviceModel        uint32
+	DeviceAttributes   uint64
+	RenderingIntent    RenderingIntent
+	Illuminant         XYZ
+	Creator            string
+	ID                 [ProfileIDSize]byte
+
+	// Tags are entries of the tag table in the order of their appearance.
+	Tags []*TagEntry
+
+	// Decoded tags. Missing tags are empty.
+	Description         string
+	Copyright           string
+	MediaWhitePoint     *XYZ
+	RedColorant         *XYZ
+	GreenColorant       *XYZ
+	BlueColorant        *XYZ
+	RedTRC              *Curve
+	GreenTRC            *Curve
+	BlueTRC             *Curve
+	GrayTRC             *Curve
+	ChromaticAdaptation *[3][3]float64
+}
+
+// New parses the profile of the ICCProfile tag of the first IFD of the TIFF.
+func New(t *tiff.TIFF) (p *Profile, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0], tag.ICCProfile)
+}
+
+// NewFromIFD parses the profile stored in the tag of the IFD. The tag is one
+// of the ProfileTags.
+func NewFromIFD(i *ifd.IFD, profileTag tag.Tag) (p *Profile, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[profileTag]
+	if !ok {
+		return nil, fmt.Errorf(ErrProfileTagIsMissing, tagName(profileTag))
+	}
+
+	return NewFromEntry(de)
+}
+
+// NewFromEntry parses the profile stored in the directory entry.
+func NewFromEntry(de *ifd.DirectoryEntry) (p *Profile, err error) {
+	data, ok := de.Value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf(ErrValueTypeIsNotBinary, de.Value)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses the profile.
+func Parse(data []byte) (p *Profile, err error) {
+	if len(data) < HeaderSize+TagCountSize {
+		return nil, fmt.Errorf(ErrProfileIsTooShort, len(data))
+	}
+
+	signature := string(data[36:40])
+	if signature != ProfileFileSignature {
+		return nil, fmt.Errorf(ErrProfileSignatureIsWrong, signature)
+	}
+
+	p = &Profile{
+		Size:         binary.BigEndian.Uint32(data[0:]),
+		PreferredCMM: string(data[4:8]),
+		Version: Version{
+			Major:  data[8],
+			Minor:  data[9] >> 4,
+			Bugfix: data[9] & 0x0F,
+		},
+		DeviceClass:        string(data[12:16]),
+		ColourSpace:        string(data[16:20]),
+		PCS:                string(data[20:24]),
+		CreationDate:       readDateTime(data[24:]),
+		Platform:           string(data[40:44]),
+		Flags:              binary.BigEndian.Uint32(data[44:]),
+		DeviceManufacturer: string(data[48:52]),
+		DeviceModel:        binary.BigEndian.Uint32(data[52:]),
+		DeviceAttributes:   binary.BigEndian.Uint64(data[56:]),
+		RenderingIntent:    RenderingIntent(binary.BigEndian.Uint32(data[64:])),
+		Illuminant:         readXYZNumber(data[68:]),
+		Creator:            string(data[80:84]),
+	}
+	copy(p.ID[:], data[84:84+ProfileIDSize])
+
+	p.Tags, err = readTagTable(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.decodeTags()
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Tag returns the entry of the tag table with the signature, or nil.
+func (p *Profile) Tag(signature string) (te *TagEntry) {
+	for _, te = range p.Tags {
+		if te.Signature == signature {
+			return te
+		}
+	}
+
+	return nil
+}
+
+// readTagTable reads the tag table which follows the header.
+func readTagTable(data []byte) (tags []*TagEntry, err error) {
+	count := int(binary.BigEndian.Uint32(data[HeaderSize:]))
+	pos := HeaderSize + TagCountSize
+	if (count < 0) || (count > (len(data)-pos)/TagEntrySize) {
+		return nil, fmt.Errorf(ErrTagTableIsTruncated, count)
+	}
+
+	tags = make([]*TagEntry, 0, count)
+	for range count {
+		te := &TagEntry{
+			Signature: string(data[pos : pos+SignatureSize]),
+			Offset:    binary.BigEndian.Uint32(data[pos+4:]),
+			Size:      binary.BigEndian.Uint32(data[pos+8:]),
+		}
+		pos += TagEntrySize
+
+		end := uint64(te.Offset) + uint64(te.Size)
+		if end > uint64(len(data)) {
+			return nil, fmt.Errorf(ErrTagIsOutOfBounds, te.Signature, te.Offset, te.Size)
+		}
+		te.Data = data[te.Offset:end]
+
+		tags = append(tags, te)
+	}
+
+	return tags, nil
+}
+
+// decodeTags decodes common tags into typed fields.
+func (p *Profile) decodeTags() (err error) {
+	r := &reader{profile: p}
+
+	p.Description = r.text(TagProfileDescription)
+	p.Copyright = r.text(TagCopyright)
+	p.MediaWhitePoint = r.xyz(TagMediaWhitePoint)
+	p.RedColorant = r.xyz(TagRedColorant)
+	p.GreenColorant = r.xyz(TagGreenColorant)
+	p.BlueColorant = r.xyz(TagBlueColorant)
+	p.RedTRC = r.curve(TagRedTRC)
+	p.GreenTRC = r.curve(TagGreenTRC)
+	p.BlueTRC = r.curve(TagBlueTRC)
+	p.GrayTRC = r.curve(TagGrayTRC)
+	p.ChromaticAdaptation = r.matrix(TagChromaticAdaptation)
+
+	return r.err
+}
+
+// readDateTime reads the date and time number, which is six numbers of 16
+// bits: year, month, day, hours, minutes and seconds in UTC.
+func readDateTime(data []byte) time.Time {
+	n := func(j int) int {
+		return int(binary.BigEndian.Uint16(data[j*2:]))
+	}
+
+	return time.Date(n(0), time.Month(n(1)), n(2), n(3), n(4), n(5), 0, time.UTC)
+}
+
+// tagName returns the name of the TIFF tag.
+func tagName(tg tag.Tag) string {
+	name, ok := tag.HumanReadableTagNames()[tg]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprint(tg)
+}
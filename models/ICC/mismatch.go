@@ -0,0 +1,135 @@
+package icc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Values of the PhotometricInterpretation tag which are defined by TIFF
+// extensions and by DNG.
+const (
+	PhotometricInterpretationICCLab    = 9
+	PhotometricInterpretationITULab    = 10
+	PhotometricInterpretationCFA       = 32803
+	PhotometricInterpretationLinearRaw = 34892
+)
+
+// colourSpaceSuffixMultiColour is the suffix of colour spaces with a number
+// of colourants, e.g. '6CLR'.
+const colourSpaceSuffixMultiColour = "CLR"
+
+const (
+	ErrInEntry  = "error in %v: %v"
+	MsgMismatch = "%v has the %q colour space, but PhotometricInterpretation is %v"
+)
+
+// Colour spaces of profiles suiting values of PhotometricInterpretation.
+// Profiles of raw images describe the camera RGB space after demosaicing.
+var suitableColourSpaces = map[int64][]string{
+	models.PhotometricInterpretationWhiteIsZero:     {ColourSpaceGray},
+	models.PhotometricInterpretationBlackIsZero:     {ColourSpaceGray},
+	models.PhotometricInterpretationRGB:             {ColourSpaceRGB},
+	models.PhotometricInterpretationRGBPaletteColor: {ColourSpaceRGB},
+	models.PhotometricInterpretationCMYK:            {ColourSpaceCMYK, ColourSpaceCMY},
+	models.PhotometricInterpretatioYCbCr:            {ColourSpaceRGB, ColourSpaceYCbCr},
+	models.PhotometricInterpretatioCIELAB:           {ColourSpaceLab},
+	PhotometricInterpretationICCLab:                 {ColourSpaceLab},
+	PhotometricInterpretationITULab:                 {ColourSpaceLab},
+	PhotometricInterpretationCFA:                    {ColourSpaceRGB},
+	PhotometricInterpretationLinearRaw:              {ColourSpaceRGB},
+}
+
+// Mismatch is a profile whose colour space does not suit the
+// PhotometricInterpretation of its directory.
+type Mismatch struct {
+	// Path is the path of the entry storing the profile, e.g.
+	// 'IFD0/ICCProfile'.
+	Path string
+
+	// ColourSpace is the colour space of the profile.
+	ColourSpace string
+
+	// PhotometricInterpretation is the value of the tag in the directory of
+	// the profile.
+	PhotometricInterpretation int64
+}
+
+// String returns a human-readable description of the mismatch.
+func (m *Mismatch) String() string {
+	return fmt.Sprintf(MsgMismatch, m.Path, m.ColourSpace, m.PhotometricInterpretation)
+}
+
+// SuitsPhotometricInterpretation tells whether the colour space of the
+// profile suits the value of the PhotometricInterpretation tag. Unknown
+// values and the transparency mask are suited by any profile. The separated
+// interpretation is also suited by profiles of multiple colourants.
+func (p *Profile) SuitsPhotometricInterpretation(pi int64) bool {
+	colourSpaces, ok := suitableColourSpaces[pi]
+	if !ok {
+		return true
+	}
+
+	for _, cs := range colourSpaces {
+		if p.ColourSpace == cs {
+			return true
+		}
+	}
+
+	if pi == models.PhotometricInterpretationCMYK {
+		return strings.HasSuffix(p.ColourSpace, colourSpaceSuffixMultiColour)
+	}
+
+	return false
+}
+
+// Mismatches parses profiles of all directories of the TIFF and compares
+// their colour spaces with the PhotometricInterpretation of the same
+// directory. Directories without the PhotometricInterpretation tag are
+// skipped.
+func Mismatches(t *tiff.TIFF) (list []*Mismatch, err error) {
+	err = t.WalkDirectories(func(d *tiff.Directory) error {
+		piEntry, ok := d.EntriesByTagNumber[tag.PhotometricInterpretation]
+		if !ok {
+			return nil
+		}
+
+		values, err := piEntry.ValueAsArrayOfInteger()
+		if err != nil {
+			return fmt.Errorf(ErrInEntry, d.EntryPath(piEntry), err)
+		}
+		if len(values) == 0 {
+			return nil
+		}
+
+		for _, tg := range ProfileTags {
+			de, ok := d.EntriesByTagNumber[tg]
+			if !ok {
+				continue
+			}
+
+			p, err := NewFromEntry(de)
+			if err != nil {
+				return fmt.Errorf(ErrInEntry, d.EntryPath(de), err)
+			}
+
+			if !p.SuitsPhotometricInterpretation(values[0]) {
+				list = append(list, &Mismatch{
+					Path:                      d.EntryPath(de),
+					ColourSpace:               p.ColourSpace,
+					PhotometricInterpretation: values[0],
+				})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
@@ -0,0 +1,360 @@
+package icc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Signatures of tag types.
+const (
+	TypeTextDescription       = "desc"
+	TypeText                  = "text"
+	TypeMultiLocalizedUnicode = "mluc"
+	TypeXYZ                   = "XYZ "
+	TypeCurve                 = "curv"
+	TypeParametricCurve       = "para"
+	TypeS15Fixed16Array       = "sf32"
+)
+
+// EnglishLanguageCode is the language of the preferred record of
+// multi-localized Unicode texts.
+const EnglishLanguageCode = "en"
+
+// Divisors of fixed-point numbers.
+const (
+	s15Fixed16Divisor = 1 << 16
+	u8Fixed8Divisor   = 1 << 8
+	curveTableDivisor = 0xFFFF
+)
+
+// multiLocalizedRecordSize is the size of a record of a multi-localized
+// Unicode text.
+const multiLocalizedRecordSize = 12
+
+// Rendering intents.
+const (
+	RenderingIntentPerceptual    = RenderingIntent(0)
+	RenderingIntentMediaRelative = RenderingIntent(1)
+	RenderingIntentSaturation    = RenderingIntent(2)
+	RenderingIntentICCAbsolute   = RenderingIntent(3)
+)
+
+var renderingIntentNames = map[RenderingIntent]string{
+	RenderingIntentPerceptual:    "Perceptual",
+	RenderingIntentMediaRelative: "Media-Relative Colorimetric",
+	RenderingIntentSaturation:    "Saturation",
+	RenderingIntentICCAbsolute:   "ICC-Absolute Colorimetric",
+}
+
+// Numbers of parameters of parametric curves by their function types.
+var parametricCurveParameterCounts = map[uint16]int{
+	0: 1,
+	1: 3,
+	2: 4,
+	3: 5,
+	4: 7,
+}
+
+// RenderingIntent is the rendering intent of the profile.
+type RenderingIntent uint32
+
+// String returns the name of the rendering intent.
+func (ri RenderingIntent) String() string {
+	name, ok := renderingIntentNames[ri]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprint(uint32(ri))
+}
+
+// XYZ is a CIE XYZ tristimulus value.
+type XYZ struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Curve is a tone reproduction curve. It is either a gamma value, a table of
+// values sampled uniformly in the [0; 1] range, or a parametric function.
+type Curve struct {
+	// Type is the signature of the type of the curve: 'curv' or 'para'.
+	Type string
+
+	// Gamma is the exponent of the curve. It is set for curves having a
+	// single value and for parametric curves of the function type 0. The
+	// identity curve has the gamma 1.
+	Gamma *float64
+
+	// Table is the list of values of the curve in the [0; 1] range.
+	Table []float64
+
+	// FunctionType is the type of the function of a parametric curve.
+	FunctionType *uint16
+
+	// Parameters are the parameters of the function of a parametric curve:
+	// g, a, b, c, d, e and f, in the order of the specification.
+	Parameters []float64
+}
+
+// reader decodes tags of the profile. The first error is saved and all
+// further reads are skipped.
+type reader struct {
+	profile *Profile
+	err     error
+}
+
+// data returns the data of the tag if it exists and if no error has happened
+// yet. The data is checked to contain the signature of the type and the
+// reserved field.
+func (r *reader) data(signature string) (data []byte) {
+	if r.err != nil {
+		return nil
+	}
+
+	te := r.profile.Tag(signature)
+	if te == nil {
+		return nil
+	}
+
+	if len(te.Data) < tagTypeDataSize {
+		r.fail(signature, fmt.Errorf(ErrTagIsTooShort, len(te.Data)))
+		return nil
+	}
+
+	return te.Data
+}
+
+// fail saves the error of the tag.
+func (r *reader) fail(signature string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf(ErrInTag, signature, err.Error())
+	}
+}
+
+// text reads a text of the 'desc', 'text' or 'mluc' type.
+func (r *reader) text(signature string) (s string) {
+	data := r.data(signature)
+	if data == nil {
+		return ""
+	}
+
+	var err error
+	s, err = decodeText(data)
+	if err != nil {
+		r.fail(signature, err)
+		return ""
+	}
+
+	return s
+}
+
+// xyz reads the first XYZ number of the 'XYZ ' type.
+func (r *reader) xyz(signature string) (v *XYZ) {
+	data := r.data(signature)
+	if data == nil {
+		return nil
+	}
+
+	if string(data[:SignatureSize]) != TypeXYZ {
+		r.fail(signature, fmt.Errorf(ErrTagTypeIsNotSupported, data[:SignatureSize]))
+		return nil
+	}
+	if len(data) < tagTypeDataSize+XYZNumberSize {
+		r.fail(signature, fmt.Errorf(ErrTagIsTooShort, len(data)))
+		return nil
+	}
+
+	xyz := readXYZNumber(data[tagTypeDataSize:])
+	return &xyz
+}
+
+// curve reads a curve of the 'curv' or 'para' type.
+func (r *reader) curve(signature string) (c *Curve) {
+	data := r.data(signature)
+	if data == nil {
+		return nil
+	}
+
+	var err error
+	c, err = decodeCurve(data)
+	if err != nil {
+		r.fail(signature, err)
+		return nil
+	}
+
+	return c
+}
+
+// matrix reads a 3x3 matrix of the 's15Fixed16' type, stored by rows.
+func (r *reader) matrix(signature string) (m *[3][3]float64) {
+	data := r.data(signature)
+	if data == nil {
+		return nil
+	}
+
+	if string(data[:SignatureSize]) != TypeS15Fixed16Array {
+		r.fail(signature, fmt.Errorf(ErrTagTypeIsNotSupported, data[:SignatureSize]))
+		return nil
+	}
+	if len(data) < tagTypeDataSize+9*4 {
+		r.fail(signature, fmt.Errorf(ErrTagIsTooShort, len(data)))
+		return nil
+	}
+
+	m = new([3][3]float64)
+	for j := range 9 {
+		m[j/3][j%3] = readS15Fixed16(data[tagTypeDataSize+j*4:])
+	}
+
+	return m
+}
+
+// decodeText decodes a text of the 'desc', 'text' or 'mluc' type.
+func decodeText(data []byte) (s string, err error) {
+	typ := string(data[:SignatureSize])
+	body := data[tagTypeDataSize:]
+
+	switch typ {
+	case TypeText:
+		return string(bytes.TrimRight(body, "\x00")), nil
+
+	case TypeTextDescription:
+		// The ASCII description is followed by Unicode and ScriptCode
+		// descriptions, which are ignored.
+		if len(body) < 4 {
+			return "", fmt.Errorf(ErrTagIsTooShort, len(data))
+		}
+		count := binary.BigEndian.Uint32(body)
+		if uint64(count) > uint64(len(body)-4) {
+			return "", errors.New(ErrTextIsNotTerminated)
+		}
+		return string(bytes.TrimRight(body[4:4+count], "\x00")), nil
+
+	case TypeMultiLocalizedUnicode:
+		return decodeMultiLocalizedText(data)
+
+	default:
+		return "", fmt.Errorf(ErrTagTypeIsNotSupported, typ)
+	}
+}
+
+// decodeMultiLocalizedText decodes a multi-localized Unicode text. The first
+// English record is preferred, otherwise the first record is used.
+func decodeMultiLocalizedText(data []byte) (s string, err error) {
+	if len(data) < tagTypeDataSize+8 {
+		return "", fmt.Errorf(ErrTagIsTooShort, len(data))
+	}
+
+	count := int(binary.BigEndian.Uint32(data[tagTypeDataSize:]))
+	recordSize := int(binary.BigEndian.Uint32(data[tagTypeDataSize+4:]))
+	if recordSize < multiLocalizedRecordSize {
+		recordSize = multiLocalizedRecordSize
+	}
+
+	start := tagTypeDataSize + 8
+	if (count < 0) || (count > (len(data)-start)/recordSize) {
+		return "", errors.New(ErrMultiLocalizedRecordSize)
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	chosen := start
+	for j := range count {
+		pos := start + j*recordSize
+		if string(data[pos:pos+2]) == EnglishLanguageCode {
+			chosen = pos
+			break
+		}
+	}
+
+	length := uint64(binary.BigEndian.Uint32(data[chosen+4:]))
+	offset := uint64(binary.BigEndian.Uint32(data[chosen+8:]))
+	if offset+length > uint64(len(data)) {
+		return "", errors.New(ErrMultiLocalizedRecordSize)
+	}
+
+	text := data[offset : offset+length]
+	units := make([]uint16, 0, len(text)/2)
+	for j := 0; j+1 < len(text); j += 2 {
+		units = append(units, binary.BigEndian.Uint16(text[j:]))
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+// decodeCurve decodes a curve of the 'curv' or 'para' type.
+func decodeCurve(data []byte) (c *Curve, err error) {
+	c = &Curve{Type: string(data[:SignatureSize])}
+	body := data[tagTypeDataSize:]
+
+	switch c.Type {
+	case TypeCurve:
+		if len(body) < 4 {
+			return nil, fmt.Errorf(ErrTagIsTooShort, len(data))
+		}
+		count := uint64(binary.BigEndian.Uint32(body))
+		if count*2 > uint64(len(body)-4) {
+			return nil, fmt.Errorf(ErrTagIsTooShort, len(data))
+		}
+
+		switch count {
+		case 0:
+			gamma := 1.0
+			c.Gamma = &gamma
+		case 1:
+			gamma := float64(binary.BigEndian.Uint16(body[4:])) / u8Fixed8Divisor
+			c.Gamma = &gamma
+		default:
+			c.Table = make([]float64, 0, count)
+			for j := range int(count) {
+				c.Table = append(c.Table, float64(binary.BigEndian.Uint16(body[4+j*2:]))/curveTableDivisor)
+			}
+		}
+
+	case TypeParametricCurve:
+		if len(body) < 4 {
+			return nil, fmt.Errorf(ErrTagIsTooShort, len(data))
+		}
+		functionType := binary.BigEndian.Uint16(body)
+		n, ok := parametricCurveParameterCounts[functionType]
+		if !ok {
+			return nil, fmt.Errorf(ErrCurveTypeIsNotSupported, functionType)
+		}
+		if len(body) < 4+n*4 {
+			return nil, fmt.Errorf(ErrTagIsTooShort, len(data))
+		}
+
+		c.FunctionType = &functionType
+		c.Parameters = make([]float64, 0, n)
+		for j := range n {
+			c.Parameters = append(c.Parameters, readS15Fixed16(body[4+j*4:]))
+		}
+		if functionType == 0 {
+			c.Gamma = &c.Parameters[0]
+		}
+
+	default:
+		return nil, fmt.Errorf(ErrTagTypeIsNotSupported, c.Type)
+	}
+
+	return c, nil
+}
+
+// readXYZNumber reads an XYZ number, which is three s15Fixed16 numbers.
+func readXYZNumber(data []byte) XYZ {
+	return XYZ{
+		X: readS15Fixed16(data[0:]),
+		Y: readS15Fixed16(data[4:]),
+		Z: readS15Fixed16(data[8:]),
+	}
+}
+
+// readS15Fixed16 reads a signed fixed-point number with 16 fractional bits.
+func readS15Fixed16(data []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / s15Fixed16Divisor
+}
@@ -14,6 +14,19 @@ import (
 	"github.com/vault-thirteen/auxie/rs"
 )
 
+// Namespaces of tags. Tags of different namespaces may have the same numbers,
+// e.g. GPSLatitude and InteroperabilityVersion.
+const (
+	NamespaceTIFF    = "TIFF"
+	NamespaceExif    = "Exif"
+	NamespaceGPS     = "GPS"
+	NamespaceInterop = "Interop"
+
+	// NamespaceMakerNote is the namespace of private tags of camera vendors
+	// stored in the MakerNote tag.
+	NamespaceMakerNote = "MakerNote"
+)
+
 // FastValueLimitSize is the maximum amount of data which can be stored in the
 // 'ValueOrOffset' field. Since this field is a DWORD, then it is 4 Bytes.
 const FastValueLimitSize = 4
@@ -50,6 +63,11 @@ type DirectoryEntry struct {
 	// Normally, value is an array of data items.
 	Value any
 
+	// ValueError is the error of a value which could not be decoded, such as
+	// a rational with a zero denominator. Value of such an entry is nil, so
+	// that a single malformed entry does not fail parsing of the whole file.
+	ValueError error
+
 	// Below are the fields for internal usage.
 
 	// Data item size (in Bytes).
@@ -82,6 +100,15 @@ type DirectoryEntry struct {
 	// as stated in the TIFF 6.0 Specification. To count those "shadow" tags,
 	// we use this flag.
 	isTypeRegistered bool
+
+	// namespace is the namespace of the tag, i.e. the kind of the directory
+	// containing the entry. It is empty for entries of IFDs.
+	namespace string
+
+	// tagNames are names of tags of a private namespace, such as the
+	// namespace of a MakerNote. When they are set, names and type rules of
+	// the TIFF tags are not used.
+	tagNames map[tag.Tag]string
 }
 
 // NewDE constructs a first-pass model of a Directory Entry from the stream.
@@ -238,6 +265,10 @@ func (de *DirectoryEntry) ValueAsArrayOfLong() (v []bt.DWord, err error) {
 
 // ValueAsArrayOfRational tries to return the value as array of rationals.
 func (de *DirectoryEntry) ValueAsArrayOfRational() (v []bt.Rational, err error) {
+	if de.ValueError != nil {
+		return nil, de.ValueError
+	}
+
 	var ok bool
 	v, ok = de.Value.([]*big.Rat)
 	if ok {
@@ -295,6 +326,10 @@ func (de *DirectoryEntry) ValueAsArrayOfSLong() (v []bt.SLong, err error) {
 // ValueAsArrayOfSRational tries to return the value as array of signed
 // rationals.
 func (de *DirectoryEntry) ValueAsArrayOfSRational() (v []bt.SRational, err error) {
+	if de.ValueError != nil {
+		return nil, de.ValueError
+	}
+
 	var ok bool
 	v, ok = de.Value.([]*big.Rat)
 	if ok {
@@ -336,11 +371,61 @@ func (de *DirectoryEntry) HasFastValue() bool {
 	return de.hasFastValue
 }
 
+// Namespace returns the namespace of the tag. Entries of IFDs and of SubIFDs
+// storing images, such as those referred by the SubIFDs tag, belong to the
+// TIFF namespace.
+func (de *DirectoryEntry) Namespace() string {
+	if de.namespace == "" {
+		return NamespaceTIFF
+	}
+
+	return de.namespace
+}
+
+// subIFDNamespace returns the namespace of entries of SubIFDs of the tag.
+func subIFDNamespace(tg tag.Tag) string {
+	switch tg {
+	case tag.ExifIFD:
+		return NamespaceExif
+	case tag.GPSIFD:
+		return NamespaceGPS
+	case tag.InteroperabilityIFD:
+		return NamespaceInterop
+	default:
+		return NamespaceTIFF
+	}
+}
+
 // HasSubIFD shows whether this DE has a sub-IFD or not.
 func (de *DirectoryEntry) HasSubIFD() bool {
 	return de.hasSubIFD
 }
 
+// HasSubIFDChain tells whether SubIFDs of the entry are chained. An entry
+// with a single offset refers to a chain of SubIFDs, an entry with multiple
+// offsets refers to a separate SubIFD by each offset.
+func (de *DirectoryEntry) HasSubIFDChain() bool {
+	return de.Count == 1
+}
+
+// SubIFDOffsets returns offsets of SubIFDs of the entry in the order of the
+// SubIFDs.
+func (de *DirectoryEntry) SubIFDOffsets() (offsets []models.OffsetOfIFD) {
+	if !de.HasSubIFDChain() {
+		offsets, _ = de.ValueAsArrayOfLong()
+		return offsets
+	}
+
+	offsets = make([]models.OffsetOfIFD, 0, len(de.SubIFDs))
+	offset := de.ValueOrOffset
+	for _, si := range de.SubIFDs {
+		offsets = append(offsets, offset)
+		offset = si.OffsetOfNextSubIFD
+	}
+
+	return offsets
+}
+
 // ProcessSubIFDs processes the directory entry sub-IFDs.
 // Here we read sub-IFDs of all tags who have them.
 func (de *DirectoryEntry) ProcessSubIFDs(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (err error) {
@@ -355,15 +440,16 @@ func (de *DirectoryEntry) ProcessSubIFDs(rs *rs.ReaderSeeker, byteOrder bo.ByteO
 
 	// The 'Sub-IFD' is not described in the TIFF 6.0 Specification and
 	// documentation for it is very poor, so we better make some fool checks.
-	if de.Type != t.Long {
+	if (de.Type != t.Long) && (de.Type != t.IFD) {
 		return errors.New(ErrSubIFDOffsetMustBeLong)
 	}
-	if de.Count != 1 {
-		return errors.New(ErrSubIFDCanNotHaveMultipleOffsets)
-	}
 
 	// Pass I.
-	err = de.readSubIFDPassOne(rs, byteOrder)
+	if de.HasSubIFDChain() {
+		err = de.readSubIFDPassOne(rs, byteOrder)
+	} else {
+		err = de.readSubIFDsPassOne(rs, byteOrder)
+	}
 	if err != nil {
 		return err
 	}
@@ -422,15 +508,45 @@ func (de *DirectoryEntry) readSubIFDPassOne(rs *rs.ReaderSeeker, byteOrder bo.By
 	return nil
 }
 
+// readSubIFDsPassOne performs a first read pass of SubIFDs of an entry
+// having multiple offsets, such as the SubIFDs tag of DNG files. Each offset
+// refers to its own SubIFD, the SubIFDs are not chained.
+func (de *DirectoryEntry) readSubIFDsPassOne(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (err error) {
+	var offsets []models.OffsetOfIFD
+	offsets, err = de.ValueAsArrayOfLong()
+	if err != nil {
+		return err
+	}
+
+	var si *SubIFD
+	for idx, offset := range offsets {
+		si, err = NewSubIFD(rs, byteOrder, offset)
+		if err != nil {
+			return fmt.Errorf(ErrInNthSubIFD, idx+1, err.Error())
+		}
+		de.SubIFDs = append(de.SubIFDs, si)
+	}
+	if len(de.SubIFDs) > 0 {
+		de.SubIFD = de.SubIFDs[0]
+	}
+
+	return nil
+}
+
 // readSubIFDPassTwo performs a second-pass read of the SubIFD.
 // In this pass we read values and try to decode them.
 func (de *DirectoryEntry) readSubIFDPassTwo(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (err error) {
+	namespace := subIFDNamespace(de.Tag)
 	for _, curIFD := range de.SubIFDs {
 		err = curIFD.ProcessValues(rs, byteOrder)
 		if err != nil {
 			return err
 		}
 
+		for _, e := range curIFD.DirectoryEntries {
+			e.namespace = namespace
+		}
+
 		curIFD.FillStatistics()
 	}
 
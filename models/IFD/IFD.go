@@ -155,6 +155,42 @@ func (i *IFD) ProcessValues(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (err er
 	return nil
 }
 
+// ProcessValuesOfNamespace processes values of the IFD whose tags belong to
+// a private namespace, such as the namespace of a MakerNote. Tags are named
+// using the table of the namespace, types of entries are not checked.
+//
+// Private data is often damaged or refers to data outside of it, so an entry
+// whose value can not be read does not stop the processing. Such entries are
+// removed from the IFD, and their errors are returned in the order of
+// entries, while the other entries are kept.
+func (i *IFD) ProcessValuesOfNamespace(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder, namespace string, tagNames map[tag.Tag]string) (entryErrors []error, err error) {
+	if tagNames == nil {
+		tagNames = make(map[tag.Tag]string)
+	}
+
+	readable := make([]*DirectoryEntry, 0, len(i.DirectoryEntries))
+	for _, curDE := range i.DirectoryEntries {
+		curDE.namespace = namespace
+		curDE.tagNames = tagNames
+
+		err = curDE.ProcessValues(rs, byteOrder)
+		if err != nil {
+			entryErrors = append(entryErrors, fmt.Errorf(ErrDE, curDE.Tag, curDE.TagName, err.Error()))
+			continue
+		}
+
+		readable = append(readable, curDE)
+	}
+	i.DirectoryEntries = readable
+
+	err = i.processDEMaps()
+	if err != nil {
+		return entryErrors, err
+	}
+
+	return entryErrors, nil
+}
+
 // processDEMaps fills the fast-access maps of Directory Entries.
 func (i *IFD) processDEMaps() (err error) {
 	i.DirectoryEntriesByTagNumber = make(map[tag.Tag]*DirectoryEntry)
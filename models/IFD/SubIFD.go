@@ -12,8 +12,7 @@ import (
 )
 
 const (
-	ErrSubIFDOffsetMustBeLong          = "sub-IFD offset must be long"
-	ErrSubIFDCanNotHaveMultipleOffsets = "sub-IFD can not have multiple offsets"
+	ErrSubIFDOffsetMustBeLong = "sub-IFD offset must be long or IFD"
 )
 
 // SubIFD is the Sub Image File Directory.
@@ -0,0 +1,191 @@
+package ifd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrInTag      = `error in tag "%v": %v`
+	ErrValueCount = "value has %v items instead of %v"
+)
+
+// EntryReader reads typed values of directory entries for typed models, which
+// read long lists of optional values.
+//
+// A value which can not be read is returned as a missing one and its error is
+// saved, so that a malformed entry leaves only its own field of the model
+// empty, while all other values are read as usual.
+type EntryReader struct {
+	// Entries are directory entries by their tags.
+	Entries map[tag.Tag]*DirectoryEntry
+
+	// Errors are errors of entries which could not be read, in the order of
+	// reading.
+	Errors []error
+}
+
+// NewEntryReader creates a reader of the directory entries.
+func NewEntryReader(entries map[tag.Tag]*DirectoryEntry) (r *EntryReader) {
+	return &EntryReader{Entries: entries}
+}
+
+// Entry returns the directory entry of the tag, or nil.
+func (r *EntryReader) Entry(tg tag.Tag) (de *DirectoryEntry) {
+	return r.Entries[tg]
+}
+
+// Fail saves the error of the tag.
+func (r *EntryReader) Fail(tg tag.Tag, err error) {
+	r.Errors = append(r.Errors, fmt.Errorf(ErrInTag, TagName(tg), err.Error()))
+}
+
+// Err returns all saved errors joined together, or nil.
+func (r *EntryReader) Err() (err error) {
+	return errors.Join(r.Errors...)
+}
+
+// Numbers reads all numbers of the value.
+func (r *EntryReader) Numbers(tg tag.Tag) (v []float64) {
+	de := r.Entry(tg)
+	if de == nil {
+		return nil
+	}
+
+	v, err := de.ValueAsArrayOfNumber()
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	return v
+}
+
+// NumbersOfCount reads all numbers of the value, which must have the count
+// of numbers. A value of another size is an error.
+func (r *EntryReader) NumbersOfCount(tg tag.Tag, count int) (v []float64) {
+	v = r.Numbers(tg)
+	if (v != nil) && (len(v) != count) {
+		r.Fail(tg, fmt.Errorf(ErrValueCount, len(v), count))
+		return nil
+	}
+
+	return v
+}
+
+// Number reads the first number of the value.
+func (r *EntryReader) Number(tg tag.Tag) (v *float64) {
+	numbers := r.Numbers(tg)
+	if len(numbers) == 0 {
+		return nil
+	}
+
+	return &numbers[0]
+}
+
+// Rational reads the first rational of the value.
+func (r *EntryReader) Rational(tg tag.Tag) (v *big.Rat) {
+	de := r.Entry(tg)
+	if de == nil {
+		return nil
+	}
+
+	rationals, err := de.ValueAsArrayOfRational()
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+	if len(rationals) == 0 {
+		return nil
+	}
+
+	return rationals[0]
+}
+
+// Integers reads all integers of the value.
+func (r *EntryReader) Integers(tg tag.Tag) (v []int) {
+	de := r.Entry(tg)
+	if de == nil {
+		return nil
+	}
+
+	integers, err := de.ValueAsArrayOfInteger()
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	v = make([]int, 0, len(integers))
+	for _, i := range integers {
+		v = append(v, int(i))
+	}
+
+	return v
+}
+
+// Integer reads the first integer of the value.
+func (r *EntryReader) Integer(tg tag.Tag) (v *int) {
+	integers := r.Integers(tg)
+	if len(integers) == 0 {
+		return nil
+	}
+
+	return &integers[0]
+}
+
+// String reads the value as a text.
+func (r *EntryReader) String(tg tag.Tag) (s string) {
+	de := r.Entry(tg)
+	if de == nil {
+		return ""
+	}
+
+	s, err := de.ValueAsString()
+	if err != nil {
+		r.Fail(tg, err)
+		return ""
+	}
+
+	return s
+}
+
+// Bytes reads the value as an array of bytes.
+func (r *EntryReader) Bytes(tg tag.Tag) (ba []byte) {
+	de := r.Entry(tg)
+	if de == nil {
+		return nil
+	}
+
+	ba, err := de.ValueAsArrayOfByte()
+	if err != nil {
+		r.Fail(tg, err)
+		return nil
+	}
+
+	return ba
+}
+
+// Enumeration reads the first integer of the value as an enumeration.
+func Enumeration[T ~int](r *EntryReader, tg tag.Tag) (v *T) {
+	i := r.Integer(tg)
+	if i == nil {
+		return nil
+	}
+
+	x := T(*i)
+	return &x
+}
+
+// TagName returns a human-readable name of the tag, or its number when the
+// tag is not known.
+func TagName(tg tag.Tag) string {
+	name, ok := tag.HumanReadableTagNames()[tg]
+	if ok {
+		return name
+	}
+
+	return fmt.Sprint(tg)
+}
@@ -0,0 +1,486 @@
+package ifd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// MaxJSONBlobSize is the maximum size of values of BYTE, ASCII and UNDEFINED
+// types written into JSON. Bigger values, such as embedded thumbnails or
+// MakerNote blobs, are truncated.
+const MaxJSONBlobSize = 64 * 1024
+
+// EncodingBase64 is the encoding of binary values in JSON.
+const EncodingBase64 = "base64"
+
+const (
+	ErrJSONTypeIsNotValid     = "type is not valid: %v"
+	ErrJSONValueIsNotValid    = "value of the tag %v is not valid: %v"
+	ErrJSONRationalIsNotValid = "rational is not valid: %v"
+	ErrJSONEntryIsNull        = "entry #%v is null"
+	ErrJSONSubIFDIsNull       = "SubIFD #%v is null"
+)
+
+// jsonEntry is the JSON representation of a Directory Entry.
+type jsonEntry struct {
+	Tag              tag.Tag              `json:"tag"`
+	Name             string               `json:"name"`
+	Namespace        string               `json:"namespace"`
+	Type             string               `json:"type"`
+	Count            models.Count         `json:"count"`
+	ValueOrOffset    models.ValueOrOffset `json:"valueOrOffset"`
+	Offset           models.OffsetOfValue `json:"offset"`
+	DataItemSize     int                  `json:"dataItemSize"`
+	HasFastValue     bool                 `json:"hasFastValue"`
+	IsTagKnown       bool                 `json:"isTagKnown"`
+	IsTypeRegistered bool                 `json:"isTypeRegistered"`
+	Value            json.RawMessage      `json:"value"`
+	Encoding         string               `json:"encoding,omitempty"`
+	Size             int                  `json:"size,omitempty"`
+	Truncated        bool                 `json:"truncated,omitempty"`
+	SubIFDs          []*SubIFD            `json:"subIFDs,omitempty"`
+}
+
+// jsonDirectory is the JSON representation of an IFD or a SubIFD.
+type jsonDirectory struct {
+	NumberOfDirectoryEntries models.NumberOfDirectoryEntries `json:"numberOfDirectoryEntries"`
+	DirectoryEntries         []*DirectoryEntry               `json:"entries"`
+	OffsetOfNext             models.OffsetOfIFD              `json:"offsetOfNext"`
+}
+
+// MarshalJSON writes the Directory Entry as the following JSON object.
+//
+//	{
+//	  "tag": 282,                 // Tag number.
+//	  "name": "XResolution",      // Tag name, 'Unknown' for unknown tags.
+//	  "namespace": "TIFF",        // TIFF, Exif, GPS or Interop.
+//	  "type": "RATIONAL",         // Type name, a number for unknown types.
+//	  "count": 1,
+//	  "valueOrOffset": 26,        // Raw field of the entry.
+//	  "offset": 26,               // Offset of the value, 0 for fast values.
+//	  "dataItemSize": 8,
+//	  "hasFastValue": false,
+//	  "isTagKnown": true,
+//	  "isTypeRegistered": true,
+//	  "value": ["300/1"],
+//	  "encoding": "base64",       // Binary values only.
+//	  "size": 1024,               // Binary values only.
+//	  "truncated": true,          // Truncated values only.
+//	  "subIFDs": [ ... ]          // Entries referring to SubIFDs only.
+//	}
+//
+// Values depend on the type:
+//   - BYTE and UNDEFINED values are base64 strings;
+//   - ASCII values are strings with all their NUL characters, or base64
+//     strings when they are not valid UTF-8;
+//   - integers are arrays of numbers;
+//   - rationals are arrays of strings 'numerator/denominator';
+//   - floating point numbers are arrays of numbers, where NaN and infinities
+//     are strings 'NaN', '+Inf' and '-Inf'.
+//
+// Binary values longer than MaxJSONBlobSize are truncated, 'size' is the size
+// of the whole value. Values which have fewer data items than 'count', either
+// truncated ones or those cut before writing, see tiff.JSONOptions, are
+// marked as truncated. Fields 'name', 'dataItemSize', 'hasFastValue',
+// 'isTagKnown', 'isTypeRegistered' and 'offset' are computed, they are
+// ignored by UnmarshalJSON.
+func (de *DirectoryEntry) MarshalJSON() (data []byte, err error) {
+	je := &jsonEntry{
+		Tag:              de.Tag,
+		Name:             de.TagName,
+		Namespace:        de.Namespace(),
+		Type:             t.Name(de.Type),
+		Count:            de.Count,
+		ValueOrOffset:    de.ValueOrOffset,
+		Offset:           de.Offset,
+		DataItemSize:     de.DataItemSize(),
+		HasFastValue:     de.hasFastValue,
+		IsTagKnown:       de.isTagKnown,
+		IsTypeRegistered: de.isTypeRegistered,
+		SubIFDs:          de.SubIFDs,
+	}
+
+	var value any
+	value, err = je.encodeValue(de)
+	if err != nil {
+		return nil, err
+	}
+	if (de.Value != nil) && (valueLength(de.Value) < int(de.Count)) {
+		je.Truncated = true
+	}
+
+	je.Value, err = json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(je)
+}
+
+// encodeValue returns the value of the entry in the form used in JSON.
+func (je *jsonEntry) encodeValue(de *DirectoryEntry) (value any, err error) {
+	switch v := de.Value.(type) {
+	case nil:
+		return nil, nil
+
+	case []byte:
+		if (de.Type == t.ASCII) && utf8.Valid(v) {
+			return string(v), nil
+		}
+
+		je.Encoding = EncodingBase64
+		je.Size = max(len(v), int(de.Count))
+		if len(v) > MaxJSONBlobSize {
+			v = v[:MaxJSONBlobSize]
+			je.Truncated = true
+		}
+		return base64.StdEncoding.EncodeToString(v), nil
+
+	case []*big.Rat:
+		texts := make([]string, 0, len(v))
+		for _, r := range v {
+			texts = append(texts, r.String())
+		}
+		return texts, nil
+
+	case []float32:
+		numbers := make([]any, 0, len(v))
+		for _, f := range v {
+			numbers = append(numbers, jsonFloat(float64(f), 32))
+		}
+		return numbers, nil
+
+	case []float64:
+		numbers := make([]any, 0, len(v))
+		for _, f := range v {
+			numbers = append(numbers, jsonFloat(f, 64))
+		}
+		return numbers, nil
+
+	case []int8:
+		// Arrays of signed bytes must not be written as base64 strings.
+		numbers := make([]int, 0, len(v))
+		for _, n := range v {
+			numbers = append(numbers, int(n))
+		}
+		return numbers, nil
+
+	case []uint16, []int16, []uint32, []int32:
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf(ErrJSONValueIsNotValid, de.Tag, de.Value)
+	}
+}
+
+// valueLength returns the number of data items of the value.
+func valueLength(value any) int {
+	switch v := value.(type) {
+	case []byte:
+		return len(v)
+	case []int8:
+		return len(v)
+	case []uint16:
+		return len(v)
+	case []int16:
+		return len(v)
+	case []uint32:
+		return len(v)
+	case []int32:
+		return len(v)
+	case []float32:
+		return len(v)
+	case []float64:
+		return len(v)
+	case []*big.Rat:
+		return len(v)
+	default:
+		return 0
+	}
+}
+
+// jsonFloat returns the floating point number as a JSON number, or as a
+// string when JSON can not represent it.
+func jsonFloat(f float64, bitSize int) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// UnmarshalJSON reads the Directory Entry written by MarshalJSON. Computed
+// fields are computed again in the same way as the parser does it. Truncated
+// values are read as they are, so their sizes do not match their counts.
+func (de *DirectoryEntry) UnmarshalJSON(data []byte) (err error) {
+	var je jsonEntry
+	err = json.Unmarshal(data, &je)
+	if err != nil {
+		return err
+	}
+
+	typ, ok := t.ByName(je.Type)
+	if !ok {
+		return fmt.Errorf(ErrJSONTypeIsNotValid, je.Type)
+	}
+
+	*de = DirectoryEntry{
+		Tag:           je.Tag,
+		Type:          typ,
+		Count:         je.Count,
+		ValueOrOffset: je.ValueOrOffset,
+		SubIFDs:       je.SubIFDs,
+	}
+	if je.Namespace != NamespaceTIFF {
+		de.namespace = je.Namespace
+	}
+
+	err = de.processDataItemSize()
+	if err != nil {
+		return err
+	}
+
+	de.processHasFastValue()
+	de.processTagName()
+
+	err = de.processType()
+	if err != nil {
+		return err
+	}
+
+	if !de.hasFastValue {
+		de.Offset = de.ValueOrOffset
+	}
+
+	de.Value, err = je.decodeValue(typ)
+	if err != nil {
+		return fmt.Errorf(ErrJSONValueIsNotValid, de.Tag, err)
+	}
+
+	de.processHasSubIFD()
+	for idx, si := range de.SubIFDs {
+		if si == nil {
+			return fmt.Errorf(ErrJSONSubIFDIsNull, idx)
+		}
+		if (idx > 0) && de.HasSubIFDChain() {
+			de.SubIFDs[idx-1].NextSubIFD = si
+		}
+	}
+	if len(de.SubIFDs) > 0 {
+		de.SubIFD = de.SubIFDs[0]
+	}
+
+	return nil
+}
+
+// decodeValue returns the value of the entry of the type from its JSON form.
+func (je *jsonEntry) decodeValue(typ t.Type) (value any, err error) {
+	if (len(je.Value) == 0) || (string(je.Value) == "null") {
+		return nil, nil
+	}
+
+	switch typ {
+	case t.Byte, t.ASCII, t.Undefined:
+		var s string
+		err = json.Unmarshal(je.Value, &s)
+		if err != nil {
+			return nil, err
+		}
+		if je.Encoding == EncodingBase64 {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		return []byte(s), nil
+
+	case t.Short:
+		return decodeJSONArray[uint16](je.Value)
+	case t.Long, t.IFD:
+		return decodeJSONArray[uint32](je.Value)
+	case t.SByte:
+		return decodeJSONArray[int8](je.Value)
+	case t.SShort:
+		return decodeJSONArray[int16](je.Value)
+	case t.SLong:
+		return decodeJSONArray[int32](je.Value)
+
+	case t.Rational, t.SRational:
+		var texts []string
+		err = json.Unmarshal(je.Value, &texts)
+		if err != nil {
+			return nil, err
+		}
+		rationals := make([]*big.Rat, 0, len(texts))
+		for _, text := range texts {
+			r, ok := new(big.Rat).SetString(text)
+			if !ok {
+				return nil, fmt.Errorf(ErrJSONRationalIsNotValid, text)
+			}
+			rationals = append(rationals, r)
+		}
+		return rationals, nil
+
+	case t.Float:
+		var numbers []float64
+		numbers, err = decodeJSONFloats(je.Value, 32)
+		if err != nil {
+			return nil, err
+		}
+		floats := make([]float32, 0, len(numbers))
+		for _, f := range numbers {
+			floats = append(floats, float32(f))
+		}
+		return floats, nil
+
+	case t.Double:
+		return decodeJSONFloats(je.Value, 64)
+
+	default:
+		return nil, fmt.Errorf(t.ErrUnknownType, typ)
+	}
+}
+
+// decodeJSONArray decodes the JSON array of integers. An empty array is
+// returned for an empty JSON array, the same way as the parser does it.
+func decodeJSONArray[T int8 | int16 | int32 | uint16 | uint32](data []byte) (values []T, err error) {
+	values = make([]T, 0)
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// decodeJSONFloats decodes the JSON array of floating point numbers written
+// by jsonFloat.
+func decodeJSONFloats(data []byte, bitSize int) (values []float64, err error) {
+	var items []json.RawMessage
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	values = make([]float64, 0, len(items))
+	for _, item := range items {
+		var text string
+		if json.Unmarshal(item, &text) != nil {
+			text = string(item)
+		}
+
+		var f float64
+		f, err = strconv.ParseFloat(text, bitSize)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, f)
+	}
+
+	return values, nil
+}
+
+// MarshalJSON writes the IFD as a JSON object with the fields
+// 'numberOfDirectoryEntries', 'entries' and 'offsetOfNext'. Entries are
+// written by DirectoryEntry.MarshalJSON.
+func (i *IFD) MarshalJSON() (data []byte, err error) {
+	return json.Marshal(&jsonDirectory{
+		NumberOfDirectoryEntries: i.NumberOfDirectoryEntries,
+		DirectoryEntries:         i.DirectoryEntries,
+		OffsetOfNext:             i.OffsetOfNextIFD,
+	})
+}
+
+// UnmarshalJSON reads the IFD written by MarshalJSON. Maps of entries and
+// statistics are filled again. Links to next IFDs are set by the TIFF.
+func (i *IFD) UnmarshalJSON(data []byte) (err error) {
+	var jd jsonDirectory
+	err = json.Unmarshal(data, &jd)
+	if err != nil {
+		return err
+	}
+
+	var entries []*DirectoryEntry
+	entries, err = checkEntries(jd.DirectoryEntries)
+	if err != nil {
+		return err
+	}
+
+	*i = IFD{
+		NumberOfDirectoryEntries: jd.NumberOfDirectoryEntries,
+		DirectoryEntries:         entries,
+		OffsetOfNextIFD:          jd.OffsetOfNext,
+		Statistics:               new(Statistics),
+	}
+
+	err = i.processDEMaps()
+	if err != nil {
+		return err
+	}
+
+	i.FillStatistics()
+
+	return nil
+}
+
+// MarshalJSON writes the SubIFD in the same way as IFD.MarshalJSON does.
+func (si *SubIFD) MarshalJSON() (data []byte, err error) {
+	return json.Marshal(&jsonDirectory{
+		NumberOfDirectoryEntries: si.NumberOfDirectoryEntries,
+		DirectoryEntries:         si.DirectoryEntries,
+		OffsetOfNext:             si.OffsetOfNextSubIFD,
+	})
+}
+
+// UnmarshalJSON reads the SubIFD in the same way as IFD.UnmarshalJSON does.
+// Links to next SubIFDs are set by the Directory Entry.
+func (si *SubIFD) UnmarshalJSON(data []byte) (err error) {
+	var jd jsonDirectory
+	err = json.Unmarshal(data, &jd)
+	if err != nil {
+		return err
+	}
+
+	var entries []*DirectoryEntry
+	entries, err = checkEntries(jd.DirectoryEntries)
+	if err != nil {
+		return err
+	}
+
+	*si = SubIFD{
+		NumberOfDirectoryEntries: jd.NumberOfDirectoryEntries,
+		DirectoryEntries:         entries,
+		OffsetOfNextSubIFD:       jd.OffsetOfNext,
+		Statistics:               new(Statistics),
+	}
+
+	err = si.processDEMaps()
+	if err != nil {
+		return err
+	}
+
+	si.FillStatistics()
+
+	return nil
+}
+
+// checkEntries checks entries read from JSON. Null entries are not allowed.
+func checkEntries(entries []*DirectoryEntry) ([]*DirectoryEntry, error) {
+	if entries == nil {
+		return make([]*DirectoryEntry, 0), nil
+	}
+
+	for idx, e := range entries {
+		if e == nil {
+			return nil, fmt.Errorf(ErrJSONEntryIsNull, idx)
+		}
+	}
+
+	return entries, nil
+}
@@ -1,15 +1,13 @@
 package ifd
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"unsafe"
 
-	"github.com/vault-thirteen/TIFFer/helper"
-	"github.com/vault-thirteen/TIFFer/models"
 	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
 	tag "github.com/vault-thirteen/TIFFer/models/Tag"
 	"github.com/vault-thirteen/TIFFer/models/Type"
@@ -17,36 +15,23 @@ import (
 	"github.com/vault-thirteen/auxie/rs"
 )
 
-func (de *DirectoryEntry) processDataItemSize() (err error) {
-	switch de.Type {
-	case t.Byte, // 8-bit.
-		t.ASCII, // 7-bit.
-		t.SByte: // 8-bit.
-		de.dataItemSize = 1
-
-	case t.Short, // 16-bit.
-		t.SShort: // 16-bit.
-		de.dataItemSize = 2
-
-	case t.Long, // 32-bit.
-		t.SLong: // 32-bit.
-		de.dataItemSize = 4
+// LargeValueSize is the size of a value, in bytes, starting from which the
+// value is checked to fit into the stream before reading it.
+const LargeValueSize = 1024 * 1024
 
-	case t.Float: // 32-bit.
-		de.dataItemSize = 4
-
-	case t.Double, // 64-bit.
-		t.Rational,  // 32-bit x2.
-		t.SRational: // 32-bit x2.
-		de.dataItemSize = 8
-
-	case t.Undefined: // 8-bit.
-		de.dataItemSize = 1
+const (
+	ErrValueIsOutOfBounds = "value is out of bounds: offset=%v, size=%v, stream size=%v"
+	ErrZeroDenominator    = "rational #%v has zero denominator"
+)
 
-	default:
+func (de *DirectoryEntry) processDataItemSize() (err error) {
+	size := t.ItemSize(de.Type)
+	if size == 0 {
 		return fmt.Errorf(t.ErrUnknownType, de.Type)
 	}
 
+	de.dataItemSize = byte(size)
+
 	return nil
 }
 
@@ -55,8 +40,13 @@ func (de *DirectoryEntry) processHasFastValue() {
 }
 
 func (de *DirectoryEntry) processTagName() {
+	names := tag.HumanReadableTagNames()
+	if de.tagNames != nil {
+		names = de.tagNames
+	}
+
 	var ok bool
-	de.TagName, ok = tag.HumanReadableTagNames()[de.Tag]
+	de.TagName, ok = names[de.Tag]
 	if ok {
 		de.isTagKnown = true
 	} else {
@@ -66,6 +56,12 @@ func (de *DirectoryEntry) processTagName() {
 }
 
 func (de *DirectoryEntry) processType() (err error) {
+	// Types of private tags are not known.
+	if de.tagNames != nil {
+		de.isTypeRegistered = false
+		return nil
+	}
+
 	if de.hasValidType() {
 		return nil
 	}
@@ -94,13 +90,10 @@ func (de *DirectoryEntry) readFastValue(byteOrder bo.ByteOrder) (err error) {
 		return fmt.Errorf(bo.ErrUnsupportedBO, byteOrder)
 	}
 
-	var readerSeeker *rs.ReaderSeeker
-	readerSeeker, err = rs.New(bytes.NewReader(buf))
-	if err != nil {
-		return err
-	}
+	// Fast values are left-justified.
+	buf = buf[:de.valueSize()]
 
-	de.Value, err = de.readValueFromStream(readerSeeker, byteOrder)
+	de.Value, err = de.decodeValue(buf, byteOrder)
 	if err != nil {
 		return err
 	}
@@ -111,12 +104,13 @@ func (de *DirectoryEntry) readFastValue(byteOrder bo.ByteOrder) (err error) {
 func (de *DirectoryEntry) readExternalValue(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (err error) {
 	de.Offset = de.ValueOrOffset
 
-	_, err = rs.Seek(int64(de.Offset), io.SeekStart)
+	var buf []byte
+	buf, err = readRawValue(rs, int64(de.Offset), de.valueSize())
 	if err != nil {
 		return err
 	}
 
-	de.Value, err = de.readValueFromStream(rs, byteOrder)
+	de.Value, err = de.decodeValue(buf, byteOrder)
 	if err != nil {
 		return err
 	}
@@ -124,295 +118,187 @@ func (de *DirectoryEntry) readExternalValue(rs *rs.ReaderSeeker, byteOrder bo.By
 	return nil
 }
 
-func (de *DirectoryEntry) readValueFromStream(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data any, err error) {
-	switch de.Type {
-	case t.Byte:
-		return de.readArrayOfByte(rs)
-	case t.ASCII:
-		return de.readArrayOfASCII(rs)
-	case t.Short:
-		return de.readArrayOfShort(rs, byteOrder)
-	case t.Long:
-		return de.readArrayOfLong(rs, byteOrder)
-	case t.Rational:
-		return de.readArrayOfRational(rs, byteOrder)
-	case t.SByte:
-		return de.readArrayOfSByte(rs)
-	case t.Undefined:
-		return de.readArrayOfUndefined(rs)
-	case t.SShort:
-		return de.readArrayOfSShort(rs, byteOrder)
-	case t.SLong:
-		return de.readArrayOfSLong(rs, byteOrder)
-	case t.SRational:
-		return de.readArrayOfSRational(rs, byteOrder)
-	case t.Float:
-		return de.readArrayOfFloat(rs, byteOrder)
-	case t.Double:
-		return de.readArrayOfDouble(rs, byteOrder)
-	default:
-		return nil, fmt.Errorf(t.ErrUnknownType, de.Type)
-	}
+// valueSize returns the size of the value in bytes.
+func (de *DirectoryEntry) valueSize() int64 {
+	return int64(de.dataItemSize) * int64(de.Count)
 }
 
-func (de *DirectoryEntry) readArrayOfByte(rs *rs.ReaderSeeker) (data []bt.Byte, err error) {
-	data = make([]byte, 0, de.Count)
-	var dataItem byte
-	for i := models.Count(0); i < de.Count; i++ {
-		dataItem, err = rs.ReadByte()
-		if err != nil {
-			return nil, err
-		}
-		data = append(data, dataItem)
-	}
-	return data, nil
-}
+// readRawValue reads raw bytes of a value with a single read. Streams which
+// support random access are read without seeking.
+func readRawValue(rs *rs.ReaderSeeker, offset int64, size int64) (buf []byte, err error) {
+	stream := rs.GetInternalReader()
 
-func (de *DirectoryEntry) readArrayOfSByte(rs *rs.ReaderSeeker) (data []bt.SByte, err error) {
-	data = make([]int8, 0, de.Count)
-	var dataItem int8
-	for i := models.Count(0); i < de.Count; i++ {
-		dataItem, err = rs.ReadSByte()
+	// Do not allocate memory for values which can not be stored in the stream.
+	if size > LargeValueSize {
+		var streamSize int64
+		streamSize, err = rs.Seek(0, io.SeekEnd)
 		if err != nil {
 			return nil, err
 		}
-		data = append(data, dataItem)
-	}
-	return data, nil
-}
-
-func (de *DirectoryEntry) readArrayOfASCII(rs *rs.ReaderSeeker) (data []byte, err error) {
-	data = make([]byte, 0, de.Count)
-	var dataItem byte
-	for i := models.Count(0); i < de.Count; i++ {
-		dataItem, err = helper.ReadASCII(rs)
-		if err != nil {
-			return nil, err
+		if offset+size > streamSize {
+			return nil, fmt.Errorf(ErrValueIsOutOfBounds, offset, size, streamSize)
 		}
-		data = append(data, dataItem)
 	}
-	return data, nil
-}
 
-func (de *DirectoryEntry) readArrayOfUndefined(rs *rs.ReaderSeeker) (data []bt.Byte, err error) {
-	data = make([]byte, 0, de.Count)
-	var dataItem byte
-	for i := models.Count(0); i < de.Count; i++ {
-		dataItem, err = helper.ReadUndefined(rs)
-		if err != nil {
-			return nil, err
+	buf = make([]byte, size)
+
+	readerAt, ok := stream.(io.ReaderAt)
+	if ok {
+		var n int
+		n, err = readerAt.ReadAt(buf, offset)
+		if n == len(buf) {
+			return buf, nil
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
 		}
-		data = append(data, dataItem)
+		return nil, err
 	}
-	return data, nil
-}
 
-func (de *DirectoryEntry) readArrayOfShort(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.Word, err error) {
-	data = make([]bt.Word, 0, de.Count)
-	var dataItem bt.Word
+	_, err = rs.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
 
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadUShort_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadUShort_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+	_, err = io.ReadFull(stream, buf)
+	if err != nil {
+		return nil, err
 	}
 
-	return data, nil
+	return buf, nil
 }
 
-func (de *DirectoryEntry) readArrayOfSShort(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.SShort, err error) {
-	data = make([]int16, 0, de.Count)
-	var dataItem int16
-
+// decodeValue decodes an array of data items from raw bytes.
+func (de *DirectoryEntry) decodeValue(buf []byte, byteOrder bo.ByteOrder) (data any, err error) {
+	var order binary.ByteOrder
 	switch byteOrder {
 	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadSShort_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+		order = binary.BigEndian
 	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadSShort_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+		order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf(bo.ErrUnsupportedBO, byteOrder)
 	}
 
-	return data, nil
+	switch de.Type {
+	case t.Byte, t.ASCII, t.Undefined:
+		// Bytes are used as is, without copying.
+		return buf, nil
+	case t.Short:
+		return decodeArrayOfShort(buf, order), nil
+	case t.Long, t.IFD:
+		return decodeArrayOfLong(buf, order), nil
+	case t.Rational:
+		return de.undecodedOnError(decodeArrayOfRational(buf, order))
+	case t.SByte:
+		return decodeArrayOfSByte(buf), nil
+	case t.SShort:
+		return decodeArrayOfSShort(buf, order), nil
+	case t.SLong:
+		return decodeArrayOfSLong(buf, order), nil
+	case t.SRational:
+		return de.undecodedOnError(decodeArrayOfSRational(buf, order))
+	case t.Float:
+		return decodeArrayOfFloat(buf, order), nil
+	case t.Double:
+		return decodeArrayOfDouble(buf, order), nil
+	default:
+		return nil, fmt.Errorf(t.ErrUnknownType, de.Type)
+	}
 }
 
-func (de *DirectoryEntry) readArrayOfLong(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.DWord, err error) {
-	data = make([]bt.DWord, 0, de.Count)
-	var dataItem bt.DWord
-
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadULong_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadULong_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+// undecodedOnError saves the error of decoding of rationals in the entry and
+// leaves the value undecoded.
+func (de *DirectoryEntry) undecodedOnError(rationals []*big.Rat, valueErr error) (data any, err error) {
+	if valueErr != nil {
+		de.ValueError = valueErr
+		return nil, nil
 	}
 
-	return data, nil
+	return rationals, nil
 }
 
-func (de *DirectoryEntry) readArrayOfSLong(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.SLong, err error) {
-	data = make([]int32, 0, de.Count)
-	var dataItem int32
-
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadSLong_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadSLong_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+func decodeArrayOfSByte(buf []byte) (data []bt.SByte) {
+	if len(buf) == 0 {
+		return []int8{}
 	}
 
-	return data, nil
+	// Signed bytes share the memory with raw bytes.
+	return unsafe.Slice((*int8)(unsafe.Pointer(&buf[0])), len(buf))
 }
 
-func (de *DirectoryEntry) readArrayOfRational(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.Rational, err error) {
-	data = make([]*big.Rat, 0, de.Count)
-	var dataItem *big.Rat
+func decodeArrayOfShort(buf []byte, order binary.ByteOrder) (data []bt.Word) {
+	data = make([]bt.Word, len(buf)/2)
+	for i := range data {
+		data[i] = order.Uint16(buf[i*2:])
+	}
+	return data
+}
 
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = helper.ReadRational_BE(rs)
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = helper.ReadRational_LE(rs)
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+func decodeArrayOfSShort(buf []byte, order binary.ByteOrder) (data []bt.SShort) {
+	data = make([]int16, len(buf)/2)
+	for i := range data {
+		data[i] = int16(order.Uint16(buf[i*2:]))
 	}
+	return data
+}
 
-	return data, nil
+func decodeArrayOfLong(buf []byte, order binary.ByteOrder) (data []bt.DWord) {
+	data = make([]bt.DWord, len(buf)/4)
+	for i := range data {
+		data[i] = order.Uint32(buf[i*4:])
+	}
+	return data
 }
 
-func (de *DirectoryEntry) readArrayOfSRational(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.SRational, err error) {
-	data = make([]*big.Rat, 0, de.Count)
-	var dataItem *big.Rat
+func decodeArrayOfSLong(buf []byte, order binary.ByteOrder) (data []bt.SLong) {
+	data = make([]int32, len(buf)/4)
+	for i := range data {
+		data[i] = int32(order.Uint32(buf[i*4:]))
+	}
+	return data
+}
 
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = helper.ReadSRational_BE(rs)
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = helper.ReadSRational_LE(rs)
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
+func decodeArrayOfRational(buf []byte, order binary.ByteOrder) (data []bt.Rational, err error) {
+	data = make([]*big.Rat, len(buf)/8)
+	for i := range data {
+		numerator := order.Uint32(buf[i*8:])
+		denominator := order.Uint32(buf[i*8+4:])
+		if denominator == 0 {
+			return nil, fmt.Errorf(ErrZeroDenominator, i)
 		}
+		data[i] = new(big.Rat).SetFrac64(int64(numerator), int64(denominator))
 	}
-
 	return data, nil
 }
 
-func (de *DirectoryEntry) readArrayOfFloat(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.Float, err error) {
-	data = make([]float32, 0, de.Count)
-	var dataItem float32
-
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadFloat_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadFloat_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
+func decodeArrayOfSRational(buf []byte, order binary.ByteOrder) (data []bt.SRational, err error) {
+	data = make([]*big.Rat, len(buf)/8)
+	for i := range data {
+		numerator := int32(order.Uint32(buf[i*8:]))
+		denominator := int32(order.Uint32(buf[i*8+4:]))
+		if denominator == 0 {
+			return nil, fmt.Errorf(ErrZeroDenominator, i)
 		}
+		data[i] = new(big.Rat).SetFrac64(int64(numerator), int64(denominator))
 	}
-
 	return data, nil
 }
 
-func (de *DirectoryEntry) readArrayOfDouble(rs *rs.ReaderSeeker, byteOrder bo.ByteOrder) (data []bt.Double, err error) {
-	data = make([]float64, 0, de.Count)
-	var dataItem float64
-
-	switch byteOrder {
-	case bo.BigEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadDouble_BE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
-	case bo.LittleEndian:
-		for i := models.Count(0); i < de.Count; i++ {
-			dataItem, err = rs.ReadDouble_LE()
-			if err != nil {
-				return nil, err
-			}
-			data = append(data, dataItem)
-		}
+func decodeArrayOfFloat(buf []byte, order binary.ByteOrder) (data []bt.Float) {
+	data = make([]float32, len(buf)/4)
+	for i := range data {
+		data[i] = math.Float32frombits(order.Uint32(buf[i*4:]))
 	}
+	return data
+}
 
-	return data, nil
+func decodeArrayOfDouble(buf []byte, order binary.ByteOrder) (data []bt.Double) {
+	data = make([]float64, len(buf)/8)
+	for i := range data {
+		data[i] = math.Float64frombits(order.Uint64(buf[i*8:]))
+	}
+	return data
 }
 
 func (de *DirectoryEntry) processHasSubIFD() {
@@ -0,0 +1,264 @@
+package ifd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"testing"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+	"github.com/vault-thirteen/auxie/rs"
+)
+
+// benchmarkItemCount is the number of data items of values of the benchmarks.
+const benchmarkItemCount = 100_000
+
+func TestDecodeValue_ZeroDenominator(t *testing.T) {
+	for _, tp := range []typ.Type{typ.Rational, typ.SRational} {
+		buf := binary.LittleEndian.AppendUint32(nil, 1)
+		buf = binary.LittleEndian.AppendUint32(buf, 2)
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+
+		de := &DirectoryEntry{Type: tp, Count: 2}
+		value, err := de.decodeValue(buf, bo.LittleEndian)
+		if err != nil {
+			t.Fatalf("%v: the entry is not kept: %v", tp, err)
+		}
+		if (value != nil) || (de.ValueError == nil) {
+			t.Errorf("%v: value %v is decoded with error %v", tp, value, de.ValueError)
+		}
+
+		de.Value = value
+		_, err = de.ValueAsArrayOfNumber()
+		if err == nil {
+			t.Errorf("%v: undecoded value is converted", tp)
+		}
+	}
+}
+
+// readItemsOneByOne reads data items of the value one by one, as values were
+// read before the bulk decoding was introduced.
+func readItemsOneByOne(stream *rs.ReaderSeeker, tp typ.Type, count int, byteOrder bo.ByteOrder) (data any, err error) {
+	_, err = stream.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	isBE := byteOrder == bo.BigEndian
+	switch tp {
+	case typ.Byte, typ.ASCII, typ.Undefined:
+		return readItems(count, stream.ReadByte)
+	case typ.SByte:
+		return readItems(count, stream.ReadSByte)
+	case typ.Short:
+		return readItems(count, pick(isBE, stream.ReadUShort_BE, stream.ReadUShort_LE))
+	case typ.SShort:
+		return readItems(count, pick(isBE, stream.ReadSShort_BE, stream.ReadSShort_LE))
+	case typ.Long:
+		return readItems(count, pick(isBE, stream.ReadULong_BE, stream.ReadULong_LE))
+	case typ.SLong:
+		return readItems(count, pick(isBE, stream.ReadSLong_BE, stream.ReadSLong_LE))
+	case typ.Float:
+		return readItems(count, pick(isBE, stream.ReadFloat_BE, stream.ReadFloat_LE))
+	case typ.Double:
+		return readItems(count, pick(isBE, stream.ReadDouble_BE, stream.ReadDouble_LE))
+	case typ.Rational:
+		read := pick(isBE, stream.ReadDWord_BE, stream.ReadDWord_LE)
+		return readItems(count, func() (*big.Rat, error) {
+			numerator, err := read()
+			if err != nil {
+				return nil, err
+			}
+			denominator, err := read()
+			if err != nil {
+				return nil, err
+			}
+			return big.NewRat(int64(numerator), int64(denominator)), nil
+		})
+	case typ.SRational:
+		read := pick(isBE, stream.ReadSLong_BE, stream.ReadSLong_LE)
+		return readItems(count, func() (*big.Rat, error) {
+			numerator, err := read()
+			if err != nil {
+				return nil, err
+			}
+			denominator, err := read()
+			if err != nil {
+				return nil, err
+			}
+			return big.NewRat(int64(numerator), int64(denominator)), nil
+		})
+	}
+
+	return nil, nil
+}
+
+// readItems reads the number of data items by the function.
+func readItems[T any](count int, read func() (T, error)) (items []T, err error) {
+	items = make([]T, 0, count)
+	for range count {
+		var item T
+		item, err = read()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// pick returns the first value when the condition is true, otherwise the
+// second one.
+func pick[T any](condition bool, a T, b T) T {
+	if condition {
+		return a
+	}
+
+	return b
+}
+
+func TestDecodeValue_MatchesItemReads(t *testing.T) {
+	types := []typ.Type{
+		typ.Byte, typ.ASCII, typ.Short, typ.Long, typ.Rational, typ.SByte,
+		typ.Undefined, typ.SShort, typ.SLong, typ.SRational, typ.Float, typ.Double,
+	}
+
+	// No byte is zero, so that denominators of rationals are not zero, and
+	// many bytes have the sign bit set.
+	raw := make([]byte, 64)
+	for j := range raw {
+		raw[j] = byte(0x81 + j*29)
+	}
+
+	for _, byteOrder := range []bo.ByteOrder{bo.BigEndian, bo.LittleEndian} {
+		var order binary.ByteOrder = binary.LittleEndian
+		if byteOrder == bo.BigEndian {
+			order = binary.BigEndian
+		}
+
+		for _, tp := range types {
+			itemSize := int(typ.ItemSize(tp))
+
+			// Fast values of all sizes up to the size of the field and one
+			// value stored outside of the entry.
+			counts := []int{5}
+			for count := 1; count*itemSize <= FastValueLimitSize; count++ {
+				counts = append(counts, count)
+			}
+
+			for _, count := range counts {
+				name := fmt.Sprintf("%v/%v/%v", byteOrder, typ.Name(tp), count)
+				value := raw[:count*itemSize]
+
+				// Fast values are left-justified in the field of the entry,
+				// other values follow the entry.
+				file := make([]byte, 8, 8+len(value))
+				if len(value) <= FastValueLimitSize {
+					copy(file, value)
+				} else {
+					order.PutUint32(file, 8)
+					file = append(file, value...)
+				}
+
+				de := &DirectoryEntry{Tag: 65000, Type: tp, Count: uint32(count), ValueOrOffset: order.Uint32(file)}
+				stream, err := rs.New(bytes.NewReader(file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = de.ProcessValues(stream, byteOrder)
+				if err != nil {
+					t.Fatalf("%v: %v", name, err)
+				}
+
+				var want any
+				stream, err = rs.New(bytes.NewReader(value))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, err = readItemsOneByOne(stream, tp, count, byteOrder)
+				if err != nil {
+					t.Fatalf("%v: %v", name, err)
+				}
+
+				if fmt.Sprintf("%T", de.Value) != fmt.Sprintf("%T", want) {
+					t.Errorf("%v: value is %T instead of %T", name, de.Value, want)
+					continue
+				}
+				rawA, errA := EncodeValue(tp, de.Value, bo.LittleEndian)
+				rawB, errB := EncodeValue(tp, want, bo.LittleEndian)
+				if (errA != nil) || (errB != nil) || !bytes.Equal(rawA, rawB) {
+					t.Errorf("%v: value is %v instead of %v", name, de.Value, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeValue_Signs(t *testing.T) {
+	buf := []byte{0xFF, 0xFE, 0x80, 0x01}
+
+	value, err := DecodeValue(typ.SByte, buf, bo.BigEndian)
+	if sbytes, _ := value.([]int8); (err != nil) || !slices.Equal(sbytes, []int8{-1, -2, -128, 1}) {
+		t.Errorf("signed bytes are %v, error %v", value, err)
+	}
+
+	value, err = DecodeValue(typ.SShort, buf, bo.BigEndian)
+	if sshorts, _ := value.([]int16); (err != nil) || !slices.Equal(sshorts, []int16{-2, -32767}) {
+		t.Errorf("signed shorts are %v, error %v", value, err)
+	}
+
+	value, err = DecodeValue(typ.SShort, buf, bo.LittleEndian)
+	if sshorts, _ := value.([]int16); (err != nil) || !slices.Equal(sshorts, []int16{-257, 384}) {
+		t.Errorf("signed shorts are %v, error %v", value, err)
+	}
+}
+
+// BenchmarkReadValue compares reading of large values item by item with the
+// bulk reading and decoding.
+func BenchmarkReadValue(b *testing.B) {
+	for _, tp := range []typ.Type{typ.Short, typ.Long, typ.Rational, typ.Double} {
+		size := int(typ.ItemSize(tp)) * benchmarkItemCount
+		raw := make([]byte, size)
+		for i := 0; i < size; i += 4 {
+			// Denominators of rationals are never zero.
+			binary.BigEndian.PutUint32(raw[i:], uint32(i/4+1))
+		}
+
+		stream, err := rs.New(bytes.NewReader(raw))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(typ.Name(tp)+"/OneByOne", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for b.Loop() {
+				_, err = readItemsOneByOne(stream, tp, benchmarkItemCount, bo.BigEndian)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(typ.Name(tp)+"/Bulk", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			de := &DirectoryEntry{Type: tp, Count: benchmarkItemCount}
+			for b.Loop() {
+				var buf []byte
+				buf, err = readRawValue(stream, 0, int64(size))
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = de.decodeValue(buf, bo.BigEndian)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -63,7 +63,7 @@ var validTypesPerTag = map[tag.Tag][]t.Type{
 	tag.BadFaxLines:               {t.Short, t.Long},
 	tag.CleanFaxData:              {t.Short},
 	tag.ConsecutiveBadFaxLines:    {t.Short, t.Long},
-	tag.SubIFDs:                   {t.Long},
+	tag.SubIFDs:                   {t.Long, t.IFD},
 	tag.InkSet:                    {t.Short},
 	tag.InkNames:                  {t.ASCII},
 	tag.NumberOfInks:              {t.Short},
@@ -110,18 +110,20 @@ var validTypesPerTag = map[tag.Tag][]t.Type{
 	tag.XMP:                         {t.Byte},
 
 	// Private Tags.
-	tag.ImageID:         {t.ASCII},
-	tag.WangAnnotation:  {t.Byte},
-	tag.Copyright:       {t.ASCII},
-	tag.MDFile:          {t.Long},
-	tag.MDScalePixel:    {t.Rational},
-	tag.MDColorTable:    {t.Short},
-	tag.MDLabName:       {t.ASCII},
-	tag.MDSampleInfo:    {t.ASCII},
-	tag.MDPrepDate:      {t.ASCII},
-	tag.MDPrepTime:      {t.ASCII},
-	tag.MDFileUnits:     {t.ASCII},
-	tag.ModelPixelScale: {t.Double},
+	tag.ImageID:             {t.ASCII},
+	tag.WangAnnotation:      {t.Byte},
+	tag.CFARepeatPatternDim: {t.Short},
+	tag.CFAPattern2:         {t.Byte},
+	tag.Copyright:           {t.ASCII},
+	tag.MDFile:              {t.Long},
+	tag.MDScalePixel:        {t.Rational},
+	tag.MDColorTable:        {t.Short},
+	tag.MDLabName:           {t.ASCII},
+	tag.MDSampleInfo:        {t.ASCII},
+	tag.MDPrepDate:          {t.ASCII},
+	tag.MDPrepTime:          {t.ASCII},
+	tag.MDFileUnits:         {t.ASCII},
+	tag.ModelPixelScale:     {t.Double},
 
 	//tag.IPTC: {t.Undefined, t.Byte}, // Official.
 	tag.IPTC: {t.Undefined, t.Byte, t.Long}, // Shitty tools.
@@ -264,6 +266,9 @@ var validTypesPerTag = map[tag.Tag][]t.Type{
 
 	tag.DateTimeOriginal:         {t.ASCII},
 	tag.DateTimeDigitized:        {t.ASCII},
+	tag.OffsetTime:               {t.ASCII},
+	tag.OffsetTimeOriginal:       {t.ASCII},
+	tag.OffsetTimeDigitized:      {t.ASCII},
 	tag.ComponentsConfiguration:  {t.Undefined},
 	tag.CompressedBitsPerPixel:   {t.Rational},
 	tag.ShutterSpeedValue:        {t.SRational},
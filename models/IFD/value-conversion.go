@@ -0,0 +1,117 @@
+package ifd
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/vault-thirteen/auxie/NTS"
+)
+
+// ValueAsArrayOfInteger tries to return the value as array of integers.
+// Values of all integer types, both signed and unsigned, are supported.
+func (de *DirectoryEntry) ValueAsArrayOfInteger() (v []int64, err error) {
+	switch x := de.Value.(type) {
+	case []byte:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	case []int8:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	case []uint16:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	case []int16:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	case []uint32:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	case []int32:
+		v = make([]int64, 0, len(x))
+		for _, item := range x {
+			v = append(v, int64(item))
+		}
+	default:
+		return nil, errors.New(ErrTypeCastFailure)
+	}
+
+	return v, nil
+}
+
+// ValueAsArrayOfNumber tries to return the value as array of floating point
+// numbers. Values of all numeric types, including rationals, are supported.
+func (de *DirectoryEntry) ValueAsArrayOfNumber() (v []float64, err error) {
+	if de.ValueError != nil {
+		return nil, de.ValueError
+	}
+
+	switch x := de.Value.(type) {
+	case []*big.Rat:
+		v = make([]float64, 0, len(x))
+		for _, item := range x {
+			f, _ := item.Float64()
+			v = append(v, f)
+		}
+	case []float32:
+		v = make([]float64, 0, len(x))
+		for _, item := range x {
+			v = append(v, float64(item))
+		}
+	case []float64:
+		v = make([]float64, 0, len(x))
+		v = append(v, x...)
+	default:
+		var integers []int64
+		integers, err = de.ValueAsArrayOfInteger()
+		if err != nil {
+			return nil, err
+		}
+
+		v = make([]float64, 0, len(integers))
+		for _, item := range integers {
+			v = append(v, float64(item))
+		}
+	}
+
+	return v, nil
+}
+
+// ValueAsString tries to return the value as a single string.
+// If the value contains several null-terminated strings, the first one is
+// returned. Strings which are not terminated properly, as it is often done by
+// some tools, are also accepted. Trailing spaces are removed.
+func (de *DirectoryEntry) ValueAsString() (s string, err error) {
+	buf, ok := de.Value.([]byte)
+	if !ok {
+		return "", errors.New(ErrTypeCastFailure)
+	}
+
+	var ss []string
+	ss, err = nts.ByteArrayToStrings(buf)
+	if err == nil {
+		if len(ss) == 0 {
+			return "", nil
+		}
+		return strings.TrimRight(ss[0], " "), nil
+	}
+
+	// Not terminated string.
+	idx := bytes.IndexByte(buf, nts.NUL)
+	if idx >= 0 {
+		buf = buf[:idx]
+	}
+
+	return strings.TrimRight(string(buf), " "), nil
+}
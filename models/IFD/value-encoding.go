@@ -0,0 +1,152 @@
+package ifd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+const (
+	ErrValueTypeMismatch = "value of type %T does not match the data item type %v"
+	ErrValueIsNotValid   = "value is out of range of the data item type %v: %v"
+	ErrDenominatorIsZero = "denominator is zero: %v"
+)
+
+// RationalParts returns the numerator and the denominator of the rational
+// number as they are stored in the file.
+func RationalParts(r *big.Rat, typ t.Type) (numerator uint32, denominator uint32, err error) {
+	num, den := r.Num(), r.Denom()
+	if den.Sign() == 0 {
+		return 0, 0, fmt.Errorf(ErrDenominatorIsZero, r)
+	}
+
+	if typ == t.SRational {
+		if !num.IsInt64() || !den.IsInt64() ||
+			(num.Int64() < math.MinInt32) || (num.Int64() > math.MaxInt32) ||
+			(den.Int64() > math.MaxInt32) {
+			return 0, 0, fmt.Errorf(ErrValueIsNotValid, t.Name(typ), r.RatString())
+		}
+		return uint32(int32(num.Int64())), uint32(int32(den.Int64())), nil
+	}
+
+	if (num.Sign() < 0) || !num.IsUint64() || !den.IsUint64() ||
+		(num.Uint64() > math.MaxUint32) || (den.Uint64() > math.MaxUint32) {
+		return 0, 0, fmt.Errorf(ErrValueIsNotValid, t.Name(typ), r.RatString())
+	}
+
+	return uint32(num.Uint64()), uint32(den.Uint64()), nil
+}
+
+// DecodeValue decodes the array of data items of the type from raw bytes
+// using the byte order, in the same way as values of entries are decoded.
+// Unlike values of entries, rationals with a zero denominator are errors.
+func DecodeValue(typ t.Type, buf []byte, byteOrder bo.ByteOrder) (value any, err error) {
+	de := &DirectoryEntry{Type: typ}
+	value, err = de.decodeValue(buf, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if de.ValueError != nil {
+		return nil, de.ValueError
+	}
+
+	return value, nil
+}
+
+// EncodeValue encodes the array of data items of the type into raw bytes
+// using the byte order. Arrays are of the same Go types which are used by
+// the parser for decoded values, see DirectoryEntry.Value.
+func EncodeValue(typ t.Type, value any, byteOrder bo.ByteOrder) (buf []byte, err error) {
+	var order binary.AppendByteOrder
+	switch byteOrder {
+	case bo.BigEndian:
+		order = binary.BigEndian
+	case bo.LittleEndian:
+		order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf(bo.ErrUnsupportedBO, byteOrder)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		if (typ == t.Byte) || (typ == t.ASCII) || (typ == t.Undefined) {
+			return append([]byte{}, v...), nil
+		}
+	case []int8:
+		if typ == t.SByte {
+			buf = make([]byte, 0, len(v))
+			for _, x := range v {
+				buf = append(buf, byte(x))
+			}
+			return buf, nil
+		}
+	case []uint16:
+		if typ == t.Short {
+			buf = make([]byte, 0, len(v)*2)
+			for _, x := range v {
+				buf = order.AppendUint16(buf, x)
+			}
+			return buf, nil
+		}
+	case []int16:
+		if typ == t.SShort {
+			buf = make([]byte, 0, len(v)*2)
+			for _, x := range v {
+				buf = order.AppendUint16(buf, uint16(x))
+			}
+			return buf, nil
+		}
+	case []uint32:
+		if (typ == t.Long) || (typ == t.IFD) {
+			buf = make([]byte, 0, len(v)*4)
+			for _, x := range v {
+				buf = order.AppendUint32(buf, x)
+			}
+			return buf, nil
+		}
+	case []int32:
+		if typ == t.SLong {
+			buf = make([]byte, 0, len(v)*4)
+			for _, x := range v {
+				buf = order.AppendUint32(buf, uint32(x))
+			}
+			return buf, nil
+		}
+	case []float32:
+		if typ == t.Float {
+			buf = make([]byte, 0, len(v)*4)
+			for _, x := range v {
+				buf = order.AppendUint32(buf, math.Float32bits(x))
+			}
+			return buf, nil
+		}
+	case []float64:
+		if typ == t.Double {
+			buf = make([]byte, 0, len(v)*8)
+			for _, x := range v {
+				buf = order.AppendUint64(buf, math.Float64bits(x))
+			}
+			return buf, nil
+		}
+	case []*big.Rat:
+		if (typ == t.Rational) || (typ == t.SRational) {
+			buf = make([]byte, 0, len(v)*8)
+			for _, x := range v {
+				var numerator, denominator uint32
+				numerator, denominator, err = RationalParts(x, typ)
+				if err != nil {
+					return nil, err
+				}
+				buf = order.AppendUint32(buf, numerator)
+				buf = order.AppendUint32(buf, denominator)
+			}
+			return buf, nil
+		}
+	}
+
+	return nil, fmt.Errorf(ErrValueTypeMismatch, value, t.Name(typ))
+}
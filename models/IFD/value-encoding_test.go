@@ -0,0 +1,68 @@
+package ifd
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+func TestEncodeValue_RoundTrip(t *testing.T) {
+	tests := []struct {
+		tp    typ.Type
+		value any
+	}{
+		{typ.Byte, []byte{0, 1, 255}},
+		{typ.ASCII, []byte("text\x00")},
+		{typ.SByte, []int8{-128, 0, 127}},
+		{typ.Short, []uint16{0, 1, 65535}},
+		{typ.SShort, []int16{-32768, 32767}},
+		{typ.Long, []uint32{0, 4294967295}},
+		{typ.SLong, []int32{-2147483648, 2147483647}},
+		{typ.Rational, []*big.Rat{big.NewRat(72, 1), big.NewRat(1, 3)}},
+		{typ.SRational, []*big.Rat{big.NewRat(-1, 3), big.NewRat(7, 2)}},
+		{typ.Float, []float32{-1.5, 3.25}},
+		{typ.Double, []float64{-1.5, 1e300}},
+	}
+	for _, test := range tests {
+		for _, byteOrder := range []bo.ByteOrder{bo.BigEndian, bo.LittleEndian} {
+			buf, err := EncodeValue(test.tp, test.value, byteOrder)
+			if err != nil {
+				t.Fatalf("%v: %v", typ.Name(test.tp), err)
+			}
+
+			de := &DirectoryEntry{Type: test.tp}
+			var decoded any
+			decoded, err = de.decodeValue(buf, byteOrder)
+			if err != nil {
+				t.Fatalf("%v: %v", typ.Name(test.tp), err)
+			}
+
+			var again []byte
+			again, err = EncodeValue(test.tp, decoded, byteOrder)
+			if (err != nil) || !bytes.Equal(buf, again) {
+				t.Errorf("%v: %v is decoded as %v", typ.Name(test.tp), test.value, decoded)
+			}
+		}
+	}
+}
+
+func TestEncodeValue_NotValid(t *testing.T) {
+	tests := []struct {
+		tp    typ.Type
+		value any
+	}{
+		{typ.Short, []uint32{1}},
+		{typ.Rational, []*big.Rat{big.NewRat(-1, 2)}},
+		{typ.Rational, []*big.Rat{big.NewRat(1<<33, 1)}},
+		{typ.SRational, []*big.Rat{big.NewRat(1, 1<<32)}},
+	}
+	for _, test := range tests {
+		_, err := EncodeValue(test.tp, test.value, bo.LittleEndian)
+		if err == nil {
+			t.Errorf("%v: %v is encoded", typ.Name(test.tp), test.value)
+		}
+	}
+}
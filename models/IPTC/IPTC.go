@@ -0,0 +1,376 @@
+package iptc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// TagMarker starts every dataset.
+const TagMarker = 0x1C
+
+// ExtendedLengthFlag is the flag of the length field of a dataset showing
+// that the field stores the size of the real length field.
+const ExtendedLengthFlag = 0x8000
+
+// CharacterSetUTF8 is the value of the CodedCharacterSet dataset for UTF-8.
+const CharacterSetUTF8 = "\x1b%G"
+
+// Formats of dates and times.
+// Examples: "20230319", "194934+0300".
+const (
+	DateLayout = "20060102"
+	TimeLayout = "150405-0700"
+
+	// TimeWithoutZoneLayout is used by writers which omit the time zone.
+	TimeWithoutZoneLayout = "150405"
+)
+
+const (
+	ErrTIFFHasNoIFD         = "TIFF has no IFD"
+	ErrIPTCTagIsMissing     = "IPTC tag is missing"
+	ErrHeaderIsTruncated    = "header of dataset at offset %v is truncated"
+	ErrDataSetIsTruncated   = "dataset %v at offset %v is truncated"
+	ErrUnexpectedByte       = "unexpected byte 0x%02x at offset %v"
+	ErrLengthIsTooBig       = "length of dataset %v is too big: %v bytes"
+	ErrInDataSet            = "error in dataset %v: %v"
+	ErrDateIsNotValid       = "date is not valid: %v"
+	ErrTimeIsNotValid       = "time is not valid: %v"
+	ErrValueTypeIsNotBinary = "value of the type %T can not be read as IIM data"
+)
+
+// tagHeaderSize is the size of the marker, the record number, the dataset
+// number and the length of a dataset.
+const tagHeaderSize = 5
+
+// maxLengthFieldSize is the maximum size of an extended length field.
+const maxLengthFieldSize = 4
+
+// DataSet is a single IIM dataset.
+type DataSet struct {
+	// ID is the identifier of the dataset.
+	ID DataSetID
+
+	// Data is the raw value.
+	Data []byte
+}
+
+// IPTC is a typed model of the IPTC-IIM data stored in the IPTC tag.
+//
+// Text values are decoded from UTF-8 when the CodedCharacterSet dataset says
+// so or when they are valid UTF-8, otherwise they are decoded from ISO
+// 8859-1. Repeatable datasets, such as keywords, are lists.
+//
+// IPTC-IIM specification (Version 4.2):
+// https://www.iptc.org/std/IIM/4.2/specification/IIMV4.2.pdf
+type IPTC struct {
+	// DataSets are all datasets in the order of their appearance.
+	DataSets []*DataSet
+
+	// IsUTF8 flag is set when the CodedCharacterSet dataset declares UTF-8.
+	IsUTF8 bool
+
+	// RecordVersion is the version of the application record.
+	RecordVersion *int
+
+	// Texts.
+	ObjectName                    string
+	EditStatus                    string
+	Urgency                       string
+	Category                      string
+	SpecialInstructions           string
+	ByLine                        []string
+	ByLineTitle                   []string
+	City                          string
+	SubLocation                   string
+	ProvinceState                 string
+	CountryCode                   string
+	Country                       string
+	OriginalTransmissionReference string
+	Headline                      string
+	Credit                        string
+	Source                        string
+	CopyrightNotice               string
+	Contact                       []string
+	Caption                       string
+	WriterEditor                  []string
+	OriginatingProgram            string
+	ProgramVersion                string
+
+	// Lists.
+	Keywords               []string
+	SupplementalCategories []string
+	SubjectReferences      []string
+
+	// Dates are combined with times. When the time is not recorded, the
+	// date is at midnight. When the time zone is not recorded, the time is
+	// put into the UTC location.
+	DateCreated         *time.Time
+	DigitalCreationDate *time.Time
+
+	// Errors are errors of values which could not be read, such as partial
+	// dates. Fields of such values are left empty, other fields are read as
+	// usual.
+	Errors []error
+}
+
+// New constructs the IPTC model from the IPTC tag of the first IFD of the
+// TIFF.
+func New(t *tiff.TIFF) (m *IPTC, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0], t.Header().ByteOrder)
+}
+
+// NewFromIFD constructs the IPTC model from the IPTC tag of the IFD. The tag
+// is often written with the LONG type, such values are converted back into
+// bytes using the byte order of the file.
+func NewFromIFD(i *ifd.IFD, byteOrder bo.ByteOrder) (m *IPTC, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[tag.IPTC]
+	if !ok {
+		return nil, errors.New(ErrIPTCTagIsMissing)
+	}
+
+	var data []byte
+	data, err = rawBytes(de, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// rawBytes returns the value of the entry as it is stored in the file.
+func rawBytes(de *ifd.DirectoryEntry, byteOrder bo.ByteOrder) (data []byte, err error) {
+	switch v := de.Value.(type) {
+	case []byte:
+		return v, nil
+	case []uint32:
+		var order binary.AppendByteOrder
+		switch byteOrder {
+		case bo.BigEndian:
+			order = binary.BigEndian
+		case bo.LittleEndian:
+			order = binary.LittleEndian
+		default:
+			return nil, fmt.Errorf(bo.ErrUnsupportedBO, byteOrder)
+		}
+
+		data = make([]byte, 0, len(v)*4)
+		for _, n := range v {
+			data = order.AppendUint32(data, n)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf(ErrValueTypeIsNotBinary, de.Value)
+	}
+}
+
+// Parse parses IIM datasets. Zero bytes after the last dataset are padding.
+func Parse(data []byte) (m *IPTC, err error) {
+	m = &IPTC{DataSets: make([]*DataSet, 0)}
+
+	m.DataSets, err = parseDataSets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m.IsUTF8 = string(m.Value(CodedCharacterSet)) == CharacterSetUTF8
+
+	m.readFields()
+
+	return m, nil
+}
+
+// parseDataSets splits the data into datasets.
+func parseDataSets(data []byte) (dataSets []*DataSet, err error) {
+	dataSets = make([]*DataSet, 0)
+
+	pos := 0
+	for pos < len(data) {
+		if data[pos] != TagMarker {
+			if len(bytes.Trim(data[pos:], "\x00")) == 0 {
+				break
+			}
+			return nil, fmt.Errorf(ErrUnexpectedByte, data[pos], pos)
+		}
+
+		if pos+tagHeaderSize > len(data) {
+			return nil, fmt.Errorf(ErrHeaderIsTruncated, pos)
+		}
+
+		id := NewDataSetID(data[pos+1], data[pos+2])
+		length := int(binary.BigEndian.Uint16(data[pos+3:]))
+		pos += tagHeaderSize
+
+		if length&ExtendedLengthFlag != 0 {
+			fieldSize := length &^ ExtendedLengthFlag
+			if (fieldSize > maxLengthFieldSize) || (pos+fieldSize > len(data)) {
+				return nil, fmt.Errorf(ErrLengthIsTooBig, id, fieldSize)
+			}
+
+			length = 0
+			for _, b := range data[pos : pos+fieldSize] {
+				length = length<<8 | int(b)
+			}
+			pos += fieldSize
+		}
+
+		if (length < 0) || (pos+length > len(data)) {
+			return nil, fmt.Errorf(ErrDataSetIsTruncated, id, pos)
+		}
+
+		dataSets = append(dataSets, &DataSet{ID: id, Data: data[pos : pos+length]})
+		pos += length
+	}
+
+	return dataSets, nil
+}
+
+// readFields fills typed fields from datasets.
+func (m *IPTC) readFields() {
+	version := m.Value(RecordVersion)
+	if len(version) == 2 {
+		v := int(binary.BigEndian.Uint16(version))
+		m.RecordVersion = &v
+	}
+
+	m.ObjectName = m.Text(ObjectName)
+	m.EditStatus = m.Text(EditStatus)
+	m.Urgency = m.Text(Urgency)
+	m.Category = m.Text(Category)
+	m.SpecialInstructions = m.Text(SpecialInstructions)
+	m.ByLine = m.Texts(ByLine)
+	m.ByLineTitle = m.Texts(ByLineTitle)
+	m.City = m.Text(City)
+	m.SubLocation = m.Text(SubLocation)
+	m.ProvinceState = m.Text(ProvinceState)
+	m.CountryCode = m.Text(CountryCode)
+	m.Country = m.Text(Country)
+	m.OriginalTransmissionReference = m.Text(OriginalTransmissionReference)
+	m.Headline = m.Text(Headline)
+	m.Credit = m.Text(Credit)
+	m.Source = m.Text(Source)
+	m.CopyrightNotice = m.Text(CopyrightNotice)
+	m.Contact = m.Texts(Contact)
+	m.Caption = m.Text(Caption)
+	m.WriterEditor = m.Texts(WriterEditor)
+	m.OriginatingProgram = m.Text(OriginatingProgram)
+	m.ProgramVersion = m.Text(ProgramVersion)
+
+	m.Keywords = m.Texts(Keywords)
+	m.SupplementalCategories = m.Texts(SupplementalCategory)
+	m.SubjectReferences = m.Texts(SubjectReference)
+
+	var err error
+	m.DateCreated, err = parseDateTime(m.Text(DateCreated), m.Text(TimeCreated))
+	if err != nil {
+		m.Errors = append(m.Errors, fmt.Errorf(ErrInDataSet, DateCreated.Name(), err))
+	}
+
+	m.DigitalCreationDate, err = parseDateTime(m.Text(DigitalCreationDate), m.Text(DigitalCreationTime))
+	if err != nil {
+		m.Errors = append(m.Errors, fmt.Errorf(ErrInDataSet, DigitalCreationDate.Name(), err))
+	}
+}
+
+// Value returns the raw value of the first dataset with the identifier, or
+// nil.
+func (m *IPTC) Value(id DataSetID) []byte {
+	for _, ds := range m.DataSets {
+		if ds.ID == id {
+			return ds.Data
+		}
+	}
+
+	return nil
+}
+
+// Text returns the text of the first dataset with the identifier. An empty
+// string is returned for missing datasets.
+func (m *IPTC) Text(id DataSetID) string {
+	for _, ds := range m.DataSets {
+		if ds.ID == id {
+			return m.decodeText(ds.Data)
+		}
+	}
+
+	return ""
+}
+
+// Texts returns texts of all datasets with the identifier, which is useful
+// for repeatable datasets.
+func (m *IPTC) Texts(id DataSetID) (texts []string) {
+	for _, ds := range m.DataSets {
+		if ds.ID == id {
+			texts = append(texts, m.decodeText(ds.Data))
+		}
+	}
+
+	return texts
+}
+
+// IsBinary tells whether the dataset stores a binary value and not a text.
+func (ds *DataSet) IsBinary() bool {
+	return binaryDataSets[ds.ID]
+}
+
+// decodeText decodes the text of a dataset. Trailing NUL characters, which
+// some writers add, are removed.
+func (m *IPTC) decodeText(data []byte) string {
+	data = bytes.TrimRight(data, "\x00")
+
+	if m.IsUTF8 || utf8.Valid(data) {
+		return strings.ToValidUTF8(string(data), string(utf8.RuneError))
+	}
+
+	// ISO 8859-1 characters are the first 256 Unicode code points.
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		runes = append(runes, rune(b))
+	}
+
+	return string(runes)
+}
+
+// parseDateTime parses the date and the optional time.
+func parseDateTime(date string, timeOfDay string) (dt *time.Time, err error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return nil, nil
+	}
+
+	var d time.Time
+	d, err = time.Parse(DateLayout, date)
+	if err != nil {
+		return nil, fmt.Errorf(ErrDateIsNotValid, date)
+	}
+
+	timeOfDay = strings.TrimSpace(timeOfDay)
+	if timeOfDay == "" {
+		return &d, nil
+	}
+
+	var tm time.Time
+	tm, err = time.Parse(TimeLayout, timeOfDay)
+	if err != nil {
+		tm, err = time.Parse(TimeWithoutZoneLayout, timeOfDay)
+		if err != nil {
+			return nil, fmt.Errorf(ErrTimeIsNotValid, timeOfDay)
+		}
+	}
+
+	d = time.Date(d.Year(), d.Month(), d.Day(), tm.Hour(), tm.Minute(), tm.Second(), 0, tm.Location())
+	return &d, nil
+}
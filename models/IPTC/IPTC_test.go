@@ -0,0 +1,35 @@
+package iptc
+
+import "testing"
+
+// dataSet returns an IIM dataset of the application record.
+func dataSet(number byte, value string) []byte {
+	return append([]byte{TagMarker, 2, number, 0, byte(len(value))}, value...)
+}
+
+func TestParse_PartialDate(t *testing.T) {
+	var data []byte
+	data = append(data, dataSet(25, "abc")...)
+	data = append(data, dataSet(55, "20230000")...)
+	data = append(data, dataSet(62, "20230319")...)
+	data = append(data, dataSet(120, "Caption")...)
+
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The partial date is left empty, other fields are read.
+	if m.DateCreated != nil {
+		t.Errorf("partial date is read as %v", m.DateCreated)
+	}
+	if len(m.Errors) != 1 {
+		t.Errorf("errors are %v", m.Errors)
+	}
+	if (m.DigitalCreationDate == nil) || (m.DigitalCreationDate.Year() != 2023) {
+		t.Errorf("digital creation date is %v", m.DigitalCreationDate)
+	}
+	if (len(m.Keywords) != 1) || (m.Keywords[0] != "abc") || (m.Caption != "Caption") {
+		t.Errorf("fields are not read: %v, %v", m.Keywords, m.Caption)
+	}
+}
@@ -0,0 +1,126 @@
+package iptc
+
+import "fmt"
+
+// DataSetID is the identifier of a dataset, i.e. the number of its record
+// in the high byte and the number of the dataset in the low byte.
+type DataSetID uint16
+
+// Records.
+const (
+	RecordEnvelope    = 1
+	RecordApplication = 2
+)
+
+// Datasets of the envelope record.
+const (
+	EnvelopeRecordVersion = DataSetID(RecordEnvelope<<8 | 0)
+	CodedCharacterSet     = DataSetID(RecordEnvelope<<8 | 90)
+)
+
+// Datasets of the application record.
+const (
+	RecordVersion                 = DataSetID(RecordApplication<<8 | 0)
+	ObjectName                    = DataSetID(RecordApplication<<8 | 5)
+	EditStatus                    = DataSetID(RecordApplication<<8 | 7)
+	Urgency                       = DataSetID(RecordApplication<<8 | 10)
+	SubjectReference              = DataSetID(RecordApplication<<8 | 12)
+	Category                      = DataSetID(RecordApplication<<8 | 15)
+	SupplementalCategory          = DataSetID(RecordApplication<<8 | 20)
+	Keywords                      = DataSetID(RecordApplication<<8 | 25)
+	SpecialInstructions           = DataSetID(RecordApplication<<8 | 40)
+	DateCreated                   = DataSetID(RecordApplication<<8 | 55)
+	TimeCreated                   = DataSetID(RecordApplication<<8 | 60)
+	DigitalCreationDate           = DataSetID(RecordApplication<<8 | 62)
+	DigitalCreationTime           = DataSetID(RecordApplication<<8 | 63)
+	OriginatingProgram            = DataSetID(RecordApplication<<8 | 65)
+	ProgramVersion                = DataSetID(RecordApplication<<8 | 70)
+	ByLine                        = DataSetID(RecordApplication<<8 | 80)
+	ByLineTitle                   = DataSetID(RecordApplication<<8 | 85)
+	City                          = DataSetID(RecordApplication<<8 | 90)
+	SubLocation                   = DataSetID(RecordApplication<<8 | 92)
+	ProvinceState                 = DataSetID(RecordApplication<<8 | 95)
+	CountryCode                   = DataSetID(RecordApplication<<8 | 100)
+	Country                       = DataSetID(RecordApplication<<8 | 101)
+	OriginalTransmissionReference = DataSetID(RecordApplication<<8 | 103)
+	Headline                      = DataSetID(RecordApplication<<8 | 105)
+	Credit                        = DataSetID(RecordApplication<<8 | 110)
+	Source                        = DataSetID(RecordApplication<<8 | 115)
+	CopyrightNotice               = DataSetID(RecordApplication<<8 | 116)
+	Contact                       = DataSetID(RecordApplication<<8 | 118)
+	Caption                       = DataSetID(RecordApplication<<8 | 120)
+	WriterEditor                  = DataSetID(RecordApplication<<8 | 122)
+)
+
+var dataSetNames = map[DataSetID]string{
+	EnvelopeRecordVersion:         "EnvelopeRecordVersion",
+	CodedCharacterSet:             "CodedCharacterSet",
+	RecordVersion:                 "RecordVersion",
+	ObjectName:                    "ObjectName",
+	EditStatus:                    "EditStatus",
+	Urgency:                       "Urgency",
+	SubjectReference:              "SubjectReference",
+	Category:                      "Category",
+	SupplementalCategory:          "SupplementalCategory",
+	Keywords:                      "Keywords",
+	SpecialInstructions:           "SpecialInstructions",
+	DateCreated:                   "DateCreated",
+	TimeCreated:                   "TimeCreated",
+	DigitalCreationDate:           "DigitalCreationDate",
+	DigitalCreationTime:           "DigitalCreationTime",
+	OriginatingProgram:            "OriginatingProgram",
+	ProgramVersion:                "ProgramVersion",
+	ByLine:                        "By-line",
+	ByLineTitle:                   "By-lineTitle",
+	City:                          "City",
+	SubLocation:                   "Sub-location",
+	ProvinceState:                 "Province-State",
+	CountryCode:                   "Country-PrimaryLocationCode",
+	Country:                       "Country-PrimaryLocationName",
+	OriginalTransmissionReference: "OriginalTransmissionReference",
+	Headline:                      "Headline",
+	Credit:                        "Credit",
+	Source:                        "Source",
+	CopyrightNotice:               "CopyrightNotice",
+	Contact:                       "Contact",
+	Caption:                       "Caption-Abstract",
+	WriterEditor:                  "Writer-Editor",
+}
+
+// Binary datasets, which are not texts.
+var binaryDataSets = map[DataSetID]bool{
+	EnvelopeRecordVersion: true,
+	CodedCharacterSet:     true,
+	RecordVersion:         true,
+}
+
+// NewDataSetID returns the identifier of the dataset of the record.
+func NewDataSetID(record byte, dataSet byte) DataSetID {
+	return DataSetID(uint16(record)<<8 | uint16(dataSet))
+}
+
+// Record returns the number of the record.
+func (id DataSetID) Record() byte {
+	return byte(id >> 8)
+}
+
+// DataSet returns the number of the dataset inside its record.
+func (id DataSetID) DataSet() byte {
+	return byte(id)
+}
+
+// String returns the identifier in the 'record:dataset' form, e.g. '2:25'.
+func (id DataSetID) String() string {
+	return fmt.Sprintf("%d:%d", id.Record(), id.DataSet())
+}
+
+// Name returns the name of the dataset as written in the IPTC-IIM
+// specification. Unknown datasets are named by their identifiers.
+func (id DataSetID) Name() string {
+	name, ok := dataSetNames[id]
+	if ok {
+		return name
+	}
+
+	return id.String()
+}
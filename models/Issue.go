@@ -0,0 +1,21 @@
+package models
+
+// Severity is the severity of an issue found in a file.
+type Severity string
+
+// Severities of issues.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a file. Validators embed it into their issues,
+// which add the location of the problem.
+type Issue struct {
+	// Severity is either an error or a warning. Errors make the file invalid,
+	// warnings do not.
+	Severity Severity `json:"severity"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
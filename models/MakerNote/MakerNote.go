@@ -0,0 +1,357 @@
+package makernote
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+	"github.com/vault-thirteen/auxie/rs"
+)
+
+const (
+	ErrTIFFHasNoIFD          = "TIFF has no IFD"
+	ErrExifIFDIsMissing      = "Exif IFD is missing"
+	ErrMakerNoteIsMissing    = "MakerNote tag is missing"
+	ErrVendorIsUnknown       = "MakerNote of an unknown vendor"
+	ErrVendorOfMakeIsUnknown = "%w, make: %q"
+	ErrHeaderIsNotValid      = "header of the %v MakerNote is not valid"
+	ErrInMakerNote           = "error in the %v MakerNote: %v"
+	ErrInSubIFD              = "error in the %v IFD of the %v MakerNote: %v"
+	ErrMakerNoteIsNotBinary  = "value of the type %T can not be read as a MakerNote"
+	ErrVendorNameIsDuplicate = "vendor is already registered: %v"
+)
+
+// ErrUnknownVendor is returned when no registered vendor matches the
+// MakerNote. It is wrapped together with the make of the camera, if any.
+var ErrUnknownVendor = errors.New(ErrVendorIsUnknown)
+
+// Vendor describes the MakerNote format of a camera vendor.
+type Vendor struct {
+	// Name is the name of the vendor, e.g. 'Nikon'.
+	Name string
+
+	// Match tells whether the MakerNote belongs to the vendor. It receives
+	// the value of the Make tag and the data of the MakerNote.
+	Match func(cameraMake string, data []byte) bool
+
+	// Locate finds the IFD inside the MakerNote. It receives the data of the
+	// MakerNote and the byte order of the file.
+	Locate func(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error)
+
+	// TagNames are names of tags of the IFD of the MakerNote.
+	TagNames map[tag.Tag]string
+
+	// SubIFDs are names of tags of IFDs referred by entries of the MakerNote,
+	// by tags of those entries.
+	SubIFDs map[tag.Tag]map[tag.Tag]string
+
+	// LensTagNames are names of tags describing the lens, in the order of
+	// preference. Tags of IFDs referred by the MakerNote may be used too.
+	LensTagNames []string
+}
+
+// Layout is the placement of the IFD inside the MakerNote.
+type Layout struct {
+	// ByteOrder is the byte order of the IFD.
+	ByteOrder bo.ByteOrder
+
+	// IFDPosition is the position of the IFD inside the MakerNote.
+	IFDPosition int64
+
+	// IsRelativeToTIFF flag is set when offsets of values are counted from
+	// the TIFF header of the file, as in the IFDs of the file.
+	IsRelativeToTIFF bool
+
+	// Origin is the position inside the MakerNote from which offsets of
+	// values are counted, when they are not relative to the TIFF header.
+	Origin int64
+}
+
+// MakerNote is a decoded MakerNote.
+//
+// Most vendors store the MakerNote as an IFD, which is placed after a header
+// of the vendor. Offsets of values are counted either from the TIFF header of
+// the file or from a position inside the MakerNote. The IFD is read with the
+// same machinery as IFDs of the file, but its tags are named by the table of
+// the vendor.
+type MakerNote struct {
+	// Vendor is the vendor of the MakerNote.
+	Vendor *Vendor
+
+	// Layout is the placement of the IFD inside the MakerNote.
+	Layout *Layout
+
+	// IFD is the IFD of the MakerNote.
+	IFD *ifd.IFD
+
+	// SubIFDs are IFDs referred by entries of the MakerNote, by tags of those
+	// entries.
+	SubIFDs map[tag.Tag]*ifd.IFD
+
+	// Errors are errors of entries whose values could not be read and of
+	// IFDs referred by the MakerNote which could not be read. Such entries
+	// and IFDs are skipped, while all other values are read as usual.
+	Errors []error
+}
+
+// registry of vendors. Vendors registered later are tried first, so that
+// built-in vendors can be replaced.
+var registry = struct {
+	sync.RWMutex
+	vendors []*Vendor
+}{
+	vendors: []*Vendor{Canon, Nikon, Sony, Olympus, Fujifilm, Panasonic, Pentax},
+}
+
+// Register adds the vendor to the registry. The name of the vendor must be
+// unique.
+func Register(v *Vendor) (err error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, rv := range registry.vendors {
+		if rv.Name == v.Name {
+			return fmt.Errorf(ErrVendorNameIsDuplicate, v.Name)
+		}
+	}
+
+	registry.vendors = append(registry.vendors, v)
+	return nil
+}
+
+// Vendors returns all registered vendors.
+func Vendors() (vendors []*Vendor) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return append(vendors, registry.vendors...)
+}
+
+// FindVendor returns the vendor of the MakerNote, or nil.
+func FindVendor(cameraMake string, data []byte) (v *Vendor) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for j := len(registry.vendors) - 1; j >= 0; j-- {
+		v = registry.vendors[j]
+		if v.Match(cameraMake, data) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// New decodes the MakerNote of the Exif IFD of the first IFD of the TIFF.
+// The vendor is found by the Make tag and by the header of the MakerNote.
+func New(tf *tiff.TIFF) (m *MakerNote, err error) {
+	dirs := tf.Directories()
+	if len(dirs) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	ifd0 := dirs[0]
+	var exifIFD *tiff.Directory
+	for _, child := range ifd0.Children {
+		if child.Parent.Tag == tag.ExifIFD {
+			exifIFD = child
+			break
+		}
+	}
+	if exifIFD == nil {
+		return nil, errors.New(ErrExifIFDIsMissing)
+	}
+
+	de, ok := exifIFD.EntriesByTagNumber[tag.MakerNote]
+	if !ok {
+		return nil, errors.New(ErrMakerNoteIsMissing)
+	}
+
+	var cameraMake string
+	makeEntry, ok := ifd0.EntriesByTagNumber[tag.Make]
+	if ok {
+		cameraMake, _ = makeEntry.ValueAsString()
+	}
+
+	return Decode(de, cameraMake, tf.Header().ByteOrder)
+}
+
+// Decode decodes the MakerNote stored in the directory entry. The make of
+// the camera and the byte order of the file are required by some vendors.
+// MakerNotes of vendors which are not registered are reported by the
+// ErrUnknownVendor error. Errors of single entries do not stop decoding,
+// they are saved into the Errors field.
+func Decode(de *ifd.DirectoryEntry, cameraMake string, byteOrder bo.ByteOrder) (m *MakerNote, err error) {
+	data, ok := de.Value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf(ErrMakerNoteIsNotBinary, de.Value)
+	}
+
+	cameraMake = strings.TrimSpace(strings.TrimRight(cameraMake, "\x00"))
+	v := FindVendor(cameraMake, data)
+	if v == nil {
+		if cameraMake == "" {
+			return nil, ErrUnknownVendor
+		}
+		return nil, fmt.Errorf(ErrVendorOfMakeIsUnknown, ErrUnknownVendor, cameraMake)
+	}
+
+	m = &MakerNote{Vendor: v, SubIFDs: make(map[tag.Tag]*ifd.IFD)}
+
+	m.Layout, err = v.Locate(data, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions of the stream are offsets of values.
+	start := -m.Layout.Origin
+	if m.Layout.IsRelativeToTIFF {
+		start = int64(de.Offset)
+	}
+
+	var stream *rs.ReaderSeeker
+	stream, err = rs.New(newWindow(data, start))
+	if err != nil {
+		return nil, err
+	}
+
+	var entryErrors []error
+	m.IFD, entryErrors, err = readIFD(stream, m.Layout.ByteOrder, start+m.Layout.IFDPosition, v.TagNames)
+	if err != nil {
+		return nil, fmt.Errorf(ErrInMakerNote, v.Name, err.Error())
+	}
+	for _, entryErr := range entryErrors {
+		m.Errors = append(m.Errors, fmt.Errorf(ErrInMakerNote, v.Name, entryErr.Error()))
+	}
+
+	for subTag, tagNames := range v.SubIFDs {
+		subDE, ok := m.IFD.DirectoryEntriesByTagNumber[subTag]
+		if !ok {
+			continue
+		}
+
+		var offset int64
+		offset, ok = subIFDOffset(subDE)
+		if !ok {
+			continue
+		}
+
+		var si *ifd.IFD
+		si, entryErrors, err = readIFD(stream, m.Layout.ByteOrder, offset, tagNames)
+		if err != nil {
+			m.Errors = append(m.Errors, fmt.Errorf(ErrInSubIFD, subDE.TagName, v.Name, err.Error()))
+			continue
+		}
+		for _, entryErr := range entryErrors {
+			m.Errors = append(m.Errors, fmt.Errorf(ErrInSubIFD, subDE.TagName, v.Name, entryErr.Error()))
+		}
+
+		m.SubIFDs[subTag] = si
+	}
+
+	return m, nil
+}
+
+// readIFD reads the IFD of the private namespace at the offset. Next IFDs
+// are not read. Entries whose values can not be read are skipped, their
+// errors are returned along with the IFD.
+func readIFD(stream *rs.ReaderSeeker, byteOrder bo.ByteOrder, offset int64, tagNames map[tag.Tag]string) (i *ifd.IFD, entryErrors []error, err error) {
+	if offset < 0 {
+		return nil, nil, errors.New(ErrPositionIsNegative)
+	}
+
+	i, err = ifd.NewIFD(stream, byteOrder, models.OffsetOfIFD(offset))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entryErrors, err = i.ProcessValuesOfNamespace(stream, byteOrder, ifd.NamespaceMakerNote, tagNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i.FillStatistics()
+	return i, entryErrors, nil
+}
+
+// subIFDOffset returns the offset of the IFD referred by the entry. The
+// entry either stores the offset, or stores the IFD itself as its value.
+func subIFDOffset(de *ifd.DirectoryEntry) (offset int64, ok bool) {
+	switch de.Type {
+	case t.Long, t.IFD:
+		offsets, err := de.ValueAsArrayOfLong()
+		if (err != nil) || (len(offsets) != 1) {
+			return 0, false
+		}
+		return int64(offsets[0]), true
+	case t.Undefined:
+		if de.HasFastValue() {
+			return 0, false
+		}
+		return int64(de.Offset), true
+	default:
+		return 0, false
+	}
+}
+
+// Entry returns the entry of the MakerNote having the tag name, or nil.
+// Entries of IFDs referred by the MakerNote are searched too.
+func (m *MakerNote) Entry(tagName string) (de *ifd.DirectoryEntry) {
+	de, ok := m.IFD.DirectoryEntriesByTagName[tagName]
+	if ok {
+		return de
+	}
+
+	for _, subTag := range slices.Sorted(maps.Keys(m.SubIFDs)) {
+		de, ok = m.SubIFDs[subTag].DirectoryEntriesByTagName[tagName]
+		if ok {
+			return de
+		}
+	}
+
+	return nil
+}
+
+// Lens returns the description of the lens from the first existing lens tag
+// of the vendor. Texts are returned as is, numbers are separated by spaces.
+func (m *MakerNote) Lens() (lens string, ok bool) {
+	for _, name := range m.Vendor.LensTagNames {
+		de := m.Entry(name)
+		if de == nil {
+			continue
+		}
+
+		if de.Type == t.ASCII {
+			s, err := de.ValueAsString()
+			if err == nil {
+				s = strings.TrimSpace(s)
+				if len(s) > 0 {
+					return s, true
+				}
+			}
+			continue
+		}
+
+		numbers, err := de.ValueAsArrayOfNumber()
+		if err != nil {
+			continue
+		}
+
+		texts := make([]string, 0, len(numbers))
+		for _, n := range numbers {
+			texts = append(texts, fmt.Sprint(n))
+		}
+		return strings.Join(texts, " "), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,153 @@
+package makernote
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+func TestNew_UnknownVendor(t *testing.T) {
+	f, err := os.Open("../../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var tf *tiff.TIFF
+	tf, err = tiff.New(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sample file has a MakerNote without a known header or make.
+	_, err = New(tf)
+	if !errors.Is(err, ErrUnknownVendor) {
+		t.Errorf("error %v is returned", err)
+	}
+}
+
+// testEntry is an entry of an IFD of a synthetic MakerNote.
+type testEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+
+	// value is either the fast value, or the offset of the value.
+	value []byte
+}
+
+// testIFD returns the IFD of the entries without the next IFD.
+func testIFD(order binary.AppendByteOrder, entries ...testEntry) (data []byte) {
+	data = order.AppendUint16(nil, uint16(len(entries)))
+	for _, e := range entries {
+		data = order.AppendUint16(data, e.tag)
+		data = order.AppendUint16(data, e.typ)
+		data = order.AppendUint32(data, e.count)
+		data = append(data, e.value...)
+		data = append(data, make([]byte, 4-len(e.value))...)
+	}
+
+	return order.AppendUint32(data, 0)
+}
+
+// entryText returns the text of the entry of the MakerNote.
+func entryText(m *MakerNote, tagName string) string {
+	de := m.Entry(tagName)
+	if de == nil {
+		return ""
+	}
+
+	s, _ := de.ValueAsString()
+	return s
+}
+
+func TestDecode_RelativeToTIFF(t *testing.T) {
+	// The MakerNote is placed at the offset 100 of the file. The IFD of
+	// three entries is followed by the text.
+	const position = 100
+	le := binary.LittleEndian
+	data := testIFD(le,
+		testEntry{tag: 0x0006, typ: typ.ASCII, count: 7, value: le.AppendUint32(nil, position+42)},
+		testEntry{tag: 0x0007, typ: typ.ASCII, count: 12, value: le.AppendUint32(nil, 5000)},
+		testEntry{tag: 0x0010, typ: typ.Long, count: 1, value: le.AppendUint32(nil, 0x80000001)},
+	)
+	data = append(data, "EOS 5D\x00"...)
+
+	de := &ifd.DirectoryEntry{Value: data, Offset: position}
+	m, err := Decode(de, "Canon", bo.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (m.Vendor != Canon) || !m.Layout.IsRelativeToTIFF {
+		t.Errorf("MakerNote is located as %+v of %v", m.Layout, m.Vendor.Name)
+	}
+	if s := entryText(m, "CanonImageType"); s != "EOS 5D" {
+		t.Errorf("CanonImageType is %q", s)
+	}
+	if id, _ := m.Entry("CanonModelID").ValueAsArrayOfLong(); (len(id) != 1) || (id[0] != 0x80000001) {
+		t.Errorf("CanonModelID is %v", id)
+	}
+
+	// The value outside of the MakerNote is skipped.
+	if m.Entry("CanonFirmwareVersion") != nil {
+		t.Error("entry out of the MakerNote is read")
+	}
+	if (len(m.Errors) != 1) || !strings.Contains(m.Errors[0].Error(), "CanonFirmwareVersion") {
+		t.Errorf("errors are %v", m.Errors)
+	}
+}
+
+func TestDecode_RelativeToOrigin(t *testing.T) {
+	// The MakerNote has its own TIFF header at the position 10, offsets are
+	// counted from it. The IFD of five entries at the position 18 is
+	// followed by the text at the position 84.
+	const origin = 10
+	be := binary.BigEndian
+	data := []byte("Nikon\x00\x02\x10\x00\x00MM\x00\x2A\x00\x00\x00\x08")
+	data = append(data, testIFD(be,
+		testEntry{tag: 0x0001, typ: typ.Undefined, count: 4, value: []byte("0210")},
+		testEntry{tag: 0x0002, typ: typ.Short, count: 2, value: []byte{0, 0, 0, 200}},
+		testEntry{tag: 0x0004, typ: typ.ASCII, count: 5, value: be.AppendUint32(nil, 84-origin)},
+		testEntry{tag: 0x0005, typ: typ.ASCII, count: 6, value: be.AppendUint32(nil, 0xFFFF00)},
+		testEntry{tag: 0x0011, typ: typ.Long, count: 1, value: be.AppendUint32(nil, 0x7FFF0000)},
+	)...)
+	data = append(data, "FINE\x00"...)
+
+	// The MakerNote is read in the big endian byte order of its header.
+	de := &ifd.DirectoryEntry{Value: data, Offset: 1000}
+	m, err := Decode(de, "", bo.LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (m.Vendor != Nikon) || m.Layout.IsRelativeToTIFF || (m.Layout.Origin != origin) ||
+		(m.Layout.ByteOrder != bo.BigEndian) {
+		t.Errorf("MakerNote is located as %+v of %v", m.Layout, m.Vendor.Name)
+	}
+	if s := entryText(m, "Quality"); s != "FINE" {
+		t.Errorf("Quality is %q", s)
+	}
+	if iso, _ := m.Entry("ISO").ValueAsArrayOfShort(); (len(iso) != 2) || (iso[1] != 200) {
+		t.Errorf("ISO is %v", iso)
+	}
+	if version, _ := m.Entry("MakerNoteVersion").ValueAsArrayOfUndefined(); string(version) != "0210" {
+		t.Errorf("MakerNoteVersion is %q", version)
+	}
+
+	// The value and the preview IFD outside of the MakerNote are skipped.
+	if (m.Entry("WhiteBalance") != nil) || (len(m.SubIFDs) != 0) {
+		t.Error("data out of the MakerNote is read")
+	}
+	if (len(m.Errors) != 2) || !strings.Contains(m.Errors[0].Error(), "WhiteBalance") ||
+		!strings.Contains(m.Errors[1].Error(), "PreviewIFD") {
+		t.Errorf("errors are %v", m.Errors)
+	}
+}
@@ -0,0 +1,71 @@
+package makernote
+
+import (
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Canon MakerNotes have no header. The IFD starts at the beginning of the
+// MakerNote, offsets are relative to the TIFF header.
+var Canon = &Vendor{
+	Name: VendorNameCanon,
+	Match: func(cameraMake string, data []byte) bool {
+		return makeHasPrefix(cameraMake, "Canon")
+	},
+	Locate: func(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+		return relativeToTIFF(byteOrder, 0), nil
+	},
+	TagNames:     canonTagNames,
+	LensTagNames: []string{"LensModel", "LensInfo"},
+}
+
+var canonTagNames = map[tag.Tag]string{
+	0x0001: "CanonCameraSettings",
+	0x0002: "CanonFocalLength",
+	0x0003: "CanonFlashInfo",
+	0x0004: "CanonShotInfo",
+	0x0005: "CanonPanorama",
+	0x0006: "CanonImageType",
+	0x0007: "CanonFirmwareVersion",
+	0x0008: "FileNumber",
+	0x0009: "OwnerName",
+	0x000c: "SerialNumber",
+	0x000d: "CanonCameraInfo",
+	0x000e: "CanonFileLength",
+	0x000f: "CustomFunctions",
+	0x0010: "CanonModelID",
+	0x0012: "CanonAFInfo",
+	0x0013: "ThumbnailImageValidArea",
+	0x0015: "SerialNumberFormat",
+	0x001a: "SuperMacro",
+	0x001c: "DateStampMode",
+	0x001d: "MyColors",
+	0x001e: "FirmwareRevision",
+	0x0023: "Categories",
+	0x0024: "FaceDetect1",
+	0x0025: "FaceDetect2",
+	0x0026: "CanonAFInfo2",
+	0x0028: "ImageUniqueID",
+	0x0081: "RawDataOffset",
+	0x0083: "OriginalDecisionDataOffset",
+	0x0093: "CanonFileInfo",
+	0x0095: "LensModel",
+	0x0096: "InternalSerialNumber",
+	0x0097: "DustRemovalData",
+	0x0099: "CustomFunctions2",
+	0x009a: "AspectInfo",
+	0x00a0: "ProcessingInfo",
+	0x00aa: "MeasuredColor",
+	0x00b4: "ColorSpace",
+	0x00d0: "VRDOffset",
+	0x00e0: "SensorInfo",
+	0x4001: "ColorData",
+	0x4008: "PictureStyleUserDef",
+	0x4010: "CustomPictureStyleFileName",
+	0x4013: "AFMicroAdj",
+	0x4015: "VignettingCorr",
+	0x4018: "LightingOpt",
+	0x4019: "LensInfo",
+	0x4020: "AmbienceInfo",
+	0x4024: "FilterInfo",
+}
@@ -0,0 +1,103 @@
+package makernote
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Header of Fujifilm MakerNotes.
+const (
+	fujifilmHeader = "FUJIFILM"
+
+	// fujifilmHeaderSize is the size of the header with the offset of the
+	// IFD.
+	fujifilmHeaderSize = 12
+)
+
+// Fujifilm MakerNotes start with a header storing the offset of the IFD.
+// They are always written in the little endian byte order, offsets are
+// relative to the start of the MakerNote.
+var Fujifilm = &Vendor{
+	Name: VendorNameFujifilm,
+	Match: func(cameraMake string, data []byte) bool {
+		return hasPrefix(data, fujifilmHeader)
+	},
+	Locate: func(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+		if len(data) < fujifilmHeaderSize {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameFujifilm)
+		}
+
+		return &Layout{
+			ByteOrder:   bo.LittleEndian,
+			IFDPosition: int64(binary.LittleEndian.Uint32(data[len(fujifilmHeader):])),
+		}, nil
+	},
+	TagNames: fujifilmTagNames,
+	LensTagNames: []string{
+		"MinFocalLength",
+		"MaxFocalLength",
+		"MaxApertureAtMinFocal",
+		"MaxApertureAtMaxFocal",
+	},
+}
+
+var fujifilmTagNames = map[tag.Tag]string{
+	0x0000: "Version",
+	0x0010: "InternalSerialNumber",
+	0x1000: "Quality",
+	0x1001: "Sharpness",
+	0x1002: "WhiteBalance",
+	0x1003: "Saturation",
+	0x1004: "Contrast",
+	0x1005: "ColorTemperature",
+	0x100a: "WhiteBalanceFineTune",
+	0x100b: "NoiseReduction",
+	0x100e: "HighISONoiseReduction",
+	0x1010: "FujiFlashMode",
+	0x1011: "FlashExposureComp",
+	0x1020: "Macro",
+	0x1021: "FocusMode",
+	0x1022: "AFMode",
+	0x1023: "FocusPixel",
+	0x1030: "SlowSync",
+	0x1031: "PictureMode",
+	0x1032: "ExposureCount",
+	0x1033: "EXRAuto",
+	0x1034: "EXRMode",
+	0x1040: "ShadowTone",
+	0x1041: "HighlightTone",
+	0x1044: "DigitalZoom",
+	0x1050: "ShutterType",
+	0x1100: "AutoBracketing",
+	0x1101: "SequenceNumber",
+	0x1153: "PanoramaAngle",
+	0x1154: "PanoramaDirection",
+	0x1201: "AdvancedFilter",
+	0x1210: "ColorMode",
+	0x1300: "BlurWarning",
+	0x1301: "FocusWarning",
+	0x1302: "ExposureWarning",
+	0x1304: "GEImageSize",
+	0x1400: "DynamicRange",
+	0x1401: "FilmMode",
+	0x1402: "DynamicRangeSetting",
+	0x1403: "DevelopmentDynamicRange",
+	0x1404: "MinFocalLength",
+	0x1405: "MaxFocalLength",
+	0x1406: "MaxApertureAtMinFocal",
+	0x1407: "MaxApertureAtMaxFocal",
+	0x140b: "AutoDynamicRange",
+	0x1422: "ImageStabilization",
+	0x1431: "Rating",
+	0x1436: "ImageGeneration",
+	0x1438: "ImageCount",
+	0x1443: "DRangePriority",
+	0x4100: "FacesDetected",
+	0x8000: "FileSource",
+	0x8002: "OrderNumber",
+	0x8003: "FrameNumber",
+	0xb211: "Parallax",
+}
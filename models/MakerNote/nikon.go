@@ -0,0 +1,175 @@
+package makernote
+
+import (
+	"fmt"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Headers of Nikon MakerNotes.
+const (
+	nikonHeaderType1 = "Nikon\x00\x01"
+	nikonHeaderType3 = "Nikon\x00\x02"
+
+	// nikonTIFFHeaderPosition is the position of the TIFF header embedded
+	// into MakerNotes of the type 3.
+	nikonTIFFHeaderPosition = 10
+
+	// nikonTIFFHeaderSize is the size of the embedded TIFF header.
+	nikonTIFFHeaderSize = 8
+
+	// nikonType1IFDPosition is the position of the IFD in MakerNotes of the
+	// type 1.
+	nikonType1IFDPosition = 8
+)
+
+// nikonPreviewIFD is the tag of the IFD of the preview image.
+const nikonPreviewIFD = 0x0011
+
+// Nikon MakerNotes of the type 3 have their own TIFF header, offsets are
+// relative to it. MakerNotes of the type 1 have a short header, and the
+// oldest MakerNotes have no header at all, offsets of both are relative to
+// the TIFF header of the file.
+var Nikon = &Vendor{
+	Name: VendorNameNikon,
+	Match: func(cameraMake string, data []byte) bool {
+		return hasPrefix(data, nikonHeaderType1) || hasPrefix(data, nikonHeaderType3) ||
+			makeHasPrefix(cameraMake, "NIKON")
+	},
+	Locate:   locateNikon,
+	TagNames: nikonTagNames,
+	SubIFDs: map[tag.Tag]map[tag.Tag]string{
+		nikonPreviewIFD: nikonPreviewTagNames,
+	},
+	LensTagNames: []string{"Lens", "LensType"},
+}
+
+// locateNikon finds the IFD of the Nikon MakerNote.
+func locateNikon(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+	switch {
+	case hasPrefix(data, nikonHeaderType3):
+		if len(data) < nikonTIFFHeaderPosition+nikonTIFFHeaderSize {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameNikon)
+		}
+
+		order, ok := readByteOrderMark(data, nikonTIFFHeaderPosition)
+		if !ok {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameNikon)
+		}
+
+		// The byte order mark and the magic number are followed by the
+		// offset of the IFD.
+		offset := readUint32(data[nikonTIFFHeaderPosition+4:], order)
+
+		return &Layout{
+			ByteOrder:   order,
+			IFDPosition: nikonTIFFHeaderPosition + int64(offset),
+			Origin:      nikonTIFFHeaderPosition,
+		}, nil
+
+	case hasPrefix(data, nikonHeaderType1):
+		return relativeToTIFF(byteOrder, nikonType1IFDPosition), nil
+
+	default:
+		return relativeToTIFF(byteOrder, 0), nil
+	}
+}
+
+var nikonTagNames = map[tag.Tag]string{
+	0x0001: "MakerNoteVersion",
+	0x0002: "ISO",
+	0x0003: "ColorMode",
+	0x0004: "Quality",
+	0x0005: "WhiteBalance",
+	0x0006: "Sharpness",
+	0x0007: "FocusMode",
+	0x0008: "FlashSetting",
+	0x0009: "FlashType",
+	0x000b: "WhiteBalanceFineTune",
+	0x000c: "WB_RBLevels",
+	0x000d: "ProgramShift",
+	0x000e: "ExposureDifference",
+	0x000f: "ISOSelection",
+	0x0010: "DataDump",
+	0x0011: "PreviewIFD",
+	0x0012: "FlashExposureComp",
+	0x0013: "ISOSetting",
+	0x0016: "ImageBoundary",
+	0x0017: "ExternalFlashExposureComp",
+	0x0018: "FlashExposureBracketValue",
+	0x0019: "ExposureBracketValue",
+	0x001a: "ImageProcessing",
+	0x001b: "CropHiSpeed",
+	0x001c: "ExposureTuning",
+	0x001d: "SerialNumber",
+	0x001e: "ColorSpace",
+	0x001f: "VRInfo",
+	0x0020: "ImageAuthentication",
+	0x0022: "ActiveD-Lighting",
+	0x0023: "PictureControlData",
+	0x0024: "WorldTime",
+	0x0025: "ISOInfo",
+	0x002a: "VignetteControl",
+	0x0080: "ImageAdjustment",
+	0x0081: "ToneComp",
+	0x0082: "AuxiliaryLens",
+	0x0083: "LensType",
+	0x0084: "Lens",
+	0x0085: "ManualFocusDistance",
+	0x0086: "DigitalZoom",
+	0x0087: "FlashMode",
+	0x0088: "AFInfo",
+	0x0089: "ShootingMode",
+	0x008b: "LensFStops",
+	0x008c: "ContrastCurve",
+	0x008d: "ColorHue",
+	0x008f: "SceneMode",
+	0x0090: "LightSource",
+	0x0091: "ShotInfo",
+	0x0092: "HueAdjustment",
+	0x0093: "NEFCompression",
+	0x0094: "SaturationAdj",
+	0x0095: "NoiseReduction",
+	0x0096: "NEFLinearizationTable",
+	0x0097: "ColorBalance",
+	0x0098: "LensData",
+	0x0099: "RawImageCenter",
+	0x009a: "SensorPixelSize",
+	0x00a2: "ImageDataSize",
+	0x00a5: "ImageCount",
+	0x00a6: "DeletedImageCount",
+	0x00a7: "ShutterCount",
+	0x00a8: "FlashInfo",
+	0x00a9: "ImageOptimization",
+	0x00aa: "Saturation",
+	0x00ab: "VariProgram",
+	0x00ac: "ImageStabilization",
+	0x00ad: "AFResponse",
+	0x00b0: "MultiExposure",
+	0x00b1: "HighISONoiseReduction",
+	0x00b6: "PowerUpTime",
+	0x00b7: "AFInfo2",
+	0x00b8: "FileInfo",
+	0x00b9: "AFTune",
+	0x00bb: "RetouchInfo",
+	0x0e00: "PrintIM",
+	0x0e01: "NikonCaptureData",
+	0x0e09: "NikonCaptureVersion",
+	0x0e0e: "NikonCaptureOffsets",
+	0x0e10: "NikonScanIFD",
+	0x0e1d: "NikonICCProfile",
+	0x0e1e: "NikonCaptureOutput",
+	0x0e22: "NEFBitDepth",
+}
+
+var nikonPreviewTagNames = map[tag.Tag]string{
+	0x00fe: "SubfileType",
+	0x0103: "Compression",
+	0x011a: "XResolution",
+	0x011b: "YResolution",
+	0x0128: "ResolutionUnit",
+	0x0201: "PreviewImageStart",
+	0x0202: "PreviewImageLength",
+	0x0213: "YCbCrPositioning",
+}
@@ -0,0 +1,241 @@
+package makernote
+
+import (
+	"fmt"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Headers of Olympus MakerNotes.
+const (
+	olympusHeaderOld      = "OLYMP\x00"
+	olympusHeaderEpson    = "EPSON\x00"
+	olympusHeaderNew      = "OLYMPUS\x00"
+	olympusHeaderOMSystem = "OM SYSTEM\x00\x00\x00"
+
+	// Positions of byte order marks and IFDs.
+	olympusOldIFDPosition            = 8
+	olympusNewByteOrderPosition      = 8
+	olympusNewIFDPosition            = 12
+	olympusOMSystemByteOrderPosition = 12
+	olympusOMSystemIFDPosition       = 16
+)
+
+// Tags of IFDs referred by Olympus MakerNotes.
+const (
+	olympusEquipment       = 0x2010
+	olympusCameraSettings  = 0x2020
+	olympusRawDevelopment  = 0x2030
+	olympusRawDevelopment2 = 0x2031
+	olympusImageProcessing = 0x2040
+	olympusFocusInfo       = 0x2050
+)
+
+// Olympus MakerNotes of the new style have a header with the byte order
+// mark, offsets are relative to the start of the MakerNote. Old MakerNotes,
+// which are also written by Epson, have a short header, and offsets are
+// relative to the TIFF header. Lenses are described in the Equipment IFD.
+var Olympus = &Vendor{
+	Name: VendorNameOlympus,
+	Match: func(cameraMake string, data []byte) bool {
+		return hasPrefix(data, olympusHeaderOld) || hasPrefix(data, olympusHeaderNew) ||
+			hasPrefix(data, olympusHeaderOMSystem) || hasPrefix(data, olympusHeaderEpson) ||
+			makeHasPrefix(cameraMake, "OLYMPUS", "OM Digital Solutions")
+	},
+	Locate:   locateOlympus,
+	TagNames: olympusTagNames,
+	SubIFDs: map[tag.Tag]map[tag.Tag]string{
+		olympusEquipment:       olympusEquipmentTagNames,
+		olympusCameraSettings:  olympusCameraSettingsTagNames,
+		olympusRawDevelopment:  nil,
+		olympusRawDevelopment2: nil,
+		olympusImageProcessing: nil,
+		olympusFocusInfo:       nil,
+	},
+	LensTagNames: []string{"LensModel", "LensType"},
+}
+
+// locateOlympus finds the IFD of the Olympus MakerNote.
+func locateOlympus(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+	var order bo.ByteOrder
+	var ok bool
+
+	switch {
+	case hasPrefix(data, olympusHeaderNew):
+		order, ok = readByteOrderMark(data, olympusNewByteOrderPosition)
+		if !ok {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameOlympus)
+		}
+		return &Layout{ByteOrder: order, IFDPosition: olympusNewIFDPosition}, nil
+
+	case hasPrefix(data, olympusHeaderOMSystem):
+		order, ok = readByteOrderMark(data, olympusOMSystemByteOrderPosition)
+		if !ok {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameOlympus)
+		}
+		return &Layout{ByteOrder: order, IFDPosition: olympusOMSystemIFDPosition}, nil
+
+	case hasPrefix(data, olympusHeaderOld) || hasPrefix(data, olympusHeaderEpson):
+		return relativeToTIFF(byteOrder, olympusOldIFDPosition), nil
+
+	default:
+		return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNameOlympus)
+	}
+}
+
+var olympusTagNames = map[tag.Tag]string{
+	0x0000: "MakerNoteVersion",
+	0x0001: "MinoltaCameraSettingsOld",
+	0x0003: "MinoltaCameraSettings",
+	0x0040: "CompressedImageSize",
+	0x0081: "PreviewImageData",
+	0x0088: "PreviewImageStart",
+	0x0089: "PreviewImageLength",
+	0x0100: "ThumbnailImage",
+	0x0104: "BodyFirmwareVersion",
+	0x0200: "SpecialMode",
+	0x0201: "Quality",
+	0x0202: "Macro",
+	0x0203: "BWMode",
+	0x0204: "DigitalZoom",
+	0x0205: "FocalPlaneDiagonal",
+	0x0206: "LensDistortionParams",
+	0x0207: "CameraType",
+	0x0208: "TextInfo",
+	0x0209: "CameraID",
+	0x020b: "EpsonImageWidth",
+	0x020c: "EpsonImageHeight",
+	0x020d: "EpsonSoftware",
+	0x0280: "PreviewImage",
+	0x0300: "PreCaptureFrames",
+	0x0301: "WhiteBoard",
+	0x0302: "OneTouchWB",
+	0x0303: "WhiteBalanceBracket",
+	0x0304: "WhiteBalanceBias",
+	0x0403: "SceneMode",
+	0x0404: "SerialNumber",
+	0x0405: "Firmware",
+	0x0e00: "PrintIM",
+	0x0f00: "DataDump",
+	0x0f01: "DataDump2",
+	0x1000: "ShutterSpeedValue",
+	0x1001: "ISOValue",
+	0x1002: "ApertureValue",
+	0x1003: "BrightnessValue",
+	0x1004: "FlashMode",
+	0x1005: "FlashDevice",
+	0x1006: "ExposureCompensation",
+	0x1007: "SensorTemperature",
+	0x1008: "LensTemperature",
+	0x1009: "LightCondition",
+	0x100a: "FocusRange",
+	0x100b: "FocusMode",
+	0x100c: "ManualFocusDistance",
+	0x100d: "ZoomStepCount",
+	0x100e: "FocusStepCount",
+	0x100f: "Sharpness",
+	0x1010: "FlashChargeLevel",
+	0x1011: "ColorMatrix",
+	0x1012: "BlackLevel",
+	0x1015: "WBMode",
+	0x1017: "RedBalance",
+	0x1018: "BlueBalance",
+	0x1023: "FlashExposureComp",
+	0x1026: "ExternalFlashBounce",
+	0x1027: "ExternalFlashZoom",
+	0x1028: "ExternalFlashMode",
+	0x1029: "Contrast",
+	0x102a: "SharpnessFactor",
+	0x102b: "ColorControl",
+	0x102c: "ValidBits",
+	0x102d: "CoringFilter",
+	0x102e: "OlympusImageWidth",
+	0x102f: "OlympusImageHeight",
+	0x1034: "CompressionRatio",
+	0x1035: "PreviewImageValid",
+	0x1039: "CCDScanMode",
+	0x103a: "NoiseReduction",
+	0x103b: "FocusStepInfinity",
+	0x103c: "FocusStepNear",
+	0x103d: "LightValueCenter",
+	0x103e: "LightValuePeriphery",
+	0x2010: "Equipment",
+	0x2020: "CameraSettings",
+	0x2030: "RawDevelopment",
+	0x2031: "RawDev2",
+	0x2040: "ImageProcessing",
+	0x2050: "FocusInfo",
+	0x2100: "Olympus2100",
+	0x3000: "RawInfo",
+	0x4000: "MainInfo",
+}
+
+var olympusEquipmentTagNames = map[tag.Tag]string{
+	0x0000: "EquipmentVersion",
+	0x0100: "CameraType2",
+	0x0101: "SerialNumber",
+	0x0102: "InternalSerialNumber",
+	0x0103: "FocalPlaneDiagonal",
+	0x0104: "BodyFirmwareVersion",
+	0x0201: "LensType",
+	0x0202: "LensSerialNumber",
+	0x0203: "LensModel",
+	0x0204: "LensFirmwareVersion",
+	0x0205: "MaxApertureAtMinFocal",
+	0x0206: "MaxApertureAtMaxFocal",
+	0x0207: "MinFocalLength",
+	0x0208: "MaxFocalLength",
+	0x020a: "MaxAperture",
+	0x020b: "LensProperties",
+	0x0301: "Extender",
+	0x0302: "ExtenderSerialNumber",
+	0x0303: "ExtenderModel",
+	0x0304: "ExtenderFirmwareVersion",
+	0x0403: "ConversionLens",
+	0x1000: "FlashType",
+	0x1001: "FlashModel",
+	0x1002: "FlashFirmwareVersion",
+	0x1003: "FlashSerialNumber",
+}
+
+var olympusCameraSettingsTagNames = map[tag.Tag]string{
+	0x0000: "CameraSettingsVersion",
+	0x0100: "PreviewImageValid",
+	0x0101: "PreviewImageStart",
+	0x0102: "PreviewImageLength",
+	0x0200: "ExposureMode",
+	0x0201: "AELock",
+	0x0202: "MeteringMode",
+	0x0203: "ExposureShift",
+	0x0300: "MacroMode",
+	0x0301: "FocusMode",
+	0x0302: "FocusProcess",
+	0x0303: "AFSearch",
+	0x0304: "AFAreas",
+	0x0305: "AFPointSelected",
+	0x0400: "FlashMode",
+	0x0401: "FlashExposureComp",
+	0x0500: "WhiteBalance2",
+	0x0501: "WhiteBalanceTemperature",
+	0x0502: "WhiteBalanceBracket",
+	0x0503: "CustomSaturation",
+	0x0504: "ModifiedSaturation",
+	0x0505: "ContrastSetting",
+	0x0506: "SharpnessSetting",
+	0x0507: "ColorSpace",
+	0x0509: "SceneMode",
+	0x050a: "NoiseReduction",
+	0x050b: "DistortionCorrection",
+	0x050c: "ShadingCompensation",
+	0x050d: "CompressionFactor",
+	0x050f: "Gradation",
+	0x0520: "PictureMode",
+	0x0521: "PictureModeSaturation",
+	0x0523: "PictureModeContrast",
+	0x0524: "PictureModeSharpness",
+	0x0600: "DriveMode",
+	0x0601: "PanoramaMode",
+	0x0603: "ImageQuality2",
+	0x0604: "ImageStabilization",
+}
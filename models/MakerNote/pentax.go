@@ -0,0 +1,204 @@
+package makernote
+
+import (
+	"fmt"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Headers of Pentax MakerNotes.
+const (
+	pentaxHeaderAOC = "AOC\x00"
+	pentaxHeaderNew = "PENTAX \x00"
+
+	// Positions of byte order marks and IFDs.
+	pentaxAOCByteOrderPosition = 4
+	pentaxAOCIFDPosition       = 6
+	pentaxNewByteOrderPosition = 8
+	pentaxNewIFDPosition       = 10
+)
+
+// Pentax MakerNotes written inside JPEG files have the 'AOC' header, offsets
+// are relative to the TIFF header. MakerNotes of raw files have the 'PENTAX'
+// header, offsets are relative to the start of the MakerNote. Both headers
+// may store the byte order mark. The oldest MakerNotes have no header.
+var Pentax = &Vendor{
+	Name: VendorNamePentax,
+	Match: func(cameraMake string, data []byte) bool {
+		return hasPrefix(data, pentaxHeaderAOC) || hasPrefix(data, pentaxHeaderNew) ||
+			makeHasPrefix(cameraMake, "PENTAX", "RICOH IMAGING", "ASAHI")
+	},
+	Locate:       locatePentax,
+	TagNames:     pentaxTagNames,
+	LensTagNames: []string{"LensRec", "LensType"},
+}
+
+// locatePentax finds the IFD of the Pentax MakerNote.
+func locatePentax(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+	switch {
+	case hasPrefix(data, pentaxHeaderAOC):
+		// A mark of two spaces means the byte order of the file.
+		order, ok := readByteOrderMark(data, pentaxAOCByteOrderPosition)
+		if !ok {
+			order = byteOrder
+		}
+		return relativeToTIFF(order, pentaxAOCIFDPosition), nil
+
+	case hasPrefix(data, pentaxHeaderNew):
+		order, ok := readByteOrderMark(data, pentaxNewByteOrderPosition)
+		if !ok {
+			return nil, fmt.Errorf(ErrHeaderIsNotValid, VendorNamePentax)
+		}
+		return &Layout{ByteOrder: order, IFDPosition: pentaxNewIFDPosition}, nil
+
+	default:
+		return relativeToTIFF(byteOrder, 0), nil
+	}
+}
+
+var pentaxTagNames = map[tag.Tag]string{
+	0x0000: "PentaxVersion",
+	0x0001: "PentaxModelType",
+	0x0002: "PreviewImageSize",
+	0x0003: "PreviewImageLength",
+	0x0004: "PreviewImageStart",
+	0x0005: "PentaxModelID",
+	0x0006: "Date",
+	0x0007: "Time",
+	0x0008: "Quality",
+	0x0009: "PentaxImageSize",
+	0x000b: "PictureMode",
+	0x000c: "FlashMode",
+	0x000d: "FocusMode",
+	0x000e: "AFPointSelected",
+	0x000f: "AFPointsInFocus",
+	0x0010: "FocusPosition",
+	0x0012: "ExposureTime",
+	0x0013: "FNumber",
+	0x0014: "ISO",
+	0x0015: "LightReading",
+	0x0016: "ExposureCompensation",
+	0x0017: "MeteringMode",
+	0x0018: "AutoBracketing",
+	0x0019: "WhiteBalance",
+	0x001a: "WhiteBalanceMode",
+	0x001b: "BlueBalance",
+	0x001c: "RedBalance",
+	0x001d: "FocalLength",
+	0x001e: "DigitalZoom",
+	0x001f: "Saturation",
+	0x0020: "Contrast",
+	0x0021: "Sharpness",
+	0x0022: "WorldTimeLocation",
+	0x0023: "HometownCity",
+	0x0024: "DestinationCity",
+	0x0025: "HometownDST",
+	0x0026: "DestinationDST",
+	0x0027: "DSPFirmwareVersion",
+	0x0028: "CPUFirmwareVersion",
+	0x0029: "FrameNumber",
+	0x002d: "EffectiveLV",
+	0x0032: "ImageEditing",
+	0x0033: "PictureMode2",
+	0x0034: "DriveMode",
+	0x0035: "SensorSize",
+	0x0037: "ColorSpace",
+	0x0038: "ImageAreaOffset",
+	0x0039: "RawImageSize",
+	0x003c: "AFPointsInFocus2",
+	0x003d: "DataScaling",
+	0x003e: "PreviewImageBorders",
+	0x003f: "LensRec",
+	0x0040: "SensitivityAdjust",
+	0x0041: "ImageEditCount",
+	0x0047: "CameraTemperature",
+	0x0048: "AELock",
+	0x0049: "NoiseReduction",
+	0x004d: "FlashExposureComp",
+	0x004f: "ImageTone",
+	0x0050: "ColorTemperature",
+	0x005c: "ShakeReductionInfo",
+	0x005d: "ShutterCount",
+	0x0060: "FaceInfo",
+	0x0067: "Hue",
+	0x0068: "AWBInfo",
+	0x0069: "DynamicRangeExpansion",
+	0x006b: "TimeInfo",
+	0x006c: "HighLowKeyAdj",
+	0x006d: "ContrastHighlight",
+	0x006e: "ContrastShadow",
+	0x006f: "ContrastHighlightShadowAdj",
+	0x0070: "FineSharpness",
+	0x0071: "HighISONoiseReduction",
+	0x0072: "AFAdjustment",
+	0x0073: "MonochromeFilterEffect",
+	0x0074: "MonochromeToning",
+	0x0076: "FaceDetect",
+	0x0077: "FaceDetectFrameSize",
+	0x0079: "ShadowCorrection",
+	0x007a: "ISOAutoParameters",
+	0x007b: "CrossProcess",
+	0x007d: "LensCorr",
+	0x007e: "WhiteLevel",
+	0x007f: "BleachBypassToning",
+	0x0080: "AspectRatio",
+	0x0082: "BlurControl",
+	0x0085: "HDR",
+	0x0087: "ShutterType",
+	0x0088: "NeutralDensityFilter",
+	0x008b: "ISO2",
+	0x0092: "IntervalShooting",
+	0x0095: "SkinToneCorrection",
+	0x0096: "ClarityControl",
+	0x0200: "BlackPoint",
+	0x0201: "WhitePoint",
+	0x0203: "ColorMatrixA",
+	0x0204: "ColorMatrixB",
+	0x0205: "CameraSettings",
+	0x0206: "AEInfo",
+	0x0207: "LensInfo",
+	0x0208: "FlashInfo",
+	0x0209: "AEMeteringSegments",
+	0x020a: "FlashMeteringSegments",
+	0x020b: "SlaveFlashMeteringSegments",
+	0x020d: "WB_RGGBLevelsDaylight",
+	0x020e: "WB_RGGBLevelsShade",
+	0x020f: "WB_RGGBLevelsCloudy",
+	0x0210: "WB_RGGBLevelsTungsten",
+	0x0211: "WB_RGGBLevelsFluorescentD",
+	0x0212: "WB_RGGBLevelsFluorescentN",
+	0x0213: "WB_RGGBLevelsFluorescentW",
+	0x0214: "WB_RGGBLevelsFlash",
+	0x0215: "CameraInfo",
+	0x0216: "BatteryInfo",
+	0x021b: "SaturationInfo",
+	0x021c: "ColorMatrixA2",
+	0x021d: "ColorMatrixB2",
+	0x021f: "AFInfo",
+	0x0220: "HuffmanTable",
+	0x0221: "KelvinWB",
+	0x0222: "ColorInfo",
+	0x0224: "EVStepInfo",
+	0x0226: "ShotInfo",
+	0x0227: "FacePos",
+	0x0228: "FaceSize",
+	0x0229: "SerialNumber",
+	0x022a: "FilterInfo",
+	0x022b: "LevelInfo",
+	0x022e: "Artist",
+	0x022f: "Copyright",
+	0x0230: "FirmwareVersion",
+	0x0231: "ContrastDetectAFArea",
+	0x0235: "CrossProcessParams",
+	0x0239: "LensInfoQ",
+	0x023f: "Model",
+	0x0243: "PixelShiftInfo",
+	0x0245: "AFPointInfo",
+	0x03fe: "DataDump",
+	0x03ff: "TempInfo",
+	0x0402: "ToneCurve",
+	0x0403: "ToneCurves",
+	0x0405: "UnknownBlock",
+	0x0e00: "PrintIM",
+}
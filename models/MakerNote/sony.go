@@ -0,0 +1,112 @@
+package makernote
+
+import (
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Headers of Sony MakerNotes.
+const (
+	sonyHeaderDSC = "SONY DSC \x00\x00\x00"
+	sonyHeaderCAM = "SONY CAM \x00\x00\x00"
+
+	// sonyIFDPosition is the position of the IFD after the header.
+	sonyIFDPosition = 12
+)
+
+// Sony MakerNotes have either a header of 12 bytes or no header. Offsets are
+// relative to the TIFF header.
+var Sony = &Vendor{
+	Name: VendorNameSony,
+	Match: func(cameraMake string, data []byte) bool {
+		return hasPrefix(data, sonyHeaderDSC) || hasPrefix(data, sonyHeaderCAM) ||
+			makeHasPrefix(cameraMake, "SONY")
+	},
+	Locate: func(data []byte, byteOrder bo.ByteOrder) (l *Layout, err error) {
+		if hasPrefix(data, sonyHeaderDSC) || hasPrefix(data, sonyHeaderCAM) {
+			return relativeToTIFF(byteOrder, sonyIFDPosition), nil
+		}
+
+		return relativeToTIFF(byteOrder, 0), nil
+	},
+	TagNames:     sonyTagNames,
+	LensTagNames: []string{"LensType", "LensSpec"},
+}
+
+var sonyTagNames = map[tag.Tag]string{
+	0x0010: "CameraInfo",
+	0x0020: "FocusInfo",
+	0x0102: "Quality",
+	0x0104: "FlashExposureComp",
+	0x0105: "Teleconverter",
+	0x0112: "WhiteBalanceFineTune",
+	0x0114: "CameraSettings",
+	0x0115: "WhiteBalance",
+	0x0116: "ExtraInfo",
+	0x0e00: "PrintIM",
+	0x1000: "MultiBurstMode",
+	0x1001: "MultiBurstImageWidth",
+	0x1002: "MultiBurstImageHeight",
+	0x1003: "Panorama",
+	0x2001: "PreviewImage",
+	0x2002: "Rating",
+	0x2004: "Contrast",
+	0x2005: "Saturation",
+	0x2006: "Sharpness",
+	0x2007: "Brightness",
+	0x2008: "LongExposureNoiseReduction",
+	0x2009: "HighISONoiseReduction",
+	0x200a: "HDR",
+	0x200b: "MultiFrameNoiseReduction",
+	0x200e: "PictureEffect",
+	0x200f: "SoftSkinEffect",
+	0x2011: "VignettingCorrection",
+	0x2012: "LateralChromaticAberration",
+	0x2013: "DistortionCorrectionSetting",
+	0x2014: "WBShiftAB_GM",
+	0x2016: "AutoPortraitFramed",
+	0x201b: "FocusMode",
+	0x201c: "AFAreaModeSetting",
+	0x201d: "FlexibleSpotPosition",
+	0x201e: "AFPointSelected",
+	0x2020: "AFPointsUsed",
+	0x2021: "AFTracking",
+	0x2022: "FocalPlaneAFPointsUsed",
+	0x2023: "MultiFrameNREffect",
+	0x2026: "WBShiftAB_GM_Precise",
+	0x2027: "FocusLocation",
+	0x2028: "VariableLowPassFilter",
+	0x2029: "RAWFileType",
+	0x202b: "PrioritySetInAWB",
+	0x202c: "MeteringMode2",
+	0x202d: "ExposureStandardAdjustment",
+	0x202e: "Quality2",
+	0x202f: "PixelShiftInfo",
+	0x2031: "SerialNumber",
+	0xb000: "FileFormat",
+	0xb001: "SonyModelID",
+	0xb020: "CreativeStyle",
+	0xb021: "ColorTemperature",
+	0xb022: "ColorCompensationFilter",
+	0xb023: "SceneMode",
+	0xb024: "ZoneMatching",
+	0xb025: "DynamicRangeOptimizer",
+	0xb026: "ImageStabilization",
+	0xb027: "LensType",
+	0xb028: "MinoltaMakerNote",
+	0xb029: "ColorMode",
+	0xb02a: "LensSpec",
+	0xb02b: "FullImageSize",
+	0xb02c: "PreviewImageSize",
+	0xb040: "Macro",
+	0xb041: "ExposureMode",
+	0xb043: "AFAreaMode",
+	0xb044: "AFIlluminator",
+	0xb047: "JPEGQuality",
+	0xb048: "FlashLevel",
+	0xb049: "ReleaseMode",
+	0xb04a: "SequenceNumber",
+	0xb04b: "Anti-Blur",
+	0xb052: "IntelligentAuto",
+	0xb054: "WhiteBalance2",
+}
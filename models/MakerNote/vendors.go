@@ -0,0 +1,74 @@
+package makernote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+)
+
+// Names of built-in vendors.
+const (
+	VendorNameCanon     = "Canon"
+	VendorNameNikon     = "Nikon"
+	VendorNameSony      = "Sony"
+	VendorNameOlympus   = "Olympus"
+	VendorNameFujifilm  = "Fujifilm"
+	VendorNamePanasonic = "Panasonic"
+	VendorNamePentax    = "Pentax"
+)
+
+// hasPrefix tells whether the data starts with the prefix.
+func hasPrefix(data []byte, prefix string) bool {
+	return bytes.HasPrefix(data, []byte(prefix))
+}
+
+// makeHasPrefix tells whether the make of the camera starts with any of the
+// prefixes. Letter case is ignored.
+func makeHasPrefix(cameraMake string, prefixes ...string) bool {
+	cameraMake = strings.ToUpper(cameraMake)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(cameraMake, strings.ToUpper(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readByteOrderMark reads the byte order mark at the position. The flag is
+// not set when there is no valid mark.
+func readByteOrderMark(data []byte, pos int) (byteOrder bo.ByteOrder, ok bool) {
+	if pos+bo.ByteOrderMarkSize > len(data) {
+		return bo.Unknown, false
+	}
+
+	switch string(data[pos : pos+bo.ByteOrderMarkSize]) {
+	case bo.LittleEndian.String():
+		return bo.LittleEndian, true
+	case bo.BigEndian.String():
+		return bo.BigEndian, true
+	default:
+		return bo.Unknown, false
+	}
+}
+
+// readUint32 reads an unsigned integer of 32 bits using the byte order.
+func readUint32(data []byte, byteOrder bo.ByteOrder) uint32 {
+	if byteOrder == bo.BigEndian {
+		return binary.BigEndian.Uint32(data)
+	}
+
+	return binary.LittleEndian.Uint32(data)
+}
+
+// relativeToTIFF returns the layout of an IFD placed at the position, whose
+// offsets are counted from the TIFF header of the file.
+func relativeToTIFF(byteOrder bo.ByteOrder, ifdPosition int64) *Layout {
+	return &Layout{
+		ByteOrder:        byteOrder,
+		IFDPosition:      ifdPosition,
+		IsRelativeToTIFF: true,
+	}
+}
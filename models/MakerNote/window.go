@@ -0,0 +1,88 @@
+package makernote
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	ErrWhenceIsNotValid    = "whence is not valid"
+	ErrPositionIsNegative  = "position is negative"
+	ErrPositionIsOutOfData = "position is out of the MakerNote"
+)
+
+// window is a stream in which the data of the MakerNote is placed at the
+// start position, so that offsets counted from any origin, e.g. from the TIFF
+// header of the file, can be read. Positions outside the data can not be
+// read.
+type window struct {
+	data  []byte
+	start int64
+	pos   int64
+}
+
+// newWindow creates the stream where the data starts at the position.
+func newWindow(data []byte, start int64) (w *window) {
+	return &window{data: data, start: start, pos: start}
+}
+
+// Read reads the data at the current position.
+func (w *window) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	j := w.pos - w.start
+	if j < 0 {
+		return 0, errors.New(ErrPositionIsOutOfData)
+	}
+	if j >= int64(len(w.data)) {
+		return 0, io.EOF
+	}
+
+	n = copy(p, w.data[j:])
+	w.pos += int64(n)
+
+	return n, nil
+}
+
+// ReadAt reads the data at the position without moving the current
+// position.
+func (w *window) ReadAt(p []byte, off int64) (n int, err error) {
+	j := off - w.start
+	if j < 0 {
+		return 0, errors.New(ErrPositionIsOutOfData)
+	}
+	if j >= int64(len(w.data)) {
+		return 0, io.EOF
+	}
+
+	n = copy(p, w.data[j:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Seek sets the current position. The end of the stream is the end of the
+// data.
+func (w *window) Seek(offset int64, whence int) (pos int64, err error) {
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = w.pos + offset
+	case io.SeekEnd:
+		pos = w.start + int64(len(w.data)) + offset
+	default:
+		return 0, errors.New(ErrWhenceIsNotValid)
+	}
+
+	if pos < 0 {
+		return 0, errors.New(ErrPositionIsNegative)
+	}
+
+	w.pos = pos
+	return pos, nil
+}
@@ -0,0 +1,249 @@
+package photoshop
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	iptc "github.com/vault-thirteen/TIFFer/models/IPTC"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Signatures of resource blocks. Photoshop writes '8BIM', other signatures
+// are used by older versions and by other applications.
+const (
+	Signature8BIM = "8BIM"
+	SignatureMeSa = "MeSa"
+	SignatureAgHg = "AgHg"
+	SignaturePHUT = "PHUT"
+	SignatureDCSR = "DCSR"
+)
+
+const (
+	ErrTIFFHasNoIFD           = "TIFF has no IFD"
+	ErrPhotoshopTagIsMissing  = "Photoshop tag is missing"
+	ErrSignatureIsUnknown     = "unknown signature of resource block at offset %v: %q"
+	ErrBlockIsTruncated       = "resource block at offset %v is truncated"
+	ErrResourceIsMissing      = "resource is missing: %v"
+	ErrResourceSizeIsNotValid = "size of resource is not valid: %v"
+)
+
+// signatureSize is the size of the signature of a resource block.
+const signatureSize = 4
+
+// Resource is a single image resource block.
+type Resource struct {
+	// Signature is the signature of the block, usually '8BIM'.
+	Signature string
+
+	// ID is the identifier of the resource.
+	ID ResourceID
+
+	// Name is the name of the resource, which is usually empty.
+	Name string
+
+	// Data is the raw value of the resource.
+	Data []byte
+
+	// Offset is the position of the data inside the value of the Photoshop
+	// tag.
+	Offset int
+}
+
+// Photoshop is the list of image resource blocks stored in the Photoshop
+// tag.
+//
+// Common resources are decoded by methods of the model, all other resources
+// are available as raw data.
+//
+// Adobe Photoshop File Formats Specification, Image Resources Section:
+// https://www.adobe.com/devnet-apps/photoshop/fileformatashtml/
+type Photoshop struct {
+	// Resources are resource blocks in the order of their appearance.
+	Resources []*Resource
+}
+
+// New parses resource blocks of the first IFD of the TIFF.
+func New(t *tiff.TIFF) (p *Photoshop, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0])
+}
+
+// NewFromIFD parses resource blocks of the IFD.
+func NewFromIFD(i *ifd.IFD) (p *Photoshop, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[tag.Photoshop]
+	if !ok {
+		return nil, errors.New(ErrPhotoshopTagIsMissing)
+	}
+
+	var data []byte
+	data, err = de.ValueAsArrayOfByte()
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse parses image resource blocks. Each block has a signature, an
+// identifier, a name written as a Pascal string padded to an even size, and
+// data padded to an even size. Zero bytes after the last block are padding.
+func Parse(data []byte) (p *Photoshop, err error) {
+	p = &Photoshop{Resources: make([]*Resource, 0)}
+
+	pos := 0
+	for pos < len(data) {
+		if isPadding(data[pos:]) {
+			break
+		}
+
+		start := pos
+		if pos+signatureSize+2+1 > len(data) {
+			return nil, fmt.Errorf(ErrBlockIsTruncated, start)
+		}
+
+		r := &Resource{Signature: string(data[pos : pos+signatureSize])}
+		if !isKnownSignature(r.Signature) {
+			return nil, fmt.Errorf(ErrSignatureIsUnknown, start, r.Signature)
+		}
+		pos += signatureSize
+
+		r.ID = ResourceID(binary.BigEndian.Uint16(data[pos:]))
+		pos += 2
+
+		var nameSize int
+		var ok bool
+		r.Name, nameSize, ok = readPascalString(data[pos:])
+		if !ok {
+			return nil, fmt.Errorf(ErrBlockIsTruncated, start)
+		}
+		pos += nameSize
+
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf(ErrBlockIsTruncated, start)
+		}
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		pos += 4
+
+		if (size < 0) || (pos+size > len(data)) {
+			return nil, fmt.Errorf(ErrBlockIsTruncated, start)
+		}
+		r.Data = data[pos : pos+size]
+		r.Offset = pos
+		pos += size + size%2
+
+		p.Resources = append(p.Resources, r)
+	}
+
+	return p, nil
+}
+
+// Resource returns the first resource with the identifier, or nil.
+func (p *Photoshop) Resource(id ResourceID) (r *Resource) {
+	for _, r = range p.Resources {
+		if r.ID == id {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// ResolutionInfo decodes the ResolutionInfo resource.
+func (p *Photoshop) ResolutionInfo() (ri *ResolutionInfoResource, err error) {
+	r := p.Resource(ResolutionInfo)
+	if r == nil {
+		return nil, fmt.Errorf(ErrResourceIsMissing, ResolutionInfo)
+	}
+
+	return DecodeResolutionInfo(r.Data)
+}
+
+// IPTC decodes the copy of IPTC-IIM data stored in the IPTC-NAA resource.
+func (p *Photoshop) IPTC() (m *iptc.IPTC, err error) {
+	r := p.Resource(IPTCNAA)
+	if r == nil {
+		return nil, fmt.Errorf(ErrResourceIsMissing, IPTCNAA)
+	}
+
+	return iptc.Parse(r.Data)
+}
+
+// Thumbnail decodes the thumbnail resource. The old thumbnail resource of
+// Photoshop 4.0 is used when the new one is missing.
+func (p *Photoshop) Thumbnail() (t *Thumbnail, err error) {
+	r := p.Resource(ThumbnailResource)
+	if r == nil {
+		r = p.Resource(ThumbnailResourceOld)
+	}
+	if r == nil {
+		return nil, fmt.Errorf(ErrResourceIsMissing, ThumbnailResource)
+	}
+
+	return DecodeThumbnail(r.Data)
+}
+
+// Paths decodes all saved paths. The name of a path is the name of its
+// resource.
+func (p *Photoshop) Paths() (paths []*Path, err error) {
+	for _, r := range p.Resources {
+		if !r.ID.IsPath() {
+			continue
+		}
+
+		var path *Path
+		path, err = DecodePath(r.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		path.ID = r.ID
+		path.Name = r.Name
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// readPascalString reads a Pascal string padded to an even size. The size
+// includes the length byte. The flag is not set when the data is too short.
+func readPascalString(data []byte) (s string, size int, ok bool) {
+	if len(data) == 0 {
+		return "", 0, false
+	}
+
+	length := int(data[0])
+	size = 1 + length
+	size += size % 2
+	if size > len(data) {
+		return "", 0, false
+	}
+
+	return string(data[1 : 1+length]), size, true
+}
+
+// isKnownSignature tells whether the signature of a resource block is known.
+func isKnownSignature(signature string) bool {
+	switch signature {
+	case Signature8BIM, SignatureMeSa, SignatureAgHg, SignaturePHUT, SignatureDCSR:
+		return true
+	default:
+		return false
+	}
+}
+
+// isPadding tells whether all bytes are zero.
+func isPadding(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
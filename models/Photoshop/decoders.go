@@ -0,0 +1,268 @@
+package photoshop
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Units of resolutions.
+const (
+	ResolutionUnitPixelsPerInch       = 1
+	ResolutionUnitPixelsPerCentimetre = 2
+)
+
+// Formats of thumbnails.
+const (
+	ThumbnailFormatRawRGB  = 0
+	ThumbnailFormatJPEGRGB = 1
+)
+
+// Selectors of path records.
+const (
+	PathRecordClosedSubpathLength = 0
+	PathRecordClosedKnotLinked    = 1
+	PathRecordClosedKnotUnlinked  = 2
+	PathRecordOpenSubpathLength   = 3
+	PathRecordOpenKnotLinked      = 4
+	PathRecordOpenKnotUnlinked    = 5
+	PathRecordFillRule            = 6
+	PathRecordClipboard           = 7
+	PathRecordInitialFillRule     = 8
+)
+
+// PathRecordSize is the size of a path record.
+const PathRecordSize = 26
+
+// Sizes of resources and of their parts.
+const (
+	resolutionInfoSize     = 16
+	thumbnailHeaderSize    = 28
+	pathRecordSelectorSize = 2
+)
+
+// Divisors of fixed-point numbers.
+const (
+	fixed16Dot16Divisor = 1 << 16
+	fixed8Dot24Divisor  = 1 << 24
+)
+
+// pathInitialFillRuleAllPixels is the value of the initial fill rule record
+// showing that the fill starts with all pixels.
+const pathInitialFillRuleAllPixels = 1
+
+// ResolutionInfoResource is the decoded ResolutionInfo resource.
+type ResolutionInfoResource struct {
+	// HorizontalResolution is the horizontal resolution in pixels per inch.
+	HorizontalResolution float64
+
+	// HorizontalResolutionUnit is the unit in which the horizontal
+	// resolution is shown to the user.
+	HorizontalResolutionUnit uint16
+
+	// WidthUnit is the unit in which the width is shown to the user.
+	WidthUnit uint16
+
+	// VerticalResolution is the vertical resolution in pixels per inch.
+	VerticalResolution float64
+
+	// VerticalResolutionUnit is the unit in which the vertical resolution is
+	// shown to the user.
+	VerticalResolutionUnit uint16
+
+	// HeightUnit is the unit in which the height is shown to the user.
+	HeightUnit uint16
+}
+
+// Thumbnail is the decoded thumbnail resource.
+type Thumbnail struct {
+	// Format is either ThumbnailFormatJPEGRGB or ThumbnailFormatRawRGB.
+	Format uint32
+
+	Width          uint32
+	Height         uint32
+	WidthBytes     uint32
+	TotalSize      uint32
+	CompressedSize uint32
+	BitsPerPixel   uint16
+	Planes         uint16
+
+	// Data is the JFIF stream or raw pixels of the thumbnail.
+	Data []byte
+}
+
+// Path is a decoded saved path.
+type Path struct {
+	// ID is the identifier of the resource of the path.
+	ID ResourceID
+
+	// Name is the name of the path.
+	Name string
+
+	// FillStartsWithAllPixels flag is set when the initial fill rule record
+	// says so.
+	FillStartsWithAllPixels bool
+
+	// Clipboard is the clipboard record, or nil.
+	Clipboard *Clipboard
+
+	// Subpaths are subpaths in the order of their appearance.
+	Subpaths []*Subpath
+}
+
+// Subpath is a sequence of Bézier knots.
+type Subpath struct {
+	// IsClosed flag is set for closed subpaths.
+	IsClosed bool
+
+	// Knots are the knots of the subpath.
+	Knots []*Knot
+}
+
+// Knot is a Bézier knot with its control points.
+type Knot struct {
+	// IsLinked flag is set when the control points are linked.
+	IsLinked bool
+
+	Preceding Point
+	Anchor    Point
+	Leaving   Point
+}
+
+// Point is a point of a path. Coordinates are fractions of the width and of
+// the height of the image, e.g. (0.5, 0.5) is the centre of the image.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Clipboard is the bounding rectangle and the resolution of a path copied to
+// the clipboard.
+type Clipboard struct {
+	Top        float64
+	Left       float64
+	Bottom     float64
+	Right      float64
+	Resolution float64
+}
+
+// DecodeResolutionInfo decodes the data of the ResolutionInfo resource.
+func DecodeResolutionInfo(data []byte) (ri *ResolutionInfoResource, err error) {
+	if len(data) < resolutionInfoSize {
+		return nil, fmt.Errorf(ErrResourceSizeIsNotValid, len(data))
+	}
+
+	ri = &ResolutionInfoResource{
+		HorizontalResolution:     readFixed(data[0:], fixed16Dot16Divisor),
+		HorizontalResolutionUnit: binary.BigEndian.Uint16(data[4:]),
+		WidthUnit:                binary.BigEndian.Uint16(data[6:]),
+		VerticalResolution:       readFixed(data[8:], fixed16Dot16Divisor),
+		VerticalResolutionUnit:   binary.BigEndian.Uint16(data[12:]),
+		HeightUnit:               binary.BigEndian.Uint16(data[14:]),
+	}
+
+	return ri, nil
+}
+
+// DecodeThumbnail decodes the data of the thumbnail resource.
+func DecodeThumbnail(data []byte) (t *Thumbnail, err error) {
+	if len(data) < thumbnailHeaderSize {
+		return nil, fmt.Errorf(ErrResourceSizeIsNotValid, len(data))
+	}
+
+	t = &Thumbnail{
+		Format:         binary.BigEndian.Uint32(data[0:]),
+		Width:          binary.BigEndian.Uint32(data[4:]),
+		Height:         binary.BigEndian.Uint32(data[8:]),
+		WidthBytes:     binary.BigEndian.Uint32(data[12:]),
+		TotalSize:      binary.BigEndian.Uint32(data[16:]),
+		CompressedSize: binary.BigEndian.Uint32(data[20:]),
+		BitsPerPixel:   binary.BigEndian.Uint16(data[24:]),
+		Planes:         binary.BigEndian.Uint16(data[26:]),
+		Data:           data[thumbnailHeaderSize:],
+	}
+
+	return t, nil
+}
+
+// IsJPEG tells whether the thumbnail is a JFIF stream.
+func (t *Thumbnail) IsJPEG() bool {
+	return t.Format == ThumbnailFormatJPEGRGB
+}
+
+// DecodePath decodes the data of a saved path resource, which is a sequence
+// of path records. Each subpath starts with a length record followed by
+// its knot records.
+func DecodePath(data []byte) (p *Path, err error) {
+	if len(data)%PathRecordSize != 0 {
+		return nil, fmt.Errorf(ErrResourceSizeIsNotValid, len(data))
+	}
+
+	p = &Path{Subpaths: make([]*Subpath, 0)}
+
+	var current *Subpath
+	for pos := 0; pos < len(data); pos += PathRecordSize {
+		record := data[pos : pos+PathRecordSize]
+		selector := binary.BigEndian.Uint16(record)
+		body := record[pathRecordSelectorSize:]
+
+		switch selector {
+		case PathRecordClosedSubpathLength, PathRecordOpenSubpathLength:
+			current = &Subpath{
+				IsClosed: selector == PathRecordClosedSubpathLength,
+				Knots:    make([]*Knot, 0, binary.BigEndian.Uint16(body)),
+			}
+			p.Subpaths = append(p.Subpaths, current)
+
+		case PathRecordClosedKnotLinked, PathRecordClosedKnotUnlinked,
+			PathRecordOpenKnotLinked, PathRecordOpenKnotUnlinked:
+			if current == nil {
+				// Knots without a length record are kept in a subpath of
+				// their own.
+				current = &Subpath{
+					IsClosed: selector <= PathRecordClosedKnotUnlinked,
+					Knots:    make([]*Knot, 0),
+				}
+				p.Subpaths = append(p.Subpaths, current)
+			}
+
+			current.Knots = append(current.Knots, &Knot{
+				IsLinked:  (selector == PathRecordClosedKnotLinked) || (selector == PathRecordOpenKnotLinked),
+				Preceding: readPoint(body[0:]),
+				Anchor:    readPoint(body[8:]),
+				Leaving:   readPoint(body[16:]),
+			})
+
+		case PathRecordClipboard:
+			p.Clipboard = &Clipboard{
+				Top:        readFixed(body[0:], fixed8Dot24Divisor),
+				Left:       readFixed(body[4:], fixed8Dot24Divisor),
+				Bottom:     readFixed(body[8:], fixed8Dot24Divisor),
+				Right:      readFixed(body[12:], fixed8Dot24Divisor),
+				Resolution: readFixed(body[16:], fixed8Dot24Divisor),
+			}
+
+		case PathRecordInitialFillRule:
+			p.FillStartsWithAllPixels = binary.BigEndian.Uint16(body) == pathInitialFillRuleAllPixels
+
+		default:
+			// The path fill rule record has no data, unknown records are
+			// skipped.
+		}
+	}
+
+	return p, nil
+}
+
+// readPoint reads a point written as the vertical and the horizontal
+// components in the 8.24 fixed-point format.
+func readPoint(data []byte) Point {
+	return Point{
+		Y: readFixed(data[0:], fixed8Dot24Divisor),
+		X: readFixed(data[4:], fixed8Dot24Divisor),
+	}
+}
+
+// readFixed reads a signed fixed-point number of 32 bits.
+func readFixed(data []byte, divisor float64) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / divisor
+}
@@ -0,0 +1,65 @@
+package photoshop
+
+import (
+	"strconv"
+
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// PreviewSource is the source of previews stored in thumbnail resources.
+const PreviewSource = "Photoshop"
+
+// FindPreviews lists JPEG thumbnails of the thumbnail resources of Photoshop
+// tags of all directories of the TIFF. It is a preview finder of the
+// Previews method of the TIFF, which is used by the 'previews' package.
+//
+// Paths of previews are paths of Photoshop tags followed by identifiers of
+// the resources, e.g. 'IFD0/Photoshop/1036'. Thumbnails of raw pixels are
+// not listed, as they are not written by Photoshop.
+func FindPreviews(t *tiff.TIFF) (previews []*tiff.Preview) {
+	_ = t.WalkDirectories(func(d *tiff.Directory) error {
+		de, ok := d.EntriesByTagNumber[tag.Photoshop]
+		if !ok {
+			return nil
+		}
+
+		data, err := de.ValueAsArrayOfByte()
+		if err != nil {
+			return nil
+		}
+
+		var p *Photoshop
+		p, err = Parse(data)
+		if err != nil {
+			return nil
+		}
+
+		for _, r := range p.Resources {
+			if (r.ID != ThumbnailResource) && (r.ID != ThumbnailResourceOld) {
+				continue
+			}
+
+			var th *Thumbnail
+			th, err = DecodeThumbnail(r.Data)
+			if (err != nil) || !th.IsJPEG() {
+				continue
+			}
+
+			previews = append(previews, &tiff.Preview{
+				Path:       d.EntryPath(de) + tiff.PathSeparator + strconv.Itoa(int(r.ID)),
+				Source:     PreviewSource,
+				Format:     tiff.PreviewFormatJPEG,
+				Width:      int(th.Width),
+				Height:     int(th.Height),
+				Offsets:    []int64{int64(de.Offset) + int64(r.Offset) + thumbnailHeaderSize},
+				ByteCounts: []int64{int64(len(th.Data))},
+				Data:       th.Data,
+			})
+		}
+
+		return nil
+	})
+
+	return previews
+}
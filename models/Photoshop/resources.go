@@ -0,0 +1,126 @@
+package photoshop
+
+import "fmt"
+
+// ResourceID is the identifier of an image resource.
+type ResourceID uint16
+
+// Identifiers of image resources.
+const (
+	ResolutionInfo           = ResourceID(1005)
+	AlphaChannelNames        = ResourceID(1006)
+	Caption                  = ResourceID(1008)
+	BorderInformation        = ResourceID(1009)
+	BackgroundColor          = ResourceID(1010)
+	PrintFlags               = ResourceID(1011)
+	ColorHalftoningInfo      = ResourceID(1013)
+	ColorTransferFunctions   = ResourceID(1016)
+	LayerStateInformation    = ResourceID(1024)
+	LayersGroupInformation   = ResourceID(1026)
+	IPTCNAA                  = ResourceID(1028)
+	JPEGQuality              = ResourceID(1030)
+	GridAndGuidesInformation = ResourceID(1032)
+	ThumbnailResourceOld     = ResourceID(1033)
+	CopyrightFlag            = ResourceID(1034)
+	URL                      = ResourceID(1035)
+	ThumbnailResource        = ResourceID(1036)
+	GlobalAngle              = ResourceID(1037)
+	ICCProfile               = ResourceID(1039)
+	Watermark                = ResourceID(1040)
+	ICCUntaggedProfile       = ResourceID(1041)
+	SpotHalftone             = ResourceID(1043)
+	DocumentSpecificIDsSeed  = ResourceID(1044)
+	UnicodeAlphaNames        = ResourceID(1045)
+	GlobalAltitude           = ResourceID(1049)
+	Slices                   = ResourceID(1050)
+	URLList                  = ResourceID(1054)
+	VersionInfo              = ResourceID(1057)
+	ExifData1                = ResourceID(1058)
+	ExifData3                = ResourceID(1059)
+	XMPMetadata              = ResourceID(1060)
+	CaptionDigest            = ResourceID(1061)
+	PrintScale               = ResourceID(1062)
+	PixelAspectRatio         = ResourceID(1064)
+	LayerComps               = ResourceID(1065)
+	LayerSelectionIDs        = ResourceID(1069)
+	LayerGroupsEnabledID     = ResourceID(1072)
+	PrintInformation         = ResourceID(1082)
+	PrintStyle               = ResourceID(1083)
+	ClippingPathName         = ResourceID(2999)
+	PrintFlagsInformation    = ResourceID(10000)
+)
+
+// Range of identifiers of saved paths.
+const (
+	PathInformationFirst = ResourceID(2000)
+	PathInformationLast  = ResourceID(2997)
+)
+
+var resourceNames = map[ResourceID]string{
+	ResolutionInfo:           "ResolutionInfo",
+	AlphaChannelNames:        "AlphaChannelNames",
+	Caption:                  "Caption",
+	BorderInformation:        "BorderInformation",
+	BackgroundColor:          "BackgroundColor",
+	PrintFlags:               "PrintFlags",
+	ColorHalftoningInfo:      "ColorHalftoningInfo",
+	ColorTransferFunctions:   "ColorTransferFunctions",
+	LayerStateInformation:    "LayerStateInformation",
+	LayersGroupInformation:   "LayersGroupInformation",
+	IPTCNAA:                  "IPTC-NAA",
+	JPEGQuality:              "JPEGQuality",
+	GridAndGuidesInformation: "GridAndGuidesInformation",
+	ThumbnailResourceOld:     "ThumbnailResourceOld",
+	CopyrightFlag:            "CopyrightFlag",
+	URL:                      "URL",
+	ThumbnailResource:        "ThumbnailResource",
+	GlobalAngle:              "GlobalAngle",
+	ICCProfile:               "ICCProfile",
+	Watermark:                "Watermark",
+	ICCUntaggedProfile:       "ICCUntaggedProfile",
+	SpotHalftone:             "SpotHalftone",
+	DocumentSpecificIDsSeed:  "DocumentSpecificIDsSeed",
+	UnicodeAlphaNames:        "UnicodeAlphaNames",
+	GlobalAltitude:           "GlobalAltitude",
+	Slices:                   "Slices",
+	URLList:                  "URLList",
+	VersionInfo:              "VersionInfo",
+	ExifData1:                "ExifData1",
+	ExifData3:                "ExifData3",
+	XMPMetadata:              "XMPMetadata",
+	CaptionDigest:            "CaptionDigest",
+	PrintScale:               "PrintScale",
+	PixelAspectRatio:         "PixelAspectRatio",
+	LayerComps:               "LayerComps",
+	LayerSelectionIDs:        "LayerSelectionIDs",
+	LayerGroupsEnabledID:     "LayerGroupsEnabledID",
+	PrintInformation:         "PrintInformation",
+	PrintStyle:               "PrintStyle",
+	ClippingPathName:         "ClippingPathName",
+	PrintFlagsInformation:    "PrintFlagsInformation",
+}
+
+// IsPath tells whether the resource is a saved path.
+func (id ResourceID) IsPath() bool {
+	return (id >= PathInformationFirst) && (id <= PathInformationLast)
+}
+
+// Name returns the name of the resource as written in the Photoshop File
+// Formats Specification. Unknown resources are named by their identifiers.
+func (id ResourceID) Name() string {
+	name, ok := resourceNames[id]
+	if ok {
+		return name
+	}
+
+	if id.IsPath() {
+		return fmt.Sprintf("PathInformation#%d", id-PathInformationFirst)
+	}
+
+	return fmt.Sprintf("%d", uint16(id))
+}
+
+// String returns the name of the resource.
+func (id ResourceID) String() string {
+	return id.Name()
+}
@@ -1,7 +1,9 @@
 package tiff
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	hdr "github.com/vault-thirteen/TIFFer/models/Header"
 	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
@@ -33,6 +35,9 @@ type TIFF struct {
 
 	// ifds is a list of IFDs.
 	ifds []*ifd.IFD
+
+	// progress is the state of parsing, used for reporting errors.
+	progress *progress
 }
 
 // New constructs the TIFF object from the byte reader.
@@ -45,8 +50,18 @@ type TIFF struct {
 // information about so-called Sub-IFDs, which are not a part of the
 // TIFF 6.0 Specification, but they are used by some tools.
 func New(stream iors.ReaderSeeker) (t *TIFF, err error) {
+	return NewWithContext(context.Background(), stream, Options{})
+}
+
+// NewWithContext constructs the TIFF object from the byte reader, the same
+// way as New does, but checks the context between IFDs and before each read
+// from the stream. When the context is done, the context's error is returned
+// wrapped into a ParseError which describes the progress of parsing.
+func NewWithContext(ctx context.Context, stream iors.ReaderSeeker, opts Options) (t *TIFF, err error) {
+	p := &progress{ctx: ctx, opts: opts, ifdIndex: -1}
 	t = &TIFF{
-		ifds: make([]*ifd.IFD, 0),
+		ifds:     make([]*ifd.IFD, 0),
+		progress: p,
 	}
 
 	err = doSelfCheck()
@@ -54,6 +69,18 @@ func New(stream iors.ReaderSeeker) (t *TIFF, err error) {
 		return nil, err
 	}
 
+	defer func() {
+		if err != nil {
+			t, err = nil, p.wrapError(err)
+			return
+		}
+		t.progress = nil
+	}()
+
+	if ctx.Done() != nil {
+		stream = newContextStream(ctx, stream, p)
+	}
+
 	var readerSeeker *rs.ReaderSeeker
 	readerSeeker, err = rs.New(stream)
 	if err != nil {
@@ -87,12 +114,23 @@ func New(stream iors.ReaderSeeker) (t *TIFF, err error) {
 	return t, nil
 }
 
+// NewFromReaderAt constructs the TIFF object from the random access reader of
+// the specified size. It is useful with readers of remote files, such as
+// those provided by the 'input' package.
+func NewFromReaderAt(ra io.ReaderAt, size int64) (t *TIFF, err error) {
+	return New(io.NewSectionReader(ra, 0, size))
+}
+
 // readPassOne performs a first read pass of the TIFF object.
 // In this pass we briefly read structures.
 func (t *TIFF) readPassOne(rs *rs.ReaderSeeker) (err error) {
 	var i *ifd.IFD
 
 	// First IFD.
+	err = t.progress.enterIFD(0)
+	if err != nil {
+		return err
+	}
 	i, err = ifd.NewIFD(rs, t.header.ByteOrder, t.header.OffsetOfFirstIFD)
 	if err != nil {
 		return fmt.Errorf(ErrInFirstIFD, err.Error())
@@ -106,6 +144,10 @@ func (t *TIFF) readPassOne(rs *rs.ReaderSeeker) (err error) {
 	// Rest IFDs.
 	n := 2
 	for !lrIFD.IsLast() {
+		err = t.progress.enterIFD(n - 1)
+		if err != nil {
+			return err
+		}
 		i, err = ifd.NewIFD(rs, t.header.ByteOrder, lrIFD.OffsetOfNextIFD)
 		if err != nil {
 			return fmt.Errorf(ErrInNthIFD, n, err.Error())
@@ -132,7 +174,11 @@ func (t *TIFF) readPassOne(rs *rs.ReaderSeeker) (err error) {
 // readPassTwo performs a second-pass read of the TIFF object.
 // In this pass we read values and try to decode them.
 func (t *TIFF) readPassTwo(rs *rs.ReaderSeeker) (err error) {
-	for _, curIFD := range t.ifds {
+	for idx, curIFD := range t.ifds {
+		err = t.progress.enterIFD(idx)
+		if err != nil {
+			return err
+		}
 		err = curIFD.ProcessValues(rs, t.header.ByteOrder)
 		if err != nil {
 			return err
@@ -147,7 +193,11 @@ func (t *TIFF) readPassTwo(rs *rs.ReaderSeeker) (err error) {
 // readPassThree performs a third-pass read of the TIFF object.
 // In this pass we read sub-IFDs of tags.
 func (t *TIFF) readPassThree(rs *rs.ReaderSeeker) (err error) {
-	for _, curIFD := range t.ifds {
+	for idx, curIFD := range t.ifds {
+		err = t.progress.enterIFD(idx)
+		if err != nil {
+			return err
+		}
 		err = curIFD.ProcessSubIFDs(rs, t.header.ByteOrder)
 		if err != nil {
 			return err
@@ -0,0 +1,155 @@
+package tiff
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	iors "github.com/vault-thirteen/auxie/ReaderSeeker"
+)
+
+const (
+	ErrTooManyIFDs = "number of IFDs exceeds the limit: %v"
+	ErrParsing     = "parsing is aborted in IFD #%v at offset %v: %v"
+)
+
+// Options are settings of the parser.
+type Options struct {
+	// MaxIFDs is the maximum number of IFDs in the chain. It protects from
+	// files with looped chains of IFDs. If it is not positive, the number of
+	// IFDs is not limited.
+	MaxIFDs int
+}
+
+// ParseError is the error of parsing, which tells where the parsing has
+// stopped. Only errors caused by the context, i.e. its cancellation or
+// deadline, are wrapped into a ParseError. Errors of the structure of the
+// file, including the limit of the number of IFDs, are returned as they are.
+type ParseError struct {
+	// IFDIndex is the index of the IFD being parsed, starting from zero. It
+	// is negative if the error has occurred before the first IFD.
+	IFDIndex int
+
+	// Offset is the position in the stream where the last read has started.
+	Offset int64
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error returns the text of the error.
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf(ErrParsing, pe.IFDIndex, pe.Offset, pe.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (pe *ParseError) Unwrap() error {
+	return pe.Err
+}
+
+// progress is the state of parsing.
+type progress struct {
+	ctx      context.Context
+	opts     Options
+	ifdIndex int
+	offset   int64
+}
+
+// enterIFD registers the start of processing of the IFD and checks the
+// context.
+func (p *progress) enterIFD(idx int) (err error) {
+	p.ifdIndex = idx
+
+	if (p.opts.MaxIFDs > 0) && (idx >= p.opts.MaxIFDs) {
+		return fmt.Errorf(ErrTooManyIFDs, p.opts.MaxIFDs)
+	}
+
+	return p.ctx.Err()
+}
+
+// wrapError wraps errors caused by the context into a ParseError.
+func (p *progress) wrapError(err error) error {
+	ctxErr := p.ctx.Err()
+	if ctxErr == nil {
+		return err
+	}
+
+	return &ParseError{
+		IFDIndex: p.ifdIndex,
+		Offset:   p.offset,
+		Err:      ctxErr,
+	}
+}
+
+// contextStream is a stream which checks the context before each read and
+// remembers the position of the last read.
+type contextStream struct {
+	ctx      context.Context
+	stream   iors.ReaderSeeker
+	progress *progress
+	position int64
+}
+
+// newContextStream wraps the stream. Streams which support random access are
+// wrapped into a stream which supports random access too.
+func newContextStream(ctx context.Context, stream iors.ReaderSeeker, p *progress) iors.ReaderSeeker {
+	cs := &contextStream{
+		ctx:      ctx,
+		stream:   stream,
+		progress: p,
+	}
+
+	readerAt, ok := stream.(io.ReaderAt)
+	if ok {
+		return &contextStreamAt{contextStream: cs, readerAt: readerAt}
+	}
+
+	return cs
+}
+
+// Read reads data at the current position.
+func (cs *contextStream) Read(p []byte) (n int, err error) {
+	err = cs.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	cs.progress.offset = cs.position
+	n, err = cs.stream.Read(p)
+	cs.position += int64(n)
+
+	return n, err
+}
+
+// Seek sets the current position.
+func (cs *contextStream) Seek(offset int64, whence int) (position int64, err error) {
+	err = cs.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	position, err = cs.stream.Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+	cs.position = position
+
+	return position, nil
+}
+
+// contextStreamAt is a contextStream with random access.
+type contextStreamAt struct {
+	*contextStream
+	readerAt io.ReaderAt
+}
+
+// ReadAt reads data at the offset.
+func (csa *contextStreamAt) ReadAt(p []byte, off int64) (n int, err error) {
+	err = csa.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	csa.progress.offset = off
+	return csa.readerAt.ReadAt(p, off)
+}
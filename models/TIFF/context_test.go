@@ -0,0 +1,118 @@
+package tiff
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// chainedIFDs returns a big endian file with a chain of IFDs at the offsets.
+// Each IFD has a single ImageWidth entry. The last IFD refers to the offset
+// of the next IFD, so the chain may be looped.
+func chainedIFDs(offsets []uint32, next uint32) (data []byte) {
+	data = []byte("MM\x00\x2A")
+	data = binary.BigEndian.AppendUint32(data, offsets[0])
+	for j, offset := range offsets {
+		data = append(data, make([]byte, int(offset)-len(data))...)
+		data = append(data, 0, 1, 0x01, 0x00, 0, 3, 0, 0, 0, 1, 0, 16, 0, 0)
+		if j+1 < len(offsets) {
+			data = binary.BigEndian.AppendUint32(data, offsets[j+1])
+		} else {
+			data = binary.BigEndian.AppendUint32(data, next)
+		}
+	}
+
+	return data
+}
+
+// cancellingReader cancels the context when a read starts at the offset.
+type cancellingReader struct {
+	*bytes.Reader
+	cancel  context.CancelFunc
+	trigger int64
+}
+
+func (cr *cancellingReader) Read(p []byte) (n int, err error) {
+	position, _ := cr.Reader.Seek(0, io.SeekCurrent)
+	if position == cr.trigger {
+		cr.cancel()
+	}
+
+	return cr.Reader.Read(p)
+}
+
+func (cr *cancellingReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off == cr.trigger {
+		cr.cancel()
+	}
+
+	return cr.Reader.ReadAt(p, off)
+}
+
+func TestNewWithContext(t *testing.T) {
+	data := chainedIFDs([]uint32{8, 26}, 0)
+
+	tf, err := NewWithContext(context.Background(), bytes.NewReader(data), Options{MaxIFDs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.IFDs()) != 2 {
+		t.Errorf("%v IFDs are read", len(tf.IFDs()))
+	}
+}
+
+func TestNewWithContext_Cancel(t *testing.T) {
+	tests := []struct {
+		trigger  int64
+		ifdIndex int
+	}{
+		// Parsing is cancelled in the header, and in the second IFD.
+		{trigger: 0, ifdIndex: -1},
+		{trigger: 26, ifdIndex: 1},
+	}
+	for _, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := &cancellingReader{
+			Reader:  bytes.NewReader(chainedIFDs([]uint32{8, 26}, 0)),
+			cancel:  cancel,
+			trigger: test.trigger,
+		}
+
+		tf, err := NewWithContext(ctx, stream, Options{})
+		cancel()
+		if tf != nil {
+			t.Errorf("trigger %v: file is parsed", test.trigger)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("trigger %v: error is %v", test.trigger, err)
+		}
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("trigger %v: error is not a ParseError: %v", test.trigger, err)
+			continue
+		}
+		if (pe.IFDIndex != test.ifdIndex) || (pe.Offset != test.trigger) {
+			t.Errorf("trigger %v: parsing is stopped in IFD #%v at offset %v", test.trigger, pe.IFDIndex, pe.Offset)
+		}
+	}
+}
+
+func TestNewWithContext_MaxIFDs(t *testing.T) {
+	// The second IFD refers to the first one.
+	data := chainedIFDs([]uint32{8, 26}, 8)
+
+	_, err := NewWithContext(context.Background(), bytes.NewReader(data), Options{MaxIFDs: 5})
+	if (err == nil) || (err.Error() != fmt.Sprintf(ErrTooManyIFDs, 5)) {
+		t.Errorf("looped chain is read with error %v", err)
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		t.Errorf("structural error is wrapped: %v", pe)
+	}
+}
@@ -0,0 +1,264 @@
+package tiff
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// PathSeparator separates elements of paths of directories and entries.
+const PathSeparator = "/"
+
+// IFDPathPrefix is the prefix of names of IFDs in paths. The prefix is
+// followed by the index of the IFD, e.g. 'IFD0'.
+const IFDPathPrefix = "IFD"
+
+// SubIFDIndexSeparator separates the name of a SubIFD and its index in the
+// chain of SubIFDs.
+const SubIFDIndexSeparator = "#"
+
+// Short names of SubIFDs used in paths.
+var subIFDAliases = map[tag.Tag]string{
+	tag.ExifIFD:             "Exif",
+	tag.GPSIFD:              "GPS",
+	tag.InteroperabilityIFD: "Interop",
+	tag.SubIFDs:             "SubIFD",
+	tag.GlobalParametersIFD: "GlobalParameters",
+}
+
+// Directory is an IFD or a SubIFD together with its path.
+//
+// Paths of IFDs are 'IFD0', 'IFD1' and so on. SubIFDs are named after the
+// tags which refer to them, e.g. 'IFD0/Exif' or 'IFD0/GPS'. SubIFDs after the
+// first one, either chained or referred by multiple offsets of the entry,
+// have an index, e.g. 'IFD0/SubIFD#1'. Paths of entries
+// are paths of their directories followed by the names of their tags, e.g.
+// 'IFD0/Exif/FNumber'. Unknown tags are named by their numbers.
+type Directory struct {
+	// Path is the path of the directory.
+	Path string
+
+	// Offset is the position of the directory in the file.
+	Offset uint32
+
+	// OffsetOfNext is the offset of the next directory in the chain.
+	OffsetOfNext uint32
+
+	// Entries are directory entries in the order of their appearance.
+	Entries []*ifd.DirectoryEntry
+
+	// EntriesByTagNumber are directory entries by their tags.
+	EntriesByTagNumber map[tag.Tag]*ifd.DirectoryEntry
+
+	// Parent is the entry which refers to the SubIFD. It is nil for IFDs.
+	Parent *ifd.DirectoryEntry
+
+	// Children are SubIFDs referred by entries of the directory.
+	Children []*Directory
+}
+
+// Directories returns the tree of IFDs and their SubIFDs.
+func (t *TIFF) Directories() (dirs []*Directory) {
+	dirs = make([]*Directory, 0, len(t.ifds))
+
+	offset := t.header.OffsetOfFirstIFD
+	for idx, i := range t.ifds {
+		d := &Directory{
+			Path:               IFDPathPrefix + strconv.Itoa(idx),
+			Offset:             offset,
+			OffsetOfNext:       i.OffsetOfNextIFD,
+			Entries:            i.DirectoryEntries,
+			EntriesByTagNumber: i.DirectoryEntriesByTagNumber,
+		}
+		d.Children = subDirectories(d)
+		dirs = append(dirs, d)
+
+		offset = i.OffsetOfNextIFD
+	}
+
+	return dirs
+}
+
+// WalkDirectories calls the function for each directory of the tree, parents
+// before children. Walking stops at the first error.
+func (t *TIFF) WalkDirectories(fn func(d *Directory) error) (err error) {
+	return walkDirectories(t.Directories(), fn)
+}
+
+// walkDirectories walks the directories recursively.
+func walkDirectories(dirs []*Directory, fn func(d *Directory) error) (err error) {
+	for _, d := range dirs {
+		err = fn(d)
+		if err != nil {
+			return err
+		}
+
+		err = walkDirectories(d.Children, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subDirectories returns SubIFDs referred by entries of the directory.
+func subDirectories(parent *Directory) (dirs []*Directory) {
+	for _, de := range parent.Entries {
+		offsets := de.SubIFDOffsets()
+		for idx, si := range de.SubIFDs {
+			var offset uint32
+			if idx < len(offsets) {
+				offset = offsets[idx]
+			}
+
+			d := &Directory{
+				Path:               SubIFDPath(parent.Path, de.Tag, idx),
+				Offset:             offset,
+				OffsetOfNext:       si.OffsetOfNextSubIFD,
+				Entries:            si.DirectoryEntries,
+				EntriesByTagNumber: si.DirectoryEntriesByTagNumber,
+				Parent:             de,
+			}
+			d.Children = subDirectories(d)
+			dirs = append(dirs, d)
+		}
+	}
+
+	return dirs
+}
+
+// SubIFDPath returns the path of the SubIFD with the index in the list of
+// SubIFDs of the tag.
+func SubIFDPath(parentPath string, tg tag.Tag, idx int) string {
+	name := SubIFDName(tg)
+	if idx > 0 {
+		name = fmt.Sprintf("%s%s%d", name, SubIFDIndexSeparator, idx)
+	}
+
+	return parentPath + PathSeparator + name
+}
+
+// EntryPath returns the path of the entry of the directory.
+func (d *Directory) EntryPath(de *ifd.DirectoryEntry) string {
+	return d.Path + PathSeparator + EntryName(de.Tag)
+}
+
+// SubIFDName returns the name of a SubIFD referred by the tag.
+func SubIFDName(tg tag.Tag) string {
+	alias, ok := subIFDAliases[tg]
+	if ok {
+		return alias
+	}
+
+	return EntryName(tg)
+}
+
+// EntryName returns the name of the tag used in paths.
+func EntryName(tg tag.Tag) string {
+	name, ok := tag.HumanReadableTagNames()[tg]
+	if ok {
+		return name
+	}
+
+	return strconv.Itoa(int(tg))
+}
+
+// TagByEntryName returns the tag named in a path. Names of tags, short names
+// of SubIFDs and numbers of tags are supported.
+func TagByEntryName(name string) (tg tag.Tag, ok bool) {
+	for subIFDTag, alias := range subIFDAliases {
+		if alias == name {
+			return subIFDTag, true
+		}
+	}
+
+	tg, ok = tag.ByName(name)
+	if ok {
+		return tg, true
+	}
+
+	n, err := strconv.ParseUint(name, 10, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return tag.Tag(n), true
+}
+
+// NormalizePathPattern converts a user-friendly path pattern into the form
+// used by MatchPath. Patterns which do not start with an IFD are related to
+// the first IFD, e.g. 'GPS/*' means 'IFD0/GPS/*'. Names of SubIFD tags may
+// be used instead of their short names, e.g. 'ExifIFD' means 'Exif'.
+func NormalizePathPattern(pattern string) string {
+	parts := strings.Split(strings.Trim(pattern, PathSeparator), PathSeparator)
+
+	for j, part := range parts {
+		tg, ok := tag.ByName(part)
+		if ok {
+			alias, isSubIFD := subIFDAliases[tg]
+			if isSubIFD {
+				parts[j] = alias
+			}
+		}
+	}
+
+	if !strings.HasPrefix(parts[0], IFDPathPrefix) || !isIFDName(parts[0]) {
+		parts = append([]string{IFDPathPrefix + "0"}, parts...)
+	}
+
+	return strings.Join(parts, PathSeparator)
+}
+
+// isIFDName tells whether the path element is a name of an IFD or a pattern
+// of it.
+func isIFDName(s string) bool {
+	suffix := strings.TrimPrefix(s, IFDPathPrefix)
+	if suffix == "*" {
+		return true
+	}
+
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// MatchPath tells whether the path matches the pattern. Each element of the
+// pattern is matched with path.Match, so '*' matches any single element. The
+// pattern matches a directory and everything inside it as well.
+func MatchPath(pattern string, p string) bool {
+	patternParts := strings.Split(NormalizePathPattern(pattern), PathSeparator)
+	pathParts := strings.Split(p, PathSeparator)
+
+	if len(pathParts) < len(patternParts) {
+		return false
+	}
+
+	for j, pp := range patternParts {
+		ok, err := path.Match(pp, pathParts[j])
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchAnyPath tells whether the path matches any of the patterns, see
+// MatchPath. Any path matches an empty list of patterns.
+func MatchAnyPath(patterns []string, p string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if MatchPath(pattern, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,200 @@
+package tiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	hdr "github.com/vault-thirteen/TIFFer/models/Header"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	mn "github.com/vault-thirteen/TIFFer/models/MagicNumber"
+)
+
+const (
+	ErrJSONByteOrderIsNotValid = "byte order is not valid: %v"
+	ErrJSONIFDIsNull           = "IFD #%v is null"
+)
+
+// JSONOptions are settings of MarshalJSONWithOptions.
+type JSONOptions struct {
+	// Filter selects entries to write by their paths, see Directory. Entries
+	// which refer to SubIFDs are written when any entry of their SubIFDs is
+	// written. All entries are written when the filter is not set.
+	Filter func(entryPath string, de *ifd.DirectoryEntry) bool
+
+	// MaxBytes is the maximum number of bytes written for values of BYTE,
+	// ASCII and UNDEFINED types. If it is not positive, only the limit of
+	// ifd.MaxJSONBlobSize is used.
+	MaxBytes int
+
+	// MaxValues is the maximum number of data items written for values of
+	// other types. If it is not positive, the number is not limited.
+	MaxValues int
+}
+
+// jsonTIFF is the JSON representation of the TIFF object.
+type jsonTIFF struct {
+	ByteOrder        string             `json:"byteOrder"`
+	MagicNumber      mn.MagicNumber     `json:"magicNumber"`
+	OffsetOfFirstIFD models.OffsetOfIFD `json:"offsetOfFirstIFD"`
+	IFDs             []*ifd.IFD         `json:"ifds"`
+}
+
+// MarshalJSON writes the TIFF object as the following JSON object.
+//
+//	{
+//	  "byteOrder": "II",          // 'II' or 'MM'.
+//	  "magicNumber": 42,
+//	  "offsetOfFirstIFD": 1238,
+//	  "ifds": [
+//	    {
+//	      "numberOfDirectoryEntries": 23,
+//	      "entries": [ ... ],     // See ifd.DirectoryEntry.MarshalJSON.
+//	      "offsetOfNext": 0
+//	    }
+//	  ]
+//	}
+//
+// SubIFDs are written inside the entries which refer to them.
+func (t *TIFF) MarshalJSON() (data []byte, err error) {
+	return json.Marshal(&jsonTIFF{
+		ByteOrder:        t.header.ByteOrder.String(),
+		MagicNumber:      t.header.MagicNumber,
+		OffsetOfFirstIFD: t.header.OffsetOfFirstIFD,
+		IFDs:             t.ifds,
+	})
+}
+
+// MarshalJSONWithOptions writes the TIFF object in the same way as
+// MarshalJSON does, but writes only the entries selected by the options and
+// cuts their values to the limits of the options. IFDs are always written,
+// so that their indices are kept.
+func (t *TIFF) MarshalJSONWithOptions(opts JSONOptions) (data []byte, err error) {
+	ifds := make([]*ifd.IFD, 0, len(t.ifds))
+	for idx, i := range t.ifds {
+		c := *i
+		c.DirectoryEntries = opts.entries(IFDPathPrefix+strconv.Itoa(idx), i.DirectoryEntries)
+		ifds = append(ifds, &c)
+	}
+
+	return json.Marshal(&jsonTIFF{
+		ByteOrder:        t.header.ByteOrder.String(),
+		MagicNumber:      t.header.MagicNumber,
+		OffsetOfFirstIFD: t.header.OffsetOfFirstIFD,
+		IFDs:             ifds,
+	})
+}
+
+// entries returns copies of the entries of the directory which pass the
+// filter. Values of the copies are cut to the limits.
+func (opts *JSONOptions) entries(dirPath string, entries []*ifd.DirectoryEntry) (copies []*ifd.DirectoryEntry) {
+	copies = make([]*ifd.DirectoryEntry, 0, len(entries))
+	for _, de := range entries {
+		c := *de
+		c.Value = opts.cut(de)
+
+		hasSubEntries := false
+		if len(de.SubIFDs) > 0 {
+			c.SubIFDs = make([]*ifd.SubIFD, 0, len(de.SubIFDs))
+			for idx, si := range de.SubIFDs {
+				sc := *si
+				sc.DirectoryEntries = opts.entries(SubIFDPath(dirPath, de.Tag, idx), si.DirectoryEntries)
+				hasSubEntries = hasSubEntries || (len(sc.DirectoryEntries) > 0)
+				c.SubIFDs = append(c.SubIFDs, &sc)
+			}
+			c.SubIFD = c.SubIFDs[0]
+		}
+
+		entryPath := dirPath + PathSeparator + EntryName(de.Tag)
+		if (opts.Filter != nil) && !hasSubEntries && !opts.Filter(entryPath, de) {
+			continue
+		}
+		copies = append(copies, &c)
+	}
+
+	return copies
+}
+
+// cut returns the value of the entry cut to the limits.
+func (opts *JSONOptions) cut(de *ifd.DirectoryEntry) (value any) {
+	switch v := de.Value.(type) {
+	case []byte:
+		return head(v, opts.MaxBytes)
+	case []int8:
+		return head(v, opts.MaxValues)
+	case []uint16:
+		return head(v, opts.MaxValues)
+	case []int16:
+		return head(v, opts.MaxValues)
+	case []uint32:
+		return head(v, opts.MaxValues)
+	case []int32:
+		return head(v, opts.MaxValues)
+	case []float32:
+		return head(v, opts.MaxValues)
+	case []float64:
+		return head(v, opts.MaxValues)
+	case []*big.Rat:
+		return head(v, opts.MaxValues)
+	default:
+		return de.Value
+	}
+}
+
+// head returns at most n first items of the slice. If n is not positive, the
+// whole slice is returned.
+func head[T any](s []T, n int) []T {
+	if (n > 0) && (len(s) > n) {
+		return s[:n]
+	}
+
+	return s
+}
+
+// UnmarshalJSON rebuilds the TIFF object written by MarshalJSON. The object
+// does not refer to any file, so only the metadata is available.
+func (t *TIFF) UnmarshalJSON(data []byte) (err error) {
+	var jt jsonTIFF
+	err = json.Unmarshal(data, &jt)
+	if err != nil {
+		return err
+	}
+
+	var byteOrder bo.ByteOrder
+	switch jt.ByteOrder {
+	case bo.BigEndian.String():
+		byteOrder = bo.BigEndian
+	case bo.LittleEndian.String():
+		byteOrder = bo.LittleEndian
+	default:
+		return fmt.Errorf(ErrJSONByteOrderIsNotValid, jt.ByteOrder)
+	}
+
+	ifds := make([]*ifd.IFD, 0, len(jt.IFDs))
+	for idx, i := range jt.IFDs {
+		if i == nil {
+			return fmt.Errorf(ErrJSONIFDIsNull, idx)
+		}
+		if idx > 0 {
+			ifds[idx-1].NextIFD = i
+		}
+		ifds = append(ifds, i)
+	}
+
+	*t = TIFF{
+		header: &hdr.Header{
+			ByteOrder:        byteOrder,
+			MagicNumber:      jt.MagicNumber,
+			OffsetOfFirstIFD: jt.OffsetOfFirstIFD,
+		},
+		ifds: ifds,
+	}
+	if len(ifds) > 0 {
+		t.header.FirstIFD = ifds[0]
+	}
+
+	return nil
+}
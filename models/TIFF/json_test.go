@@ -0,0 +1,148 @@
+package tiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+)
+
+// parseTestFile parses the sample TIFF file of the repository.
+func parseTestFile(t *testing.T) (tf *TIFF) {
+	t.Helper()
+
+	data, err := os.ReadFile("../../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err = New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tf
+}
+
+// entriesByPath returns all entries of the file by their paths.
+func entriesByPath(tf *TIFF) (entries map[string]*ifd.DirectoryEntry) {
+	entries = make(map[string]*ifd.DirectoryEntry)
+	_ = tf.WalkDirectories(func(d *Directory) error {
+		for _, de := range d.Entries {
+			entries[d.EntryPath(de)] = de
+		}
+		return nil
+	})
+
+	return entries
+}
+
+func TestTIFF_JSONRoundTrip(t *testing.T) {
+	tf := parseTestFile(t)
+
+	data, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restored TIFF
+	err = json.Unmarshal(data, &restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (restored.Header().ByteOrder != tf.Header().ByteOrder) ||
+		(restored.Header().MagicNumber != tf.Header().MagicNumber) ||
+		(restored.Header().OffsetOfFirstIFD != tf.Header().OffsetOfFirstIFD) {
+		t.Errorf("header is %+v instead of %+v", restored.Header(), tf.Header())
+	}
+
+	original := entriesByPath(tf)
+	restoredEntries := entriesByPath(&restored)
+	if len(restoredEntries) != len(original) {
+		t.Errorf("%v entries are restored instead of %v", len(restoredEntries), len(original))
+	}
+	for p, de := range original {
+		re, ok := restoredEntries[p]
+		if !ok {
+			t.Errorf("%v is not restored", p)
+			continue
+		}
+		if (re.Type != de.Type) || (re.Count != de.Count) || (re.Offset != de.Offset) ||
+			(re.HasFastValue() != de.HasFastValue()) || (re.TagName != de.TagName) {
+			t.Errorf("%v is restored as %+v instead of %+v", p, re, de)
+		}
+
+		rawA, errA := ifd.EncodeValue(de.Type, de.Value, bo.LittleEndian)
+		rawB, errB := ifd.EncodeValue(re.Type, re.Value, bo.LittleEndian)
+		if (errA != nil) || (errB != nil) || !bytes.Equal(rawA, rawB) {
+			t.Errorf("%v has value %v instead of %v", p, re.Value, de.Value)
+		}
+	}
+
+	// The restored model is written in the same way.
+	var again []byte
+	again, err = json.Marshal(&restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Error("restored model is written differently")
+	}
+}
+
+func TestTIFF_MarshalJSONWithOptions(t *testing.T) {
+	tf := parseTestFile(t)
+
+	data, err := tf.MarshalJSONWithOptions(JSONOptions{
+		Filter: func(entryPath string, _ *ifd.DirectoryEntry) bool {
+			return MatchPath("GPS/GPSLatitude", entryPath) || (entryPath == "IFD0/ICCProfile")
+		},
+		MaxBytes:  4,
+		MaxValues: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restored TIFF
+	err = json.Unmarshal(data, &restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := entriesByPath(&restored)
+	want := []string{"IFD0/ICCProfile", "IFD0/GPSIFD", "IFD0/GPS/GPSLatitude"}
+	if len(entries) != len(want) {
+		t.Errorf("entries %v are written", entries)
+	}
+	for _, p := range want {
+		if entries[p] == nil {
+			t.Errorf("%v is not written", p)
+		}
+	}
+
+	if latitude := entries["IFD0/GPS/GPSLatitude"]; latitude != nil {
+		rationals, _ := latitude.ValueAsArrayOfRational()
+		if (len(rationals) != 2) || (latitude.Count != 3) {
+			t.Errorf("latitude is written as %v of %v", rationals, latitude.Count)
+		}
+	}
+	if profile := entries["IFD0/ICCProfile"]; profile != nil {
+		if ba, _ := profile.ValueAsArrayOfUndefined(); len(ba) != 4 {
+			t.Errorf("profile is written with %v bytes", len(ba))
+		}
+	}
+
+	// The model itself is not changed.
+	if gps := entriesByPath(tf)["IFD0/GPS/GPSLatitude"]; len(gps.Value.([]*big.Rat)) != 3 {
+		t.Error("value of the model is cut")
+	}
+	if !bytes.Contains(data, []byte(`"truncated":true`)) {
+		t.Error("cut values are not marked as truncated")
+	}
+}
@@ -0,0 +1,543 @@
+package tiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	ErrPreviewFormatIsUnsupported = "preview format is not supported: %v"
+	ErrPreviewLayoutIsUnsupported = "preview layout is not supported: %v"
+	ErrPreviewSizeIsUnknown       = "size of the preview is unknown"
+	ErrPreviewSizeIsTooBig        = "preview is too big: %v×%v"
+	ErrPreviewHasNoData           = "preview has no data"
+	ErrPreviewBlockIsTruncated    = "block #%v of the preview is truncated"
+)
+
+// Formats of previews.
+const (
+	PreviewFormatJPEG         = "JPEG"
+	PreviewFormatUncompressed = "Uncompressed"
+	PreviewFormatUnsupported  = "Unsupported"
+)
+
+// Sources of previews found in directories. Other sources are added by
+// preview finders.
+const (
+	// PreviewSourceJPEGInterchangeFormat is a JPEG stream referred by the
+	// JPEGInterchangeFormat and JPEGInterchangeFormatLength tags, e.g. the
+	// EXIF thumbnail of IFD1.
+	PreviewSourceJPEGInterchangeFormat = "JPEGInterchangeFormat"
+
+	// PreviewSourceImageData is the image data of a reduced-resolution
+	// directory, stored in strips or tiles, e.g. the preview SubIFD of a DNG
+	// file.
+	PreviewSourceImageData = "ImageData"
+)
+
+// Values of the NewSubfileType tag, which are flags.
+const (
+	NewSubfileTypeReducedResolution = 1
+	NewSubfileTypePage              = 2
+	NewSubfileTypeMask              = 4
+)
+
+// SubfileTypeReducedResolution is the value of the obsolete SubfileType tag
+// of reduced-resolution images.
+const SubfileTypeReducedResolution = 2
+
+// Values of the Compression tag of previews.
+const (
+	CompressionNone      = 1
+	CompressionJPEG      = 7
+	CompressionLossyJPEG = 34892
+)
+
+// Values of the PhotometricInterpretation tag of previews.
+const (
+	PhotometricWhiteIsZero = 0
+	PhotometricBlackIsZero = 1
+	PhotometricRGB         = 2
+)
+
+// MaxPreviewPixels is the maximal number of pixels of a preview which is
+// decoded from strips or tiles.
+const MaxPreviewPixels = 1 << 28
+
+// jpegMarkerSize is the size of SOI and EOI markers of JPEG streams.
+const jpegMarkerSize = 2
+
+// Preview is an embedded preview or thumbnail of the image.
+//
+// The data of the preview is located in the file by its offsets and byte
+// counts, previews which are stored inside values of tags also have their
+// data in memory.
+type Preview struct {
+	// Path is the path of the directory or of the entry storing the
+	// preview, e.g. 'IFD1', 'IFD0/SubIFD#1' or 'IFD0/Photoshop'.
+	Path string
+
+	// Source tells how the preview is stored, e.g.
+	// PreviewSourceJPEGInterchangeFormat.
+	Source string
+
+	// Format is the format of the data, e.g. PreviewFormatJPEG.
+	Format string
+
+	// Width and Height are the size of the preview in pixels. They are zero
+	// when the size is not written outside the data, e.g. for EXIF
+	// thumbnails. The size is then read by the DecodeConfig method.
+	Width  int
+	Height int
+
+	// ColorSpace is the value of the PreviewColorSpace tag of DNG previews,
+	// or zero.
+	ColorSpace int
+
+	// Offsets and ByteCounts are positions and sizes of blocks of data in the
+	// file: of strips, of tiles or of a single JPEG stream.
+	Offsets    []int64
+	ByteCounts []int64
+
+	// IsTiled flag is set when the blocks are tiles. BlockWidth and
+	// BlockLength are the size of a block in pixels.
+	IsTiled     bool
+	BlockWidth  int
+	BlockLength int
+
+	// Data is the data of the preview when it is stored inside the value of
+	// a tag. Data is then read from memory rather than from the file.
+	Data []byte
+
+	// jpegTables are the JPEGTables shared by JPEG streams of blocks.
+	jpegTables []byte
+
+	// Layout of uncompressed samples.
+	photometric     int
+	samplesPerPixel int
+	bitsPerSample   []int64
+	planar          int
+}
+
+// PreviewFinder lists previews of the TIFF which are stored in a way not
+// known to this package, e.g. in Photoshop image resources.
+type PreviewFinder func(t *TIFF) (previews []*Preview)
+
+// Previews lists embedded previews and thumbnails of the TIFF.
+//
+// A directory is a preview when its NewSubfileType, or the obsolete
+// SubfileType, says that it is a reduced-resolution image, unless it is a
+// transparency mask. IFD1 without the NewSubfileType tag is a preview when
+// the file has EXIF data or when IFD1 has a JPEG stream, as EXIF thumbnails
+// are written this way. The JPEG stream of the JPEGInterchangeFormat tag is
+// preferred to strips and tiles of the same directory. Previews found by the
+// finders, e.g. thumbnails of Photoshop image resources, follow previews of
+// directories. All known previews are listed by the 'previews' package.
+func (t *TIFF) Previews(finders ...PreviewFinder) (previews []*Preview) {
+	previews = make([]*Preview, 0)
+
+	dirs := t.Directories()
+	hasExif := false
+	if len(dirs) > 0 {
+		_, hasExif = dirs[0].EntriesByTagNumber[tag.ExifIFD]
+	}
+
+	_ = walkDirectories(dirs, func(d *Directory) error {
+		if isPreviewDirectory(d, hasExif) {
+			p := newDirectoryPreview(d)
+			if p != nil {
+				previews = append(previews, p)
+			}
+		}
+
+		return nil
+	})
+
+	for _, finder := range finders {
+		previews = append(previews, finder(t)...)
+	}
+
+	return previews
+}
+
+// isPreviewDirectory tells whether the directory stores a preview.
+func isPreviewDirectory(d *Directory, hasExif bool) bool {
+	if nst, ok := directoryInteger(d, tag.NewSubfileType); ok {
+		return (nst&NewSubfileTypeReducedResolution != 0) && (nst&NewSubfileTypeMask == 0)
+	}
+	if st, ok := directoryInteger(d, tag.SubfileType); ok {
+		return st == SubfileTypeReducedResolution
+	}
+
+	_, hasJPEG := d.EntriesByTagNumber[tag.JPEGInterchangeFormat]
+	return (d.Path == IFDPathPrefix+"1") && (hasExif || hasJPEG)
+}
+
+// newDirectoryPreview describes the preview stored in the directory, or
+// returns nil when the directory has no image data.
+func newDirectoryPreview(d *Directory) (p *Preview) {
+	p = &Preview{
+		Path:            d.Path,
+		samplesPerPixel: 1,
+		bitsPerSample:   []int64{1},
+		planar:          1,
+	}
+
+	if v, ok := directoryInteger(d, tag.ImageWidth); ok {
+		p.Width = int(v)
+	}
+	if v, ok := directoryInteger(d, tag.ImageLength); ok {
+		p.Height = int(v)
+	}
+	if v, ok := directoryInteger(d, tag.PreviewColorSpace); ok {
+		p.ColorSpace = int(v)
+	}
+
+	offset, ok1 := directoryInteger(d, tag.JPEGInterchangeFormat)
+	length, ok2 := directoryInteger(d, tag.JPEGInterchangeFormatLength)
+	if ok1 && ok2 {
+		p.Source = PreviewSourceJPEGInterchangeFormat
+		p.Format = PreviewFormatJPEG
+		p.Offsets = []int64{offset}
+		p.ByteCounts = []int64{length}
+		return p
+	}
+
+	p.Source = PreviewSourceImageData
+	if p.Offsets = directoryIntegers(d, tag.StripOffsets); p.Offsets != nil {
+		p.ByteCounts = directoryIntegers(d, tag.StripByteCounts)
+		p.BlockWidth = p.Width
+		p.BlockLength = p.Height
+		if v, ok := directoryInteger(d, tag.RowsPerStrip); ok && (int(v) < p.Height) {
+			p.BlockLength = int(v)
+		}
+	} else if p.Offsets = directoryIntegers(d, tag.TileOffsets); p.Offsets != nil {
+		p.ByteCounts = directoryIntegers(d, tag.TileByteCounts)
+		p.IsTiled = true
+		if v, ok := directoryInteger(d, tag.TileWidth); ok {
+			p.BlockWidth = int(v)
+		}
+		if v, ok := directoryInteger(d, tag.TileLength); ok {
+			p.BlockLength = int(v)
+		}
+	} else {
+		return nil
+	}
+	if len(p.ByteCounts) != len(p.Offsets) {
+		return nil
+	}
+
+	compression := int64(CompressionNone)
+	if v, ok := directoryInteger(d, tag.Compression); ok {
+		compression = v
+	}
+	switch compression {
+	case CompressionNone:
+		p.Format = PreviewFormatUncompressed
+	case CompressionJPEG, CompressionLossyJPEG:
+		p.Format = PreviewFormatJPEG
+	default:
+		p.Format = PreviewFormatUnsupported
+	}
+
+	if de, ok := d.EntriesByTagNumber[tag.JPEGTables]; ok {
+		p.jpegTables, _ = de.ValueAsArrayOfUndefined()
+	}
+	if v, ok := directoryInteger(d, tag.PhotometricInterpretation); ok {
+		p.photometric = int(v)
+	}
+	if v, ok := directoryInteger(d, tag.SamplesPerPixel); ok {
+		p.samplesPerPixel = int(v)
+	}
+	if v := directoryIntegers(d, tag.BitsPerSample); v != nil {
+		p.bitsPerSample = v
+	}
+	if v, ok := directoryInteger(d, tag.PlanarConfiguration); ok {
+		p.planar = int(v)
+	}
+
+	return p
+}
+
+// Size returns the size of the data of the preview in bytes.
+func (p *Preview) Size() (size int64) {
+	if p.Data != nil {
+		return int64(len(p.Data))
+	}
+
+	for _, bc := range p.ByteCounts {
+		size += bc
+	}
+
+	return size
+}
+
+// Open returns a reader of the stored data of the preview. A JPEG preview
+// stored as a single block is read as a complete JPEG stream, with the
+// shared JPEGTables inserted when they exist. Data of other previews is read
+// as it is stored, block after block.
+func (p *Preview) Open(file io.ReaderAt) (r io.Reader, err error) {
+	if p.Data != nil {
+		return bytes.NewReader(p.Data), nil
+	}
+	if len(p.Offsets) == 0 {
+		return nil, errors.New(ErrPreviewHasNoData)
+	}
+
+	if (p.Format == PreviewFormatJPEG) && (len(p.Offsets) == 1) {
+		return p.openBlock(file, 0), nil
+	}
+
+	readers := make([]io.Reader, 0, len(p.Offsets))
+	for j := range p.Offsets {
+		readers = append(readers, io.NewSectionReader(file, p.Offsets[j], p.ByteCounts[j]))
+	}
+
+	return io.MultiReader(readers...), nil
+}
+
+// openBlock returns a reader of the block. JPEG tables are inserted into
+// abbreviated JPEG streams: the stream of the tables without its EOI marker
+// is followed by the stream of the block without its SOI marker.
+func (p *Preview) openBlock(file io.ReaderAt, j int) (r io.Reader) {
+	block := io.NewSectionReader(file, p.Offsets[j], p.ByteCounts[j])
+	if (p.Format != PreviewFormatJPEG) || (len(p.jpegTables) <= 2*jpegMarkerSize) ||
+		(p.ByteCounts[j] < jpegMarkerSize) {
+		return block
+	}
+
+	tables := p.jpegTables[:len(p.jpegTables)-jpegMarkerSize]
+	return io.MultiReader(bytes.NewReader(tables), io.NewSectionReader(block, jpegMarkerSize, p.ByteCounts[j]-jpegMarkerSize))
+}
+
+// WriteJPEG writes the preview as a JPEG stream. A JPEG preview stored as a
+// single block is copied as it is, other previews are decoded and encoded
+// with the default quality.
+func (p *Preview) WriteJPEG(file io.ReaderAt, w io.Writer) (err error) {
+	if (p.Format == PreviewFormatJPEG) && ((p.Data != nil) || (len(p.Offsets) == 1)) {
+		var r io.Reader
+		r, err = p.Open(file)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, r)
+		return err
+	}
+
+	var img image.Image
+	img, err = p.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	return jpeg.Encode(w, img, nil)
+}
+
+// DecodeConfig returns the colour model and the size of the preview. The
+// header of the JPEG stream is read when the preview is a single JPEG
+// stream.
+func (p *Preview) DecodeConfig(file io.ReaderAt) (cfg image.Config, err error) {
+	if (p.Format == PreviewFormatJPEG) && ((p.Data != nil) || (len(p.Offsets) == 1)) {
+		var r io.Reader
+		r, err = p.Open(file)
+		if err != nil {
+			return cfg, err
+		}
+
+		return jpeg.DecodeConfig(r)
+	}
+
+	err = p.checkSize()
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg = image.Config{ColorModel: color.RGBAModel, Width: p.Width, Height: p.Height}
+	if (p.Format == PreviewFormatUncompressed) && (p.photometric != PhotometricRGB) {
+		cfg.ColorModel = color.GrayModel
+	}
+
+	return cfg, nil
+}
+
+// Decode decodes the preview. JPEG previews are decoded by the 'image/jpeg'
+// package, JPEG streams of strips and tiles are decoded separately and
+// assembled. Uncompressed previews having 8 bits per sample with chunky
+// samples are decoded as grey or RGB images, extra samples are ignored.
+func (p *Preview) Decode(file io.ReaderAt) (img image.Image, err error) {
+	switch p.Format {
+	case PreviewFormatJPEG:
+		if (p.Data != nil) || (len(p.Offsets) == 1) {
+			var r io.Reader
+			r, err = p.Open(file)
+			if err != nil {
+				return nil, err
+			}
+
+			return jpeg.Decode(r)
+		}
+
+		return p.decodeJPEGBlocks(file)
+
+	case PreviewFormatUncompressed:
+		return p.decodeUncompressed(file)
+
+	default:
+		return nil, fmt.Errorf(ErrPreviewFormatIsUnsupported, p.Format)
+	}
+}
+
+// decodeJPEGBlocks decodes JPEG streams of strips or tiles and draws them
+// into a single image.
+func (p *Preview) decodeJPEGBlocks(file io.ReaderAt) (img image.Image, err error) {
+	err = p.checkSize()
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, p.Width, p.Height))
+	for j := range p.Offsets {
+		var block image.Image
+		block, err = jpeg.Decode(p.openBlock(file, j))
+		if err != nil {
+			return nil, err
+		}
+
+		x0, y0 := p.blockOrigin(j)
+		r := block.Bounds().Sub(block.Bounds().Min).Add(image.Pt(x0, y0))
+		draw.Draw(dst, r, block, block.Bounds().Min, draw.Src)
+	}
+
+	return dst, nil
+}
+
+// decodeUncompressed decodes uncompressed 8-bit samples of strips or tiles.
+func (p *Preview) decodeUncompressed(file io.ReaderAt) (img image.Image, err error) {
+	err = p.checkSize()
+	if err != nil {
+		return nil, err
+	}
+
+	spp := p.samplesPerPixel
+	for _, bps := range p.bitsPerSample {
+		if bps != 8 {
+			return nil, fmt.Errorf(ErrPreviewLayoutIsUnsupported, p.bitsPerSample)
+		}
+	}
+	if (spp > 1) && (p.planar != 1) {
+		return nil, fmt.Errorf(ErrPreviewLayoutIsUnsupported, p.planar)
+	}
+
+	var set func(x, y int, pixel []byte)
+	switch {
+	case (p.photometric == PhotometricRGB) && (spp >= 3):
+		dst := image.NewRGBA(image.Rect(0, 0, p.Width, p.Height))
+		set = func(x, y int, pixel []byte) {
+			dst.SetRGBA(x, y, color.RGBA{R: pixel[0], G: pixel[1], B: pixel[2], A: 0xFF})
+		}
+		img = dst
+
+	case (p.photometric == PhotometricBlackIsZero) || (p.photometric == PhotometricWhiteIsZero):
+		dst := image.NewGray(image.Rect(0, 0, p.Width, p.Height))
+		invert := p.photometric == PhotometricWhiteIsZero
+		set = func(x, y int, pixel []byte) {
+			v := pixel[0]
+			if invert {
+				v = 0xFF - v
+			}
+			dst.SetGray(x, y, color.Gray{Y: v})
+		}
+		img = dst
+
+	default:
+		return nil, fmt.Errorf(ErrPreviewLayoutIsUnsupported, p.photometric)
+	}
+
+	for j := range p.Offsets {
+		x0, y0 := p.blockOrigin(j)
+		rows := min(p.BlockLength, p.Height-y0)
+		columns := min(p.BlockWidth, p.Width-x0)
+
+		// The last strip may be shorter than the others.
+		need := int64(((rows-1)*p.BlockWidth + columns) * spp)
+		if p.ByteCounts[j] < need {
+			return nil, fmt.Errorf(ErrPreviewBlockIsTruncated, j)
+		}
+
+		data := make([]byte, need)
+		_, err = file.ReadAt(data, p.Offsets[j])
+		if err != nil {
+			return nil, fmt.Errorf(ErrPreviewBlockIsTruncated, j)
+		}
+
+		for y := 0; y < rows; y++ {
+			for x := 0; x < columns; x++ {
+				pos := (y*p.BlockWidth + x) * spp
+				set(x0+x, y0+y, data[pos:pos+spp])
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// checkSize checks that the size of the preview and of its blocks is known
+// and that the preview is not too big to be decoded.
+func (p *Preview) checkSize() (err error) {
+	if (p.Width <= 0) || (p.Height <= 0) || (p.BlockWidth <= 0) || (p.BlockLength <= 0) {
+		return errors.New(ErrPreviewSizeIsUnknown)
+	}
+	if int64(p.Width)*int64(p.Height) > MaxPreviewPixels {
+		return fmt.Errorf(ErrPreviewSizeIsTooBig, p.Width, p.Height)
+	}
+	if len(p.Offsets) < p.blocks() {
+		return fmt.Errorf(ErrPreviewBlockIsTruncated, len(p.Offsets))
+	}
+
+	return nil
+}
+
+// blocks returns the number of blocks covering the preview.
+func (p *Preview) blocks() int {
+	across := (p.Width + p.BlockWidth - 1) / p.BlockWidth
+	down := (p.Height + p.BlockLength - 1) / p.BlockLength
+	return across * down
+}
+
+// blockOrigin returns the position of the top left pixel of the block.
+func (p *Preview) blockOrigin(j int) (x, y int) {
+	across := (p.Width + p.BlockWidth - 1) / p.BlockWidth
+	return (j % across) * p.BlockWidth, (j / across) * p.BlockLength
+}
+
+// directoryIntegers returns integer values of the tag of the directory, or
+// nil.
+func directoryIntegers(d *Directory, tg tag.Tag) (v []int64) {
+	de, ok := d.EntriesByTagNumber[tg]
+	if !ok {
+		return nil
+	}
+
+	v, _ = de.ValueAsArrayOfInteger()
+	return v
+}
+
+// directoryInteger returns the first integer value of the tag of the
+// directory.
+func directoryInteger(d *Directory, tg tag.Tag) (v int64, ok bool) {
+	values := directoryIntegers(d, tg)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	return values[0], true
+}
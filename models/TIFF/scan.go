@@ -0,0 +1,190 @@
+package tiff
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultScanHeadSize is the default size of the beginning of a file which is
+// read at once by the scanner. Meta-data of most files is stored there.
+const DefaultScanHeadSize = 64 * 1024
+
+// ScanOptions are settings of the batch scanner.
+type ScanOptions struct {
+	// Workers is the maximum number of files parsed concurrently. If it is
+	// not positive, the number of CPUs is used.
+	Workers int
+
+	// FileTimeout is the maximum duration of parsing of a single file. If it
+	// is not positive, files are parsed without a time limit.
+	FileTimeout time.Duration
+
+	// HeadSize is the size of the beginning of a file which is read at once
+	// into a reusable buffer. If it is not positive, DefaultScanHeadSize is
+	// used.
+	HeadSize int
+}
+
+// ScanResult is the result of parsing of a single file.
+type ScanResult struct {
+	// Path is the path of the file.
+	Path string
+
+	// TIFF is the parsed file. It is nil when an error has occurred.
+	TIFF *TIFF
+
+	// Err is the error which has occurred.
+	Err error
+}
+
+// ScanFiles parses the files concurrently using a bounded pool of workers.
+//
+// Results are sent to the returned channel in the order of completion. The
+// channel is closed when all the files are processed. When the context is
+// cancelled, files which have not been started yet are skipped, and files
+// being parsed are aborted with the context's error. The caller must read the
+// channel until it is closed.
+func ScanFiles(ctx context.Context, paths []string, opts ScanOptions) <-chan ScanResult {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.HeadSize <= 0 {
+		opts.HeadSize = DefaultScanHeadSize
+	}
+
+	results := make(chan ScanResult, opts.Workers)
+	jobs := make(chan string)
+
+	s := &scanner{
+		opts: opts,
+		buffers: sync.Pool{
+			New: func() any {
+				buf := make([]byte, opts.HeadSize)
+				return &buf
+			},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				results <- s.scanFile(ctx, path)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer close(jobs)
+
+		for _, path := range paths {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- path:
+			}
+		}
+	}()
+
+	return results
+}
+
+// openScanFile opens files of the batch scanner.
+var openScanFile = os.Open
+
+// scanner holds resources shared by workers of the batch scanner.
+type scanner struct {
+	opts    ScanOptions
+	buffers sync.Pool
+}
+
+// scanFile parses a single file.
+func (s *scanner) scanFile(ctx context.Context, path string) (result ScanResult) {
+	result.Path = path
+
+	if s.opts.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.FileTimeout)
+		defer cancel()
+	}
+
+	if ctx.Err() != nil {
+		result.Err = ctx.Err()
+		return result
+	}
+
+	f, err := openScanFile(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (result.Err == nil) {
+			result.TIFF, result.Err = nil, derr
+		}
+	}()
+
+	var fi os.FileInfo
+	fi, err = f.Stat()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	bufPtr := s.buffers.Get().(*[]byte)
+	defer s.buffers.Put(bufPtr)
+
+	var hra *headReaderAt
+	hra, err = newHeadReaderAt(f, fi.Size(), *bufPtr)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	stream := io.NewSectionReader(hra, 0, fi.Size())
+	result.TIFF, result.Err = NewWithContext(ctx, stream, Options{})
+
+	return result
+}
+
+// headReaderAt is a reader which serves reads of the beginning of a file from
+// a buffer and reads the rest of the file directly.
+type headReaderAt struct {
+	file io.ReaderAt
+	head []byte
+}
+
+// newHeadReaderAt reads the beginning of the file into the buffer.
+func newHeadReaderAt(file io.ReaderAt, size int64, buf []byte) (hra *headReaderAt, err error) {
+	headSize := min(int64(len(buf)), size)
+
+	var n int
+	n, err = file.ReadAt(buf[:headSize], 0)
+	if int64(n) < headSize {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return &headReaderAt{file: file, head: buf[:headSize]}, nil
+}
+
+// ReadAt reads len(p) bytes starting at the offset.
+func (hra *headReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if (off >= 0) && (off+int64(len(p)) <= int64(len(hra.head))) {
+		return copy(p, hra.head[off:]), nil
+	}
+
+	return hra.file.ReadAt(p, off)
+}
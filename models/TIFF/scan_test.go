@@ -0,0 +1,295 @@
+package tiff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// scanTimeout is the time after which a test of the scanner is failed.
+const scanTimeout = 10 * time.Second
+
+// writeScanFiles writes copies of the data into temporary files.
+func writeScanFiles(t *testing.T, data []byte, n int) (paths []string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for j := range n {
+		p := filepath.Join(dir, strconv.Itoa(j)+".tiff")
+		err := os.WriteFile(p, data, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+// readTestData reads the sample TIFF file of the repository.
+func readTestData(t *testing.T) (data []byte) {
+	t.Helper()
+
+	data, err := os.ReadFile("../../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// setOpenScanFile replaces the function which opens files of the scanner
+// until the end of the test.
+func setOpenScanFile(t *testing.T, open func(name string) (*os.File, error)) {
+	original := openScanFile
+	openScanFile = open
+	t.Cleanup(func() { openScanFile = original })
+}
+
+// gatedOpener holds opening of files until the gate is opened.
+type gatedOpener struct {
+	gate    chan struct{}
+	entered chan string
+
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func newGatedOpener(files int) (g *gatedOpener) {
+	return &gatedOpener{
+		gate:    make(chan struct{}),
+		entered: make(chan string, files),
+	}
+}
+
+func (g *gatedOpener) open(name string) (*os.File, error) {
+	g.mu.Lock()
+	g.active++
+	g.maxActive = max(g.maxActive, g.active)
+	g.mu.Unlock()
+
+	g.entered <- name
+	<-g.gate
+
+	g.mu.Lock()
+	g.active--
+	g.mu.Unlock()
+
+	return os.Open(name)
+}
+
+// waitEntered waits until n files are being opened.
+func (g *gatedOpener) waitEntered(t *testing.T, n int) {
+	t.Helper()
+
+	for range n {
+		select {
+		case <-g.entered:
+		case <-time.After(scanTimeout):
+			t.Fatal("file is not opened")
+		}
+	}
+}
+
+// collectResults reads the channel until it is closed.
+func collectResults(t *testing.T, results <-chan ScanResult) (all []ScanResult) {
+	t.Helper()
+
+	timeout := time.After(scanTimeout)
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return all
+			}
+			all = append(all, r)
+		case <-timeout:
+			t.Fatal("channel of results is not closed")
+		}
+	}
+}
+
+// marshalTIFF returns the model of the file in JSON format.
+func marshalTIFF(t *testing.T, tf *TIFF) (data []byte) {
+	t.Helper()
+
+	data, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestScanFiles_Workers(t *testing.T) {
+	const workers = 3
+
+	paths := writeScanFiles(t, readTestData(t), 8)
+	g := newGatedOpener(len(paths))
+	setOpenScanFile(t, g.open)
+
+	results := ScanFiles(context.Background(), paths, ScanOptions{Workers: workers})
+
+	// Other files are not started while the workers are busy.
+	g.waitEntered(t, workers)
+	select {
+	case name := <-g.entered:
+		t.Errorf("%v is opened by an extra worker", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(g.gate)
+
+	all := collectResults(t, results)
+	if len(all) != len(paths) {
+		t.Errorf("%v results are received instead of %v", len(all), len(paths))
+	}
+	for _, r := range all {
+		if (r.Err != nil) || (r.TIFF == nil) {
+			t.Errorf("%v: %v", r.Path, r.Err)
+		}
+	}
+	if g.maxActive != workers {
+		t.Errorf("%v files are opened concurrently instead of %v", g.maxActive, workers)
+	}
+}
+
+func TestScanFiles_Cancel(t *testing.T) {
+	const workers = 2
+
+	paths := writeScanFiles(t, readTestData(t), 8)
+	g := newGatedOpener(len(paths))
+	setOpenScanFile(t, g.open)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := ScanFiles(ctx, paths, ScanOptions{Workers: workers})
+
+	// Files being opened are aborted, other files are skipped.
+	g.waitEntered(t, workers)
+	cancel()
+	close(g.gate)
+
+	all := collectResults(t, results)
+	if len(all) > workers {
+		t.Errorf("%v files are processed after cancellation", len(all))
+	}
+	for _, r := range all {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%v is not aborted: %v", r.Path, r.Err)
+		}
+	}
+}
+
+func TestScanFiles_FileTimeout(t *testing.T) {
+	paths := writeScanFiles(t, readTestData(t), 3)
+	slow := paths[1]
+	setOpenScanFile(t, func(name string) (*os.File, error) {
+		if name == slow {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return os.Open(name)
+	})
+
+	opts := ScanOptions{Workers: 2, FileTimeout: 50 * time.Millisecond}
+	all := collectResults(t, ScanFiles(context.Background(), paths, opts))
+	if len(all) != len(paths) {
+		t.Fatalf("%v results are received instead of %v", len(all), len(paths))
+	}
+	for _, r := range all {
+		if r.Path == slow {
+			if !errors.Is(r.Err, context.DeadlineExceeded) || (r.TIFF != nil) {
+				t.Errorf("slow file is not aborted: %v", r.Err)
+			}
+		} else if r.Err != nil {
+			t.Errorf("%v: %v", r.Path, r.Err)
+		}
+	}
+}
+
+func TestScanFiles_HeadSize(t *testing.T) {
+	data := readTestData(t)
+	want := marshalTIFF(t, parseTestFile(t))
+
+	// The file is larger than the head buffer, so most of it is read
+	// directly.
+	paths := writeScanFiles(t, data, 1)
+	missing := filepath.Join(t.TempDir(), "missing.tiff")
+	paths = append(paths, missing)
+
+	for _, headSize := range []int{16, len(data) / 2, 0} {
+		all := collectResults(t, ScanFiles(context.Background(), paths, ScanOptions{HeadSize: headSize}))
+		if len(all) != len(paths) {
+			t.Fatalf("%v results are received instead of %v", len(all), len(paths))
+		}
+		for _, r := range all {
+			if r.Path == missing {
+				if !errors.Is(r.Err, os.ErrNotExist) {
+					t.Errorf("head size %v: missing file is scanned: %v", headSize, r.Err)
+				}
+				continue
+			}
+			if r.Err != nil {
+				t.Errorf("head size %v: %v", headSize, r.Err)
+				continue
+			}
+			if !bytes.Equal(marshalTIFF(t, r.TIFF), want) {
+				t.Errorf("head size %v: file is parsed differently", headSize)
+			}
+		}
+	}
+}
+
+func TestScanFiles_BuffersAreNotShared(t *testing.T) {
+	data := readTestData(t)
+	want := marshalTIFF(t, parseTestFile(t))
+
+	// The second file differs in text values stored in the head buffer.
+	other := bytes.Clone(data)
+	changed := false
+	for _, de := range entriesByPath(parseTestFile(t)) {
+		if (de.Type != typ.ASCII) || de.HasFastValue() || (de.Count < 2) {
+			continue
+		}
+		start := int(de.Offset)
+		for j := start; j < start+int(de.Count)-1; j++ {
+			other[j] = 'Z'
+		}
+		changed = true
+	}
+	if !changed {
+		t.Fatal("file has no text values")
+	}
+
+	paths := append(writeScanFiles(t, data, 1), writeScanFiles(t, other, 1)...)
+
+	// A single worker reuses its buffer for both files.
+	opts := ScanOptions{Workers: 1, HeadSize: len(data)}
+	all := collectResults(t, ScanFiles(context.Background(), paths, opts))
+	if len(all) != 2 {
+		t.Fatalf("%v results are received", len(all))
+	}
+	for _, r := range all {
+		if r.Err != nil {
+			t.Fatalf("%v: %v", r.Path, r.Err)
+		}
+	}
+
+	first := marshalTIFF(t, all[0].TIFF)
+	if !bytes.Equal(first, want) {
+		t.Error("first file is changed by the second one")
+	}
+	if bytes.Equal(marshalTIFF(t, all[1].TIFF), first) {
+		t.Error("second file is parsed as the first one")
+	}
+}
@@ -2,6 +2,7 @@ package tiff
 
 import (
 	"fmt"
+	"sync"
 	"unsafe"
 
 	"github.com/vault-thirteen/TIFFer/models"
@@ -12,8 +13,23 @@ import (
 
 const ErrSizeErrorInType = "size error in type: %v"
 
-// doSelfCheck performs various self checks of the library.
+var (
+	selfCheckOnce  sync.Once
+	selfCheckError error
+)
+
+// doSelfCheck performs various self checks of the library. Checks are
+// performed only once per process, their result is remembered.
 func doSelfCheck() (err error) {
+	selfCheckOnce.Do(func() {
+		selfCheckError = performSelfCheck()
+	})
+
+	return selfCheckError
+}
+
+// performSelfCheck performs various self checks of the library.
+func performSelfCheck() (err error) {
 	err = checkTypeSizes()
 	if err != nil {
 		return err
@@ -106,6 +106,8 @@ const (
 const (
 	ImageID                      = 32781
 	WangAnnotation               = 32932
+	CFARepeatPatternDim          = 33421
+	CFAPattern2                  = 33422
 	Copyright                    = 33432
 	MDFile                       = 33445
 	MDScalePixel                 = 33446
@@ -252,6 +254,9 @@ const (
 	ExifVersion              = 36864
 	DateTimeOriginal         = 36867
 	DateTimeDigitized        = 36868
+	OffsetTime               = 36880
+	OffsetTimeOriginal       = 36881
+	OffsetTimeDigitized      = 36882
 	ComponentsConfiguration  = 37121
 	CompressedBitsPerPixel   = 37122
 	ShutterSpeedValue        = 37377
@@ -440,6 +445,8 @@ var humanReadableTagNames = map[Tag]string{
 	// Private Tags.
 	ImageID:                      "ImageID",
 	WangAnnotation:               "WangAnnotation",
+	CFARepeatPatternDim:          "CFARepeatPatternDim",
+	CFAPattern2:                  "CFAPattern2",
 	Copyright:                    "Copyright",
 	MDFile:                       "MDFile",
 	MDScalePixel:                 "MDScalePixel",
@@ -584,6 +591,9 @@ var humanReadableTagNames = map[Tag]string{
 	ExifVersion:              "ExifVersion",
 	DateTimeOriginal:         "DateTimeOriginal",
 	DateTimeDigitized:        "DateTimeDigitized",
+	OffsetTime:               "OffsetTime",
+	OffsetTimeOriginal:       "OffsetTimeOriginal",
+	OffsetTimeDigitized:      "OffsetTimeDigitized",
 	ComponentsConfiguration:  "ComponentsConfiguration",
 	CompressedBitsPerPixel:   "CompressedBitsPerPixel",
 	ShutterSpeedValue:        "ShutterSpeedValue",
@@ -682,6 +692,20 @@ var tagsUsingSubIFDStyle = []Tag{
 	InteroperabilityIFD, // https://www.awaresystems.be/imaging/tiff/tifftags/interoperabilityifd.html
 }
 
+var tagsByName = func() map[string]Tag {
+	m := make(map[string]Tag, len(humanReadableTagNames))
+	for t, name := range humanReadableTagNames {
+		m[name] = t
+	}
+	return m
+}()
+
+// ByName returns the tag having the human-readable name.
+func ByName(name string) (t Tag, ok bool) {
+	t, ok = tagsByName[name]
+	return t, ok
+}
+
 // TagsUsingSubIFDStyle shows a list of all possible tags which use sub-IFDs.
 // While Golang does not allow to make a variable constant or read-only like in
 // C# language, we use a wrapper-function to show variables in a read-only
@@ -3,7 +3,11 @@ package t
 // The word 'type' is reserved by Go language.
 // Well, you reap what you sow. Ha-ha.
 
-import bt "github.com/vault-thirteen/TIFFer/models/basic-types"
+import (
+	"strconv"
+
+	bt "github.com/vault-thirteen/TIFFer/models/basic-types"
+)
 
 const (
 	Byte      = 1  // Byte.
@@ -18,9 +22,88 @@ const (
 	SRational = 10 // Two DWORD, two int32, 2x4 Bytes.
 	Float     = 11 // float32, 4 Bytes.
 	Double    = 12 // float64, 8 Bytes.
+	IFD       = 13 // DWORD, offset of an IFD, 4 Bytes.
 )
 
 const ErrUnknownType = "unknown data item type: %v"
 
 // Type is the type of data items of a Directory Entry.
 type Type = bt.Word
+
+var typeNames = map[Type]string{
+	Byte:      "BYTE",
+	ASCII:     "ASCII",
+	Short:     "SHORT",
+	Long:      "LONG",
+	Rational:  "RATIONAL",
+	SByte:     "SBYTE",
+	Undefined: "UNDEFINED",
+	SShort:    "SSHORT",
+	SLong:     "SLONG",
+	SRational: "SRATIONAL",
+	Float:     "FLOAT",
+	Double:    "DOUBLE",
+	IFD:       "IFD",
+}
+
+// TypeNames shows a list of names of all known types, as they are written in
+// the TIFF 6.0 Specification.
+func TypeNames() map[Type]string {
+	return typeNames
+}
+
+// Name returns the name of the type. Unknown types are shown as numbers.
+func Name(x Type) string {
+	name, ok := typeNames[x]
+	if ok {
+		return name
+	}
+
+	return strconv.Itoa(int(x))
+}
+
+var itemSizes = map[Type]int{
+	Byte:      1,
+	ASCII:     1,
+	Short:     2,
+	Long:      4,
+	Rational:  8,
+	SByte:     1,
+	Undefined: 1,
+	SShort:    2,
+	SLong:     4,
+	SRational: 8,
+	Float:     4,
+	Double:    8,
+	IFD:       4,
+}
+
+// ItemSize returns the size of a data item of the type in bytes. Zero is
+// returned for unknown types.
+func ItemSize(x Type) int {
+	return itemSizes[x]
+}
+
+var typesByName = func() map[string]Type {
+	m := make(map[string]Type, len(typeNames))
+	for x, name := range typeNames {
+		m[name] = x
+	}
+	return m
+}()
+
+// ByName returns the type having the name. Names of unknown types are their
+// numbers, as returned by the Name function.
+func ByName(name string) (x Type, ok bool) {
+	x, ok = typesByName[name]
+	if ok {
+		return x, true
+	}
+
+	n, err := strconv.ParseUint(name, 10, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return Type(n), true
+}
@@ -0,0 +1,193 @@
+package xmp
+
+import (
+	"errors"
+	"strings"
+
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// Namespaces of well-known schemas.
+const (
+	NamespaceDC        = "http://purl.org/dc/elements/1.1/"
+	NamespaceXMP       = "http://ns.adobe.com/xap/1.0/"
+	NamespacePhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+	NamespaceExif      = "http://ns.adobe.com/exif/1.0/"
+	NamespaceTIFF      = "http://ns.adobe.com/tiff/1.0/"
+	NamespaceCRS       = "http://ns.adobe.com/camera-raw-settings/1.0/"
+)
+
+// Namespaces of the syntax of packets.
+const (
+	NamespaceRDF   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	NamespaceXML   = "http://www.w3.org/XML/1998/namespace"
+	NamespaceXMLNS = "xmlns"
+)
+
+// DefaultLanguage is the language of the default item of language
+// alternatives.
+const DefaultLanguage = "x-default"
+
+// ArrayItemSeparator separates items of arrays shown as texts.
+const ArrayItemSeparator = "; "
+
+const (
+	ErrTIFFHasNoIFD    = "TIFF has no IFD"
+	ErrXMPTagIsMissing = "XMP tag is missing"
+	ErrRDFIsMissing    = "rdf:RDF element is missing"
+)
+
+// Conventional prefixes of well-known namespaces. They are used when a packet
+// does not declare a prefix of a namespace, which never happens in valid
+// packets.
+var conventionalPrefixes = map[string]string{
+	NamespaceDC:        "dc",
+	NamespaceXMP:       "xmp",
+	NamespacePhotoshop: "photoshop",
+	NamespaceExif:      "exif",
+	NamespaceTIFF:      "tiff",
+	NamespaceCRS:       "crs",
+}
+
+// XMP is a parsed XMP packet.
+//
+// Properties of all rdf:Description elements of the packet are collected
+// into a single list. Values of properties are simple values, structures
+// and arrays: unordered (Bag), ordered (Seq) and alternatives (Alt), the
+// latter are mostly used for texts in several languages.
+//
+// XMP Specification Part 1, Data Model, Serialization, and Core Properties:
+// https://github.com/adobe/XMP-Toolkit-SDK/tree/main/docs
+type XMP struct {
+	// Prefixes are prefixes of namespaces declared in the packet by the
+	// namespace URIs.
+	Prefixes map[string]string
+
+	// Properties are top-level properties in the order of their appearance.
+	Properties []*Property
+}
+
+// Property is a named value. Names of properties are qualified by their
+// namespaces.
+type Property struct {
+	// Namespace is the URI of the namespace of the property.
+	Namespace string
+
+	// Prefix is the prefix of the namespace used in the packet.
+	Prefix string
+
+	// Name is the local name of the property.
+	Name string
+
+	// Value is the value of the property.
+	Value *Value
+}
+
+// New parses the XMP packet of the first IFD of the TIFF.
+func New(t *tiff.TIFF) (x *XMP, err error) {
+	if len(t.IFDs()) == 0 {
+		return nil, errors.New(ErrTIFFHasNoIFD)
+	}
+
+	return NewFromIFD(t.IFDs()[0])
+}
+
+// NewFromIFD parses the XMP packet of the IFD.
+func NewFromIFD(i *ifd.IFD) (x *XMP, err error) {
+	de, ok := i.DirectoryEntriesByTagNumber[tag.XMP]
+	if !ok {
+		return nil, errors.New(ErrXMPTagIsMissing)
+	}
+
+	var packet []byte
+	packet, err = de.ValueAsArrayOfByte()
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(packet)
+}
+
+// QualifiedName returns the name of the property with the prefix of its
+// namespace, e.g. 'dc:creator'.
+func (p *Property) QualifiedName() string {
+	if p.Prefix == "" {
+		return p.Name
+	}
+
+	return p.Prefix + ":" + p.Name
+}
+
+// Property returns the top-level property, or nil.
+func (x *XMP) Property(namespace string, name string) (p *Property) {
+	return findProperty(x.Properties, namespace, name)
+}
+
+// Value returns the value of the top-level property, or nil.
+func (x *XMP) Value(namespace string, name string) (v *Value) {
+	p := x.Property(namespace, name)
+	if p == nil {
+		return nil
+	}
+
+	return p.Value
+}
+
+// Text returns the value of the top-level property as a text, see
+// Value.String. An empty string is returned for missing properties.
+func (x *XMP) Text(namespace string, name string) string {
+	return x.Value(namespace, name).String()
+}
+
+// PropertiesOf returns top-level properties of the namespace.
+func (x *XMP) PropertiesOf(namespace string) (list []*Property) {
+	for _, p := range x.Properties {
+		if p.Namespace == namespace {
+			list = append(list, p)
+		}
+	}
+
+	return list
+}
+
+// Namespaces returns URIs of namespaces of top-level properties in the order
+// of their first appearance.
+func (x *XMP) Namespaces() (namespaces []string) {
+	seen := make(map[string]bool)
+	for _, p := range x.Properties {
+		if !seen[p.Namespace] {
+			seen[p.Namespace] = true
+			namespaces = append(namespaces, p.Namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// findProperty returns the property of the list, or nil.
+func findProperty(list []*Property, namespace string, name string) (p *Property) {
+	for _, p = range list {
+		if (p.Namespace == namespace) && (p.Name == name) {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// prefixOf returns the prefix of the namespace.
+func (x *XMP) prefixOf(namespace string) string {
+	prefix, ok := x.Prefixes[namespace]
+	if ok {
+		return prefix
+	}
+
+	prefix, ok = conventionalPrefixes[namespace]
+	if ok {
+		return prefix
+	}
+
+	return strings.TrimRight(namespace, "/#")
+}
@@ -0,0 +1,287 @@
+package xmp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	exif "github.com/vault-thirteen/TIFFer/models/EXIF"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+// NumberTolerance is the relative difference of numbers which are considered
+// equal. Writers often round rationals when they write them into XMP.
+const NumberTolerance = 1e-4
+
+// Layouts of XMP dates with their precisions. The time zone is optional.
+var dateLayouts = []struct {
+	layout    string
+	precision time.Duration
+}{
+	{"2006-01-02T15:04:05.999999999Z07:00", time.Second},
+	{"2006-01-02T15:04:05.999999999", time.Second},
+	{"2006-01-02T15:04Z07:00", time.Minute},
+	{"2006-01-02T15:04", time.Minute},
+	{"2006-01-02", 24 * time.Hour},
+}
+
+// Contradiction is a property of XMP which has a value different from the
+// value of the corresponding native tag.
+type Contradiction struct {
+	// Property is the qualified name of the property, e.g. 'tiff:Make'.
+	Property string
+
+	// Path is the path of the native entry, e.g. 'IFD0/Make'.
+	Path string
+
+	// XMPValue is the value of the property as a text.
+	XMPValue string
+
+	// NativeValue is the value of the native entry as a text.
+	NativeValue string
+}
+
+// comparison compares the value of a property with the value of an entry.
+type comparison func(v *Value, de *ifd.DirectoryEntry) bool
+
+// correspondence links a property with the native tag having the same
+// meaning.
+type correspondence struct {
+	namespace string
+	name      string
+	isExif    bool
+	tag       tag.Tag
+	equal     comparison
+}
+
+// Properties and their native tags, as described in the Metadata Working
+// Group guidelines and in the XMP Specification Part 2.
+var correspondences = []correspondence{
+	{NamespaceTIFF, "ImageWidth", false, tag.ImageWidth, equalIntegers},
+	{NamespaceTIFF, "ImageLength", false, tag.ImageLength, equalIntegers},
+	{NamespaceTIFF, "BitsPerSample", false, tag.BitsPerSample, equalIntegers},
+	{NamespaceTIFF, "Compression", false, tag.Compression, equalIntegers},
+	{NamespaceTIFF, "PhotometricInterpretation", false, tag.PhotometricInterpretation, equalIntegers},
+	{NamespaceTIFF, "Orientation", false, tag.Orientation, equalIntegers},
+	{NamespaceTIFF, "SamplesPerPixel", false, tag.SamplesPerPixel, equalIntegers},
+	{NamespaceTIFF, "XResolution", false, tag.XResolution, equalNumbers},
+	{NamespaceTIFF, "YResolution", false, tag.YResolution, equalNumbers},
+	{NamespaceTIFF, "ResolutionUnit", false, tag.ResolutionUnit, equalIntegers},
+	{NamespaceTIFF, "Make", false, tag.Make, equalTexts},
+	{NamespaceTIFF, "Model", false, tag.Model, equalTexts},
+	{NamespaceTIFF, "Software", false, tag.Software, equalTexts},
+	{NamespaceXMP, "CreatorTool", false, tag.Software, equalTexts},
+	{NamespaceXMP, "ModifyDate", false, tag.DateTime, equalDates},
+	{NamespaceDC, "description", false, tag.ImageDescription, equalTexts},
+	{NamespaceDC, "rights", false, tag.Copyright, equalTexts},
+	{NamespaceDC, "creator", false, tag.Artist, equalNames},
+
+	{NamespaceExif, "ExposureTime", true, tag.ExposureTime, equalNumbers},
+	{NamespaceExif, "FNumber", true, tag.FNumber, equalNumbers},
+	{NamespaceExif, "ExposureProgram", true, tag.ExposureProgram, equalIntegers},
+	{NamespaceExif, "ISOSpeedRatings", true, tag.ISOSpeedRatings, equalIntegers},
+	{NamespaceExif, "DateTimeOriginal", true, tag.DateTimeOriginal, equalDates},
+	{NamespaceExif, "DateTimeDigitized", true, tag.DateTimeDigitized, equalDates},
+	{NamespaceXMP, "CreateDate", true, tag.DateTimeDigitized, equalDates},
+	{NamespaceExif, "ExposureBiasValue", true, tag.ExposureBiasValue, equalNumbers},
+	{NamespaceExif, "MeteringMode", true, tag.MeteringMode, equalIntegers},
+	{NamespaceExif, "FocalLength", true, tag.FocalLength, equalNumbers},
+	{NamespaceExif, "FocalLengthIn35mmFilm", true, tag.FocalLengthIn35mmFilm, equalIntegers},
+	{NamespaceExif, "ColorSpace", true, tag.ColorSpace, equalIntegers},
+	{NamespaceExif, "PixelXDimension", true, tag.PixelXDimension, equalIntegers},
+	{NamespaceExif, "PixelYDimension", true, tag.PixelYDimension, equalIntegers},
+	{NamespaceExif, "WhiteBalance", true, tag.WhiteBalance, equalIntegers},
+}
+
+// Contradictions compares properties of XMP with the native tags of the first
+// IFD of the TIFF and of its Exif IFD. Only properties and tags which both
+// exist are compared. Texts are compared without surrounding spaces, numbers
+// are compared with the NumberTolerance, dates are compared without time zones
+// and fractions of seconds, since native tags store them separately.
+func (x *XMP) Contradictions(t *tiff.TIFF) (list []*Contradiction) {
+	dirs := t.Directories()
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	ifd0 := dirs[0]
+	var exifIFD *tiff.Directory
+	for _, child := range ifd0.Children {
+		if child.Parent.Tag == tag.ExifIFD {
+			exifIFD = child
+			break
+		}
+	}
+
+	for _, c := range correspondences {
+		p := x.Property(c.namespace, c.name)
+		if p == nil {
+			continue
+		}
+
+		d := ifd0
+		if c.isExif {
+			d = exifIFD
+		}
+		if d == nil {
+			continue
+		}
+
+		de, ok := d.EntriesByTagNumber[c.tag]
+		if !ok || c.equal(p.Value, de) {
+			continue
+		}
+
+		list = append(list, &Contradiction{
+			Property:    p.QualifiedName(),
+			Path:        d.EntryPath(de),
+			XMPValue:    p.Value.String(),
+			NativeValue: nativeText(de),
+		})
+	}
+
+	return list
+}
+
+// String returns a human-readable description of the contradiction.
+func (c *Contradiction) String() string {
+	return fmt.Sprintf("%v = %q, but %v = %q", c.Property, c.XMPValue, c.Path, c.NativeValue)
+}
+
+// nativeText returns the value of the entry as a text.
+func nativeText(de *ifd.DirectoryEntry) string {
+	s, err := de.ValueAsString()
+	if err == nil {
+		return strings.TrimSpace(s)
+	}
+
+	numbers, err := de.ValueAsArrayOfNumber()
+	if err != nil {
+		return ""
+	}
+
+	texts := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		texts = append(texts, strconv.FormatFloat(n, 'g', -1, 64))
+	}
+
+	return strings.Join(texts, ArrayItemSeparator)
+}
+
+// equalTexts compares texts. Language alternatives are compared in the
+// default language.
+func equalTexts(v *Value, de *ifd.DirectoryEntry) bool {
+	s, err := de.ValueAsString()
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(v.String()) == strings.TrimSpace(s)
+}
+
+// equalNames compares the list of names with the native text, where names are
+// separated by semicolons.
+func equalNames(v *Value, de *ifd.DirectoryEntry) bool {
+	s, err := de.ValueAsString()
+	if err != nil {
+		return false
+	}
+
+	var names []string
+	for _, name := range strings.Split(s, ";") {
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	var items []string
+	for _, item := range v.Strings() {
+		items = append(items, strings.TrimSpace(item))
+	}
+
+	return slices.Equal(items, names)
+}
+
+// equalIntegers compares integers. Arrays are compared item by item.
+func equalIntegers(v *Value, de *ifd.DirectoryEntry) bool {
+	native, err := de.ValueAsArrayOfInteger()
+	if err != nil {
+		return false
+	}
+
+	texts := v.Strings()
+	if len(texts) != len(native) {
+		return false
+	}
+
+	for j, text := range texts {
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if (err != nil) || (n != native[j]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalNumbers compares numbers. Values of XMP may be rationals or decimal
+// numbers.
+func equalNumbers(v *Value, de *ifd.DirectoryEntry) bool {
+	native, err := de.ValueAsArrayOfNumber()
+	if err != nil {
+		return false
+	}
+
+	texts := v.Strings()
+	if len(texts) != len(native) {
+		return false
+	}
+
+	for j, text := range texts {
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(text))
+		if !ok {
+			return false
+		}
+
+		f, _ := r.Float64()
+		if math.Abs(f-native[j]) > NumberTolerance*math.Max(math.Abs(f), math.Abs(native[j])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalDates compares dates and times.
+func equalDates(v *Value, de *ifd.DirectoryEntry) bool {
+	s, err := de.ValueAsString()
+	if err != nil {
+		return false
+	}
+
+	var native time.Time
+	native, err = time.Parse(exif.DateTimeLayout, strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+
+	text := strings.TrimSpace(v.String())
+	for _, dl := range dateLayouts {
+		var dt time.Time
+		dt, err = time.Parse(dl.layout, text)
+		if err != nil {
+			continue
+		}
+
+		// Wall clock times are compared, so that time zones are ignored.
+		// Native values are truncated to the precision of the XMP value.
+		dt = time.Date(dt.Year(), dt.Month(), dt.Day(), dt.Hour(), dt.Minute(), dt.Second(), 0, time.UTC)
+		return dt.Equal(native.Truncate(dl.precision))
+	}
+
+	return false
+}
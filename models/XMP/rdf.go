@@ -0,0 +1,273 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Names of elements and attributes of the RDF syntax.
+const (
+	rdfRDF         = "RDF"
+	rdfDescription = "Description"
+	rdfBag         = "Bag"
+	rdfSeq         = "Seq"
+	rdfAlt         = "Alt"
+	rdfLi          = "li"
+	rdfResource    = "resource"
+	rdfParseType   = "parseType"
+	rdfValue       = "value"
+	xmlLang        = "lang"
+
+	parseTypeResource = "Resource"
+)
+
+// element is a node of the XML tree of the packet.
+type element struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*element
+	text     strings.Builder
+}
+
+// Parse parses the XMP packet. The packet may be wrapped into the
+// 'xpacket' processing instructions and the 'x:xmpmeta' element, padding
+// and trailing NUL characters are ignored.
+func Parse(packet []byte) (x *XMP, err error) {
+	packet = bytes.TrimRight(packet, "\x00")
+
+	var root *element
+	root, err = parseTree(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	rdf := findRDF(root)
+	if rdf == nil {
+		return nil, errors.New(ErrRDFIsMissing)
+	}
+
+	x = &XMP{
+		Prefixes:   make(map[string]string),
+		Properties: make([]*Property, 0),
+	}
+	x.collectPrefixes(root)
+
+	for _, child := range rdf.children {
+		if isRDF(child.name, rdfDescription) {
+			x.Properties = append(x.Properties, x.descriptionProperties(child)...)
+		}
+	}
+
+	return x, nil
+}
+
+// parseTree reads the XML tree of the packet.
+func parseTree(packet []byte) (root *element, err error) {
+	d := xml.NewDecoder(bytes.NewReader(packet))
+	d.Strict = false
+
+	var stack []*element
+	for {
+		var tok xml.Token
+		tok, err = d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			el := &element{name: tt.Name, attrs: tt.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			} else if root == nil {
+				root = el
+			}
+			stack = append(stack, el)
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tt)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New(ErrRDFIsMissing)
+	}
+
+	return root, nil
+}
+
+// findRDF returns the rdf:RDF element of the tree, or nil.
+func findRDF(el *element) *element {
+	if isRDF(el.name, rdfRDF) {
+		return el
+	}
+
+	for _, child := range el.children {
+		rdf := findRDF(child)
+		if rdf != nil {
+			return rdf
+		}
+	}
+
+	return nil
+}
+
+// collectPrefixes collects declarations of prefixes of namespaces of the tree.
+func (x *XMP) collectPrefixes(el *element) {
+	for _, a := range el.attrs {
+		if a.Name.Space == NamespaceXMLNS {
+			_, ok := x.Prefixes[a.Value]
+			if !ok {
+				x.Prefixes[a.Value] = a.Name.Local
+			}
+		}
+	}
+
+	for _, child := range el.children {
+		x.collectPrefixes(child)
+	}
+}
+
+// descriptionProperties returns properties of the rdf:Description element.
+// Simple properties may be written as attributes.
+func (x *XMP) descriptionProperties(el *element) (list []*Property) {
+	for _, a := range el.attrs {
+		if isSyntaxAttr(a.Name) {
+			continue
+		}
+		list = append(list, x.newProperty(a.Name, &Value{Kind: KindSimple, Text: a.Value}))
+	}
+
+	for _, child := range el.children {
+		list = append(list, x.newProperty(child.name, x.parseValue(child)))
+	}
+
+	return list
+}
+
+// newProperty creates a property of the name and the value.
+func (x *XMP) newProperty(name xml.Name, v *Value) *Property {
+	return &Property{
+		Namespace: name.Space,
+		Prefix:    x.prefixOf(name.Space),
+		Name:      name.Local,
+		Value:     v,
+	}
+}
+
+// parseValue parses the value of the property element or of the array item.
+func (x *XMP) parseValue(el *element) (v *Value) {
+	v = &Value{Kind: KindSimple}
+
+	var parseType string
+	for _, a := range el.attrs {
+		switch {
+		case (a.Name.Space == NamespaceXML) && (a.Name.Local == xmlLang):
+			v.Language = a.Value
+		case isRDF(a.Name, rdfResource):
+			v.Text = a.Value
+			return v
+		case isRDF(a.Name, rdfParseType):
+			parseType = a.Value
+		}
+	}
+
+	// Structure with fields written as child elements.
+	if parseType == parseTypeResource {
+		v.Kind = KindStruct
+		v.Fields = x.descriptionProperties(el)
+		return v.simplify()
+	}
+
+	if len(el.children) == 0 {
+		fields := x.descriptionProperties(el)
+		if len(fields) > 0 {
+			// Structure with fields written as attributes.
+			v.Kind = KindStruct
+			v.Fields = fields
+			return v.simplify()
+		}
+
+		v.Text = el.text.String()
+		return v
+	}
+
+	child := el.children[0]
+	switch {
+	case isRDF(child.name, rdfBag):
+		v.Kind = KindBag
+	case isRDF(child.name, rdfSeq):
+		v.Kind = KindSeq
+	case isRDF(child.name, rdfAlt):
+		v.Kind = KindAlt
+	case isRDF(child.name, rdfDescription):
+		v.Kind = KindStruct
+		v.Fields = x.descriptionProperties(child)
+		return v.simplify()
+	default:
+		// Fields without the rdf:Description element are not allowed by
+		// the specification, but some writers produce them.
+		v.Kind = KindStruct
+		v.Fields = x.descriptionProperties(el)
+		return v.simplify()
+	}
+
+	v.Items = make([]*Value, 0, len(child.children))
+	for _, li := range child.children {
+		if isRDF(li.name, rdfLi) {
+			v.Items = append(v.Items, x.parseValue(li))
+		}
+	}
+
+	return v
+}
+
+// simplify turns a structure having the rdf:value field into a simple value.
+// Other fields of such structures are qualifiers, which are dropped.
+func (v *Value) simplify() *Value {
+	for _, f := range v.Fields {
+		if (f.Namespace == NamespaceRDF) && (f.Name == rdfValue) {
+			f.Value.Language = v.Language
+			return f.Value
+		}
+	}
+
+	return v
+}
+
+// isRDF tells whether the name belongs to the RDF namespace and has the
+// local name.
+func isRDF(name xml.Name, local string) bool {
+	return (name.Space == NamespaceRDF) && (name.Local == local)
+}
+
+// isSyntaxAttr tells whether the attribute is a part of the syntax and not a
+// property, e.g. 'rdf:about' or 'xmlns:dc'.
+func isSyntaxAttr(name xml.Name) bool {
+	switch name.Space {
+	case NamespaceXMLNS, NamespaceXML:
+		return true
+	case NamespaceRDF:
+		return name.Local != rdfValue
+	case "":
+		// Attributes without namespaces, including default namespace
+		// declarations.
+		return true
+	default:
+		return false
+	}
+}
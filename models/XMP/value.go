@@ -0,0 +1,119 @@
+package xmp
+
+import "strings"
+
+// Kind is the kind of a value.
+type Kind string
+
+// Kinds of values.
+const (
+	KindSimple = Kind("simple")
+	KindStruct = Kind("struct")
+	KindBag    = Kind("bag")
+	KindSeq    = Kind("seq")
+	KindAlt    = Kind("alt")
+)
+
+// Value is the value of a property or an item of an array.
+type Value struct {
+	// Kind is the kind of the value.
+	Kind Kind
+
+	// Text is the text of a simple value.
+	Text string
+
+	// Language is the language of the value set by the 'xml:lang'
+	// attribute. Items of language alternatives always have languages.
+	Language string
+
+	// Fields are fields of a structure.
+	Fields []*Property
+
+	// Items are items of an array.
+	Items []*Value
+}
+
+// IsArray tells whether the value is an array.
+func (v *Value) IsArray() bool {
+	return (v.Kind == KindBag) || (v.Kind == KindSeq) || (v.Kind == KindAlt)
+}
+
+// String returns the value as a text. Language alternatives are shown in the
+// default language, other arrays are shown as their items separated by
+// semicolons. Structures are shown as empty strings.
+func (v *Value) String() string {
+	if v == nil {
+		return ""
+	}
+
+	switch v.Kind {
+	case KindSimple:
+		return v.Text
+	case KindAlt:
+		return v.LanguageText(DefaultLanguage)
+	case KindBag, KindSeq:
+		return strings.Join(v.Strings(), ArrayItemSeparator)
+	default:
+		return ""
+	}
+}
+
+// Strings returns texts of items of the array. A simple value is returned as
+// a single text.
+func (v *Value) Strings() (texts []string) {
+	if v == nil {
+		return nil
+	}
+
+	if v.Kind == KindSimple {
+		return []string{v.Text}
+	}
+
+	for _, item := range v.Items {
+		if item.Kind == KindSimple {
+			texts = append(texts, item.Text)
+		}
+	}
+
+	return texts
+}
+
+// LanguageText returns the text of the language alternative in the language.
+// When the language is missing, the text of the default language is
+// returned, and when it is missing as well, the first text is returned.
+func (v *Value) LanguageText(language string) string {
+	if v == nil {
+		return ""
+	}
+	if v.Kind == KindSimple {
+		return v.Text
+	}
+
+	var defaultItem *Value
+	for _, item := range v.Items {
+		if strings.EqualFold(item.Language, language) {
+			return item.Text
+		}
+		if (defaultItem == nil) && strings.EqualFold(item.Language, DefaultLanguage) {
+			defaultItem = item
+		}
+	}
+
+	if defaultItem != nil {
+		return defaultItem.Text
+	}
+	if len(v.Items) > 0 {
+		return v.Items[0].Text
+	}
+
+	return ""
+}
+
+// Field returns the field of the structure, or nil.
+func (v *Value) Field(namespace string, name string) (p *Property) {
+	if v == nil {
+		return nil
+	}
+
+	return findProperty(v.Fields, namespace, name)
+}
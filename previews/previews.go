@@ -0,0 +1,21 @@
+// Package previews lists all embedded previews and thumbnails of a TIFF
+// file: previews stored in directories, which are found by the 'tiff'
+// package, and previews stored in values of tags, which are found by
+// packages of typed models.
+package previews
+
+import (
+	photoshop "github.com/vault-thirteen/TIFFer/models/Photoshop"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+)
+
+// finders are preview finders of typed models.
+var finders = []tiff.PreviewFinder{
+	photoshop.FindPreviews,
+}
+
+// All lists previews of directories of the TIFF, followed by previews found
+// by typed models, e.g. JPEG thumbnails of Photoshop image resources.
+func All(t *tiff.TIFF) (previews []*tiff.Preview) {
+	return t.Previews(finders...)
+}
@@ -0,0 +1,79 @@
+package previews
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/vault-thirteen/TIFFer/edit"
+	photoshop "github.com/vault-thirteen/TIFFer/models/Photoshop"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// thumbnailResource returns the image resource block of a JPEG thumbnail.
+func thumbnailResource(width, height uint32, jpeg []byte) (data []byte) {
+	data = append([]byte("8BIM"), 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(data[4:], uint16(photoshop.ThumbnailResource))
+
+	header := make([]byte, 28)
+	binary.BigEndian.PutUint32(header[0:], photoshop.ThumbnailFormatJPEGRGB)
+	binary.BigEndian.PutUint32(header[4:], width)
+	binary.BigEndian.PutUint32(header[8:], height)
+	binary.BigEndian.PutUint16(header[24:], 24)
+	binary.BigEndian.PutUint16(header[26:], 1)
+
+	data = binary.BigEndian.AppendUint32(data, uint32(len(header)+len(jpeg)))
+	data = append(data, header...)
+	return append(data, jpeg...)
+}
+
+func TestAll(t *testing.T) {
+	data, err := os.ReadFile("../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e *edit.Editor
+	e, err = edit.New(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jpeg := []byte{0xFF, 0xD8, 1, 2, 3, 0xFF, 0xD9}
+	err = e.SetValue("IFD0/Photoshop", typ.Byte, thumbnailResource(16, 12, jpeg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = e.Write(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tf *tiff.TIFF
+	tf, err = tiff.New(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The thumbnail follows previews of directories.
+	previews := All(tf)
+	if len(previews) != len(tf.Previews())+1 {
+		t.Fatalf("%v previews are listed", len(previews))
+	}
+
+	p := previews[len(previews)-1]
+	if (p.Source != photoshop.PreviewSource) || (p.Path != "IFD0/Photoshop/1036") ||
+		(p.Width != 16) || (p.Height != 12) || !bytes.Equal(p.Data, jpeg) {
+		t.Errorf("thumbnail is listed as %+v", p)
+	}
+
+	// The thumbnail is located in the file.
+	start := p.Offsets[0]
+	if !bytes.Equal(out.Bytes()[start:start+p.ByteCounts[0]], jpeg) {
+		t.Error("offset of the thumbnail is not valid")
+	}
+}
@@ -0,0 +1,396 @@
+package validate
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	bo "github.com/vault-thirteen/TIFFer/models/ByteOrder"
+	hdr "github.com/vault-thirteen/TIFFer/models/Header"
+	ifd "github.com/vault-thirteen/TIFFer/models/IFD"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	t "github.com/vault-thirteen/TIFFer/models/Type"
+	"github.com/vault-thirteen/auxie/rs"
+)
+
+// Values of the PhotometricInterpretation tag.
+const (
+	PhotometricWhiteIsZero = 0
+	PhotometricBlackIsZero = 1
+	PhotometricRGB         = 2
+	PhotometricPalette     = 3
+	PhotometricCFA         = 32803
+	PhotometricLinearRaw   = 34892
+)
+
+// PlanarConfigurationPlanar is the value of the PlanarConfiguration tag for
+// images which store components in separate planes.
+const PlanarConfigurationPlanar = 2
+
+const (
+	MsgFileCanNotBeParsed  = "file can not be parsed: %v"
+	MsgRulesAreNotChecked  = "rules are not checked, because the file can not be parsed"
+	MsgTagIsMissing        = "required tag is missing: %v"
+	MsgImageSizeIsZero     = "image size is zero: %vx%v"
+	MsgCountMismatch       = "%v has %v values, but %v has %v values"
+	MsgBlockCountMismatch  = "image has %v %v, but %v are expected"
+	MsgBlockIsOutOfBounds  = "%v #%v at offset %v with size %v ends beyond the end of the file at %v"
+	MsgEntriesAreNotSorted = "entry of tag %v follows the entry of tag %v"
+	MsgTypeIsNotValid      = "type %v is not valid for %v, valid types are: %v"
+)
+
+// Tags which are replaced in tiled images.
+var stripTagReplacements = map[tag.Tag]tag.Tag{
+	tag.StripOffsets:    tag.TileOffsets,
+	tag.StripByteCounts: tag.TileByteCounts,
+	tag.RowsPerStrip:    tag.TileLength,
+}
+
+// reportParseError reports the error of parsing.
+func (c *context) reportParseError(err error) {
+	c.addIssue(models.SeverityError, RuleParse, "", MsgFileCanNotBeParsed, err)
+
+	// Other rule sets can not be checked without the parsed file.
+	for _, name := range c.report.RuleSets {
+		if name == RuleSetBaseline {
+			continue
+		}
+		c.ruleSet = name
+		c.addIssue(models.SeverityError, RuleNotChecked, "", MsgRulesAreNotChecked)
+	}
+	c.ruleSet = RuleSetBaseline
+
+	for _, name := range c.report.RuleSets {
+		if name == RuleSetBaseline {
+			return
+		}
+	}
+	c.report.RuleSets = append([]string{RuleSetBaseline}, c.report.RuleSets...)
+}
+
+// checkBaseline checks the rules of the TIFF 6.0 Specification.
+func checkBaseline(c *context) {
+	for _, d := range c.tiff.Directories() {
+		// Images other than the first one are often thumbnails written by
+		// tools which do not follow the specification strictly.
+		severity := models.SeverityWarning
+		if d.Path == tiff.IFDPathPrefix+"0" {
+			severity = models.SeverityError
+		}
+
+		c.checkRequiredTags(d, severity)
+		c.checkImageSize(d)
+		c.checkDataLayout(d)
+	}
+
+	c.checkTagTypes()
+
+	_ = c.tiff.WalkDirectories(func(d *tiff.Directory) error {
+		c.checkTagOrder(d)
+		c.checkDataBounds(d)
+		return nil
+	})
+}
+
+// checkTagTypes checks that entries of all IFDs and SubIFDs have data item
+// types allowed for their tags. The parser rejects files with such entries,
+// so entries are read from the stream rather than taken from the parsed
+// file. Directories which can not be read are skipped, as they are reported
+// by the parse rule.
+func (c *context) checkTagTypes() {
+	_, err := c.stream.Seek(0, io.SeekStart)
+	if err != nil {
+		return
+	}
+
+	var stream *rs.ReaderSeeker
+	stream, err = rs.New(c.stream)
+	if err != nil {
+		return
+	}
+
+	var h *hdr.Header
+	h, err = hdr.New(stream)
+	if err != nil {
+		return
+	}
+
+	tc := &typeCheck{c: c, stream: stream, byteOrder: h.ByteOrder, visited: make(map[models.OffsetOfIFD]bool)}
+	tc.checkChain(h.OffsetOfFirstIFD, func(idx int) string {
+		return tiff.IFDPathPrefix + strconv.Itoa(idx)
+	})
+}
+
+// typeCheck reads entries of directories for the tag-types rule.
+type typeCheck struct {
+	c         *context
+	stream    *rs.ReaderSeeker
+	byteOrder bo.ByteOrder
+
+	// visited are offsets of read directories, so that looped chains are
+	// read once.
+	visited map[models.OffsetOfIFD]bool
+}
+
+// checkChain checks the chain of directories starting at the offset. Paths
+// of directories are made by their indices in the chain.
+func (tc *typeCheck) checkChain(offset models.OffsetOfIFD, path func(idx int) string) {
+	for idx := 0; offset != ifd.LastIFDOffsetOfNextIFD; idx++ {
+		i := tc.readIFD(offset)
+		if i == nil {
+			return
+		}
+
+		tc.checkEntries(path(idx), i.DirectoryEntries)
+		offset = i.OffsetOfNextIFD
+	}
+}
+
+// readIFD reads the directory at the offset, or returns nil when it can not
+// be read or has been read already.
+func (tc *typeCheck) readIFD(offset models.OffsetOfIFD) (i *ifd.IFD) {
+	if tc.visited[offset] {
+		return nil
+	}
+	tc.visited[offset] = true
+
+	i, err := ifd.NewIFD(tc.stream, tc.byteOrder, offset)
+	if err != nil {
+		return nil
+	}
+
+	return i
+}
+
+// checkEntries reports entries of the directory with types which are not
+// allowed, and checks SubIFDs referred by the other entries.
+func (tc *typeCheck) checkEntries(dirPath string, entries []*ifd.DirectoryEntry) {
+	for _, de := range entries {
+		validTypes, ok := ifd.ValidTypesPerTag()[de.Tag]
+		if ok && !slices.Contains(validTypes, de.Type) {
+			names := make([]string, 0, len(validTypes))
+			for _, validType := range validTypes {
+				names = append(names, t.Name(validType))
+			}
+
+			tc.c.addIssue(models.SeverityError, RuleTagTypes, dirPath+tiff.PathSeparator+tiff.EntryName(de.Tag),
+				MsgTypeIsNotValid, t.Name(de.Type), tiff.EntryName(de.Tag), strings.Join(names, ", "))
+			continue
+		}
+
+		if tag.IsSubIFDTag(de.Tag) {
+			tc.checkSubIFDs(dirPath, de)
+		}
+	}
+}
+
+// checkSubIFDs checks SubIFDs of the entry. An entry with a single offset
+// refers to a chain of SubIFDs, an entry with multiple offsets refers to a
+// separate SubIFD by each offset.
+func (tc *typeCheck) checkSubIFDs(dirPath string, de *ifd.DirectoryEntry) {
+	if (de.Type != t.Long) && (de.Type != t.IFD) {
+		return
+	}
+
+	path := func(idx int) string {
+		return tiff.SubIFDPath(dirPath, de.Tag, idx)
+	}
+
+	if de.HasSubIFDChain() {
+		tc.checkChain(de.ValueOrOffset, path)
+		return
+	}
+
+	err := de.ProcessValues(tc.stream, tc.byteOrder)
+	if err != nil {
+		return
+	}
+
+	for idx, offset := range de.SubIFDOffsets() {
+		i := tc.readIFD(offset)
+		if i != nil {
+			tc.checkEntries(path(idx), i.DirectoryEntries)
+		}
+	}
+}
+
+// checkRequiredTags checks that the image has all the tags required for its
+// photometric interpretation.
+func (c *context) checkRequiredTags(d *tiff.Directory, severity models.Severity) {
+	var required []tag.Tag
+	photometric, ok := firstInteger(d, tag.PhotometricInterpretation)
+	switch {
+	case !ok:
+		required = ifd.RequiredTagsForBilevelImages()
+	case photometric == PhotometricRGB:
+		required = ifd.RequiredTagsForRGBImages()
+	case photometric == PhotometricPalette:
+		required = ifd.RequiredTagsForRGBPaletteColorImages()
+	case (photometric == PhotometricWhiteIsZero) || (photometric == PhotometricBlackIsZero):
+		required = ifd.RequiredTagsForGrayscaleImages()
+		bitsPerSample, hasBits := firstInteger(d, tag.BitsPerSample)
+		if !hasBits || (bitsPerSample == 1) {
+			required = ifd.RequiredTagsForBilevelImages()
+		}
+	default:
+		// Other colour spaces are not baseline, so only the tags common
+		// for all images are required.
+		required = ifd.RequiredTagsForBilevelImages()
+	}
+
+	_, isTiled := d.EntriesByTagNumber[tag.TileWidth]
+	for _, tg := range required {
+		if isTiled {
+			replacement, isReplaced := stripTagReplacements[tg]
+			if isReplaced {
+				tg = replacement
+			}
+		}
+
+		_, ok = d.EntriesByTagNumber[tg]
+		if !ok {
+			c.addIssue(severity, RuleRequiredTags, d.Path, MsgTagIsMissing, tiff.EntryName(tg))
+		}
+	}
+}
+
+// checkImageSize checks that the image is not empty.
+func (c *context) checkImageSize(d *tiff.Directory) {
+	width, hasWidth := firstInteger(d, tag.ImageWidth)
+	length, hasLength := firstInteger(d, tag.ImageLength)
+	if !hasWidth || !hasLength {
+		// Missing tags are reported by another rule.
+		return
+	}
+
+	if (width == 0) || (length == 0) {
+		c.addIssue(models.SeverityError, RuleImageSize, d.Path, MsgImageSizeIsZero, width, length)
+	}
+}
+
+// checkDataLayout checks that the numbers of strips or tiles match the size
+// of the image and the numbers of their byte counts.
+func (c *context) checkDataLayout(d *tiff.Directory) {
+	var offsetsTag, countsTag tag.Tag = tag.StripOffsets, tag.StripByteCounts
+	name := "strips"
+	_, isTiled := d.EntriesByTagNumber[tag.TileWidth]
+	if isTiled {
+		offsetsTag, countsTag, name = tag.TileOffsets, tag.TileByteCounts, "tiles"
+	}
+
+	offsets, hasOffsets := d.EntriesByTagNumber[offsetsTag]
+	counts, hasCounts := d.EntriesByTagNumber[countsTag]
+	if !hasOffsets || !hasCounts {
+		// Missing tags are reported by another rule.
+		return
+	}
+
+	if offsets.Count != counts.Count {
+		c.addIssue(models.SeverityError, RuleDataLayout, d.Path, MsgCountMismatch,
+			tiff.EntryName(offsetsTag), offsets.Count, tiff.EntryName(countsTag), counts.Count)
+	}
+
+	width, hasWidth := firstInteger(d, tag.ImageWidth)
+	length, hasLength := firstInteger(d, tag.ImageLength)
+	if !hasWidth || !hasLength {
+		return
+	}
+
+	var expected int64
+	if isTiled {
+		tileWidth, ok1 := firstInteger(d, tag.TileWidth)
+		tileLength, ok2 := firstInteger(d, tag.TileLength)
+		if !ok1 || !ok2 || (tileWidth == 0) || (tileLength == 0) {
+			return
+		}
+		expected = ceilDiv(width, tileWidth) * ceilDiv(length, tileLength)
+	} else {
+		rowsPerStrip, ok := firstInteger(d, tag.RowsPerStrip)
+		if !ok || (rowsPerStrip == 0) || (rowsPerStrip > length) {
+			rowsPerStrip = length
+		}
+		if rowsPerStrip == 0 {
+			return
+		}
+		expected = ceilDiv(length, rowsPerStrip)
+	}
+
+	planar, _ := firstInteger(d, tag.PlanarConfiguration)
+	if planar == PlanarConfigurationPlanar {
+		samplesPerPixel, ok := firstInteger(d, tag.SamplesPerPixel)
+		if !ok {
+			samplesPerPixel = 1
+		}
+		expected *= samplesPerPixel
+	}
+
+	if int64(offsets.Count) != expected {
+		c.addIssue(models.SeverityError, RuleDataLayout, d.Path, MsgBlockCountMismatch, offsets.Count, name, expected)
+	}
+}
+
+// checkDataBounds checks that strips and tiles of the directory are stored
+// inside the file.
+func (c *context) checkDataBounds(d *tiff.Directory) {
+	pairs := [][2]tag.Tag{
+		{tag.StripOffsets, tag.StripByteCounts},
+		{tag.TileOffsets, tag.TileByteCounts},
+	}
+
+	for _, pair := range pairs {
+		offsets, err1 := integers(d, pair[0])
+		counts, err2 := integers(d, pair[1])
+		if (err1 != nil) || (err2 != nil) {
+			continue
+		}
+
+		for j := 0; (j < len(offsets)) && (j < len(counts)); j++ {
+			if offsets[j]+counts[j] > c.size {
+				c.addIssue(models.SeverityError, RuleDataBounds, d.Path, MsgBlockIsOutOfBounds,
+					tiff.EntryName(pair[0]), j, offsets[j], counts[j], c.size)
+				// One issue per tag is enough, a broken file may have
+				// thousands of blocks.
+				break
+			}
+		}
+	}
+}
+
+// checkTagOrder checks that entries are sorted by their tags.
+func (c *context) checkTagOrder(d *tiff.Directory) {
+	for j := 1; j < len(d.Entries); j++ {
+		if d.Entries[j].Tag < d.Entries[j-1].Tag {
+			c.addIssue(models.SeverityWarning, RuleTagOrder, d.Path, MsgEntriesAreNotSorted,
+				tiff.EntryName(d.Entries[j].Tag), tiff.EntryName(d.Entries[j-1].Tag))
+			return
+		}
+	}
+}
+
+// integers returns integer values of the tag of the directory.
+func integers(d *tiff.Directory, tg tag.Tag) (v []int64, err error) {
+	de, ok := d.EntriesByTagNumber[tg]
+	if !ok {
+		return nil, nil
+	}
+
+	return de.ValueAsArrayOfInteger()
+}
+
+// firstInteger returns the first integer value of the tag of the directory.
+func firstInteger(d *tiff.Directory, tg tag.Tag) (v int64, ok bool) {
+	values, err := integers(d, tg)
+	if (err != nil) || (len(values) == 0) {
+		return 0, false
+	}
+
+	return values[0], true
+}
+
+// ceilDiv divides rounding up.
+func ceilDiv(a int64, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	typ "github.com/vault-thirteen/TIFFer/models/Type"
+)
+
+// Offset of the Exif SubIFD of the sample file.
+const testExifOffset = 42
+
+// fileWithInvalidTypes returns the sample file where types of several entries
+// are replaced with types which are not valid for their tags.
+func fileWithInvalidTypes(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("../test/test.tiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setTypes := func(offset uint32, types map[tag.Tag]typ.Type) {
+		n := int(binary.LittleEndian.Uint16(data[offset:]))
+		for j := range n {
+			entry := data[int(offset)+2+12*j:]
+			newType, ok := types[binary.LittleEndian.Uint16(entry)]
+			if ok {
+				binary.LittleEndian.PutUint16(entry[2:], newType)
+			}
+		}
+	}
+	setTypes(binary.LittleEndian.Uint32(data[4:]), map[tag.Tag]typ.Type{
+		tag.Orientation:    typ.Long,
+		tag.ResolutionUnit: typ.Long,
+	})
+	setTypes(testExifOffset, map[tag.Tag]typ.Type{
+		tag.FNumber: typ.SRational,
+	})
+
+	return data
+}
+
+func TestCheckTagTypes(t *testing.T) {
+	data := fileWithInvalidTypes(t)
+
+	// The parser rejects invalid types.
+	_, err := tiff.New(bytes.NewReader(data))
+	if err == nil {
+		t.Error("file with invalid types is parsed")
+	}
+
+	var r *Report
+	r, err = Validate(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.IsValid {
+		t.Error("file with invalid types is valid")
+	}
+
+	// Every invalid entry is reported, together with the error of parsing.
+	paths := make([]string, 0)
+	parseErrors := 0
+	for _, issue := range r.Issues {
+		switch issue.Rule {
+		case RuleTagTypes:
+			paths = append(paths, issue.Path)
+		case RuleParse:
+			parseErrors++
+		}
+	}
+	if parseErrors != 1 {
+		t.Errorf("%v parse errors are reported", parseErrors)
+	}
+	want := []string{"IFD0/Orientation", "IFD0/ResolutionUnit", "IFD0/Exif/FNumber"}
+	if len(paths) != len(want) {
+		t.Fatalf("invalid types are reported for %v", paths)
+	}
+	for j := range want {
+		if paths[j] != want[j] {
+			t.Errorf("invalid types are reported for %v", paths)
+		}
+	}
+}
+
+func TestValidate_SampleFile(t *testing.T) {
+	r, err := ValidateFile("../test/test.tiff", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.IsValid {
+		t.Errorf("sample file is not valid: %v", r.Issues)
+	}
+}
@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	cog "github.com/vault-thirteen/TIFFer/models/COG"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+)
+
+// checkCOG checks the rules of the Cloud-Optimized GeoTIFF layout, see the
+// COG package.
+func checkCOG(c *context) {
+	r, err := cog.ValidateTIFF(c.tiff, c.stream)
+	if err != nil {
+		c.addIssue(models.SeverityError, cog.RuleImageLayout, "", "%v", err)
+		return
+	}
+
+	for _, issue := range r.Issues {
+		var path string
+		if issue.IFDIndex >= 0 {
+			path = fmt.Sprintf("%s%d", tiff.IFDPathPrefix, issue.IFDIndex)
+		}
+
+		c.addIssue(issue.Severity, issue.Rule, path, "%s", issue.Message)
+	}
+}
@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"io"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	dng "github.com/vault-thirteen/TIFFer/models/DNG"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+	iors "github.com/vault-thirteen/auxie/ReaderSeeker"
+)
+
+// Size of the value of the DNGVersion tag and the major version of the DNG
+// specification.
+const (
+	DNGVersionSize  = 4
+	DNGMajorVersion = 1
+)
+
+const (
+	MsgDNGVersionIsNotValid = "DNGVersion is not valid: %v"
+	MsgCameraModelIsEmpty   = "UniqueCameraModel is empty"
+	MsgRawImageIsMissing    = "file has no full-resolution raw image"
+	MsgDigestsAreNotChecked = "digests can not be checked: %v"
+	MsgDigestIsNotChecked   = "%v can not be checked: %v"
+	MsgDigestMismatch       = "%v does not match the data: stored %x, computed %x"
+)
+
+// Tags of digests verified by the DNG rule set.
+var digestTags = []tag.Tag{
+	tag.RawImageDigest,
+	tag.NewRawImageDigest,
+	tag.OriginalRawFileDigest,
+}
+
+// checkDNG checks the rules of the DNG specification.
+func checkDNG(c *context) {
+	dirs := c.tiff.Directories()
+	if len(dirs) == 0 {
+		return
+	}
+	first := dirs[0]
+
+	version, err := integers(first, tag.DNGVersion)
+	switch {
+	case err != nil:
+		c.addIssue(models.SeverityError, RuleDNGVersion, first.Path, MsgDNGVersionIsNotValid, err)
+	case version == nil:
+		c.addIssue(models.SeverityError, RuleDNGVersion, first.Path, MsgTagIsMissing, tiff.EntryName(tag.DNGVersion))
+	case (len(version) != DNGVersionSize) || (version[0] != DNGMajorVersion):
+		c.addIssue(models.SeverityError, RuleDNGVersion, first.Path, MsgDNGVersionIsNotValid, version)
+	}
+
+	de, ok := first.EntriesByTagNumber[tag.UniqueCameraModel]
+	if !ok {
+		c.addIssue(models.SeverityError, RuleUniqueCameraModel, first.Path, MsgTagIsMissing, tiff.EntryName(tag.UniqueCameraModel))
+	} else if s, _ := de.ValueAsString(); len(s) == 0 {
+		c.addIssue(models.SeverityError, RuleUniqueCameraModel, first.Path, MsgCameraModelIsEmpty)
+	}
+
+	// The raw image is either the first IFD or one of its SubIFDs.
+	var raw *tiff.Directory
+	_ = c.tiff.WalkDirectories(func(d *tiff.Directory) error {
+		if (raw == nil) && isRawImage(d) {
+			raw = d
+		}
+		return nil
+	})
+	if raw == nil {
+		c.addIssue(models.SeverityError, RuleRawImage, "", MsgRawImageIsMissing)
+		return
+	}
+
+	// Colour matrices are not required for monochrome images only.
+	photometric, _ := firstInteger(raw, tag.PhotometricInterpretation)
+	samplesPerPixel, _ := firstInteger(raw, tag.SamplesPerPixel)
+	if (photometric == PhotometricCFA) || (samplesPerPixel > 1) {
+		_, ok = first.EntriesByTagNumber[tag.ColorMatrix1]
+		if !ok {
+			c.addIssue(models.SeverityError, RuleColorMatrix, first.Path, MsgTagIsMissing, tiff.EntryName(tag.ColorMatrix1))
+		}
+	}
+
+	c.checkDigests(first)
+}
+
+// checkDigests verifies MD5 digests of the raw image data and of the
+// original raw file. Digests which can not be computed, e.g. because of an
+// unsupported compression, are reported as warnings.
+func (c *context) checkDigests(first *tiff.Directory) {
+	hasDigests := false
+	for _, tg := range digestTags {
+		if _, ok := first.EntriesByTagNumber[tg]; ok {
+			hasDigests = true
+		}
+	}
+	if !hasDigests {
+		return
+	}
+
+	d, err := dng.NewFromDirectory(first)
+	if err != nil {
+		c.addIssue(models.SeverityWarning, RuleDigests, first.Path, MsgDigestsAreNotChecked, err)
+		return
+	}
+
+	file, ok := c.stream.(io.ReaderAt)
+	if !ok {
+		file = &streamReaderAt{stream: c.stream}
+	}
+
+	for _, dc := range d.VerifyDigests(file) {
+		entryPath := first.Path + tiff.PathSeparator + dc.Name()
+		switch {
+		case dc.Err != nil:
+			c.addIssue(models.SeverityWarning, RuleDigests, entryPath, MsgDigestIsNotChecked, dc.Name(), dc.Err)
+		case !dc.Passed:
+			c.addIssue(models.SeverityError, RuleDigests, entryPath, MsgDigestMismatch, dc.Name(), dc.Stored, dc.Computed)
+		}
+	}
+}
+
+// streamReaderAt reads data at offsets of a stream which does not support
+// random access.
+type streamReaderAt struct {
+	stream iors.ReaderSeeker
+}
+
+// ReadAt reads data at the offset.
+func (sra *streamReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	_, err = sra.stream.Seek(off, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	return io.ReadFull(sra.stream, p)
+}
+
+// isRawImage tells whether the directory is a full-resolution raw image.
+func isRawImage(d *tiff.Directory) bool {
+	newSubfileType, _ := firstInteger(d, tag.NewSubfileType)
+	if newSubfileType != 0 {
+		return false
+	}
+
+	photometric, _ := firstInteger(d, tag.PhotometricInterpretation)
+	return (photometric == PhotometricCFA) || (photometric == PhotometricLinearRaw)
+}
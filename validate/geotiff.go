@@ -0,0 +1,71 @@
+package validate
+
+import (
+	"github.com/vault-thirteen/TIFFer/models"
+	geotiff "github.com/vault-thirteen/TIFFer/models/GeoTIFF"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	tag "github.com/vault-thirteen/TIFFer/models/Tag"
+)
+
+const (
+	MsgKeyDirectoryIsMissing    = "GeoKey directory is missing"
+	MsgKeyDirectoryIsNotValid   = "GeoKey directory is not valid: %v"
+	MsgModelTypeIsMissing       = "model type is not specified"
+	MsgCRSIsMissing             = "coordinate reference system is not specified"
+	MsgGeoreferencingIsNotValid = "georeferencing can not be built: %v"
+)
+
+// checkGeoTIFF checks the rules of the GeoTIFF specification for the first
+// image.
+func checkGeoTIFF(c *context) {
+	ifds := c.tiff.IFDs()
+	if len(ifds) == 0 {
+		return
+	}
+	path := tiff.IFDPathPrefix + "0"
+	entries := ifds[0].DirectoryEntriesByTagNumber
+
+	_, ok := entries[tag.GeoKeyDirectory]
+	if !ok {
+		c.addIssue(models.SeverityError, RuleGeoKeyDirectory, path, MsgKeyDirectoryIsMissing)
+		return
+	}
+
+	kd, err := geotiff.NewKeyDirectoryFromEntries(entries)
+	if err != nil {
+		c.addIssue(models.SeverityError, RuleGeoKeyDirectory, path, MsgKeyDirectoryIsNotValid, err)
+		return
+	}
+
+	_, ok = kd.ModelType()
+	if !ok {
+		c.addIssue(models.SeverityError, RuleModelType, path, MsgModelTypeIsMissing)
+	}
+
+	if !hasCRS(kd) {
+		c.addIssue(models.SeverityWarning, RuleCRS, path, MsgCRSIsMissing)
+	}
+
+	_, err = geotiff.NewGeoreferencingFromEntries(entries)
+	if err != nil {
+		c.addIssue(models.SeverityError, RuleGeoreferencing, path, MsgGeoreferencingIsNotValid, err)
+	}
+}
+
+// hasCRS tells whether the coordinate reference system is specified either
+// by a code or by a citation.
+func hasCRS(kd *geotiff.KeyDirectory) bool {
+	_, ok := kd.EPSG()
+	if ok {
+		return true
+	}
+
+	for _, citation := range []func() (string, bool){kd.PCSCitation, kd.GeogCitation, kd.Citation} {
+		v, hasCitation := citation()
+		if hasCitation && (len(v) > 0) {
+			return true
+		}
+	}
+
+	return false
+}
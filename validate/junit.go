@@ -0,0 +1,119 @@
+package validate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vault-thirteen/TIFFer/models"
+)
+
+// JUnitSuitesName is the name of the set of test suites in JUnit reports.
+const JUnitSuitesName = "tiffer validate"
+
+// junitSuites is the root element of a JUnit XML report.
+type junitSuites struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Name     string        `xml:"name,attr"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is a test suite. Each rule set is a test suite.
+type junitSuite struct {
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Cases    []*junitCase `xml:"testcase"`
+}
+
+// junitCase is a test case. Each file is a test case of every rule set.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes errors found in a file.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the reports as a JUnit XML report. Every rule set is a
+// test suite and every file is a test case in it, which fails when errors of
+// the rule set are found. Warnings are written into the output of a test
+// case.
+func WriteJUnit(w io.Writer, reports []*Report) (err error) {
+	root := &junitSuites{Name: JUnitSuitesName}
+	suites := make(map[string]*junitSuite)
+
+	for _, r := range reports {
+		for _, ruleSet := range r.RuleSets {
+			suite, ok := suites[ruleSet]
+			if !ok {
+				suite = &junitSuite{Name: ruleSet}
+				suites[ruleSet] = suite
+				root.Suites = append(root.Suites, suite)
+			}
+
+			tc := &junitCase{Name: r.File, ClassName: ruleSet}
+
+			var errs, warnings []string
+			for _, issue := range r.IssuesOf(ruleSet) {
+				line := issueLine(issue)
+				if issue.Severity == models.SeverityError {
+					errs = append(errs, line)
+				} else {
+					warnings = append(warnings, line)
+				}
+			}
+
+			if len(errs) > 0 {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%v error(s) found", len(errs)),
+					Type:    string(models.SeverityError),
+					Text:    strings.Join(errs, "\n"),
+				}
+				suite.Failures++
+				root.Failures++
+			}
+			tc.SystemOut = strings.Join(warnings, "\n")
+
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+			root.Tests++
+		}
+	}
+
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(root)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// issueLine returns a one-line description of the issue.
+func issueLine(issue *Issue) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] %s", issue.Severity, FullRuleID(issue.RuleSet, issue.Rule)))
+	if len(issue.Path) > 0 {
+		sb.WriteString(" " + issue.Path)
+	}
+	sb.WriteString(": " + issue.Message)
+
+	return sb.String()
+}
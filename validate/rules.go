@@ -0,0 +1,139 @@
+package validate
+
+import (
+	"sort"
+
+	cog "github.com/vault-thirteen/TIFFer/models/COG"
+)
+
+// Names of rule sets.
+const (
+	RuleSetBaseline = "baseline"
+	RuleSetGeoTIFF  = "geotiff"
+	RuleSetDNG      = "dng"
+	RuleSetCOG      = "cog"
+)
+
+// Rules of the baseline rule set.
+const (
+	RuleParse        = "parse"
+	RuleTagTypes     = "tag-types"
+	RuleRequiredTags = "required-tags"
+	RuleImageSize    = "image-size"
+	RuleDataLayout   = "data-layout"
+	RuleDataBounds   = "data-bounds"
+	RuleTagOrder     = "tag-order"
+)
+
+// RuleNotChecked is reported for every rule set, except the baseline one,
+// when the file can not be parsed.
+const RuleNotChecked = "not-checked"
+
+// Rules of the GeoTIFF rule set.
+const (
+	RuleGeoKeyDirectory = "geokey-directory"
+	RuleModelType       = "model-type"
+	RuleCRS             = "crs"
+	RuleGeoreferencing  = "georeferencing"
+)
+
+// Rules of the DNG rule set.
+const (
+	RuleDNGVersion        = "dng-version"
+	RuleUniqueCameraModel = "unique-camera-model"
+	RuleRawImage          = "raw-image"
+	RuleColorMatrix       = "color-matrix"
+	RuleDigests           = "digests"
+)
+
+// Rule is a rule checked by the validator.
+type Rule struct {
+	// ID is the identifier of the rule inside its rule set.
+	ID string
+
+	// RuleSet is the name of the rule set.
+	RuleSet string
+
+	// Description is a short description of the rule.
+	Description string
+}
+
+// FullID returns the identifier of the rule which is unique among all rule
+// sets, e.g. 'baseline/required-tags'.
+func (r *Rule) FullID() string {
+	return FullRuleID(r.RuleSet, r.ID)
+}
+
+// FullRuleID returns the identifier of the rule which is unique among all
+// rule sets.
+func FullRuleID(ruleSet string, rule string) string {
+	return ruleSet + "/" + rule
+}
+
+// checkers are functions checking the rule sets.
+var checkers = map[string]checker{
+	RuleSetBaseline: checkBaseline,
+	RuleSetGeoTIFF:  checkGeoTIFF,
+	RuleSetDNG:      checkDNG,
+	RuleSetCOG:      checkCOG,
+}
+
+var rules = []*Rule{
+	{RuleParse, RuleSetBaseline, "File can be parsed."},
+	{RuleTagTypes, RuleSetBaseline, "Tags have data item types allowed by the specification."},
+	{RuleRequiredTags, RuleSetBaseline, "Images have all tags required for their photometric interpretation."},
+	{RuleImageSize, RuleSetBaseline, "Images have non-zero width and length."},
+	{RuleDataLayout, RuleSetBaseline, "Numbers of strips or tiles match the size of the image and numbers of their byte counts."},
+	{RuleDataBounds, RuleSetBaseline, "Strips and tiles are stored inside the file."},
+	{RuleTagOrder, RuleSetBaseline, "Entries of directories are sorted in ascending order of tags."},
+
+	{RuleNotChecked, RuleSetGeoTIFF, "File can be parsed, so that the rules can be checked."},
+	{RuleGeoKeyDirectory, RuleSetGeoTIFF, "The first image has a valid GeoKey directory."},
+	{RuleModelType, RuleSetGeoTIFF, "Model type is specified."},
+	{RuleCRS, RuleSetGeoTIFF, "Coordinate reference system is specified by a code or by a citation."},
+	{RuleGeoreferencing, RuleSetGeoTIFF, "Raster coordinates can be converted into model coordinates."},
+
+	{RuleNotChecked, RuleSetDNG, "File can be parsed, so that the rules can be checked."},
+	{RuleDNGVersion, RuleSetDNG, "The first IFD has a valid DNGVersion tag."},
+	{RuleUniqueCameraModel, RuleSetDNG, "The first IFD has the UniqueCameraModel tag."},
+	{RuleRawImage, RuleSetDNG, "File has a full-resolution raw image with the CFA or LinearRaw photometric interpretation."},
+	{RuleColorMatrix, RuleSetDNG, "Colour images have the ColorMatrix1 tag."},
+	{RuleDigests, RuleSetDNG, "MD5 digests of the raw image data and of the original raw file match the data."},
+
+	{RuleNotChecked, RuleSetCOG, "File can be parsed, so that the rules can be checked."},
+	{cog.RuleGhostHeader, RuleSetCOG, "Ghost header written by GDAL is valid."},
+	{cog.RuleMainImageIsTiled, RuleSetCOG, "Main image is tiled."},
+	{cog.RuleOverviewIsReduced, RuleSetCOG, "Overviews are reduced-resolution images."},
+	{cog.RuleOverviewIsTiled, RuleSetCOG, "Overviews and masks are tiled."},
+	{cog.RuleOverviewSize, RuleSetCOG, "Sizes of overviews are decreasing."},
+	{cog.RuleIFDsBeforeData, RuleSetCOG, "IFDs are stored before the image data."},
+	{cog.RuleOverviewDataFirst, RuleSetCOG, "Data of overviews is stored before the data of bigger images."},
+	{cog.RuleBlockOrder, RuleSetCOG, "Tiles are stored in the row-major order."},
+	{cog.RuleImageLayout, RuleSetCOG, "Layout of images can be read."},
+	{cog.RuleIncompatibleEdition, RuleSetCOG, "File has not been modified after its creation."},
+}
+
+// RuleSets returns names of all rule sets.
+func RuleSets() (names []string) {
+	names = make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Rules returns all rules of the rule sets.
+func Rules(ruleSets []string) (list []*Rule) {
+	for _, r := range rules {
+		for _, name := range ruleSets {
+			if r.RuleSet == name {
+				list = append(list, r)
+				break
+			}
+		}
+	}
+
+	return list
+}
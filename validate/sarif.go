@@ -0,0 +1,142 @@
+package validate
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+)
+
+// Version and schema of SARIF reports.
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFToolName is the name of the tool in SARIF reports.
+const SARIFToolName = "tiffer"
+
+// Minimal model of a SARIF log.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string       `json:"name"`
+	Rules []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   sarifMessage     `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation   `json:"physicalLocation"`
+	LogicalLocations []*sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// WriteSARIF writes the reports as a SARIF log. Every issue is a result
+// located in its file, the path of the directory of the issue is its logical
+// location. Levels of results are the severities of issues.
+func WriteSARIF(w io.Writer, reports []*Report) (err error) {
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:  SARIFToolName,
+				Rules: make([]*sarifRule, 0),
+			},
+		},
+		Results: make([]*sarifResult, 0),
+	}
+
+	ruleSets := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, r := range reports {
+		for _, ruleSet := range r.RuleSets {
+			if !seen[ruleSet] {
+				seen[ruleSet] = true
+				ruleSets = append(ruleSets, ruleSet)
+			}
+		}
+	}
+	for _, rule := range Rules(ruleSets) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{
+			ID:               rule.FullID(),
+			ShortDescription: sarifMessage{Text: rule.Description},
+		})
+	}
+
+	for _, r := range reports {
+		for _, issue := range r.Issues {
+			location := &sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: fileURI(r.File)},
+				},
+			}
+			if len(issue.Path) > 0 {
+				location.LogicalLocations = []*sarifLogicalLocation{{FullyQualifiedName: issue.Path}}
+			}
+
+			run.Results = append(run.Results, &sarifResult{
+				RuleID:    FullRuleID(issue.RuleSet, issue.Rule),
+				Level:     string(issue.Severity),
+				Message:   sarifMessage{Text: issue.Message},
+				Locations: []*sarifLocation{location},
+			})
+		}
+	}
+
+	log := &sarifLog{
+		Version: SARIFVersion,
+		Schema:  SARIFSchema,
+		Runs:    []*sarifRun{run},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// fileURI returns the relative URI of the file, as it is expected by code
+// scanning tools.
+func fileURI(filePath string) string {
+	u := url.URL{Path: filepath.ToSlash(filePath)}
+	return u.String()
+}
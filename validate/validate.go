@@ -0,0 +1,192 @@
+// Package validate checks TIFF files against sets of rules and writes reports
+// in formats understood by continuous integration systems.
+package validate
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vault-thirteen/TIFFer/models"
+	tiff "github.com/vault-thirteen/TIFFer/models/TIFF"
+	iors "github.com/vault-thirteen/auxie/ReaderSeeker"
+)
+
+const ErrUnknownRuleSet = "unknown rule set: %v"
+
+// Issue is a single problem found by the validator.
+type Issue struct {
+	models.Issue
+
+	// RuleSet is the name of the rule set of the violated rule.
+	RuleSet string `json:"ruleSet"`
+
+	// Rule is the identifier of the violated rule inside its rule set.
+	Rule string `json:"rule"`
+
+	// Path is the path of the directory or of the entry which the issue is
+	// related to, e.g. 'IFD0' or 'IFD0/Exif'. It is empty for issues
+	// related to the whole file.
+	Path string `json:"path,omitempty"`
+}
+
+// Report is the result of a validation of a file.
+type Report struct {
+	// File is the name of the file.
+	File string `json:"file"`
+
+	// RuleSets are names of the checked rule sets.
+	RuleSets []string `json:"ruleSets"`
+
+	// IsValid flag is set when no errors were found. Warnings do not make a
+	// file invalid.
+	IsValid bool `json:"isValid"`
+
+	// Issues are problems found by the validator.
+	Issues []*Issue `json:"issues"`
+}
+
+// checker checks a parsed file against the rules of a rule set.
+type checker func(c *context)
+
+// context is the state of a validation.
+type context struct {
+	report *Report
+	tiff   *tiff.TIFF
+	stream iors.ReaderSeeker
+	size   int64
+
+	// ruleSet is the rule set being checked.
+	ruleSet string
+}
+
+// ValidateFile checks the file against the rule sets. When no rule sets are
+// specified, the baseline rule set is used. An error is returned only when
+// the file can not be read, problems of the file itself are reported as
+// issues.
+func ValidateFile(filePath string, ruleSets []string) (r *Report, err error) {
+	var f *os.File
+	f, err = os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		derr := f.Close()
+		if (derr != nil) && (err == nil) {
+			err = derr
+		}
+	}()
+
+	r, err = Validate(f, ruleSets)
+	if err != nil {
+		return nil, err
+	}
+	r.File = filePath
+
+	return r, nil
+}
+
+// Validate checks the stream against the rule sets, see ValidateFile.
+//
+// The file must be parsed before other rules can be checked, so a file which
+// can not be parsed is reported by the rules of the baseline rule set even if
+// the baseline rule set is not requested.
+func Validate(stream iors.ReaderSeeker, ruleSets []string) (r *Report, err error) {
+	if len(ruleSets) == 0 {
+		ruleSets = []string{RuleSetBaseline}
+	}
+	for _, name := range ruleSets {
+		_, ok := checkers[name]
+		if !ok {
+			return nil, fmt.Errorf(ErrUnknownRuleSet, name)
+		}
+	}
+
+	c := &context{
+		report: &Report{
+			RuleSets: ruleSets,
+			Issues:   make([]*Issue, 0),
+		},
+		stream: stream,
+	}
+
+	c.size, err = stream.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	_, err = stream.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	c.tiff, err = tiff.New(stream)
+	if err != nil {
+		c.ruleSet = RuleSetBaseline
+		c.reportParseError(err)
+
+		// Invalid types of tags fail the parsing, but they are read from
+		// the stream and reported for every entry.
+		c.checkTagTypes()
+	} else {
+		for _, name := range ruleSets {
+			c.ruleSet = name
+			checkers[name](c)
+		}
+	}
+
+	c.report.IsValid = true
+	for _, issue := range c.report.Issues {
+		if issue.Severity == models.SeverityError {
+			c.report.IsValid = false
+			break
+		}
+	}
+
+	return c.report, nil
+}
+
+// addIssue adds an issue of the current rule set to the report.
+func (c *context) addIssue(severity models.Severity, rule string, path string, format string, args ...any) {
+	c.report.Issues = append(c.report.Issues, &Issue{
+		Issue: models.Issue{
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		},
+		RuleSet: c.ruleSet,
+		Rule:    rule,
+		Path:    path,
+	})
+}
+
+// Errors returns the number of errors in the report.
+func (r *Report) Errors() (n int) {
+	return r.count(models.SeverityError)
+}
+
+// Warnings returns the number of warnings in the report.
+func (r *Report) Warnings() (n int) {
+	return r.count(models.SeverityWarning)
+}
+
+// count returns the number of issues of the severity.
+func (r *Report) count(severity models.Severity) (n int) {
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+
+	return n
+}
+
+// IssuesOf returns issues of the rule set.
+func (r *Report) IssuesOf(ruleSet string) (issues []*Issue) {
+	for _, issue := range r.Issues {
+		if issue.RuleSet == ruleSet {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}